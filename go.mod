@@ -6,6 +6,7 @@ require (
 	github.com/gin-contrib/cors v1.3.1
 	github.com/gin-gonic/gin v1.7.1
 	github.com/go-sql-driver/mysql v1.6.0
+	github.com/gorilla/websocket v1.4.2
 	github.com/lmurature/golang-restclient v0.0.0-20191104170228-162ed620df66
 	github.com/onatm/clockwerk v0.0.0-20190910145222-354c9bd6cf28
 	github.com/sirupsen/logrus v1.8.1
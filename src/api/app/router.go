@@ -1,20 +1,30 @@
 package app
 
 import (
+	"fmt"
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
+	"github.com/lmurature/melist-api/src/api/clients/database"
 	"github.com/lmurature/melist-api/src/api/config"
+	tokens_service "github.com/lmurature/melist-api/src/api/services/tokens"
+	_ "github.com/lmurature/melist-api/src/api/storage"
+	http_utils "github.com/lmurature/melist-api/src/api/utils/http"
 	"github.com/lmurature/melist-api/src/jobs"
 	"github.com/onatm/clockwerk"
+	"github.com/sirupsen/logrus"
 	"time"
 )
 
 var (
 	router *gin.Engine
+
+	// metricsRouter serves the operational metrics on config.MetricsAddr, which is not exposed like the API.
+	metricsRouter *gin.Engine
 )
 
 func init() {
-	router = gin.Default()
+	router = gin.New()
+	router.Use(gin.LoggerWithFormatter(logFormatter), gin.Recovery())
 
 	corsConfig := cors.Config{
 		AllowMethods:     []string{"GET", "POST", "PUT", "PATCH", "DELETE", "HEAD"},
@@ -25,15 +35,54 @@ func init() {
 	}
 
 	router.Use(cors.New(corsConfig))
+
+	metricsRouter = gin.New()
+	metricsRouter.Use(gin.Recovery())
 }
 
 func StartApp() {
+	// config only defaults the key in development, and tokens cannot be saved without it
+	if config.TokenEncryptionKey == "" {
+		panic("TOKEN_ENCRYPTION_KEY must be set outside development")
+	}
+	if err := database.RunMigrations(config.DbMigrations); err != nil {
+		panic(err)
+	}
+	if err := tokens_service.TokensService.EncryptStoredTokens(); err != nil {
+		logrus.Error("error when trying to encrypt stored tokens", err)
+	}
+
 	mapUrls()
 
 	jobs.ItemsJobs.Run()
 	c := clockwerk.New()
 	c.Every(10 * time.Hour).Do(jobs.ItemsJobs)
+	c.Every(24 * time.Hour).Do(jobs.ListsJobs)
+	c.Every(time.Minute).Do(jobs.MailJobs)
+	c.Every(24 * time.Hour).Do(jobs.SessionsJobs)
+	c.Every(5 * time.Minute).Do(jobs.TokensJobs)
 	c.Start()
 
+	if config.MetricsAddr != "off" {
+		go func() {
+			if err := metricsRouter.Run(config.MetricsAddr); err != nil {
+				logrus.Error("error when trying to serve metrics", err)
+			}
+		}()
+	}
+
 	router.Run(config.ApiPort)
 }
+
+// logFormatter is the format of gin's default logger without the credentials stream clients send in the url.
+func logFormatter(param gin.LogFormatterParams) string {
+	return fmt.Sprintf("[GIN] %v | %3d | %13v | %15s | %-7s %#v\n%s",
+		param.TimeStamp.Format("2006/01/02 - 15:04:05"),
+		param.StatusCode,
+		param.Latency,
+		param.ClientIP,
+		param.Method,
+		http_utils.RedactPath(param.Path),
+		param.ErrorMessage,
+	)
+}
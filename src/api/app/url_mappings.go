@@ -1,9 +1,12 @@
 package app
 
 import (
+	alerts_controller "github.com/lmurature/melist-api/src/api/controllers/alerts"
 	auth_controller "github.com/lmurature/melist-api/src/api/controllers/auth"
 	items_controller "github.com/lmurature/melist-api/src/api/controllers/items"
 	lists_controller "github.com/lmurature/melist-api/src/api/controllers/lists"
+	metrics_controller "github.com/lmurature/melist-api/src/api/controllers/metrics"
+	notifications_controller "github.com/lmurature/melist-api/src/api/controllers/notifications"
 	"github.com/lmurature/melist-api/src/api/controllers/ping"
 	users_controller "github.com/lmurature/melist-api/src/api/controllers/users"
 	"github.com/lmurature/melist-api/src/api/middlewares"
@@ -12,11 +15,19 @@ import (
 func mapUrls() {
 	router.GET("/ping", ping.Ping)
 
+	// Metrics are only served on the internal listener
+	metricsRouter.GET("/metrics/cache", metrics_controller.GetCacheStats)
+	metricsRouter.GET("/metrics/outbound", metrics_controller.GetOutboundStats)
+
 	// Authentication management
+	router.POST("/api/users/auth/login", auth_controller.StartLogin)
 	router.POST("/api/users/auth/generate_token", auth_controller.AuthenticateUser)
 	router.POST("/api/users/auth/refresh_token", auth_controller.RefreshAuthentication)
+	router.POST("/api/users/auth/logout", middlewares.Authenticate, auth_controller.Logout)
 
 	router.GET("/api/users/me", middlewares.Authenticate, users_controller.GetUserMe)
+	router.GET("/api/users/me/mail_preferences", middlewares.Authenticate, users_controller.GetMailPreferences)
+	router.PUT("/api/users/me/mail_preferences", middlewares.Authenticate, users_controller.UpdateMailPreferences)
 	router.GET("/api/users/search", middlewares.Authenticate, users_controller.SearchUsers)
 	router.POST("/api/users/invite", middlewares.Authenticate, users_controller.InviteUser)
 	router.GET("/api/users/invite/pending", middlewares.Authenticate, users_controller.GetPendingUsersByList)
@@ -32,6 +43,8 @@ func mapUrls() {
 	router.GET("/api/lists/get/:list_id", middlewares.Authenticate, lists_controller.GetListById)
 	router.GET("/api/lists/get/:list_id/shares", middlewares.Authenticate, lists_controller.GetListShareConfigs)
 	router.PUT("/api/lists/update/:list_id", middlewares.Authenticate, lists_controller.UpdateList)
+	router.DELETE("/api/lists/:list_id", middlewares.Authenticate, lists_controller.DeleteList)
+	router.PUT("/api/lists/restore/:list_id", middlewares.Authenticate, lists_controller.RestoreList)
 	router.PUT("/api/lists/access/:list_id", middlewares.Authenticate, lists_controller.GiveUsersAccessToList)
 	router.DELETE("/api/lists/access/:list_id", middlewares.Authenticate, lists_controller.RevokeUserAccessToList)
 	router.PUT("/api/lists/favorite/:list_id", middlewares.Authenticate, lists_controller.SetListFavorite)
@@ -40,14 +53,29 @@ func mapUrls() {
 	router.GET("/api/lists/get/all_owned", middlewares.Authenticate, lists_controller.GetMyLists)
 	router.GET("/api/lists/get/all_shared", middlewares.Authenticate, lists_controller.GetMySharedLists)
 	router.GET("/api/lists/get/favorites", middlewares.Authenticate, lists_controller.GetFavoriteLists)
+	router.GET("/api/lists/get/trash", middlewares.Authenticate, lists_controller.GetTrashedLists)
 	router.GET("/api/lists/get/:list_id/permissions", middlewares.Authenticate, lists_controller.GetMyPermissions)
 	router.GET("/api/lists/get/:list_id/notifications", middlewares.Authenticate, lists_controller.GetListNotifications)
 
 	// List items management
 	router.POST("/api/lists/:list_id/items/:item_id", middlewares.Authenticate, lists_controller.AddItemsToList)
 	router.GET("/api/lists/:list_id/items", middlewares.Authenticate, lists_controller.GetItems)
+	router.PUT("/api/lists/:list_id/items/:item_id", middlewares.Authenticate, lists_controller.UpdateListItem)
 	router.DELETE("/api/lists/:list_id/items/:item_id", middlewares.Authenticate, lists_controller.DeleteItem)
 	router.PUT("/api/lists/:list_id/check/:item_id", middlewares.Authenticate, lists_controller.CheckItem)
 	router.PUT("/api/lists/:list_id/uncheck/:item_id", middlewares.Authenticate, lists_controller.UncheckItem)
 	router.GET("/api/lists/:list_id/status/:item_id", middlewares.Authenticate, lists_controller.GetListItemStatus)
+
+	// Alert rules
+	router.POST("/api/lists/:list_id/alerts", middlewares.Authenticate, alerts_controller.CreateRule)
+	router.GET("/api/lists/:list_id/alerts", middlewares.Authenticate, alerts_controller.GetListRules)
+	router.GET("/api/alerts", middlewares.Authenticate, alerts_controller.GetMyRules)
+	router.DELETE("/api/alerts/:rule_id", middlewares.Authenticate, alerts_controller.DeleteRule)
+
+	// Notifications inbox
+	router.GET("/api/notifications", middlewares.Authenticate, notifications_controller.GetInbox)
+	router.GET("/api/notifications/stream", middlewares.AuthenticateStream, notifications_controller.StreamNotifications)
+	router.GET("/api/notifications/ws", middlewares.AuthenticateStream, notifications_controller.StreamNotificationsWebSocket)
+	router.PUT("/api/notifications/seen", middlewares.Authenticate, notifications_controller.MarkAllAsSeen)
+	router.PUT("/api/notifications/:notification_id/seen", middlewares.Authenticate, notifications_controller.MarkAsSeen)
 }
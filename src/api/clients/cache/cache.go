@@ -0,0 +1,165 @@
+package cache
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/lmurature/melist-api/src/api/domain/apierrors"
+	"github.com/sirupsen/logrus"
+)
+
+// Entry is a cached value encoded as json, so that it can live in a store shared by several instances.
+type Entry struct {
+	Value    []byte    `json:"value"`
+	StoredAt time.Time `json:"stored_at"`
+}
+
+// Store keeps cache entries. Implementations must be safe for concurrent use and may drop entries at any time,
+// for example when they reach a size limit; ttl is how long the entry is worth keeping.
+type Store interface {
+	Get(key string) (*Entry, bool)
+	Set(key string, entry Entry, ttl time.Duration)
+	Delete(key string)
+	Flush()
+}
+
+// Policy is how long the values of one kind of resource are fresh, and for how long after that a stale value is
+// still served while it is refreshed in the background.
+type Policy struct {
+	Name     string
+	TTL      time.Duration
+	StaleTTL time.Duration
+}
+
+// Stats are the counters of one policy.
+type Stats struct {
+	Hits      int64 `json:"hits"`
+	StaleHits int64 `json:"stale_hits"`
+	Misses    int64 `json:"misses"`
+	Coalesced int64 `json:"coalesced"`
+	Errors    int64 `json:"errors"`
+}
+
+type LoadFunc func() (interface{}, apierrors.ApiError)
+
+// Cache serves values from a Store and loads the missing ones once no matter how many callers ask for them at the
+// same time. Only successful loads are cached.
+type Cache struct {
+	store Store
+	calls *group
+	now   func() time.Time
+
+	mu    sync.Mutex
+	stats map[string]*Stats
+}
+
+func New(store Store) *Cache {
+	return &Cache{
+		store: store,
+		calls: &group{calls: make(map[string]*call)},
+		now:   time.Now,
+		stats: make(map[string]*Stats),
+	}
+}
+
+// Fetch decodes the value cached under key into dest, loading it with load when it is missing or expired.
+func (c *Cache) Fetch(key string, policy Policy, dest interface{}, load LoadFunc) apierrors.ApiError {
+	if entry, ok := c.store.Get(key); ok {
+		age := c.now().Sub(entry.StoredAt)
+		if age < policy.TTL {
+			c.count(policy, func(s *Stats) { s.Hits++ })
+			return decode(entry.Value, dest)
+		}
+		if age < policy.TTL+policy.StaleTTL {
+			c.count(policy, func(s *Stats) { s.StaleHits++ })
+			if !c.calls.inFlight(key) {
+				go c.load(key, policy, load)
+			}
+			return decode(entry.Value, dest)
+		}
+	}
+
+	c.count(policy, func(s *Stats) { s.Misses++ })
+	value, err, shared := c.calls.do(key, func() ([]byte, apierrors.ApiError) {
+		return c.loadAndStore(key, policy, load)
+	})
+	if shared {
+		c.count(policy, func(s *Stats) { s.Coalesced++ })
+	}
+	if err != nil {
+		return err
+	}
+
+	return decode(value, dest)
+}
+
+// Stats returns a copy of the counters of every policy used so far.
+func (c *Cache) Stats() map[string]Stats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	result := make(map[string]Stats, len(c.stats))
+	for name, s := range c.stats {
+		result[name] = *s
+	}
+	return result
+}
+
+func (c *Cache) Delete(key string) {
+	c.store.Delete(key)
+}
+
+// Flush empties the store and resets the counters.
+func (c *Cache) Flush() {
+	c.store.Flush()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.stats = make(map[string]*Stats)
+}
+
+// load refreshes a stale entry. Errors are only logged because the caller has already been served.
+func (c *Cache) load(key string, policy Policy, load LoadFunc) {
+	if _, err, _ := c.calls.do(key, func() ([]byte, apierrors.ApiError) {
+		return c.loadAndStore(key, policy, load)
+	}); err != nil {
+		logrus.Warn(fmt.Sprintf("error refreshing stale cache entry %s", key), err)
+	}
+}
+
+func (c *Cache) loadAndStore(key string, policy Policy, load LoadFunc) ([]byte, apierrors.ApiError) {
+	value, err := load()
+	if err != nil {
+		c.count(policy, func(s *Stats) { s.Errors++ })
+		return nil, err
+	}
+
+	encoded, marshalErr := json.Marshal(value)
+	if marshalErr != nil {
+		return nil, apierrors.NewInternalServerApiError(fmt.Sprintf("error encoding cache entry %s", key), marshalErr)
+	}
+
+	c.store.Set(key, Entry{Value: encoded, StoredAt: c.now()}, policy.TTL+policy.StaleTTL)
+	return encoded, nil
+}
+
+func (c *Cache) count(policy Policy, update func(s *Stats)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	s, ok := c.stats[policy.Name]
+	if !ok {
+		s = &Stats{}
+		c.stats[policy.Name] = s
+	}
+	update(s)
+}
+
+func decode(value []byte, dest interface{}) apierrors.ApiError {
+	if err := json.Unmarshal(value, dest); err != nil {
+		return apierrors.NewInternalServerApiError("error decoding cache entry", err)
+	}
+	return nil
+}
@@ -0,0 +1,182 @@
+package cache
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/lmurature/melist-api/src/api/domain/apierrors"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	testPolicy = Policy{Name: "test", TTL: time.Minute, StaleTTL: time.Minute}
+)
+
+type clock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *clock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *clock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+func newTestCache() (*Cache, *clock) {
+	clk := &clock{now: time.Date(2021, 5, 1, 12, 0, 0, 0, time.UTC)}
+	store := NewMemoryStore(0, 0)
+	store.now = clk.Now
+	c := New(store)
+	c.now = clk.Now
+	return c, clk
+}
+
+func TestFetchServesFreshValuesFromTheStore(t *testing.T) {
+	c, clk := newTestCache()
+	loads := 0
+	load := func() (interface{}, apierrors.ApiError) {
+		loads++
+		return map[string]int{"loads": loads}, nil
+	}
+
+	var result map[string]int
+	assert.Nil(t, c.Fetch("key", testPolicy, &result, load))
+	assert.EqualValues(t, 1, result["loads"])
+
+	clk.Advance(30 * time.Second)
+	assert.Nil(t, c.Fetch("key", testPolicy, &result, load))
+	assert.EqualValues(t, 1, result["loads"])
+	assert.EqualValues(t, 1, loads)
+
+	clk.Advance(3 * time.Minute)
+	assert.Nil(t, c.Fetch("key", testPolicy, &result, load))
+	assert.EqualValues(t, 2, result["loads"])
+
+	assert.EqualValues(t, Stats{Hits: 1, Misses: 2}, c.Stats()["test"])
+}
+
+func TestFetchServesStaleValuesWhileRevalidating(t *testing.T) {
+	c, clk := newTestCache()
+	var loads int32
+	refreshed := make(chan bool, 1)
+	load := func() (interface{}, apierrors.ApiError) {
+		n := atomic.AddInt32(&loads, 1)
+		if n > 1 {
+			defer func() { refreshed <- true }()
+		}
+		return n, nil
+	}
+
+	var result int32
+	assert.Nil(t, c.Fetch("key", testPolicy, &result, load))
+	assert.EqualValues(t, 1, result)
+
+	clk.Advance(90 * time.Second)
+	assert.Nil(t, c.Fetch("key", testPolicy, &result, load))
+	assert.EqualValues(t, 1, result)
+
+	select {
+	case <-refreshed:
+	case <-time.After(time.Second):
+		t.Fatal("stale entry was not refreshed")
+	}
+	assert.Eventually(t, func() bool { return !c.calls.inFlight("key") }, time.Second, time.Millisecond)
+
+	assert.Nil(t, c.Fetch("key", testPolicy, &result, load))
+	assert.EqualValues(t, 2, result)
+	assert.EqualValues(t, Stats{Hits: 1, StaleHits: 1, Misses: 1}, c.Stats()["test"])
+}
+
+func TestFetchCoalescesConcurrentLoads(t *testing.T) {
+	c, _ := newTestCache()
+	var loads int32
+	release := make(chan bool)
+	load := func() (interface{}, apierrors.ApiError) {
+		atomic.AddInt32(&loads, 1)
+		<-release
+		return "value", nil
+	}
+
+	var wg sync.WaitGroup
+	results := make([]string, 10)
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			assert.Nil(t, c.Fetch("key", testPolicy, &results[i], load))
+		}(i)
+	}
+
+	assert.Eventually(t, func() bool { return c.Stats()["test"].Misses == 10 }, time.Second, time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	assert.EqualValues(t, 1, atomic.LoadInt32(&loads))
+	for _, r := range results {
+		assert.EqualValues(t, "value", r)
+	}
+	assert.EqualValues(t, 9, c.Stats()["test"].Coalesced)
+}
+
+func TestFetchDoesNotCacheErrors(t *testing.T) {
+	c, _ := newTestCache()
+	fail := true
+	load := func() (interface{}, apierrors.ApiError) {
+		if fail {
+			return nil, apierrors.NewInternalServerApiError("meli is down", errors.New("timeout"))
+		}
+		return "value", nil
+	}
+
+	var result string
+	err := c.Fetch("key", testPolicy, &result, load)
+	assert.NotNil(t, err)
+	assert.EqualValues(t, "meli is down", err.Message())
+
+	fail = false
+	assert.Nil(t, c.Fetch("key", testPolicy, &result, load))
+	assert.EqualValues(t, "value", result)
+	assert.EqualValues(t, Stats{Misses: 2, Errors: 1}, c.Stats()["test"])
+}
+
+func TestMemoryStoreEvictsLeastRecentlyUsed(t *testing.T) {
+	store := NewMemoryStore(2, 10)
+
+	store.Set("a", Entry{Value: []byte("1")}, time.Minute)
+	store.Set("b", Entry{Value: []byte("2")}, time.Minute)
+	_, ok := store.Get("a")
+	assert.True(t, ok)
+
+	store.Set("c", Entry{Value: []byte("3")}, time.Minute)
+	_, ok = store.Get("b")
+	assert.False(t, ok)
+	_, ok = store.Get("a")
+	assert.True(t, ok)
+
+	store.Set("d", Entry{Value: []byte("12345678")}, time.Minute)
+	entries, bytes := store.Len()
+	assert.EqualValues(t, 2, entries)
+	assert.EqualValues(t, 9, bytes)
+	assert.EqualValues(t, 2, store.Evictions())
+	_, ok = store.Get("c")
+	assert.False(t, ok)
+
+	store.Set("b", Entry{Value: []byte("123")}, time.Minute)
+	entries, bytes = store.Len()
+	assert.EqualValues(t, 1, entries)
+	assert.EqualValues(t, 3, bytes)
+
+	store.Set("e", Entry{Value: []byte("12345678901")}, time.Minute)
+	_, ok = store.Get("e")
+	assert.False(t, ok)
+}
@@ -0,0 +1,48 @@
+package cache
+
+import (
+	"sync"
+
+	"github.com/lmurature/melist-api/src/api/domain/apierrors"
+)
+
+type call struct {
+	wg    sync.WaitGroup
+	value []byte
+	err   apierrors.ApiError
+}
+
+// group runs at most one load per key at a time; callers arriving while it runs wait for its result.
+type group struct {
+	mu    sync.Mutex
+	calls map[string]*call
+}
+
+func (g *group) do(key string, fn func() ([]byte, apierrors.ApiError)) ([]byte, apierrors.ApiError, bool) {
+	g.mu.Lock()
+	if c, ok := g.calls[key]; ok {
+		g.mu.Unlock()
+		c.wg.Wait()
+		return c.value, c.err, true
+	}
+	c := &call{}
+	c.wg.Add(1)
+	g.calls[key] = c
+	g.mu.Unlock()
+
+	c.value, c.err = fn()
+	c.wg.Done()
+
+	g.mu.Lock()
+	delete(g.calls, key)
+	g.mu.Unlock()
+
+	return c.value, c.err, false
+}
+
+func (g *group) inFlight(key string) bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	_, ok := g.calls[key]
+	return ok
+}
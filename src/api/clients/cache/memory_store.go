@@ -0,0 +1,127 @@
+package cache
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+type memoryItem struct {
+	key       string
+	entry     Entry
+	expiresAt time.Time
+}
+
+// MemoryStore is a Store local to the process that evicts the least recently used entries once it holds more than
+// maxEntries entries or maxBytes bytes of values. A zero limit means no limit.
+type MemoryStore struct {
+	mu         sync.Mutex
+	maxEntries int
+	maxBytes   int
+	bytes      int
+	evictions  int64
+	order      *list.List
+	items      map[string]*list.Element
+	now        func() time.Time
+}
+
+func NewMemoryStore(maxEntries int, maxBytes int) *MemoryStore {
+	return &MemoryStore{
+		maxEntries: maxEntries,
+		maxBytes:   maxBytes,
+		order:      list.New(),
+		items:      make(map[string]*list.Element),
+		now:        time.Now,
+	}
+}
+
+func (s *MemoryStore) Get(key string) (*Entry, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e, ok := s.items[key]
+	if !ok {
+		return nil, false
+	}
+	item := e.Value.(*memoryItem)
+	if !s.now().Before(item.expiresAt) {
+		s.removeLocked(e)
+		return nil, false
+	}
+	s.order.MoveToFront(e)
+
+	entry := item.entry
+	return &entry, true
+}
+
+func (s *MemoryStore) Set(key string, entry Entry, ttl time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if e, ok := s.items[key]; ok {
+		s.removeLocked(e)
+	}
+	if s.maxBytes > 0 && len(entry.Value) > s.maxBytes {
+		return
+	}
+
+	s.items[key] = s.order.PushFront(&memoryItem{key: key, entry: entry, expiresAt: s.now().Add(ttl)})
+	s.bytes += len(entry.Value)
+
+	for (s.maxEntries > 0 && s.order.Len() > s.maxEntries) || (s.maxBytes > 0 && s.bytes > s.maxBytes) {
+		s.removeLocked(s.order.Back())
+		s.evictions++
+	}
+}
+
+func (s *MemoryStore) Delete(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if e, ok := s.items[key]; ok {
+		s.removeLocked(e)
+	}
+}
+
+func (s *MemoryStore) Flush() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.order.Init()
+	s.items = make(map[string]*list.Element)
+	s.bytes = 0
+}
+
+// Len returns how many entries the store holds and how many bytes their values take.
+func (s *MemoryStore) Len() (int, int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.order.Len(), s.bytes
+}
+
+// Evictions returns how many entries were dropped to stay within the limits.
+func (s *MemoryStore) Evictions() int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.evictions
+}
+
+// removeLocked drops the entry of element e. The caller must hold mu.
+func (s *MemoryStore) removeLocked(e *list.Element) {
+	item := s.order.Remove(e).(*memoryItem)
+	delete(s.items, item.key)
+	s.bytes -= len(item.entry.Value)
+}
+
+// NopStore keeps nothing, which turns a Cache into a request coalescer.
+type NopStore struct{}
+
+func (NopStore) Get(key string) (*Entry, bool) {
+	return nil, false
+}
+
+func (NopStore) Set(key string, entry Entry, ttl time.Duration) {}
+
+func (NopStore) Delete(key string) {}
+
+func (NopStore) Flush() {}
@@ -0,0 +1,116 @@
+package migrations
+
+func init() {
+	register(Migration{
+		Version: 1,
+		Name:    "initial_schema",
+		Up: []string{
+			"CREATE TABLE `user` (" +
+				"`id` bigint unsigned NOT NULL, " +
+				"`first_name` varchar(100) DEFAULT NULL, " +
+				"`last_name` varchar(100) DEFAULT NULL, " +
+				"`nickname` varchar(100) DEFAULT NULL, " +
+				"`refresh_token` varchar(256) DEFAULT NULL, " +
+				"`access_token` varchar(256) DEFAULT NULL, " +
+				"`date_created` datetime DEFAULT NULL, " +
+				"`email` varchar(64) DEFAULT NULL, " +
+				"PRIMARY KEY (`id`)" +
+				") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci;",
+
+			"CREATE TABLE `item` (" +
+				"`item_id` varchar(64) NOT NULL, " +
+				"PRIMARY KEY (`item_id`)" +
+				") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci;",
+
+			"CREATE TABLE `list` (" +
+				"`id` bigint unsigned NOT NULL AUTO_INCREMENT, " +
+				"`owner_id` bigint unsigned NOT NULL, " +
+				"`title` varchar(100) DEFAULT NULL, " +
+				"`description` text, " +
+				"`privacy` varchar(64) NOT NULL, " +
+				"`date_created` datetime DEFAULT NULL, " +
+				"PRIMARY KEY (`id`), " +
+				"KEY `list_FK` (`owner_id`), " +
+				"CONSTRAINT `list_FK` FOREIGN KEY (`owner_id`) REFERENCES `user` (`id`)" +
+				") ENGINE=InnoDB AUTO_INCREMENT=75618245 DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci;",
+
+			"CREATE TABLE `share_config` (" +
+				"`user_id` bigint unsigned NOT NULL, " +
+				"`list_id` bigint unsigned NOT NULL, " +
+				"`type` varchar(64) NOT NULL, " +
+				"KEY `share_config_FK` (`user_id`), " +
+				"KEY `share_config_FK_1` (`list_id`), " +
+				"CONSTRAINT `share_config_FK` FOREIGN KEY (`user_id`) REFERENCES `user` (`id`), " +
+				"CONSTRAINT `share_config_FK_1` FOREIGN KEY (`list_id`) REFERENCES `list` (`id`)" +
+				") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci;",
+
+			"CREATE TABLE `user_favorite_list` (" +
+				"`user_id` bigint unsigned NOT NULL, " +
+				"`list_id` bigint unsigned NOT NULL, " +
+				"KEY `user_favorite_list_FK` (`user_id`), " +
+				"KEY `user_favorite_list_FK_1` (`list_id`), " +
+				"CONSTRAINT `user_favorite_list_FK` FOREIGN KEY (`user_id`) REFERENCES `user` (`id`), " +
+				"CONSTRAINT `user_favorite_list_FK_1` FOREIGN KEY (`list_id`) REFERENCES `list` (`id`)" +
+				") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci;",
+
+			"CREATE TABLE `list_item` (" +
+				"`list_id` bigint unsigned NOT NULL, " +
+				"`item_id` varchar(64) NOT NULL, " +
+				"`status` varchar(100) DEFAULT NULL, " +
+				"`variation_external_id` bigint unsigned DEFAULT NULL, " +
+				"`user_id` bigint unsigned DEFAULT NULL, " +
+				"KEY `list_item_FK` (`list_id`), " +
+				"KEY `list_item_FK_1` (`item_id`), " +
+				"KEY `list_item_FK_2` (`user_id`), " +
+				"CONSTRAINT `list_item_FK` FOREIGN KEY (`list_id`) REFERENCES `list` (`id`), " +
+				"CONSTRAINT `list_item_FK_1` FOREIGN KEY (`item_id`) REFERENCES `item` (`item_id`), " +
+				"CONSTRAINT `list_item_FK_2` FOREIGN KEY (`user_id`) REFERENCES `user` (`id`)" +
+				") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci;",
+
+			"CREATE TABLE `item_history` (" +
+				"`id` bigint unsigned NOT NULL AUTO_INCREMENT, " +
+				"`item_id` varchar(64) NOT NULL, " +
+				"`price` float DEFAULT NULL, " +
+				"`quantity` int DEFAULT NULL, " +
+				"`status` varchar(100) DEFAULT NULL, " +
+				"`has_deal` tinyint(1) DEFAULT NULL, " +
+				"`date_fetched` datetime NOT NULL, " +
+				"`reviews_quantity` int DEFAULT NULL, " +
+				"PRIMARY KEY (`id`), " +
+				"KEY `item_history_FK` (`item_id`), " +
+				"CONSTRAINT `item_history_FK` FOREIGN KEY (`item_id`) REFERENCES `item` (`item_id`)" +
+				") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci;",
+
+			"CREATE TABLE `future_colaborator` (" +
+				"`list_id` bigint unsigned NOT NULL, " +
+				"`user_email` varchar(100) NOT NULL, " +
+				"`share_type` varchar(100) DEFAULT NULL, " +
+				"KEY `future_colaborator_FK` (`list_id`), " +
+				"CONSTRAINT `future_colaborator_FK` FOREIGN KEY (`list_id`) REFERENCES `list` (`id`)" +
+				") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci;",
+
+			"CREATE TABLE `list_notifications` (" +
+				"`id` bigint unsigned NOT NULL AUTO_INCREMENT, " +
+				"`list_id` bigint unsigned NOT NULL, " +
+				"`message` text NOT NULL, " +
+				"`timestamp` datetime NOT NULL, " +
+				"`seen` tinyint(1) NOT NULL DEFAULT 0, " +
+				"`permalink` varchar(256) DEFAULT NULL, " +
+				"PRIMARY KEY (`id`), " +
+				"KEY `list_notifications_FK` (`list_id`), " +
+				"CONSTRAINT `list_notifications_FK` FOREIGN KEY (`list_id`) REFERENCES `list` (`id`)" +
+				") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci;",
+		},
+		Down: []string{
+			"DROP TABLE `list_notifications`;",
+			"DROP TABLE `future_colaborator`;",
+			"DROP TABLE `item_history`;",
+			"DROP TABLE `list_item`;",
+			"DROP TABLE `user_favorite_list`;",
+			"DROP TABLE `share_config`;",
+			"DROP TABLE `list`;",
+			"DROP TABLE `item`;",
+			"DROP TABLE `user`;",
+		},
+	})
+}
@@ -0,0 +1,16 @@
+package migrations
+
+func init() {
+	register(Migration{
+		Version: 2,
+		Name:    "list_trash",
+		Up: []string{
+			"ALTER TABLE `list` ADD COLUMN `date_deleted` datetime DEFAULT NULL;",
+			"CREATE INDEX `list_date_deleted_idx` ON `list` (`date_deleted`);",
+		},
+		Down: []string{
+			"DROP INDEX `list_date_deleted_idx` ON `list`;",
+			"ALTER TABLE `list` DROP COLUMN `date_deleted`;",
+		},
+	})
+}
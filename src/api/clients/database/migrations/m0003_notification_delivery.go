@@ -0,0 +1,23 @@
+package migrations
+
+func init() {
+	register(Migration{
+		Version: 3,
+		Name:    "notification_delivery",
+		Up: []string{
+			"CREATE TABLE `notification_delivery` (" +
+				"`notification_id` bigint unsigned NOT NULL, " +
+				"`user_id` bigint unsigned NOT NULL, " +
+				"`seen` tinyint(1) NOT NULL DEFAULT 0, " +
+				"`date_seen` datetime DEFAULT NULL, " +
+				"PRIMARY KEY (`notification_id`, `user_id`), " +
+				"KEY `notification_delivery_user_idx` (`user_id`, `seen`), " +
+				"CONSTRAINT `notification_delivery_FK` FOREIGN KEY (`notification_id`) REFERENCES `list_notifications` (`id`), " +
+				"CONSTRAINT `notification_delivery_FK_1` FOREIGN KEY (`user_id`) REFERENCES `user` (`id`)" +
+				") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci;",
+		},
+		Down: []string{
+			"DROP TABLE `notification_delivery`;",
+		},
+	})
+}
@@ -0,0 +1,29 @@
+package migrations
+
+func init() {
+	register(Migration{
+		Version: 4,
+		Name:    "mail_delivery",
+		Up: []string{
+			"ALTER TABLE `list_notifications` ADD COLUMN `type` varchar(32) NOT NULL DEFAULT '';",
+			"ALTER TABLE `notification_delivery` ADD COLUMN `date_mailed` datetime DEFAULT NULL;",
+			// notifications delivered before email existed must not be mailed on the first run
+			"UPDATE `notification_delivery` SET `date_mailed`=UTC_TIMESTAMP();",
+			"CREATE INDEX `notification_delivery_mail_idx` ON `notification_delivery` (`date_mailed`, `seen`);",
+			"CREATE TABLE `mail_preferences` (" +
+				"`user_id` bigint unsigned NOT NULL, " +
+				"`frequency` varchar(16) NOT NULL, " +
+				"`disabled_types` varchar(512) NOT NULL DEFAULT '', " +
+				"`date_last_digest` datetime DEFAULT NULL, " +
+				"PRIMARY KEY (`user_id`), " +
+				"CONSTRAINT `mail_preferences_FK` FOREIGN KEY (`user_id`) REFERENCES `user` (`id`)" +
+				") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci;",
+		},
+		Down: []string{
+			"DROP TABLE `mail_preferences`;",
+			"DROP INDEX `notification_delivery_mail_idx` ON `notification_delivery`;",
+			"ALTER TABLE `notification_delivery` DROP COLUMN `date_mailed`;",
+			"ALTER TABLE `list_notifications` DROP COLUMN `type`;",
+		},
+	})
+}
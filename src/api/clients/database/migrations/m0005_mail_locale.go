@@ -0,0 +1,14 @@
+package migrations
+
+func init() {
+	register(Migration{
+		Version: 5,
+		Name:    "mail_locale",
+		Up: []string{
+			"ALTER TABLE `mail_preferences` ADD COLUMN `locale` varchar(8) NOT NULL DEFAULT 'es-AR';",
+		},
+		Down: []string{
+			"ALTER TABLE `mail_preferences` DROP COLUMN `locale`;",
+		},
+	})
+}
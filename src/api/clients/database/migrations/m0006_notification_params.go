@@ -0,0 +1,14 @@
+package migrations
+
+func init() {
+	register(Migration{
+		Version: 6,
+		Name:    "notification_params",
+		Up: []string{
+			"ALTER TABLE `list_notifications` ADD COLUMN `params` json DEFAULT NULL;",
+		},
+		Down: []string{
+			"ALTER TABLE `list_notifications` DROP COLUMN `params`;",
+		},
+	})
+}
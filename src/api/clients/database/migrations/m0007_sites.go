@@ -0,0 +1,18 @@
+package migrations
+
+func init() {
+	register(Migration{
+		Version: 7,
+		Name:    "sites",
+		Up: []string{
+			"ALTER TABLE `user` ADD COLUMN `site_id` varchar(8) NOT NULL DEFAULT 'MLA';",
+			"ALTER TABLE `list` ADD COLUMN `site_id` varchar(8) NOT NULL DEFAULT 'MLA';",
+			"ALTER TABLE `item_history` ADD COLUMN `currency_id` varchar(8) DEFAULT NULL;",
+		},
+		Down: []string{
+			"ALTER TABLE `item_history` DROP COLUMN `currency_id`;",
+			"ALTER TABLE `list` DROP COLUMN `site_id`;",
+			"ALTER TABLE `user` DROP COLUMN `site_id`;",
+		},
+	})
+}
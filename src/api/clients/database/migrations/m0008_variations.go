@@ -0,0 +1,19 @@
+package migrations
+
+func init() {
+	register(Migration{
+		Version: 8,
+		Name:    "variations",
+		Up: []string{
+			"UPDATE `list_item` SET `variation_external_id`=0 WHERE `variation_external_id` IS NULL;",
+			"ALTER TABLE `list_item` MODIFY COLUMN `variation_external_id` bigint unsigned NOT NULL DEFAULT 0;",
+			"ALTER TABLE `item_history` ADD COLUMN `variation_id` bigint unsigned NOT NULL DEFAULT 0;",
+			"CREATE INDEX `item_history_item_variation` ON `item_history` (`item_id`, `variation_id`, `date_fetched`);",
+		},
+		Down: []string{
+			"DROP INDEX `item_history_item_variation` ON `item_history`;",
+			"ALTER TABLE `item_history` DROP COLUMN `variation_id`;",
+			"ALTER TABLE `list_item` MODIFY COLUMN `variation_external_id` bigint unsigned DEFAULT NULL;",
+		},
+	})
+}
@@ -0,0 +1,20 @@
+package migrations
+
+func init() {
+	register(Migration{
+		Version: 9,
+		Name:    "list_item_details",
+		Up: []string{
+			"ALTER TABLE `list_item` ADD COLUMN `quantity` int unsigned NOT NULL DEFAULT 1;",
+			"ALTER TABLE `list_item` ADD COLUMN `note` varchar(500) NOT NULL DEFAULT '';",
+			"ALTER TABLE `list_item` ADD COLUMN `target_price` float NOT NULL DEFAULT 0;",
+			"ALTER TABLE `list_item` ADD COLUMN `priority` varchar(8) NOT NULL DEFAULT 'normal';",
+		},
+		Down: []string{
+			"ALTER TABLE `list_item` DROP COLUMN `priority`;",
+			"ALTER TABLE `list_item` DROP COLUMN `target_price`;",
+			"ALTER TABLE `list_item` DROP COLUMN `note`;",
+			"ALTER TABLE `list_item` DROP COLUMN `quantity`;",
+		},
+	})
+}
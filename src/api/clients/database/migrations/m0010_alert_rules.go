@@ -0,0 +1,30 @@
+package migrations
+
+func init() {
+	register(Migration{
+		Version: 10,
+		Name:    "alert_rules",
+		Up: []string{
+			"CREATE TABLE `alert_rule` (" +
+				"`id` bigint unsigned NOT NULL AUTO_INCREMENT, " +
+				"`user_id` bigint unsigned NOT NULL, " +
+				"`list_id` bigint unsigned NOT NULL, " +
+				"`item_id` varchar(64) NOT NULL DEFAULT '', " +
+				"`variation_id` bigint unsigned NOT NULL DEFAULT 0, " +
+				"`type` varchar(32) NOT NULL, " +
+				"`threshold` float NOT NULL DEFAULT 0, " +
+				"`date_created` datetime DEFAULT NULL, " +
+				"PRIMARY KEY (`id`), " +
+				"KEY `alert_rule_list_idx` (`list_id`), " +
+				"KEY `alert_rule_user_idx` (`user_id`), " +
+				"CONSTRAINT `alert_rule_FK` FOREIGN KEY (`user_id`) REFERENCES `user` (`id`), " +
+				"CONSTRAINT `alert_rule_FK_1` FOREIGN KEY (`list_id`) REFERENCES `list` (`id`)" +
+				") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci;",
+			"ALTER TABLE `list_notifications` ADD COLUMN `recipient_id` bigint unsigned NOT NULL DEFAULT 0;",
+		},
+		Down: []string{
+			"ALTER TABLE `list_notifications` DROP COLUMN `recipient_id`;",
+			"DROP TABLE `alert_rule`;",
+		},
+	})
+}
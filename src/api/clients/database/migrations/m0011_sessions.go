@@ -0,0 +1,28 @@
+package migrations
+
+func init() {
+	register(Migration{
+		Version: 11,
+		Name:    "sessions",
+		Up: []string{
+			"CREATE TABLE `session` (" +
+				"`id` bigint unsigned NOT NULL AUTO_INCREMENT, " +
+				"`user_id` bigint unsigned NOT NULL, " +
+				"`access_token_hash` char(64) NOT NULL, " +
+				"`refresh_token_hash` char(64) NOT NULL, " +
+				"`access_expires_at` datetime NOT NULL, " +
+				"`refresh_expires_at` datetime NOT NULL, " +
+				"`date_created` datetime DEFAULT NULL, " +
+				"`revoked` tinyint(1) NOT NULL DEFAULT 0, " +
+				"PRIMARY KEY (`id`), " +
+				"UNIQUE KEY `session_access_token_idx` (`access_token_hash`), " +
+				"UNIQUE KEY `session_refresh_token_idx` (`refresh_token_hash`), " +
+				"KEY `session_user_idx` (`user_id`), " +
+				"CONSTRAINT `session_FK` FOREIGN KEY (`user_id`) REFERENCES `user` (`id`)" +
+				") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci;",
+		},
+		Down: []string{
+			"DROP TABLE `session`;",
+		},
+	})
+}
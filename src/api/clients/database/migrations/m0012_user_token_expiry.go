@@ -0,0 +1,20 @@
+package migrations
+
+func init() {
+	register(Migration{
+		Version: 12,
+		Name:    "user_token_expiry",
+		Up: []string{
+			"ALTER TABLE `user` " +
+				"MODIFY COLUMN `refresh_token` varchar(512) DEFAULT NULL, " +
+				"MODIFY COLUMN `access_token` varchar(512) DEFAULT NULL, " +
+				"ADD COLUMN `token_expires_at` datetime DEFAULT NULL;",
+		},
+		Down: []string{
+			"ALTER TABLE `user` " +
+				"DROP COLUMN `token_expires_at`, " +
+				"MODIFY COLUMN `access_token` varchar(256) DEFAULT NULL, " +
+				"MODIFY COLUMN `refresh_token` varchar(256) DEFAULT NULL;",
+		},
+	})
+}
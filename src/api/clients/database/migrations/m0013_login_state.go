@@ -0,0 +1,22 @@
+package migrations
+
+func init() {
+	register(Migration{
+		Version: 13,
+		Name:    "login_state",
+		Up: []string{
+			"CREATE TABLE `login_state` (" +
+				"`state` varchar(64) NOT NULL, " +
+				"`code_verifier` varchar(128) NOT NULL, " +
+				"`redirect_uri` varchar(255) NOT NULL, " +
+				"`date_created` datetime DEFAULT NULL, " +
+				"`expires_at` datetime NOT NULL, " +
+				"PRIMARY KEY (`state`), " +
+				"KEY `login_state_expires_idx` (`expires_at`)" +
+				") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci;",
+		},
+		Down: []string{
+			"DROP TABLE `login_state`;",
+		},
+	})
+}
@@ -0,0 +1,14 @@
+package migrations
+
+func init() {
+	register(Migration{
+		Version: 14,
+		Name:    "login_state_nonce",
+		Up: []string{
+			"ALTER TABLE `login_state` ADD COLUMN `nonce_hash` char(64) NOT NULL DEFAULT '' AFTER `code_verifier`;",
+		},
+		Down: []string{
+			"ALTER TABLE `login_state` DROP COLUMN `nonce_hash`;",
+		},
+	})
+}
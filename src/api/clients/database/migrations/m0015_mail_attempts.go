@@ -0,0 +1,14 @@
+package migrations
+
+func init() {
+	register(Migration{
+		Version: 15,
+		Name:    "mail_attempts",
+		Up: []string{
+			"ALTER TABLE `notification_delivery` ADD COLUMN `mail_attempts` int unsigned NOT NULL DEFAULT 0;",
+		},
+		Down: []string{
+			"ALTER TABLE `notification_delivery` DROP COLUMN `mail_attempts`;",
+		},
+	})
+}
@@ -0,0 +1,71 @@
+package migrations
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Migration is a single versioned schema change. Up and Down hold one SQL
+// statement per element, since the mysql driver does not run multi statements.
+type Migration struct {
+	Version int64
+	Name    string
+	Up      []string
+	Down    []string
+}
+
+type Migrations []Migration
+
+var (
+	registry = make(Migrations, 0)
+)
+
+func register(m Migration) {
+	registry = append(registry, m)
+}
+
+// All returns every registered migration ordered by version.
+func All() Migrations {
+	result := make(Migrations, len(registry))
+	copy(result, registry)
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Version < result[j].Version
+	})
+	return result
+}
+
+// Checksum identifies the content of the up statements, so an applied migration
+// that was edited afterwards can be detected.
+func (m Migration) Checksum() string {
+	hash := sha256.New()
+	for _, stmt := range m.Up {
+		hash.Write([]byte(strings.TrimSpace(stmt)))
+		hash.Write([]byte{0})
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+func (m Migration) String() string {
+	return fmt.Sprintf("%04d_%s", m.Version, m.Name)
+}
+
+// Validate checks that versions are unique, positive and that every migration can be rolled back.
+func (migrations Migrations) Validate() error {
+	seen := make(map[int64]bool)
+	for _, m := range migrations {
+		if m.Version <= 0 {
+			return fmt.Errorf("migration %s has an invalid version", m)
+		}
+		if seen[m.Version] {
+			return fmt.Errorf("migration version %d is registered more than once", m.Version)
+		}
+		if len(m.Up) == 0 || len(m.Down) == 0 {
+			return fmt.Errorf("migration %s must define up and down statements", m)
+		}
+		seen[m.Version] = true
+	}
+	return nil
+}
@@ -0,0 +1,45 @@
+package migrations
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegisteredMigrationsAreValid(t *testing.T) {
+	all := All()
+
+	assert.NotEmpty(t, all)
+	assert.Nil(t, all.Validate())
+	for i := 1; i < len(all); i++ {
+		assert.True(t, all[i-1].Version < all[i].Version)
+	}
+}
+
+func TestValidateDuplicatedVersion(t *testing.T) {
+	m := Migrations{
+		{Version: 1, Name: "a", Up: []string{"SELECT 1;"}, Down: []string{"SELECT 1;"}},
+		{Version: 1, Name: "b", Up: []string{"SELECT 1;"}, Down: []string{"SELECT 1;"}},
+	}
+
+	err := m.Validate()
+	assert.NotNil(t, err)
+	assert.EqualValues(t, "migration version 1 is registered more than once", err.Error())
+}
+
+func TestValidateMissingDown(t *testing.T) {
+	m := Migrations{{Version: 1, Name: "a", Up: []string{"SELECT 1;"}}}
+
+	err := m.Validate()
+	assert.NotNil(t, err)
+	assert.EqualValues(t, "migration 0001_a must define up and down statements", err.Error())
+}
+
+func TestChecksumChangesWithStatements(t *testing.T) {
+	a := Migration{Version: 1, Name: "a", Up: []string{"CREATE TABLE a (id int);"}}
+	b := Migration{Version: 1, Name: "a", Up: []string{"  CREATE TABLE a (id int);\n"}}
+	c := Migration{Version: 1, Name: "a", Up: []string{"CREATE TABLE a (id bigint);"}}
+
+	assert.EqualValues(t, a.Checksum(), b.Checksum())
+	assert.NotEqual(t, a.Checksum(), c.Checksum())
+}
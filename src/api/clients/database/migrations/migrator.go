@@ -0,0 +1,235 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/lmurature/melist-api/src/api/config"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	lockName    = "melist_schema_migrations"
+	lockTimeout = 30
+
+	createSchemaVersionTable = "CREATE TABLE IF NOT EXISTS `schema_version` (" +
+		"`version` bigint unsigned NOT NULL, " +
+		"`name` varchar(255) NOT NULL, " +
+		"`checksum` char(64) NOT NULL, " +
+		"`applied_at` datetime NOT NULL, " +
+		"PRIMARY KEY (`version`)" +
+		") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci;"
+	getAppliedVersions   = "SELECT version, name, checksum, applied_at FROM schema_version ORDER BY version ASC;"
+	insertAppliedVersion = "INSERT INTO schema_version(version, name, checksum, applied_at) VALUES(?,?,?,?);"
+	deleteAppliedVersion = "DELETE FROM schema_version WHERE version=?;"
+	acquireLock          = "SELECT GET_LOCK(?, ?);"
+	releaseLock          = "SELECT RELEASE_LOCK(?);"
+)
+
+var (
+	ErrPendingMigrations = errors.New("database schema has pending migrations")
+)
+
+// AppliedVersion is a row of the schema_version table.
+type AppliedVersion struct {
+	Version   int64
+	Name      string
+	Checksum  string
+	AppliedAt string
+}
+
+// Status describes every known migration and whether it is applied to the database.
+type Status struct {
+	Migration Migration
+	Applied   *AppliedVersion
+}
+
+type Migrator struct {
+	db         *sql.DB
+	migrations Migrations
+}
+
+func NewMigrator(db *sql.DB) *Migrator {
+	return &Migrator{
+		db:         db,
+		migrations: All(),
+	}
+}
+
+// Up applies every pending migration in version order.
+func (m *Migrator) Up() error {
+	return m.withLock(func(conn *sql.Conn) error {
+		applied, err := m.verifyApplied(conn)
+		if err != nil {
+			return err
+		}
+
+		for _, migration := range m.migrations {
+			if _, ok := applied[migration.Version]; ok {
+				continue
+			}
+
+			logrus.Info(fmt.Sprintf("applying migration %s", migration))
+			if err := execAll(conn, migration.Up); err != nil {
+				return fmt.Errorf("error applying migration %s: %v", migration, err)
+			}
+
+			if _, err := conn.ExecContext(context.Background(), insertAppliedVersion, migration.Version, migration.Name,
+				migration.Checksum(), time.Now().UTC().Format(config.DbDateLayout)); err != nil {
+				return fmt.Errorf("error recording migration %s: %v", migration, err)
+			}
+		}
+
+		return nil
+	})
+}
+
+// Down rolls back the last applied migrations, at most steps of them.
+func (m *Migrator) Down(steps int) error {
+	return m.withLock(func(conn *sql.Conn) error {
+		applied, err := m.verifyApplied(conn)
+		if err != nil {
+			return err
+		}
+
+		for i := len(m.migrations) - 1; i >= 0 && steps > 0; i-- {
+			migration := m.migrations[i]
+			if _, ok := applied[migration.Version]; !ok {
+				continue
+			}
+
+			logrus.Info(fmt.Sprintf("rolling back migration %s", migration))
+			if err := execAll(conn, migration.Down); err != nil {
+				return fmt.Errorf("error rolling back migration %s: %v", migration, err)
+			}
+
+			if _, err := conn.ExecContext(context.Background(), deleteAppliedVersion, migration.Version); err != nil {
+				return fmt.Errorf("error removing migration %s from schema version: %v", migration, err)
+			}
+			steps--
+		}
+
+		return nil
+	})
+}
+
+// Verify fails if an applied migration was modified, is unknown, or if there are pending migrations.
+func (m *Migrator) Verify() error {
+	return m.withLock(func(conn *sql.Conn) error {
+		applied, err := m.verifyApplied(conn)
+		if err != nil {
+			return err
+		}
+
+		for _, migration := range m.migrations {
+			if _, ok := applied[migration.Version]; !ok {
+				return fmt.Errorf("%w: %s is not applied", ErrPendingMigrations, migration)
+			}
+		}
+
+		return nil
+	})
+}
+
+func (m *Migrator) Status() ([]Status, error) {
+	result := make([]Status, 0)
+	err := m.withLock(func(conn *sql.Conn) error {
+		applied, err := getApplied(conn)
+		if err != nil {
+			return err
+		}
+
+		for _, migration := range m.migrations {
+			status := Status{Migration: migration}
+			if version, ok := applied[migration.Version]; ok {
+				status.Applied = &version
+			}
+			result = append(result, status)
+		}
+		return nil
+	})
+	return result, err
+}
+
+func (m *Migrator) verifyApplied(conn *sql.Conn) (map[int64]AppliedVersion, error) {
+	if err := m.migrations.Validate(); err != nil {
+		return nil, err
+	}
+
+	applied, err := getApplied(conn)
+	if err != nil {
+		return nil, err
+	}
+
+	known := make(map[int64]Migration)
+	for _, migration := range m.migrations {
+		known[migration.Version] = migration
+	}
+
+	for version, a := range applied {
+		migration, ok := known[version]
+		if !ok {
+			return nil, fmt.Errorf("database has migration %04d_%s applied which is unknown to this build", version, a.Name)
+		}
+		if migration.Checksum() != a.Checksum {
+			return nil, fmt.Errorf("checksum mismatch for migration %s: it was modified after being applied", migration)
+		}
+	}
+
+	return applied, nil
+}
+
+func (m *Migrator) withLock(f func(conn *sql.Conn) error) error {
+	ctx := context.Background()
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	var locked sql.NullInt64
+	if err := conn.QueryRowContext(ctx, acquireLock, lockName, lockTimeout).Scan(&locked); err != nil {
+		return err
+	}
+	if !locked.Valid || locked.Int64 != 1 {
+		return errors.New("could not acquire schema migrations lock")
+	}
+	defer conn.ExecContext(ctx, releaseLock, lockName)
+
+	if _, err := conn.ExecContext(ctx, createSchemaVersionTable); err != nil {
+		return err
+	}
+
+	return f(conn)
+}
+
+func getApplied(conn *sql.Conn) (map[int64]AppliedVersion, error) {
+	rows, err := conn.QueryContext(context.Background(), getAppliedVersions)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := make(map[int64]AppliedVersion)
+	for rows.Next() {
+		var a AppliedVersion
+		if err := rows.Scan(&a.Version, &a.Name, &a.Checksum, &a.AppliedAt); err != nil {
+			return nil, err
+		}
+		result[a.Version] = a
+	}
+
+	return result, rows.Err()
+}
+
+func execAll(conn *sql.Conn, statements []string) error {
+	for _, stmt := range statements {
+		if _, err := conn.ExecContext(context.Background(), stmt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
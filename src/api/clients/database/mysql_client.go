@@ -3,17 +3,27 @@ package database
 import (
 	"database/sql"
 	"fmt"
+	"github.com/lmurature/melist-api/src/api/clients/database/migrations"
 	"github.com/lmurature/melist-api/src/api/config"
 	"time"
 
 	_ "github.com/go-sql-driver/mysql"
 )
 
+const (
+	MigrationsUp     = "up"
+	MigrationsVerify = "verify"
+)
+
 var (
 	DbClient *sql.DB
 )
 
 func init() {
+	if config.StorageBackend != config.StorageMySql {
+		return
+	}
+
 	var err error
 	url := fmt.Sprintf("%s:%s@tcp(%s)/%s", config.DbUser, config.DbPass, config.DbHost, config.DbName)
 	fmt.Println("about to connect to url ", url)
@@ -25,3 +35,22 @@ func init() {
 	DbClient.SetMaxOpenConns(10)
 	DbClient.SetMaxIdleConns(10)
 }
+
+// RunMigrations applies ("up") or checks ("verify") the schema migrations, or does nothing when mode is empty. It is
+// only called by the API, so the migrate command manages migrations on its own.
+func RunMigrations(mode string) error {
+	if DbClient == nil {
+		return nil
+	}
+
+	switch mode {
+	case MigrationsUp:
+		return migrations.NewMigrator(DbClient).Up()
+	case MigrationsVerify:
+		return migrations.NewMigrator(DbClient).Verify()
+	case "":
+		return nil
+	default:
+		return fmt.Errorf("invalid DB_MIGRATIONS value '%s', expected '%s' or '%s'", mode, MigrationsUp, MigrationsVerify)
+	}
+}
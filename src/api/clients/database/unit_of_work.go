@@ -0,0 +1,159 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"sync"
+
+	"github.com/lmurature/melist-api/src/api/domain/apierrors"
+	error_utils "github.com/lmurature/melist-api/src/api/utils/error"
+	"github.com/sirupsen/logrus"
+)
+
+var (
+	ErrUnitOfWorkFinished = errors.New("unit of work was already committed or rolled back")
+)
+
+// Executor prepares statements either on the connection pool or inside a transaction.
+type Executor interface {
+	Prepare(query string) (*sql.Stmt, error)
+}
+
+// UnitOfWork groups the writes of several DAOs so they are committed or rolled back together.
+// With the mysql backend it wraps a *sql.Tx; storage backends without transactions register
+// undo functions through OnRollback instead.
+type UnitOfWork struct {
+	mu         sync.Mutex
+	tx         *sql.Tx
+	finished   bool
+	onCommit   []func()
+	onRollback []func()
+}
+
+// Begin starts a unit of work. A database transaction is only opened when the mysql client is initialised.
+func Begin() (*UnitOfWork, error) {
+	uow := &UnitOfWork{}
+	if DbClient == nil {
+		return uow, nil
+	}
+
+	tx, err := DbClient.Begin()
+	if err != nil {
+		return nil, err
+	}
+	uow.tx = tx
+	return uow, nil
+}
+
+// GetExecutor returns the transaction of the unit of work, or the connection pool when uow is nil.
+func GetExecutor(uow *UnitOfWork) Executor {
+	if uow == nil || uow.tx == nil {
+		return DbClient
+	}
+	return uow.tx
+}
+
+// OnCommit registers f to run after a successful commit. Calling it on a nil unit of work runs f right away,
+// since the write it belongs to is already durable.
+func (uow *UnitOfWork) OnCommit(f func()) {
+	if uow == nil {
+		f()
+		return
+	}
+	uow.mu.Lock()
+	defer uow.mu.Unlock()
+	uow.onCommit = append(uow.onCommit, f)
+}
+
+// OnRollback registers f to undo a write if the unit of work is rolled back. Undo functions run in reverse
+// registration order. Calling it on a nil unit of work does nothing.
+func (uow *UnitOfWork) OnRollback(f func()) {
+	if uow == nil {
+		return
+	}
+	uow.mu.Lock()
+	defer uow.mu.Unlock()
+	uow.onRollback = append(uow.onRollback, f)
+}
+
+func (uow *UnitOfWork) Commit() error {
+	uow.mu.Lock()
+	if uow.finished {
+		uow.mu.Unlock()
+		return ErrUnitOfWorkFinished
+	}
+	uow.finished = true
+	hooks := uow.onCommit
+	uow.mu.Unlock()
+
+	if uow.tx != nil {
+		if err := uow.tx.Commit(); err != nil {
+			uow.runRollbackHooks()
+			return err
+		}
+	}
+
+	for _, f := range hooks {
+		f()
+	}
+	return nil
+}
+
+func (uow *UnitOfWork) Rollback() error {
+	uow.mu.Lock()
+	if uow.finished {
+		uow.mu.Unlock()
+		return ErrUnitOfWorkFinished
+	}
+	uow.finished = true
+	uow.mu.Unlock()
+
+	var err error
+	if uow.tx != nil {
+		err = uow.tx.Rollback()
+	}
+	uow.runRollbackHooks()
+	return err
+}
+
+func (uow *UnitOfWork) runRollbackHooks() {
+	uow.mu.Lock()
+	hooks := uow.onRollback
+	uow.onRollback = nil
+	uow.mu.Unlock()
+
+	for i := len(hooks) - 1; i >= 0; i-- {
+		hooks[i]()
+	}
+}
+
+// RunInTransaction runs f inside a new unit of work, committing it if f succeeds and rolling it back if f
+// returns an error or panics.
+func RunInTransaction(f func(uow *UnitOfWork) apierrors.ApiError) apierrors.ApiError {
+	uow, err := Begin()
+	if err != nil {
+		logrus.Error("error when trying to begin transaction", err)
+		return apierrors.NewInternalServerApiError("error when trying to begin transaction", error_utils.GetDatabaseGenericError())
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			uow.Rollback()
+			panic(r)
+		}
+	}()
+
+	if apiErr := f(uow); apiErr != nil {
+		if err := uow.Rollback(); err != nil {
+			logrus.Error("error when trying to rollback transaction", err)
+		}
+		return apiErr
+	}
+
+	if err := uow.Commit(); err != nil {
+		logrus.Error("error when trying to commit transaction", err)
+		return apierrors.NewInternalServerApiError("error when trying to commit transaction", error_utils.GetDatabaseGenericError())
+	}
+
+	return nil
+}
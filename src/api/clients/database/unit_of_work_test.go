@@ -0,0 +1,73 @@
+package database
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/lmurature/melist-api/src/api/domain/apierrors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunInTransactionCommit(t *testing.T) {
+	DbClient = nil
+	calls := make([]string, 0)
+
+	err := RunInTransaction(func(uow *UnitOfWork) apierrors.ApiError {
+		uow.OnRollback(func() { calls = append(calls, "rollback") })
+		uow.OnCommit(func() { calls = append(calls, "commit") })
+		return nil
+	})
+
+	assert.Nil(t, err)
+	assert.EqualValues(t, []string{"commit"}, calls)
+}
+
+func TestRunInTransactionRollbackInReverseOrder(t *testing.T) {
+	DbClient = nil
+	calls := make([]string, 0)
+
+	err := RunInTransaction(func(uow *UnitOfWork) apierrors.ApiError {
+		uow.OnCommit(func() { calls = append(calls, "commit") })
+		uow.OnRollback(func() { calls = append(calls, "undo first") })
+		uow.OnRollback(func() { calls = append(calls, "undo second") })
+		return apierrors.NewBadRequestApiError("failed")
+	})
+
+	assert.NotNil(t, err)
+	assert.EqualValues(t, "failed", err.Message())
+	assert.EqualValues(t, []string{"undo second", "undo first"}, calls)
+}
+
+func TestRunInTransactionRollbackOnPanic(t *testing.T) {
+	DbClient = nil
+	rolledBack := false
+
+	assert.Panics(t, func() {
+		RunInTransaction(func(uow *UnitOfWork) apierrors.ApiError {
+			uow.OnRollback(func() { rolledBack = true })
+			panic("unexpected")
+		})
+	})
+	assert.True(t, rolledBack)
+}
+
+func TestUnitOfWorkFinishesOnce(t *testing.T) {
+	DbClient = nil
+	uow, err := Begin()
+	assert.Nil(t, err)
+
+	assert.Nil(t, uow.Commit())
+	assert.True(t, errors.Is(uow.Rollback(), ErrUnitOfWorkFinished))
+	assert.True(t, errors.Is(uow.Commit(), ErrUnitOfWorkFinished))
+}
+
+func TestNilUnitOfWork(t *testing.T) {
+	var uow *UnitOfWork
+	committed := false
+
+	uow.OnRollback(func() { t.Fail() })
+	uow.OnCommit(func() { committed = true })
+
+	assert.True(t, committed)
+	assert.Nil(t, GetExecutor(uow))
+}
@@ -0,0 +1,128 @@
+package restclient
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/lmurature/melist-api/src/api/domain/apierrors"
+)
+
+const (
+	BreakerClosed   = "closed"
+	BreakerOpen     = "open"
+	BreakerHalfOpen = "half_open"
+)
+
+type BreakerStats struct {
+	State               string `json:"state"`
+	ConsecutiveFailures int    `json:"consecutive_failures"`
+	Rejected            int64  `json:"rejected"`
+}
+
+// breaker stops sending requests to a host after maxFailures consecutive failures. Once openTime has passed a single
+// request is let through: if it succeeds the circuit closes again, otherwise it stays open for another openTime.
+type breaker struct {
+	mu          sync.Mutex
+	host        string
+	maxFailures int
+	openTime    time.Duration
+	state       string
+	failures    int
+	openedAt    time.Time
+	probing     bool
+	rejected    int64
+}
+
+func (b *breaker) allow() apierrors.ApiError {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == BreakerOpen && !now().Before(b.openedAt.Add(b.openTime)) {
+		b.state = BreakerHalfOpen
+	}
+
+	switch b.state {
+	case BreakerOpen:
+		b.rejected++
+		return b.unavailable()
+	case BreakerHalfOpen:
+		if b.probing {
+			b.rejected++
+			return b.unavailable()
+		}
+		b.probing = true
+	}
+	return nil
+}
+
+// release gives back the probe of a half open circuit when its request was not sent after all.
+func (b *breaker) release() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.probing = false
+}
+
+func (b *breaker) record(success bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.probing = false
+	if success {
+		b.failures = 0
+		b.state = BreakerClosed
+		return
+	}
+
+	b.failures++
+	if b.state == BreakerHalfOpen || (b.maxFailures > 0 && b.failures >= b.maxFailures) {
+		b.state = BreakerOpen
+		b.openedAt = now()
+	}
+}
+
+func (b *breaker) unavailable() apierrors.ApiError {
+	msg := fmt.Sprintf("%s is not available, try again later", b.host)
+	return apierrors.NewApiError(msg, "service_unavailable", http.StatusServiceUnavailable, apierrors.CauseList{})
+}
+
+type breakerRegistry struct {
+	mu          sync.Mutex
+	maxFailures int
+	openTime    time.Duration
+	breakers    map[string]*breaker
+}
+
+func newBreakerRegistry(maxFailures int, openTime time.Duration) *breakerRegistry {
+	return &breakerRegistry{
+		maxFailures: maxFailures,
+		openTime:    openTime,
+		breakers:    make(map[string]*breaker),
+	}
+}
+
+func (r *breakerRegistry) get(host string) *breaker {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	b, ok := r.breakers[host]
+	if !ok {
+		b = &breaker{host: host, maxFailures: r.maxFailures, openTime: r.openTime, state: BreakerClosed}
+		r.breakers[host] = b
+	}
+	return b
+}
+
+func (r *breakerRegistry) stats() map[string]BreakerStats {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	result := make(map[string]BreakerStats, len(r.breakers))
+	for host, b := range r.breakers {
+		b.mu.Lock()
+		result[host] = BreakerStats{State: b.state, ConsecutiveFailures: b.failures, Rejected: b.rejected}
+		b.mu.Unlock()
+	}
+	return result
+}
@@ -0,0 +1,69 @@
+package restclient
+
+import (
+	"sync"
+	"time"
+
+	"github.com/lmurature/melist-api/src/api/domain/apierrors"
+)
+
+// rateLimiter is a token bucket refilled at rate tokens per second that holds at most burst tokens. A rate of zero
+// or less does not limit anything.
+type rateLimiter struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func newRateLimiter(rate float64, burst int) *rateLimiter {
+	if burst < 1 {
+		burst = 1
+	}
+	return &rateLimiter{rate: rate, burst: float64(burst), tokens: float64(burst)}
+}
+
+// wait takes a token, sleeping until there is one. When that would take longer than maxWait no token is taken and
+// a too many requests error is returned instead.
+func (l *rateLimiter) wait(maxWait time.Duration) apierrors.ApiError {
+	delay, ok := l.reserve(maxWait)
+	if !ok {
+		return apierrors.NewTooManyRequestsError("too many requests to Mercado Libre, try again later")
+	}
+	if delay > 0 {
+		sleep(delay)
+	}
+	return nil
+}
+
+func (l *rateLimiter) reserve(maxWait time.Duration) (time.Duration, bool) {
+	if l.rate <= 0 {
+		return 0, true
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	current := now()
+	if !l.last.IsZero() {
+		l.tokens += current.Sub(l.last).Seconds() * l.rate
+		if l.tokens > l.burst {
+			l.tokens = l.burst
+		}
+	}
+	l.last = current
+
+	if l.tokens >= 1 {
+		l.tokens--
+		return 0, true
+	}
+
+	// the token is borrowed from the future, so whoever comes next waits for the one after it
+	delay := time.Duration((1 - l.tokens) / l.rate * float64(time.Second))
+	if delay > maxWait {
+		return 0, false
+	}
+	l.tokens--
+	return delay, true
+}
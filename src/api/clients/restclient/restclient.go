@@ -0,0 +1,197 @@
+package restclient
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/lmurature/golang-restclient/rest"
+	"github.com/lmurature/melist-api/src/api/config"
+	"github.com/lmurature/melist-api/src/api/domain/apierrors"
+	"github.com/sirupsen/logrus"
+)
+
+var (
+	// limiter is shared by every client, so it bounds all the requests Melist sends to the outside.
+	limiter  = newRateLimiter(config.OutboundRateLimit, config.OutboundRateBurst)
+	breakers = newBreakerRegistry(config.OutboundBreakerFailures, config.OutboundBreakerOpenTime)
+
+	now   = time.Now
+	sleep = time.Sleep
+)
+
+// Options configure a Client. A nil Retry uses DefaultRetryPolicy. Headers are sent with every request of the client
+// and must not change once the client is created; credentials of a user go in the options of each request instead.
+type Options struct {
+	BaseURL      string
+	Timeout      time.Duration
+	DisableCache bool
+	Retry        *RetryPolicy
+	Headers      http.Header
+}
+
+// Client sends requests through the global rate limiter and the circuit breaker of the host, retrying idempotent
+// requests that fail with a network error, a 429 or a 5xx. A Client is safe to share between goroutines.
+type Client struct {
+	options Options
+}
+
+// RequestOption customizes a single request of a Client without affecting the other requests sent with it.
+type RequestOption func(request *requestOptions)
+
+type requestOptions struct {
+	headers http.Header
+}
+
+// WithHeader sets a header of a single request, replacing the value the client sends by default.
+func WithHeader(key string, value string) RequestOption {
+	return func(request *requestOptions) {
+		request.headers.Set(key, value)
+	}
+}
+
+// WithBearerToken authenticates a single request with an OAuth access token.
+func WithBearerToken(accessToken string) RequestOption {
+	return WithHeader("Authorization", "Bearer "+accessToken)
+}
+
+func New(options Options) *Client {
+	return &Client{options: options}
+}
+
+// Get returns the response of Mercado Libre or an error when the request was not sent because the rate limit budget
+// was exhausted or the circuit of the host is open. Network errors are left in the response like rest does.
+func (c *Client) Get(uri string, options ...RequestOption) (*rest.Response, apierrors.ApiError) {
+	return c.do(http.MethodGet, uri, nil, options)
+}
+
+func (c *Client) Post(uri string, body interface{}, options ...RequestOption) (*rest.Response, apierrors.ApiError) {
+	return c.do(http.MethodPost, uri, body, options)
+}
+
+func (c *Client) Put(uri string, body interface{}, options ...RequestOption) (*rest.Response, apierrors.ApiError) {
+	return c.do(http.MethodPut, uri, body, options)
+}
+
+func (c *Client) Delete(uri string, options ...RequestOption) (*rest.Response, apierrors.ApiError) {
+	return c.do(http.MethodDelete, uri, nil, options)
+}
+
+func (c *Client) do(method string, uri string, body interface{}, options []RequestOption) (*rest.Response, apierrors.ApiError) {
+	policy := c.retryPolicy()
+	attempts := 1
+	if isIdempotent(method) && policy.MaxAttempts > 1 {
+		attempts = policy.MaxAttempts
+	}
+
+	host := hostOf(c.options.BaseURL + uri)
+	breaker := breakers.get(host)
+
+	var response *rest.Response
+	for attempt := 1; ; attempt++ {
+		if err := breaker.allow(); err != nil {
+			return lastResponseOr(response, err)
+		}
+		if err := limiter.wait(config.OutboundRateMaxWait); err != nil {
+			breaker.release()
+			return lastResponseOr(response, err)
+		}
+
+		response = c.send(method, uri, body, options)
+		breaker.record(!isFailure(response))
+
+		if attempt >= attempts || !isRetryable(response) {
+			return response, nil
+		}
+
+		delay, ok := policy.delay(attempt, response)
+		if !ok {
+			return response, nil
+		}
+		logrus.Warn(fmt.Sprintf("retrying %s %s%s in %s, attempt %d of %d", method, c.options.BaseURL, uri, delay, attempt+1, attempts))
+		sleep(delay)
+	}
+}
+
+// send uses a new builder for every request because rest.RequestBuilder writes to its http.Client on every call,
+// which races when the builder is shared between goroutines. The transport, and so the connections, are still shared.
+// Requests with credentials are never cached, since their responses belong to a single user.
+func (c *Client) send(method string, uri string, body interface{}, options []RequestOption) *rest.Response {
+	request := requestOptions{headers: make(http.Header)}
+	for key, values := range c.options.Headers {
+		request.headers[key] = append([]string(nil), values...)
+	}
+	for _, option := range options {
+		option(&request)
+	}
+
+	builder := &rest.RequestBuilder{
+		BaseURL:        c.options.BaseURL,
+		Timeout:        c.options.Timeout,
+		DisableCache:   c.options.DisableCache || request.headers.Get("Authorization") != "",
+		DisableTimeout: c.options.Timeout == 0,
+		Headers:        request.headers,
+	}
+
+	switch method {
+	case http.MethodPost:
+		return builder.Post(uri, body)
+	case http.MethodPut:
+		return builder.Put(uri, body)
+	case http.MethodDelete:
+		return builder.Delete(uri)
+	default:
+		return builder.Get(uri)
+	}
+}
+
+func (c *Client) retryPolicy() RetryPolicy {
+	if c.options.Retry != nil {
+		return *c.options.Retry
+	}
+	return DefaultRetryPolicy
+}
+
+// DisableResilience sends every request once, with no rate limit nor circuit breakers. It is meant for tests that
+// mock failures of Mercado Libre and expect to get them as they are.
+func DisableResilience() {
+	DefaultRetryPolicy = RetryPolicy{MaxAttempts: 1}
+	limiter = newRateLimiter(0, 0)
+	breakers = newBreakerRegistry(0, 0)
+}
+
+// Stats returns the state of the circuit of every host a request was sent to.
+func Stats() map[string]BreakerStats {
+	return breakers.stats()
+}
+
+// lastResponseOr prefers the response of a previous attempt over the error of a request that could not be retried,
+// so the caller sees what Mercado Libre answered.
+func lastResponseOr(response *rest.Response, err apierrors.ApiError) (*rest.Response, apierrors.ApiError) {
+	if response != nil {
+		return response, nil
+	}
+	return nil, err
+}
+
+func isIdempotent(method string) bool {
+	return method == http.MethodGet || method == http.MethodPut || method == http.MethodDelete
+}
+
+// isFailure is whether the response counts against the circuit of the host: the host could not be reached or failed.
+func isFailure(response *rest.Response) bool {
+	return response == nil || response.Response == nil || response.StatusCode >= http.StatusInternalServerError
+}
+
+func isRetryable(response *rest.Response) bool {
+	return isFailure(response) || response.StatusCode == http.StatusTooManyRequests
+}
+
+func hostOf(rawUrl string) string {
+	parsed, err := url.Parse(rawUrl)
+	if err != nil || parsed.Host == "" {
+		return rawUrl
+	}
+	return parsed.Host
+}
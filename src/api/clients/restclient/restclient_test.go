@@ -0,0 +1,205 @@
+package restclient
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeClock struct {
+	now    time.Time
+	sleeps []time.Duration
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+func (c *fakeClock) Sleep(d time.Duration) {
+	c.sleeps = append(c.sleeps, d)
+	c.now = c.now.Add(d)
+}
+
+// setup replaces the shared limiter, breakers and clock of the package for a single test.
+func setup(t *testing.T, rate float64, burst int) *fakeClock {
+	clk := &fakeClock{now: time.Date(2021, 5, 1, 12, 0, 0, 0, time.UTC)}
+	previousNow, previousSleep, previousJitter := now, sleep, jitter
+	previousLimiter, previousBreakers := limiter, breakers
+
+	now, sleep = clk.Now, clk.Sleep
+	jitter = func(n int64) int64 { return 0 }
+	limiter = newRateLimiter(rate, burst)
+	breakers = newBreakerRegistry(5, 30*time.Second)
+
+	t.Cleanup(func() {
+		now, sleep, jitter = previousNow, previousSleep, previousJitter
+		limiter, breakers = previousLimiter, previousBreakers
+	})
+	return clk
+}
+
+// newServer answers with the given statuses in order and then always with the last one.
+func newServer(statuses ...int) (*httptest.Server, *int32) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := int(atomic.AddInt32(&calls, 1))
+		if n > len(statuses) {
+			n = len(statuses)
+		}
+		if statuses[n-1] == http.StatusTooManyRequests {
+			w.Header().Set("Retry-After", "2")
+		}
+		w.WriteHeader(statuses[n-1])
+		w.Write([]byte(`{}`))
+	}))
+	return server, &calls
+}
+
+func TestGetRetriesFailuresWithBackoff(t *testing.T) {
+	clk := setup(t, 0, 0)
+	server, calls := newServer(http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusOK)
+	defer server.Close()
+
+	response, err := New(Options{BaseURL: server.URL, DisableCache: true}).Get("/items/MLA1")
+
+	assert.Nil(t, err)
+	assert.EqualValues(t, http.StatusOK, response.StatusCode)
+	assert.EqualValues(t, 3, atomic.LoadInt32(calls))
+	assert.EqualValues(t, []time.Duration{50 * time.Millisecond, 100 * time.Millisecond}, clk.sleeps)
+}
+
+func TestGetHonoursRetryAfter(t *testing.T) {
+	clk := setup(t, 0, 0)
+	server, calls := newServer(http.StatusTooManyRequests, http.StatusOK)
+	defer server.Close()
+
+	response, err := New(Options{BaseURL: server.URL, DisableCache: true}).Get("/items/MLA1")
+
+	assert.Nil(t, err)
+	assert.EqualValues(t, http.StatusOK, response.StatusCode)
+	assert.EqualValues(t, 2, atomic.LoadInt32(calls))
+	assert.EqualValues(t, []time.Duration{2 * time.Second}, clk.sleeps)
+}
+
+func TestGetDoesNotWaitForLongRetryAfter(t *testing.T) {
+	clk := setup(t, 0, 0)
+	server, calls := newServer(http.StatusTooManyRequests, http.StatusOK)
+	defer server.Close()
+
+	client := New(Options{BaseURL: server.URL, DisableCache: true, Retry: &RetryPolicy{MaxAttempts: 3, MaxRetryAfter: time.Second}})
+	response, err := client.Get("/items/MLA1")
+
+	assert.Nil(t, err)
+	assert.EqualValues(t, http.StatusTooManyRequests, response.StatusCode)
+	assert.EqualValues(t, 1, atomic.LoadInt32(calls))
+	assert.Empty(t, clk.sleeps)
+}
+
+func TestPostIsNotRetried(t *testing.T) {
+	setup(t, 0, 0)
+	server, calls := newServer(http.StatusInternalServerError, http.StatusOK)
+	defer server.Close()
+
+	response, err := New(Options{BaseURL: server.URL}).Post("/oauth/token", map[string]string{"code": "abc"})
+
+	assert.Nil(t, err)
+	assert.EqualValues(t, http.StatusInternalServerError, response.StatusCode)
+	assert.EqualValues(t, 1, atomic.LoadInt32(calls))
+}
+
+func TestCircuitOpensAfterConsecutiveFailures(t *testing.T) {
+	clk := setup(t, 0, 0)
+	server, calls := newServer(http.StatusInternalServerError)
+	defer server.Close()
+	client := New(Options{BaseURL: server.URL, DisableCache: true, Retry: &RetryPolicy{MaxAttempts: 1}})
+
+	for i := 0; i < 5; i++ {
+		response, err := client.Get("/items/MLA1")
+		assert.Nil(t, err)
+		assert.EqualValues(t, http.StatusInternalServerError, response.StatusCode)
+	}
+
+	response, err := client.Get("/items/MLA1")
+	assert.Nil(t, response)
+	assert.NotNil(t, err)
+	assert.EqualValues(t, http.StatusServiceUnavailable, err.Status())
+	assert.EqualValues(t, 5, atomic.LoadInt32(calls))
+
+	host, _ := url.Parse(server.URL)
+	assert.EqualValues(t, BreakerStats{State: BreakerOpen, ConsecutiveFailures: 5, Rejected: 1}, Stats()[host.Host])
+
+	clk.now = clk.now.Add(30 * time.Second)
+	response, err = client.Get("/items/MLA1")
+	assert.Nil(t, err)
+	assert.EqualValues(t, 6, atomic.LoadInt32(calls))
+	assert.EqualValues(t, BreakerOpen, Stats()[host.Host].State)
+}
+
+func TestHalfOpenCircuitClosesAfterSuccess(t *testing.T) {
+	clk := setup(t, 0, 0)
+	b := breakers.get("api.mercadolibre.com")
+	for i := 0; i < 5; i++ {
+		assert.Nil(t, b.allow())
+		b.record(false)
+	}
+	assert.NotNil(t, b.allow())
+
+	clk.now = clk.now.Add(30 * time.Second)
+	assert.Nil(t, b.allow())
+	assert.NotNil(t, b.allow(), "only one request probes a half open circuit")
+	b.record(true)
+
+	assert.Nil(t, b.allow())
+	assert.EqualValues(t, BreakerClosed, Stats()["api.mercadolibre.com"].State)
+}
+
+func TestRateLimiterRejectsWhenBudgetIsExhausted(t *testing.T) {
+	clk := setup(t, 10, 2)
+	server, calls := newServer(http.StatusOK)
+	defer server.Close()
+	client := New(Options{BaseURL: server.URL, DisableCache: true})
+
+	for i := 0; i < 12; i++ {
+		_, err := client.Get("/items/MLA1")
+		assert.Nil(t, err)
+	}
+	// the burst goes right away and every request after it waits 100ms for its token
+	assert.EqualValues(t, 10, len(clk.sleeps))
+	assert.EqualValues(t, 12, atomic.LoadInt32(calls))
+
+	// a token every two seconds is longer than a request is allowed to wait
+	limiter = newRateLimiter(0.5, 1)
+	_, err := client.Get("/items/MLA1")
+	assert.Nil(t, err)
+	_, err = client.Get("/items/MLA1")
+	assert.NotNil(t, err)
+	assert.EqualValues(t, http.StatusTooManyRequests, err.Status())
+	assert.EqualValues(t, "too_many_requests", err.Code())
+}
+
+func TestRequestOptionsOnlyApplyToTheirRequest(t *testing.T) {
+	setup(t, 0, 0)
+	var received []http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = append(received, r.Header.Clone())
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	client := New(Options{BaseURL: server.URL, DisableCache: true, Headers: http.Header{"X-Client": {"melist"}}})
+	_, err := client.Get("/users/me", WithBearerToken("abc"), WithHeader("X-Client", "jobs"))
+	assert.Nil(t, err)
+	_, err = client.Get("/users/me")
+	assert.Nil(t, err)
+
+	assert.EqualValues(t, 2, len(received))
+	assert.EqualValues(t, "Bearer abc", received[0].Get("Authorization"))
+	assert.EqualValues(t, "jobs", received[0].Get("X-Client"))
+	assert.EqualValues(t, "", received[1].Get("Authorization"))
+	assert.EqualValues(t, "melist", received[1].Get("X-Client"))
+}
@@ -0,0 +1,75 @@
+package restclient
+
+import (
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/lmurature/golang-restclient/rest"
+)
+
+var (
+	DefaultRetryPolicy = RetryPolicy{
+		MaxAttempts:   3,
+		BaseDelay:     100 * time.Millisecond,
+		MaxDelay:      2 * time.Second,
+		MaxRetryAfter: 5 * time.Second,
+	}
+
+	jitter = rand.Int63n
+)
+
+// RetryPolicy is how many times an idempotent request is sent and how long to wait between attempts. The wait doubles
+// on every attempt up to MaxDelay, with a random jitter, unless the response has a Retry-After. A Retry-After longer
+// than MaxRetryAfter is not waited for and the response is returned as it is.
+type RetryPolicy struct {
+	MaxAttempts   int
+	BaseDelay     time.Duration
+	MaxDelay      time.Duration
+	MaxRetryAfter time.Duration
+}
+
+// delay returns how long to wait after the given attempt, starting at 1, and whether to retry at all.
+func (p RetryPolicy) delay(attempt int, response *rest.Response) (time.Duration, bool) {
+	if retryAfter, ok := retryAfterOf(response); ok {
+		return retryAfter, retryAfter <= p.MaxRetryAfter
+	}
+
+	backoff := p.BaseDelay << uint(attempt-1)
+	if backoff > p.MaxDelay || backoff <= 0 {
+		backoff = p.MaxDelay
+	}
+	if backoff <= 0 {
+		return 0, true
+	}
+
+	// equal jitter: at least half of the backoff, so retries never pile up right away
+	half := backoff / 2
+	return half + time.Duration(jitter(int64(backoff-half)+1)), true
+}
+
+// retryAfterOf reads the Retry-After header, which holds either seconds or an HTTP date.
+func retryAfterOf(response *rest.Response) (time.Duration, bool) {
+	if response == nil || response.Response == nil {
+		return 0, false
+	}
+
+	value := response.Header.Get("Retry-After")
+	if value == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	if date, err := http.ParseTime(value); err == nil {
+		if wait := date.Sub(now()); wait > 0 {
+			return wait, true
+		}
+		return 0, true
+	}
+
+	return 0, false
+}
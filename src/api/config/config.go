@@ -2,49 +2,226 @@ package config
 
 import (
 	"fmt"
+	"net"
+	"net/url"
 	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	StorageMySql  = "mysql"
+	StorageMemory = "memory"
 )
 
 var (
-	AppId       int64 = 5112680121711673
-	RedirectUri string
-	SecretKey   string
+	AppId     int64 = 5112680121711673
+	SecretKey string
+
+	// RedirectUris are where Mercado Libre may send users back to after they authorize Melist, as registered in the
+	// application. A login goes back to the first one unless it asks for another.
+	RedirectUris []string
+
+	// MeliAuthUrl is where users are sent to authorize Melist in Mercado Libre. When empty, users authorize Melist in
+	// the domain of the site they log in to.
+	MeliAuthUrl string
+
+	// OAuthStateTtl is how long a login may take from its start to the token exchange.
+	OAuthStateTtl = 10 * time.Minute
+
+	// AppBaseUrl is the web app that links inside emails point to.
+	AppBaseUrl string
+
+	// AllowedOrigins are the web origins allowed to open notification WebSockets, by default the ones of AppBaseUrl
+	// and RedirectUris.
+	AllowedOrigins []string
+
+	// MeliBaseUrl points every Mercado Libre provider to the real API or to a local simulator.
+	MeliBaseUrl string
+
+	// StorageBackend selects where DAOs persist data: StorageMySql (default) or StorageMemory.
+	StorageBackend string
 
 	DbUser string
 	DbPass string
 	DbHost string
 	DbName string
 
+	// DbMigrations is what the API does with schema migrations when it starts: "up", "verify" or nothing.
+	DbMigrations string
+
 	ApiPort string
 
+	// MetricsAddr is the internal listener of the operational metrics, kept apart from the public API. It listens on
+	// the loopback interface by default and METRICS_ADDR=off turns it off.
+	MetricsAddr = "127.0.0.1:9091"
+
+	// ListTrashRetentionDays is how long a deleted list stays in the trash bin before it is purged.
+	ListTrashRetentionDays = 30
+
 	DbDateLayout = "2006-01-02 15:04:05"
 
+	// ItemsFanOutConcurrency and ItemsFanOutTimeout bound how list items are fetched from Mercado Libre.
+	ItemsFanOutConcurrency = 8
+	ItemsFanOutTimeout     = 10 * time.Second
+
+	// ProviderCacheEnabled turns off the cache of Mercado Libre responses when PROVIDER_CACHE is "off".
+	ProviderCacheEnabled = true
+
+	// ProviderCacheMaxEntries and ProviderCacheMaxBytes bound the in-memory cache of Mercado Libre responses.
+	ProviderCacheMaxEntries = 10000
+	ProviderCacheMaxBytes   = 64 << 20
+
+	// OutboundRateLimit and OutboundRateBurst bound the requests per second sent to Mercado Libre by every provider.
+	// A request waits at most OutboundRateMaxWait for its turn before failing with too many requests.
+	OutboundRateLimit   = 50.0
+	OutboundRateBurst   = 50
+	OutboundRateMaxWait = time.Second
+
+	// OutboundBreakerFailures consecutive failures of a host stop requests to it for OutboundBreakerOpenTime.
+	OutboundBreakerFailures = 5
+	OutboundBreakerOpenTime = 30 * time.Second
+
+	// SessionAccessTokenTtl and SessionRefreshTokenTtl are how long the tokens of a Melist session are valid for.
+	SessionAccessTokenTtl  = time.Hour
+	SessionRefreshTokenTtl = 30 * 24 * time.Hour
+
+	// TokenEncryptionKey encrypts the Mercado Libre tokens of users at rest. It is hashed into an AES-256 key, so it
+	// should be a long random secret.
+	TokenEncryptionKey string
+
+	// MeliTokenRefreshMargin is how long before it expires the Mercado Libre access token of a user is refreshed.
+	MeliTokenRefreshMargin = 10 * time.Minute
+
 	EmailAddress string
 	EmailPassword string
 
+	// MailMaxAttempts is how many times sending the mail of a notification is tried before giving up on it.
+	MailMaxAttempts = 5
+
+	// MailSender is the From of every email sent by Melist.
+	MailSender = "Melist <melistapplication@gmail.com>"
+
+	// SmtpAddress can point to a local sink such as src/cmd/mailsink; without EmailAddress mails are sent unauthenticated.
 	SmtpHost    = "smtp.gmail.com"
 	SmtpAddress = "smtp.gmail.com:587"
 )
 
 func init() {
 	if !isDevelopment() {
-		RedirectUri = "https://melist-app.herokuapp.com/auth/authorized"
+		AppBaseUrl = "https://melist-app.herokuapp.com"
 		DbUser = os.Getenv("DB_USER")
 		DbPass = os.Getenv("DB_PASS")
 		DbHost = os.Getenv("DB_HOST")
 		DbName = os.Getenv("DB_NAME")
 		ApiPort = fmt.Sprintf(":%s", os.Getenv("PORT"))
 	} else {
-		RedirectUri = "http://localhost:3000/auth/authorized"
+		AppBaseUrl = "http://localhost:3000"
 		DbUser = "root"
 		DbPass = "root"
 		DbName = "melist"
 		ApiPort = ":8080"
 	}
 
+	if appBaseUrl := os.Getenv("APP_BASE_URL"); appBaseUrl != "" {
+		AppBaseUrl = appBaseUrl
+	}
+
+	// OAUTH_REDIRECT_URIS is a comma separated list, by default the callback of the web app
+	RedirectUris = []string{AppBaseUrl + "/auth/authorized"}
+	if redirectUris := os.Getenv("OAUTH_REDIRECT_URIS"); redirectUris != "" {
+		RedirectUris = RedirectUris[:0]
+		for _, uri := range strings.Split(redirectUris, ",") {
+			if uri = strings.TrimSpace(uri); uri != "" {
+				RedirectUris = append(RedirectUris, uri)
+			}
+		}
+	}
+
+	// ALLOWED_ORIGINS is a comma separated list of origins such as https://melist-app.herokuapp.com
+	AllowedOrigins = make([]string, 0)
+	if allowedOrigins := os.Getenv("ALLOWED_ORIGINS"); allowedOrigins != "" {
+		for _, origin := range strings.Split(allowedOrigins, ",") {
+			if origin = strings.TrimSpace(origin); origin != "" {
+				AllowedOrigins = append(AllowedOrigins, origin)
+			}
+		}
+	} else {
+		for _, uri := range append([]string{AppBaseUrl}, RedirectUris...) {
+			if parsed, err := url.Parse(uri); err == nil && parsed.Scheme != "" && parsed.Host != "" {
+				AllowedOrigins = append(AllowedOrigins, parsed.Scheme+"://"+parsed.Host)
+			}
+		}
+	}
+
+	MeliAuthUrl = os.Getenv("MELI_AUTH_URL")
+
+	MeliBaseUrl = os.Getenv("MELI_BASE_URL")
+	if MeliBaseUrl == "" {
+		MeliBaseUrl = "https://api.mercadolibre.com"
+	}
+
+	if metricsAddr := os.Getenv("METRICS_ADDR"); metricsAddr != "" {
+		MetricsAddr = metricsAddr
+	}
+
+	StorageBackend = os.Getenv("STORAGE_BACKEND")
+	if StorageBackend == "" {
+		StorageBackend = StorageMySql
+	}
+
+	DbMigrations = os.Getenv("DB_MIGRATIONS")
+
+	if retention, err := strconv.Atoi(os.Getenv("LIST_TRASH_RETENTION_DAYS")); err == nil && retention > 0 {
+		ListTrashRetentionDays = retention
+	}
+
+	ProviderCacheEnabled = os.Getenv("PROVIDER_CACHE") != "off"
+
+	if maxEntries, err := strconv.Atoi(os.Getenv("PROVIDER_CACHE_MAX_ENTRIES")); err == nil && maxEntries >= 0 {
+		ProviderCacheMaxEntries = maxEntries
+	}
+
+	if maxBytes, err := strconv.Atoi(os.Getenv("PROVIDER_CACHE_MAX_BYTES")); err == nil && maxBytes >= 0 {
+		ProviderCacheMaxBytes = maxBytes
+	}
+
+	if rateLimit, err := strconv.ParseFloat(os.Getenv("OUTBOUND_RATE_LIMIT"), 64); err == nil {
+		OutboundRateLimit = rateLimit
+	}
+
+	if rateBurst, err := strconv.Atoi(os.Getenv("OUTBOUND_RATE_BURST")); err == nil && rateBurst > 0 {
+		OutboundRateBurst = rateBurst
+	}
+
+	if accessTtl, err := time.ParseDuration(os.Getenv("SESSION_ACCESS_TOKEN_TTL")); err == nil && accessTtl > 0 {
+		SessionAccessTokenTtl = accessTtl
+	}
+
+	if refreshTtl, err := time.ParseDuration(os.Getenv("SESSION_REFRESH_TOKEN_TTL")); err == nil && refreshTtl > 0 {
+		SessionRefreshTokenTtl = refreshTtl
+	}
+
 	SecretKey = os.Getenv("SECRET_KEY")
+	TokenEncryptionKey = os.Getenv("TOKEN_ENCRYPTION_KEY")
+	if TokenEncryptionKey == "" && isDevelopment() {
+		TokenEncryptionKey = "melist-development-token-encryption-key"
+	}
+
 	EmailAddress = os.Getenv("EMAIL_ADDRESS")
 	EmailPassword = os.Getenv("EMAIL_PASSWORD")
+
+	if sender := os.Getenv("MAIL_SENDER"); sender != "" {
+		MailSender = sender
+	}
+
+	if smtpAddress := os.Getenv("SMTP_ADDRESS"); smtpAddress != "" {
+		SmtpAddress = smtpAddress
+		if host, _, err := net.SplitHostPort(smtpAddress); err == nil {
+			SmtpHost = host
+		}
+	}
 }
 
 func isDevelopment() bool {
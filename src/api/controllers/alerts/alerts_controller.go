@@ -0,0 +1,93 @@
+package alerts_controller
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/lmurature/melist-api/src/api/domain/alerts"
+	"github.com/lmurature/melist-api/src/api/domain/apierrors"
+	alerts_service "github.com/lmurature/melist-api/src/api/services/alerts"
+	"net/http"
+	"strconv"
+)
+
+func CreateRule(c *gin.Context) {
+	listParam := c.Param("list_id")
+	listId, err := strconv.ParseInt(listParam, 10, 64)
+	if err != nil {
+		br := apierrors.NewBadRequestApiError("list id must be an integer")
+		c.JSON(br.Status(), br)
+		return
+	}
+
+	var rule alerts.AlertRule
+	if err := c.ShouldBindJSON(&rule); err != nil {
+		br := apierrors.NewBadRequestApiError("invalid alert rule json body")
+		c.JSON(br.Status(), br)
+		return
+	}
+	rule.ListId = listId
+
+	userId, _ := c.Get("user_id")
+	callerId := userId.(int64)
+
+	result, createErr := alerts_service.AlertsService.CreateRule(rule, callerId)
+	if createErr != nil {
+		c.JSON(createErr.Status(), createErr)
+		return
+	}
+
+	c.JSON(http.StatusCreated, result)
+}
+
+func GetListRules(c *gin.Context) {
+	listParam := c.Param("list_id")
+	listId, err := strconv.ParseInt(listParam, 10, 64)
+	if err != nil {
+		br := apierrors.NewBadRequestApiError("list id must be an integer")
+		c.JSON(br.Status(), br)
+		return
+	}
+
+	userId, _ := c.Get("user_id")
+	callerId := userId.(int64)
+
+	result, getErr := alerts_service.AlertsService.GetListRules(listId, callerId)
+	if getErr != nil {
+		c.JSON(getErr.Status(), getErr)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+func GetMyRules(c *gin.Context) {
+	userId, _ := c.Get("user_id")
+	callerId := userId.(int64)
+
+	result, err := alerts_service.AlertsService.GetMyRules(callerId)
+	if err != nil {
+		c.JSON(err.Status(), err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+func DeleteRule(c *gin.Context) {
+	ruleParam := c.Param("rule_id")
+	ruleId, err := strconv.ParseInt(ruleParam, 10, 64)
+	if err != nil {
+		br := apierrors.NewBadRequestApiError("rule id must be an integer")
+		c.JSON(br.Status(), br)
+		return
+	}
+
+	userId, _ := c.Get("user_id")
+	callerId := userId.(int64)
+
+	if err := alerts_service.AlertsService.DeleteRule(ruleId, callerId); err != nil {
+		c.JSON(err.Status(), err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "deleted"})
+}
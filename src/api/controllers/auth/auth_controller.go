@@ -4,10 +4,28 @@ import (
 	"github.com/gin-gonic/gin"
 	apierrors2 "github.com/lmurature/melist-api/src/api/domain/apierrors"
 	auth2 "github.com/lmurature/melist-api/src/api/domain/auth"
+	"github.com/lmurature/melist-api/src/api/domain/sessions"
 	auth_service2 "github.com/lmurature/melist-api/src/api/services/auth"
 	"net/http"
 )
 
+func StartLogin(c *gin.Context) {
+	var request auth2.LoginRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		jsonErr := apierrors2.NewBadRequestApiError("bad request body")
+		c.JSON(http.StatusBadRequest, jsonErr)
+		return
+	}
+
+	response, err := auth_service2.AuthService.StartLogin(c.Query("redirect_uri"), c.Query("site_id"), request.Nonce)
+	if err != nil {
+		c.JSON(err.Status(), err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
 func AuthenticateUser(c *gin.Context) {
 	var request auth2.ClientAuthRequest
 	if err := c.ShouldBindJSON(&request); err != nil {
@@ -16,7 +34,7 @@ func AuthenticateUser(c *gin.Context) {
 		return
 	}
 
-	response, err := auth_service2.AuthService.AuthenticateUser(request.AuthorizationCode)
+	response, err := auth_service2.AuthService.AuthenticateUser(request.AuthorizationCode, request.State, request.Nonce)
 	if err != nil {
 		c.JSON(err.Status(), err)
 		return
@@ -41,3 +59,15 @@ func RefreshAuthentication(c *gin.Context) {
 
 	c.JSON(http.StatusOK, response)
 }
+
+func Logout(c *gin.Context) {
+	session, _ := c.Get("session")
+	everywhere := c.Query("everywhere") == "true"
+
+	if err := auth_service2.AuthService.Logout(session.(sessions.Session), everywhere); err != nil {
+		c.JSON(err.Status(), err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "logged out"})
+}
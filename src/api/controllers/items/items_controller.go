@@ -3,7 +3,10 @@ package items_controller
 import (
 	"github.com/gin-gonic/gin"
 	"github.com/lmurature/melist-api/src/api/domain/apierrors"
+	"github.com/lmurature/melist-api/src/api/domain/items"
+	"github.com/lmurature/melist-api/src/api/domain/sites"
 	items_service "github.com/lmurature/melist-api/src/api/services/items"
+	users_service "github.com/lmurature/melist-api/src/api/services/users"
 	"net/http"
 	"net/url"
 	"strconv"
@@ -25,7 +28,23 @@ func SearchItems(c *gin.Context) {
 		return
 	}
 
-	result, err := items_service.ItemsService.SearchItems(url.QueryEscape(query), offset)
+	limitParam := c.DefaultQuery("limit", "0")
+	limit, parseErr := strconv.Atoi(limitParam)
+	if parseErr != nil {
+		br := apierrors.NewBadRequestApiError("limit must be a number")
+		c.JSON(br.Status(), br)
+		return
+	}
+
+	search := items.ItemSearch{
+		Query:   url.QueryEscape(query),
+		Offset:  offset,
+		Limit:   limit,
+		Sort:    c.Query("sort"),
+		Filters: items.SearchFiltersFromQuery(c.Request.URL.Query()),
+	}
+
+	result, err := items_service.ItemsService.SearchItems(getSiteId(c), search)
 	if err != nil {
 		c.JSON(err.Status(), err)
 		return
@@ -59,7 +78,14 @@ func GetItemHistory(c *gin.Context) {
 		return
 	}
 
-	result, err := items_service.ItemsService.GetItemHistory(itemId)
+	variationId, parseErr := strconv.ParseInt(c.DefaultQuery("variation_id", "0"), 10, 64)
+	if parseErr != nil {
+		err := apierrors.NewBadRequestApiError("'variation_id' must be an integer")
+		c.JSON(err.Status(), err)
+		return
+	}
+
+	result, err := items_service.ItemsService.GetItemHistory(itemId, variationId)
 	if err != nil {
 		c.JSON(err.Status(), err)
 		return
@@ -93,7 +119,7 @@ func GetCategoryTrends(c *gin.Context) {
 		return
 	}
 
-	result, err := items_service.ItemsService.GetCategoryTrends(categoryId)
+	result, err := items_service.ItemsService.GetCategoryTrends(c.Query("site_id"), categoryId)
 	if err != nil {
 		c.JSON(err.Status(), err)
 		return
@@ -101,3 +127,19 @@ func GetCategoryTrends(c *gin.Context) {
 
 	c.JSON(http.StatusOK, result)
 }
+
+// getSiteId returns the site asked for with the 'site_id' query parameter or, by default, the site of the caller.
+func getSiteId(c *gin.Context) string {
+	if siteId := c.Query("site_id"); siteId != "" {
+		return siteId
+	}
+
+	callerId, _ := c.Get("user_id")
+	if userId, ok := callerId.(int64); ok {
+		if user, err := users_service.UsersService.GetUserFromDb(userId); err == nil {
+			return sites.GetSiteIdOrDefault(user.SiteId)
+		}
+	}
+
+	return sites.DefaultSiteId
+}
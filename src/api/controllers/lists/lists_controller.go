@@ -3,9 +3,11 @@ package lists
 import (
 	"github.com/gin-gonic/gin"
 	"github.com/lmurature/melist-api/src/api/domain/apierrors"
+	"github.com/lmurature/melist-api/src/api/domain/items"
 	"github.com/lmurature/melist-api/src/api/domain/lists"
 	"github.com/lmurature/melist-api/src/api/domain/share"
 	lists_service "github.com/lmurature/melist-api/src/api/services/lists"
+	i18n_utils "github.com/lmurature/melist-api/src/api/utils/i18n"
 	"net/http"
 	"strconv"
 )
@@ -214,16 +216,23 @@ func AddItemsToList(c *gin.Context) {
 		return
 	}
 
-	var variationId int64
-	variationIdParam := c.Param("variation_id")
-	if variationIdParam != "" {
-		var err error
-		variationId, err = strconv.ParseInt(variationIdParam, 10, 64)
-		if err != nil {
-			br := apierrors.NewBadRequestApiError("variation id must be an integer")
+	variationId, varErr := getVariationId(c)
+	if varErr != nil {
+		c.JSON(varErr.Status(), varErr)
+		return
+	}
+
+	// the variation can also come in the body, for clients that send the chosen item as json
+	if c.Request.ContentLength > 0 {
+		var body addItemBody
+		if err := c.ShouldBindJSON(&body); err != nil {
+			br := apierrors.NewBadRequestApiError("invalid item json body")
 			c.JSON(br.Status(), br)
 			return
 		}
+		if body.VariationId != 0 {
+			variationId = body.VariationId
+		}
 	}
 
 	userId, _ := c.Get("user_id")
@@ -238,6 +247,25 @@ func AddItemsToList(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"status": "added"})
 }
 
+// addItemBody is the optional body of AddItemsToList.
+type addItemBody struct {
+	VariationId int64 `json:"variation_id"`
+}
+
+// getVariationId reads the variation of the item from the variation_id query parameter, 0 when there is none.
+func getVariationId(c *gin.Context) (int64, apierrors.ApiError) {
+	variationIdParam := c.Query("variation_id")
+	if variationIdParam == "" {
+		return 0, nil
+	}
+
+	variationId, err := strconv.ParseInt(variationIdParam, 10, 64)
+	if err != nil || variationId < 0 {
+		return 0, apierrors.NewBadRequestApiError("variation id must be an integer")
+	}
+	return variationId, nil
+}
+
 func GetItems(c *gin.Context) {
 	listParam := c.Param("list_id")
 	listId, err := strconv.ParseInt(listParam, 10, 64)
@@ -258,7 +286,22 @@ func GetItems(c *gin.Context) {
 	userId, _ := c.Get("user_id")
 	callerId := userId.(int64)
 
-	items, getErr := lists_service.ListsService.GetItemsFromList(listId, callerId, info)
+	strict, err := strconv.ParseBool(c.DefaultQuery("strict", "false"))
+	if err != nil {
+		br := apierrors.NewBadRequestApiError("strict must be a boolean [true or false]")
+		c.JSON(br.Status(), br)
+		return
+	}
+
+	// by default, items that cannot be fetched from Mercado Libre are returned with their error instead of failing the list
+	mode := lists_service.InfoNone
+	if info && strict {
+		mode = lists_service.InfoStrict
+	} else if info {
+		mode = lists_service.InfoPartial
+	}
+
+	items, getErr := lists_service.ListsService.GetItemsFromList(c.Request.Context(), listId, callerId, mode)
 	if getErr != nil {
 		c.JSON(getErr.Status(), getErr)
 		return
@@ -283,10 +326,16 @@ func DeleteItem(c *gin.Context) {
 		return
 	}
 
+	variationId, varErr := getVariationId(c)
+	if varErr != nil {
+		c.JSON(varErr.Status(), varErr)
+		return
+	}
+
 	userId, _ := c.Get("user_id")
 	callerId := userId.(int64)
 
-	result, addErr := lists_service.ListsService.DeleteItemFromList(itemId, listId, callerId)
+	result, addErr := lists_service.ListsService.DeleteItemFromList(itemId, variationId, listId, callerId)
 	if addErr != nil {
 		c.JSON(addErr.Status(), addErr)
 		return
@@ -311,10 +360,16 @@ func CheckItem(c *gin.Context) {
 		return
 	}
 
+	variationId, varErr := getVariationId(c)
+	if varErr != nil {
+		c.JSON(varErr.Status(), varErr)
+		return
+	}
+
 	userId, _ := c.Get("user_id")
 	callerId := userId.(int64)
 
-	result, addErr := lists_service.ListsService.CheckItem(itemId, listId, callerId)
+	result, addErr := lists_service.ListsService.CheckItem(itemId, variationId, listId, callerId)
 	if addErr != nil {
 		c.JSON(addErr.Status(), addErr)
 		return
@@ -323,6 +378,47 @@ func CheckItem(c *gin.Context) {
 	c.JSON(http.StatusOK, result)
 }
 
+func UpdateListItem(c *gin.Context) {
+	listParam := c.Param("list_id")
+	listId, err := strconv.ParseInt(listParam, 10, 64)
+	if err != nil {
+		br := apierrors.NewBadRequestApiError("list id must be an integer")
+		c.JSON(br.Status(), br)
+		return
+	}
+
+	itemId := c.Param("item_id")
+	if itemId == "" {
+		br := apierrors.NewBadRequestApiError("item id is mandatory")
+		c.JSON(br.Status(), br)
+		return
+	}
+
+	variationId, varErr := getVariationId(c)
+	if varErr != nil {
+		c.JSON(varErr.Status(), varErr)
+		return
+	}
+
+	var update items.ItemListUpdate
+	if err := c.ShouldBindJSON(&update); err != nil {
+		br := apierrors.NewBadRequestApiError("invalid item json body")
+		c.JSON(br.Status(), br)
+		return
+	}
+
+	userId, _ := c.Get("user_id")
+	callerId := userId.(int64)
+
+	result, updateErr := lists_service.ListsService.UpdateListItem(itemId, variationId, listId, callerId, update)
+	if updateErr != nil {
+		c.JSON(updateErr.Status(), updateErr)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
 func UncheckItem(c *gin.Context) {
 	listParam := c.Param("list_id")
 	listId, err := strconv.ParseInt(listParam, 10, 64)
@@ -339,10 +435,16 @@ func UncheckItem(c *gin.Context) {
 		return
 	}
 
+	variationId, varErr := getVariationId(c)
+	if varErr != nil {
+		c.JSON(varErr.Status(), varErr)
+		return
+	}
+
 	userId, _ := c.Get("user_id")
 	callerId := userId.(int64)
 
-	result, addErr := lists_service.ListsService.UncheckItem(itemId, listId, callerId)
+	result, addErr := lists_service.ListsService.UncheckItem(itemId, variationId, listId, callerId)
 	if addErr != nil {
 		c.JSON(addErr.Status(), addErr)
 		return
@@ -439,7 +541,7 @@ func GetListNotifications(c *gin.Context) {
 		return
 	}
 
-	result, resErr := lists_service.ListsService.GetListNotifications(listId, callerId)
+	result, resErr := lists_service.ListsService.GetListNotifications(listId, callerId, i18n_utils.GetRequestLocale(c))
 	if resErr != nil {
 		c.JSON(resErr.Status(), resErr)
 		return
@@ -464,10 +566,16 @@ func GetListItemStatus(c *gin.Context) {
 		return
 	}
 
+	variationId, varErr := getVariationId(c)
+	if varErr != nil {
+		c.JSON(varErr.Status(), varErr)
+		return
+	}
+
 	userId, _ := c.Get("user_id")
 	callerId := userId.(int64)
 
-	result, addErr := lists_service.ListsService.GetListItemStatus(itemId, listId, callerId)
+	result, addErr := lists_service.ListsService.GetListItemStatus(itemId, variationId, listId, callerId)
 	if addErr != nil {
 		c.JSON(addErr.Status(), addErr)
 		return
@@ -475,3 +583,58 @@ func GetListItemStatus(c *gin.Context) {
 
 	c.JSON(http.StatusOK, result)
 }
+
+func DeleteList(c *gin.Context) {
+	// Only the owner can do this. The list goes to the trash bin and can be restored until it is purged.
+	listParam := c.Param("list_id")
+	listId, err := strconv.ParseInt(listParam, 10, 64)
+	if err != nil {
+		br := apierrors.NewBadRequestApiError("list id must be an integer")
+		c.JSON(br.Status(), br)
+		return
+	}
+
+	userId, _ := c.Get("user_id")
+	callerId := userId.(int64)
+
+	if err := lists_service.ListsService.DeleteList(listId, callerId); err != nil {
+		c.JSON(err.Status(), err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "list moved to trash"})
+}
+
+func RestoreList(c *gin.Context) {
+	listParam := c.Param("list_id")
+	listId, err := strconv.ParseInt(listParam, 10, 64)
+	if err != nil {
+		br := apierrors.NewBadRequestApiError("list id must be an integer")
+		c.JSON(br.Status(), br)
+		return
+	}
+
+	userId, _ := c.Get("user_id")
+	callerId := userId.(int64)
+
+	result, resErr := lists_service.ListsService.RestoreList(listId, callerId)
+	if resErr != nil {
+		c.JSON(resErr.Status(), resErr)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+func GetTrashedLists(c *gin.Context) {
+	userId, _ := c.Get("user_id")
+	callerId := userId.(int64)
+
+	result, err := lists_service.ListsService.GetTrashedLists(callerId)
+	if err != nil {
+		c.JSON(err.Status(), err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,16 @@
+package metrics_controller
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/lmurature/melist-api/src/api/clients/restclient"
+	items_provider "github.com/lmurature/melist-api/src/api/providers/items"
+	"net/http"
+)
+
+func GetCacheStats(c *gin.Context) {
+	c.JSON(http.StatusOK, items_provider.CacheStats())
+}
+
+func GetOutboundStats(c *gin.Context) {
+	c.JSON(http.StatusOK, restclient.Stats())
+}
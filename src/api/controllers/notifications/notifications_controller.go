@@ -0,0 +1,57 @@
+package notifications_controller
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/lmurature/melist-api/src/api/domain/apierrors"
+	notifications_service "github.com/lmurature/melist-api/src/api/services/notifications"
+	i18n_utils "github.com/lmurature/melist-api/src/api/utils/i18n"
+	"net/http"
+	"strconv"
+)
+
+func GetInbox(c *gin.Context) {
+	userId, _ := c.Get("user_id")
+	callerId := userId.(int64)
+
+	onlyUnread := c.Query("unread") == "true"
+
+	result, err := notifications_service.NotificationsService.GetInbox(callerId, onlyUnread, i18n_utils.GetRequestLocale(c))
+	if err != nil {
+		c.JSON(err.Status(), err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+func MarkAsSeen(c *gin.Context) {
+	notificationParam := c.Param("notification_id")
+	notificationId, err := strconv.ParseInt(notificationParam, 10, 64)
+	if err != nil {
+		br := apierrors.NewBadRequestApiError("notification id must be an integer")
+		c.JSON(br.Status(), br)
+		return
+	}
+
+	userId, _ := c.Get("user_id")
+	callerId := userId.(int64)
+
+	if err := notifications_service.NotificationsService.MarkAsSeen(notificationId, callerId); err != nil {
+		c.JSON(err.Status(), err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "notification marked as seen"})
+}
+
+func MarkAllAsSeen(c *gin.Context) {
+	userId, _ := c.Get("user_id")
+	callerId := userId.(int64)
+
+	if err := notifications_service.NotificationsService.MarkAllAsSeen(callerId); err != nil {
+		c.JSON(err.Status(), err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "notifications marked as seen"})
+}
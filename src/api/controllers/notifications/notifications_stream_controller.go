@@ -0,0 +1,201 @@
+package notifications_controller
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	"github.com/lmurature/melist-api/src/api/domain/apierrors"
+	"github.com/lmurature/melist-api/src/api/domain/notifications"
+	notifications_service "github.com/lmurature/melist-api/src/api/services/notifications"
+	http_utils "github.com/lmurature/melist-api/src/api/utils/http"
+	i18n_utils "github.com/lmurature/melist-api/src/api/utils/i18n"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	heartbeatInterval = 25 * time.Second
+	wsWriteTimeout    = 10 * time.Second
+	wsReadTimeout     = 2 * heartbeatInterval
+)
+
+var (
+	upgrader = websocket.Upgrader{
+		// the token of the stream travels in the url, so only pages of the web app may open it: any other site that
+		// got hold of a token could otherwise read the notifications of its user from the victim's browser
+		CheckOrigin: func(r *http.Request) bool { return http_utils.IsAllowedOrigin(r.Header.Get("Origin")) },
+	}
+)
+
+// StreamNotifications streams the caller's notifications as Server-Sent Events. Clients resume after a reconnect
+// with the Last-Event-ID header, which EventSource sends automatically, or the last_event_id query parameter.
+func StreamNotifications(c *gin.Context) {
+	callerId, lastEventId, ok := streamParams(c, c.GetHeader("Last-Event-ID"))
+	if !ok {
+		return
+	}
+	locale := i18n_utils.GetRequestLocale(c)
+
+	subscription, backlog, err := notifications_service.NotificationsService.OpenStream(callerId, lastEventId)
+	if err != nil {
+		c.JSON(err.Status(), err)
+		return
+	}
+	defer subscription.Close()
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+
+	for _, n := range backlog {
+		writeEvent(c, n.Localize(locale))
+	}
+	c.Writer.Flush()
+	sent := sentIds(backlog)
+
+	heartbeat := time.NewTicker(heartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case n, open := <-subscription.C:
+			if !open {
+				// the client fell behind, it reconnects and resumes from the last event it got
+				return
+			}
+			if sent[n.Id] {
+				continue
+			}
+			writeEvent(c, n.Localize(locale))
+			c.Writer.Flush()
+		case <-heartbeat.C:
+			fmt.Fprint(c.Writer, ": heartbeat\n\n")
+			c.Writer.Flush()
+		}
+	}
+}
+
+// StreamNotificationsWebSocket streams the caller's notifications as JSON text messages over a WebSocket.
+// Clients resume after a reconnect with the last_event_id query parameter.
+func StreamNotificationsWebSocket(c *gin.Context) {
+	callerId, lastEventId, ok := streamParams(c, "")
+	if !ok {
+		return
+	}
+	locale := i18n_utils.GetRequestLocale(c)
+
+	subscription, backlog, apiErr := notifications_service.NotificationsService.OpenStream(callerId, lastEventId)
+	if apiErr != nil {
+		c.JSON(apiErr.Status(), apiErr)
+		return
+	}
+	defer subscription.Close()
+
+	conn, err := upgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		logrus.Error("error when trying to upgrade notifications stream to websocket", err)
+		return
+	}
+	defer conn.Close()
+
+	// the client is not expected to send anything, reading only handles pongs and detects the close
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+		conn.SetReadDeadline(time.Now().Add(wsReadTimeout))
+		conn.SetPongHandler(func(string) error {
+			return conn.SetReadDeadline(time.Now().Add(wsReadTimeout))
+		})
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	write := func(n notifications.Notification) bool {
+		conn.SetWriteDeadline(time.Now().Add(wsWriteTimeout))
+		return conn.WriteJSON(n.Localize(locale)) == nil
+	}
+
+	for _, n := range backlog {
+		if !write(n) {
+			return
+		}
+	}
+	sent := sentIds(backlog)
+
+	heartbeat := time.NewTicker(heartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-closed:
+			return
+		case n, open := <-subscription.C:
+			if !open {
+				conn.WriteControl(websocket.CloseMessage,
+					websocket.FormatCloseMessage(websocket.CloseTryAgainLater, "stream fell behind, resume from last event id"),
+					time.Now().Add(wsWriteTimeout))
+				return
+			}
+			if sent[n.Id] {
+				continue
+			}
+			if !write(n) {
+				return
+			}
+		case <-heartbeat.C:
+			if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(wsWriteTimeout)); err != nil {
+				return
+			}
+		}
+	}
+}
+
+func streamParams(c *gin.Context, lastEventHeader string) (int64, int64, bool) {
+	userId, _ := c.Get("user_id")
+	callerId := userId.(int64)
+
+	lastEventParam := lastEventHeader
+	if lastEventParam == "" {
+		lastEventParam = c.Query("last_event_id")
+	}
+
+	var lastEventId int64
+	if lastEventParam != "" {
+		parsed, err := strconv.ParseInt(lastEventParam, 10, 64)
+		if err != nil || parsed < 0 {
+			br := apierrors.NewBadRequestApiError("last event id must be a positive integer")
+			c.JSON(br.Status(), br)
+			return 0, 0, false
+		}
+		lastEventId = parsed
+	}
+
+	return callerId, lastEventId, true
+}
+
+// sentIds are the ids of the backlog, which live notifications may repeat since the subscription opens before the
+// backlog is read. Ids are assigned when notifications are saved but published when their transaction commits, so a
+// live notification can have a lower id than one already sent and only repeated ids are skipped.
+func sentIds(backlog []notifications.Notification) map[int64]bool {
+	result := make(map[int64]bool, len(backlog))
+	for _, n := range backlog {
+		result[n.Id] = true
+	}
+	return result
+}
+
+func writeEvent(c *gin.Context, n notifications.Notification) {
+	data, _ := json.Marshal(n)
+	fmt.Fprintf(c.Writer, "id: %d\nevent: notification\ndata: %s\n\n", n.Id, data)
+}
@@ -3,6 +3,8 @@ package users_controller
 import (
 	"github.com/gin-gonic/gin"
 	"github.com/lmurature/melist-api/src/api/domain/apierrors"
+	"github.com/lmurature/melist-api/src/api/domain/users"
+	mail_service "github.com/lmurature/melist-api/src/api/services/mail"
 	users_service "github.com/lmurature/melist-api/src/api/services/users"
 	"net/http"
 	"strconv"
@@ -10,8 +12,8 @@ import (
 
 
 func GetUserMe(c *gin.Context) {
-	token, _ := c.Get("token")
-	user, userErr := users_service.UsersService.GetMyUser(token.(string))
+	userId, _ := c.Get("user_id")
+	user, userErr := users_service.UsersService.GetSessionUser(userId.(int64))
 	if userErr != nil {
 		c.JSON(userErr.Status(), userErr)
 		return
@@ -69,3 +71,33 @@ func GetPendingUsersByList(c *gin.Context) {
 
 	c.JSON(http.StatusOK, result)
 }
+
+func GetMailPreferences(c *gin.Context) {
+	callerId, _ := c.Get("user_id")
+
+	result, err := mail_service.MailService.GetMailPreferences(callerId.(int64))
+	if err != nil {
+		c.JSON(err.Status(), err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+func UpdateMailPreferences(c *gin.Context) {
+	var preferences users.MailPreferences
+	if err := c.ShouldBindJSON(&preferences); err != nil {
+		br := apierrors.NewBadRequestApiError("invalid mail preferences json body")
+		c.JSON(br.Status(), br)
+		return
+	}
+	callerId, _ := c.Get("user_id")
+
+	result, err := mail_service.MailService.UpdateMailPreferences(callerId.(int64), preferences)
+	if err != nil {
+		c.JSON(err.Status(), err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
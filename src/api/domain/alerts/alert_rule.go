@@ -0,0 +1,168 @@
+package alerts
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/lmurature/melist-api/src/api/domain/apierrors"
+	"github.com/lmurature/melist-api/src/api/domain/items"
+	"github.com/lmurature/melist-api/src/api/domain/notifications"
+)
+
+const (
+	RuleTypePriceDrop       = "price_drop"
+	RuleTypePriceBelow      = "price_below"
+	RuleTypeBackInStock     = "back_in_stock"
+	RuleTypeStockBelow      = "stock_below"
+	RuleTypeLowRatingReview = "low_rating_review"
+	RuleTypeHistoricalLow   = "historical_low"
+)
+
+var (
+	RuleTypes = []string{RuleTypePriceDrop, RuleTypePriceBelow, RuleTypeBackInStock, RuleTypeStockBelow,
+		RuleTypeLowRatingReview, RuleTypeHistoricalLow}
+)
+
+// AlertRule is an alert a user sets on a list, or on a single item of it, to be notified of changes the items job
+// does not tell everyone about. Threshold is the percentage of a price_drop, the price of a price_below, the units of
+// a stock_below and the highest rating of a low_rating_review; the other types have none.
+type AlertRule struct {
+	Id          int64   `json:"id"`
+	UserId      int64   `json:"user_id"`
+	ListId      int64   `json:"list_id"`
+	ItemId      string  `json:"item_id,omitempty"`
+	VariationId int64   `json:"variation_id,omitempty"`
+	Type        string  `json:"type"`
+	Threshold   float32 `json:"threshold,omitempty"`
+	DateCreated string  `json:"date_created"`
+}
+
+type AlertRules []AlertRule
+
+// Observation is what the items job knows of an item of a list when it evaluates the alert rules.
+type Observation struct {
+	ListId      int64
+	Item        *items.Item
+	LastHistory *items.ItemHistory
+
+	// StockReliable is whether the available quantity of Item and LastHistory can be trusted for stock alerts.
+	StockReliable bool
+
+	// LowestPrice is the lowest price in the history of the item, 0 when it was not looked up.
+	LowestPrice float32
+
+	// NewReviews are the reviews of the item published since LastHistory.
+	NewReviews []items.Review
+}
+
+func (r AlertRule) Validate() apierrors.ApiError {
+	causes := apierrors.CauseList{}
+
+	switch r.Type {
+	case RuleTypePriceDrop:
+		if r.Threshold <= 0 || r.Threshold >= 100 {
+			causes = append(causes, "threshold of a price_drop must be a percentage between 0 and 100")
+		}
+	case RuleTypePriceBelow:
+		if r.Threshold <= 0 {
+			causes = append(causes, "threshold of a price_below must be a positive price")
+		}
+	case RuleTypeStockBelow:
+		if r.Threshold < 1 || r.Threshold != float32(int(r.Threshold)) {
+			causes = append(causes, "threshold of a stock_below must be a whole number of units")
+		}
+	case RuleTypeLowRatingReview:
+		if r.Threshold < 1 || r.Threshold > 5 || r.Threshold != float32(int(r.Threshold)) {
+			causes = append(causes, "threshold of a low_rating_review must be a rating between 1 and 5")
+		}
+	case RuleTypeBackInStock, RuleTypeHistoricalLow:
+	default:
+		causes = append(causes, fmt.Sprintf("invalid type '%s', expected one of %s", r.Type, strings.Join(RuleTypes, ", ")))
+	}
+
+	if r.VariationId != 0 && r.ItemId == "" {
+		causes = append(causes, "a variation can only be chosen along with its item")
+	}
+
+	if len(causes) > 0 {
+		return apierrors.NewValidationApiError("invalid alert rule", "bad_request", causes)
+	}
+	return nil
+}
+
+// AppliesTo is whether the rule watches the item of the list with that variation. Rules without an item watch every
+// item of the list.
+func (r AlertRule) AppliesTo(itemId string, variationId int64) bool {
+	return r.ItemId == "" || (r.ItemId == itemId && r.VariationId == variationId)
+}
+
+// Evaluate returns the notification of the rule for the user that set it, or nil when the item does not meet the rule.
+// Rules only fire on a change since the last history, so an alert is not repeated on every run of the job.
+func (r AlertRule) Evaluate(o Observation) *notifications.Notification {
+	if o.Item == nil || o.LastHistory == nil {
+		return nil
+	}
+	item, last := o.Item, o.LastHistory
+
+	var n *notifications.Notification
+	switch r.Type {
+	case RuleTypePriceDrop:
+		if last.Price > 0 && item.Price < last.Price && (last.Price-item.Price)*100/last.Price >= r.Threshold {
+			n = notifications.NewPriceDropAlertNotification(o.ListId, item.Id, last.Price, item.Price, r.Threshold, item.CurrencyId, item.Title)
+		}
+	case RuleTypePriceBelow:
+		if last.Price >= r.Threshold && item.Price < r.Threshold {
+			n = notifications.NewPriceBelowAlertNotification(o.ListId, item.Id, item.Price, r.Threshold, item.CurrencyId, item.Title)
+		}
+	case RuleTypeBackInStock:
+		if o.StockReliable && last.Quantity == 0 && item.AvailableQuantity > 0 {
+			n = notifications.NewBackInStockAlertNotification(o.ListId, item.Id, item.AvailableQuantity, item.Title)
+		}
+	case RuleTypeStockBelow:
+		units := int(r.Threshold)
+		if o.StockReliable && last.Quantity >= units && item.AvailableQuantity < units {
+			n = notifications.NewStockBelowAlertNotification(o.ListId, item.Id, item.AvailableQuantity, r.Threshold, item.Title)
+		}
+	case RuleTypeLowRatingReview:
+		lowest := 0
+		for _, review := range o.NewReviews {
+			if float32(review.Rate) <= r.Threshold && (lowest == 0 || review.Rate < lowest) {
+				lowest = review.Rate
+			}
+		}
+		if lowest > 0 {
+			n = notifications.NewLowRatingReviewAlertNotification(o.ListId, item.Id, lowest, item.Title)
+		}
+	case RuleTypeHistoricalLow:
+		if o.LowestPrice > 0 && item.Price < o.LowestPrice {
+			n = notifications.NewHistoricalLowAlertNotification(o.ListId, item.Id, o.LowestPrice, item.Price, item.CurrencyId, item.Title)
+		}
+	}
+
+	if n == nil {
+		return nil
+	}
+	n.RecipientId = r.UserId
+	n.Params.RuleId = r.Id
+	return n
+}
+
+// ForItem returns the rules that watch the item of the list with that variation.
+func (rules AlertRules) ForItem(itemId string, variationId int64) AlertRules {
+	result := make(AlertRules, 0)
+	for _, r := range rules {
+		if r.AppliesTo(itemId, variationId) {
+			result = append(result, r)
+		}
+	}
+	return result
+}
+
+func (rules AlertRules) HasType(ruleType string) bool {
+	for _, r := range rules {
+		if r.Type == ruleType {
+			return true
+		}
+	}
+	return false
+}
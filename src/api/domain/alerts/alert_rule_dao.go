@@ -0,0 +1,145 @@
+package alerts
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/lmurature/melist-api/src/api/clients/database"
+	"github.com/lmurature/melist-api/src/api/domain/apierrors"
+	error_utils "github.com/lmurature/melist-api/src/api/utils/error"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	insertRule      = "INSERT INTO alert_rule(user_id, list_id, item_id, variation_id, `type`, threshold, date_created) VALUES(?,?,?,?,?,?,?);"
+	getRule         = "SELECT r.id, r.user_id, r.list_id, r.item_id, r.variation_id, r.`type`, r.threshold, r.date_created FROM alert_rule r WHERE r.id=?;"
+	getListRules    = "SELECT r.id, r.user_id, r.list_id, r.item_id, r.variation_id, r.`type`, r.threshold, r.date_created FROM alert_rule r WHERE r.list_id=? ORDER BY r.id;"
+	getUserRules    = "SELECT r.id, r.user_id, r.list_id, r.item_id, r.variation_id, r.`type`, r.threshold, r.date_created FROM alert_rule r WHERE r.user_id=? ORDER BY r.id;"
+	deleteRule      = "DELETE FROM alert_rule WHERE id=?;"
+	deleteListRules = "DELETE FROM alert_rule WHERE list_id=?;"
+)
+
+var (
+	AlertRuleDao alertRuleDaoInterface
+)
+
+type alertRuleDaoInterface interface {
+	WithUnitOfWork(uow *database.UnitOfWork) alertRuleDaoInterface
+	CreateRule(rule AlertRule) (*AlertRule, apierrors.ApiError)
+	GetRule(ruleId int64) (*AlertRule, apierrors.ApiError)
+	GetListRules(listId int64) (AlertRules, apierrors.ApiError)
+	GetUserRules(userId int64) (AlertRules, apierrors.ApiError)
+	DeleteRule(ruleId int64) apierrors.ApiError
+	DeleteListRules(listId int64) apierrors.ApiError
+}
+
+type alertRuleDao struct {
+	uow *database.UnitOfWork
+}
+
+func init() {
+	AlertRuleDao = &alertRuleDao{}
+}
+
+// WithUnitOfWork returns a copy of the dao whose statements run inside the transaction of uow.
+func (dao *alertRuleDao) WithUnitOfWork(uow *database.UnitOfWork) alertRuleDaoInterface {
+	return &alertRuleDao{uow: uow}
+}
+
+func (dao *alertRuleDao) CreateRule(rule AlertRule) (*AlertRule, apierrors.ApiError) {
+	stmt, err := database.GetExecutor(dao.uow).Prepare(insertRule)
+	if err != nil {
+		logrus.Error("error when trying to prepare insert alert rule statement", err)
+		return nil, apierrors.NewInternalServerApiError("error when trying to create alert rule", error_utils.GetDatabaseGenericError())
+	}
+	defer stmt.Close()
+
+	result, saveErr := stmt.Exec(rule.UserId, rule.ListId, rule.ItemId, rule.VariationId, rule.Type, rule.Threshold, rule.DateCreated)
+	if saveErr != nil {
+		logrus.Error("error when trying to insert alert rule", saveErr)
+		return nil, apierrors.NewInternalServerApiError("error when trying to create alert rule", error_utils.GetDatabaseGenericError())
+	}
+
+	rule.Id, _ = result.LastInsertId()
+	logrus.Info(fmt.Sprintf("successfully created alert rule %d on list %d", rule.Id, rule.ListId))
+	return &rule, nil
+}
+
+func (dao *alertRuleDao) GetRule(ruleId int64) (*AlertRule, apierrors.ApiError) {
+	stmt, err := database.GetExecutor(dao.uow).Prepare(getRule)
+	if err != nil {
+		logrus.Error("error when trying to prepare get alert rule statement", err)
+		return nil, apierrors.NewInternalServerApiError("error when trying to get alert rule", error_utils.GetDatabaseGenericError())
+	}
+	defer stmt.Close()
+
+	var r AlertRule
+	if queryErr := stmt.QueryRow(ruleId).Scan(&r.Id, &r.UserId, &r.ListId, &r.ItemId, &r.VariationId, &r.Type, &r.Threshold, &r.DateCreated); queryErr != nil {
+		if queryErr == sql.ErrNoRows {
+			return nil, apierrors.NewNotFoundApiError(fmt.Sprintf("alert rule %d not found", ruleId))
+		}
+		logrus.Error("error when trying to get alert rule", queryErr)
+		return nil, apierrors.NewInternalServerApiError("error when trying to get alert rule", error_utils.GetDatabaseGenericError())
+	}
+
+	return &r, nil
+}
+
+func (dao *alertRuleDao) GetListRules(listId int64) (AlertRules, apierrors.ApiError) {
+	return dao.queryRules(getListRules, listId)
+}
+
+func (dao *alertRuleDao) GetUserRules(userId int64) (AlertRules, apierrors.ApiError) {
+	return dao.queryRules(getUserRules, userId)
+}
+
+func (dao *alertRuleDao) DeleteRule(ruleId int64) apierrors.ApiError {
+	return dao.exec(deleteRule, ruleId)
+}
+
+func (dao *alertRuleDao) DeleteListRules(listId int64) apierrors.ApiError {
+	return dao.exec(deleteListRules, listId)
+}
+
+func (dao *alertRuleDao) queryRules(query string, args ...interface{}) (AlertRules, apierrors.ApiError) {
+	stmt, err := database.GetExecutor(dao.uow).Prepare(query)
+	if err != nil {
+		logrus.Error("error when trying to prepare get alert rules statement", err)
+		return nil, apierrors.NewInternalServerApiError("error when trying to get alert rules", error_utils.GetDatabaseGenericError())
+	}
+	defer stmt.Close()
+
+	rows, err := stmt.Query(args...)
+	if err != nil {
+		logrus.Error("error while getting alert rules", err)
+		return nil, apierrors.NewInternalServerApiError("error when trying to get alert rules", error_utils.GetDatabaseGenericError())
+	}
+	defer rows.Close()
+
+	result := make(AlertRules, 0)
+	for rows.Next() {
+		var r AlertRule
+		if err := rows.Scan(&r.Id, &r.UserId, &r.ListId, &r.ItemId, &r.VariationId, &r.Type, &r.Threshold, &r.DateCreated); err != nil {
+			logrus.Error("error when scan alert rule row into alert rule struct", err)
+			return nil, apierrors.NewInternalServerApiError("error when trying to get alert rules", error_utils.GetDatabaseGenericError())
+		}
+		result = append(result, r)
+	}
+
+	return result, nil
+}
+
+func (dao *alertRuleDao) exec(query string, args ...interface{}) apierrors.ApiError {
+	stmt, err := database.GetExecutor(dao.uow).Prepare(query)
+	if err != nil {
+		logrus.Error("error when trying to prepare delete alert rules statement", err)
+		return apierrors.NewInternalServerApiError("error when trying to delete alert rules", error_utils.GetDatabaseGenericError())
+	}
+	defer stmt.Close()
+
+	if _, deleteErr := stmt.Exec(args...); deleteErr != nil {
+		logrus.Error("error when trying to delete alert rules", deleteErr)
+		return apierrors.NewInternalServerApiError("error when trying to delete alert rules", error_utils.GetDatabaseGenericError())
+	}
+	return nil
+}
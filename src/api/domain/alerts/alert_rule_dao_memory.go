@@ -0,0 +1,116 @@
+package alerts
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/lmurature/melist-api/src/api/clients/database"
+	"github.com/lmurature/melist-api/src/api/domain/apierrors"
+	"github.com/sirupsen/logrus"
+)
+
+type alertRuleMemoryStore struct {
+	mu     sync.RWMutex
+	lastId int64
+	rules  AlertRules
+}
+
+type alertRuleMemoryDao struct {
+	*alertRuleMemoryStore
+	uow *database.UnitOfWork
+}
+
+// UseMemoryStorage replaces AlertRuleDao with an empty in-memory implementation.
+func UseMemoryStorage() {
+	AlertRuleDao = &alertRuleMemoryDao{alertRuleMemoryStore: &alertRuleMemoryStore{}}
+}
+
+func (dao *alertRuleMemoryDao) WithUnitOfWork(uow *database.UnitOfWork) alertRuleDaoInterface {
+	return &alertRuleMemoryDao{alertRuleMemoryStore: dao.alertRuleMemoryStore, uow: uow}
+}
+
+func (dao *alertRuleMemoryDao) CreateRule(rule AlertRule) (*AlertRule, apierrors.ApiError) {
+	dao.mu.Lock()
+	defer dao.mu.Unlock()
+
+	dao.lastId++
+	rule.Id = dao.lastId
+	dao.rules = append(dao.rules, rule)
+	dao.uow.OnRollback(func() {
+		dao.mu.Lock()
+		defer dao.mu.Unlock()
+		dao.removeLocked(func(r AlertRule) bool { return r.Id == rule.Id })
+	})
+
+	logrus.Info(fmt.Sprintf("successfully created alert rule %d on list %d", rule.Id, rule.ListId))
+	return &rule, nil
+}
+
+func (dao *alertRuleMemoryDao) GetRule(ruleId int64) (*AlertRule, apierrors.ApiError) {
+	dao.mu.RLock()
+	defer dao.mu.RUnlock()
+
+	for _, r := range dao.rules {
+		if r.Id == ruleId {
+			return &r, nil
+		}
+	}
+	return nil, apierrors.NewNotFoundApiError(fmt.Sprintf("alert rule %d not found", ruleId))
+}
+
+func (dao *alertRuleMemoryDao) GetListRules(listId int64) (AlertRules, apierrors.ApiError) {
+	return dao.filter(func(r AlertRule) bool { return r.ListId == listId }), nil
+}
+
+func (dao *alertRuleMemoryDao) GetUserRules(userId int64) (AlertRules, apierrors.ApiError) {
+	return dao.filter(func(r AlertRule) bool { return r.UserId == userId }), nil
+}
+
+func (dao *alertRuleMemoryDao) DeleteRule(ruleId int64) apierrors.ApiError {
+	return dao.delete(func(r AlertRule) bool { return r.Id == ruleId })
+}
+
+func (dao *alertRuleMemoryDao) DeleteListRules(listId int64) apierrors.ApiError {
+	return dao.delete(func(r AlertRule) bool { return r.ListId == listId })
+}
+
+func (dao *alertRuleMemoryDao) delete(remove func(r AlertRule) bool) apierrors.ApiError {
+	dao.mu.Lock()
+	defer dao.mu.Unlock()
+
+	removed := dao.removeLocked(remove)
+	dao.uow.OnRollback(func() {
+		dao.mu.Lock()
+		defer dao.mu.Unlock()
+		dao.rules = append(dao.rules, removed...)
+	})
+	return nil
+}
+
+func (dao *alertRuleMemoryDao) filter(f func(r AlertRule) bool) AlertRules {
+	dao.mu.RLock()
+	defer dao.mu.RUnlock()
+
+	result := make(AlertRules, 0)
+	for _, r := range dao.rules {
+		if f(r) {
+			result = append(result, r)
+		}
+	}
+	return result
+}
+
+// removeLocked deletes the rules remove returns true for and returns them. The caller must hold mu.
+func (dao *alertRuleMemoryDao) removeLocked(remove func(r AlertRule) bool) AlertRules {
+	result := make(AlertRules, 0, len(dao.rules))
+	removed := make(AlertRules, 0)
+	for _, r := range dao.rules {
+		if remove(r) {
+			removed = append(removed, r)
+		} else {
+			result = append(result, r)
+		}
+	}
+	dao.rules = result
+	return removed
+}
@@ -0,0 +1,127 @@
+package alerts
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/lmurature/melist-api/src/api/domain/items"
+	"github.com/lmurature/melist-api/src/api/domain/notifications"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name  string
+		rule  AlertRule
+		valid bool
+	}{
+		{"price drop", AlertRule{Type: RuleTypePriceDrop, Threshold: 15}, true},
+		{"price drop of everything", AlertRule{Type: RuleTypePriceDrop, Threshold: 100}, false},
+		{"price below", AlertRule{Type: RuleTypePriceBelow, Threshold: 999.9}, true},
+		{"price below nothing", AlertRule{Type: RuleTypePriceBelow}, false},
+		{"back in stock", AlertRule{Type: RuleTypeBackInStock}, true},
+		{"stock below", AlertRule{Type: RuleTypeStockBelow, Threshold: 5}, true},
+		{"stock below half a unit", AlertRule{Type: RuleTypeStockBelow, Threshold: 2.5}, false},
+		{"low rating review", AlertRule{Type: RuleTypeLowRatingReview, Threshold: 2}, true},
+		{"low rating review of six stars", AlertRule{Type: RuleTypeLowRatingReview, Threshold: 6}, false},
+		{"historical low of an item", AlertRule{Type: RuleTypeHistoricalLow, ItemId: "MLA1", VariationId: 11}, true},
+		{"variation without item", AlertRule{Type: RuleTypeHistoricalLow, VariationId: 11}, false},
+		{"unknown type", AlertRule{Type: "price_up"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.rule.Validate()
+			if tt.valid {
+				assert.Nil(t, err)
+			} else {
+				assert.NotNil(t, err)
+				assert.EqualValues(t, http.StatusBadRequest, err.Status())
+			}
+		})
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	item := func(price float32, quantity int) *items.Item {
+		return &items.Item{Id: "MLA1", Title: "Coffee", Price: price, AvailableQuantity: quantity}
+	}
+	last := &items.ItemHistory{ItemId: "MLA1", Price: 1000, Quantity: 10, ReviewsQuantity: 4}
+	soldOut := &items.ItemHistory{ItemId: "MLA1", Price: 1000, Quantity: 0}
+
+	tests := []struct {
+		name        string
+		rule        AlertRule
+		observation Observation
+		expected    string
+	}{
+		{"price dropped enough", AlertRule{Type: RuleTypePriceDrop, Threshold: 20},
+			Observation{Item: item(800, 10), LastHistory: last}, notifications.TypeAlertPriceDrop},
+		{"price dropped too little", AlertRule{Type: RuleTypePriceDrop, Threshold: 20},
+			Observation{Item: item(850, 10), LastHistory: last}, ""},
+		{"price went under", AlertRule{Type: RuleTypePriceBelow, Threshold: 900},
+			Observation{Item: item(899, 10), LastHistory: last}, notifications.TypeAlertPriceBelow},
+		{"price was already under", AlertRule{Type: RuleTypePriceBelow, Threshold: 1500},
+			Observation{Item: item(899, 10), LastHistory: last}, ""},
+		{"back in stock", AlertRule{Type: RuleTypeBackInStock},
+			Observation{Item: item(1000, 2), LastHistory: soldOut, StockReliable: true}, notifications.TypeAlertBackInStock},
+		{"back in stock by the rounded quantity", AlertRule{Type: RuleTypeBackInStock},
+			Observation{Item: item(1000, 2), LastHistory: soldOut}, ""},
+		{"stock went under", AlertRule{Type: RuleTypeStockBelow, Threshold: 5},
+			Observation{Item: item(1000, 4), LastHistory: last, StockReliable: true}, notifications.TypeAlertStockBelow},
+		{"stock is still enough", AlertRule{Type: RuleTypeStockBelow, Threshold: 5},
+			Observation{Item: item(1000, 5), LastHistory: last, StockReliable: true}, ""},
+		{"low rating review", AlertRule{Type: RuleTypeLowRatingReview, Threshold: 2},
+			Observation{Item: item(1000, 10), LastHistory: last, NewReviews: []items.Review{{Rate: 5}, {Rate: 1}}}, notifications.TypeAlertLowRatingReview},
+		{"good reviews", AlertRule{Type: RuleTypeLowRatingReview, Threshold: 2},
+			Observation{Item: item(1000, 10), LastHistory: last, NewReviews: []items.Review{{Rate: 4}}}, ""},
+		{"historical low", AlertRule{Type: RuleTypeHistoricalLow},
+			Observation{Item: item(700, 10), LastHistory: last, LowestPrice: 750}, notifications.TypeAlertHistoricalLow},
+		{"not the lowest price", AlertRule{Type: RuleTypeHistoricalLow},
+			Observation{Item: item(750, 10), LastHistory: last, LowestPrice: 750}, ""},
+		{"first history", AlertRule{Type: RuleTypeBackInStock},
+			Observation{Item: item(1000, 2), StockReliable: true}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.rule.Id, tt.rule.UserId = 7, 2
+			tt.observation.ListId = 1
+
+			notification := tt.rule.Evaluate(tt.observation)
+			if tt.expected == "" {
+				assert.Nil(t, notification)
+				return
+			}
+			assert.NotNil(t, notification)
+			assert.EqualValues(t, tt.expected, notification.Type)
+			assert.EqualValues(t, 2, notification.RecipientId)
+			assert.EqualValues(t, 7, notification.Params.RuleId)
+		})
+	}
+}
+
+func TestEvaluateMessages(t *testing.T) {
+	last := &items.ItemHistory{ItemId: "MLA1", Price: 1000, Quantity: 10}
+
+	drop := AlertRule{Type: RuleTypePriceDrop, Threshold: 12.5}.Evaluate(Observation{Item: &items.Item{Id: "MLA1", Title: "Coffee", Price: 800}, LastHistory: last})
+	assert.EqualValues(t, "The price of Coffee dropped 12.5% or more! It was 1000.00, now it is 800.00.", drop.Localize("en").Message)
+
+	review := AlertRule{Type: RuleTypeLowRatingReview, Threshold: 3}.Evaluate(Observation{Item: &items.Item{Id: "MLA1", Title: "Coffee"}, LastHistory: last,
+		NewReviews: []items.Review{{Rate: 3}, {Rate: 2}}})
+	assert.EqualValues(t, "El producto Coffee recibió una revisión de 2 estrellas.", review.Message)
+}
+
+func TestForItem(t *testing.T) {
+	rules := AlertRules{
+		{Id: 1, Type: RuleTypeBackInStock},
+		{Id: 2, Type: RuleTypeHistoricalLow, ItemId: "MLA1"},
+		{Id: 3, Type: RuleTypeHistoricalLow, ItemId: "MLA1", VariationId: 11},
+	}
+
+	assert.EqualValues(t, AlertRules{rules[0], rules[1]}, rules.ForItem("MLA1", 0))
+	assert.EqualValues(t, AlertRules{rules[0], rules[2]}, rules.ForItem("MLA1", 11))
+	assert.EqualValues(t, AlertRules{rules[0]}, rules.ForItem("MLA2", 0))
+	assert.True(t, rules.ForItem("MLA1", 0).HasType(RuleTypeHistoricalLow))
+	assert.False(t, rules.ForItem("MLA2", 0).HasType(RuleTypeHistoricalLow))
+}
@@ -3,6 +3,8 @@ package auth
 const (
 	GrantTypeAuthorizationCode = "authorization_code"
 	GrantTypeRefreshToken      = "refresh_token"
+
+	CodeChallengeMethodS256 = "S256"
 )
 
 type MeliAuthResponse struct {
@@ -21,9 +23,36 @@ type MeliAuthRequest struct {
 	Code         string `json:"code,omitempty"`
 	RefreshToken string `json:"refresh_token,omitempty"`
 	RedirectUri  string `json:"redirect_uri,omitempty"`
+	CodeVerifier string `json:"code_verifier,omitempty"`
 }
 
 type ClientAuthRequest struct {
 	AuthorizationCode string `json:"authorization_code,omitempty"`
+	State             string `json:"state,omitempty"`
+	Nonce             string `json:"nonce,omitempty"`
 	RefreshToken      string `json:"refresh_token,omitempty"`
 }
+
+// LoginRequest is the body of a login start. The nonce goes in the body rather than the url so it never reaches
+// access logs.
+type LoginRequest struct {
+	Nonce string `json:"nonce"`
+}
+
+// LoginResponse tells the client where to send the user to authorize Melist. Mercado Libre sends the user back to
+// RedirectUri with the authorization code and State, which are exchanged for a session.
+type LoginResponse struct {
+	AuthorizationUrl string `json:"authorization_url"`
+	State            string `json:"state"`
+	RedirectUri      string `json:"redirect_uri"`
+}
+
+// SessionResponse holds the tokens of a Melist session. Clients only ever see these, the Mercado Libre tokens of the
+// user stay in the server.
+type SessionResponse struct {
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int64  `json:"expires_in"`
+	UserId       int64  `json:"user_id"`
+	RefreshToken string `json:"refresh_token"`
+}
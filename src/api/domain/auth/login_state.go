@@ -0,0 +1,108 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/lmurature/melist-api/src/api/config"
+	"github.com/lmurature/melist-api/src/api/domain/apierrors"
+	"github.com/lmurature/melist-api/src/api/domain/sites"
+)
+
+// LoginNonceMinLength is the shortest nonce a client can bind a login to, 128 bits in base64url.
+const LoginNonceMinLength = 22
+
+// LoginState is a login that was started and not finished yet. State protects the token exchange against login
+// CSRF and CodeVerifier, which never leaves the server, binds the authorization code to this login (PKCE, RFC 7636).
+// State travels in urls, so the login is also bound to the browser that started it by NonceHash, the hash of a nonce
+// the client generated and keeps to itself until it finishes the login.
+type LoginState struct {
+	State        string `json:"state"`
+	CodeVerifier string `json:"-"`
+	NonceHash    string `json:"-"`
+	RedirectUri  string `json:"redirect_uri"`
+	DateCreated  string `json:"date_created"`
+	ExpiresAt    string `json:"expires_at"`
+}
+
+// NewLoginState returns a login of the client that holds nonce that goes back to redirectUri and starts at now.
+func NewLoginState(redirectUri string, nonce string, now time.Time) (*LoginState, apierrors.ApiError) {
+	state, err := randomString(24)
+	if err != nil {
+		return nil, apierrors.NewInternalServerApiError("error when trying to generate login state", err)
+	}
+	// 32 bytes are 43 characters, the shortest verifier RFC 7636 allows
+	codeVerifier, err := randomString(32)
+	if err != nil {
+		return nil, apierrors.NewInternalServerApiError("error when trying to generate login state", err)
+	}
+
+	now = now.UTC()
+	return &LoginState{
+		State:        state,
+		CodeVerifier: codeVerifier,
+		NonceHash:    hashNonce(nonce),
+		RedirectUri:  redirectUri,
+		DateCreated:  now.Format(config.DbDateLayout),
+		ExpiresAt:    now.Add(config.OAuthStateTtl).Format(config.DbDateLayout),
+	}, nil
+}
+
+// CodeChallenge is the S256 challenge of CodeVerifier that goes in the authorization url.
+func (s LoginState) CodeChallenge() string {
+	sum := sha256.Sum256([]byte(s.CodeVerifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// MatchesNonce is whether nonce is the one the login was started with.
+func (s LoginState) MatchesNonce(nonce string) bool {
+	return nonce != "" && subtle.ConstantTimeCompare([]byte(hashNonce(nonce)), []byte(s.NonceHash)) == 1
+}
+
+// IsExpired is whether the login took too long to be finished at now.
+func (s LoginState) IsExpired(now time.Time) bool {
+	expiresAt, err := time.Parse(config.DbDateLayout, s.ExpiresAt)
+	return err != nil || !now.UTC().Before(expiresAt)
+}
+
+// AuthorizationUrl is where the user authorizes Melist in the Mercado Libre site siteId for this login.
+func (s LoginState) AuthorizationUrl(siteId string) string {
+	query := url.Values{}
+	query.Set("response_type", "code")
+	query.Set("client_id", fmt.Sprintf("%d", config.AppId))
+	query.Set("redirect_uri", s.RedirectUri)
+	query.Set("state", s.State)
+	query.Set("code_challenge", s.CodeChallenge())
+	query.Set("code_challenge_method", CodeChallengeMethodS256)
+	return fmt.Sprintf("%s/authorization?%s", authUrl(siteId), query.Encode())
+}
+
+func authUrl(siteId string) string {
+	if config.MeliAuthUrl != "" {
+		return config.MeliAuthUrl
+	}
+	site, ok := sites.GetSite(siteId)
+	if !ok {
+		site, _ = sites.GetSite(sites.DefaultSiteId)
+	}
+	return site.AuthUrl
+}
+
+func hashNonce(nonce string) string {
+	sum := sha256.Sum256([]byte(nonce))
+	return hex.EncodeToString(sum[:])
+}
+
+func randomString(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
@@ -0,0 +1,102 @@
+package auth
+
+import (
+	"database/sql"
+
+	"github.com/lmurature/melist-api/src/api/clients/database"
+	"github.com/lmurature/melist-api/src/api/domain/apierrors"
+	error_utils "github.com/lmurature/melist-api/src/api/utils/error"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	insertLoginState        = "INSERT INTO login_state(state, code_verifier, nonce_hash, redirect_uri, date_created, expires_at) VALUES(?,?,?,?,?,?);"
+	getLoginState           = "SELECT s.state, s.code_verifier, s.nonce_hash, s.redirect_uri, s.date_created, s.expires_at FROM login_state s WHERE s.state=?;"
+	deleteLoginState        = "DELETE FROM login_state WHERE state=?;"
+	deleteExpiredLoginState = "DELETE FROM login_state WHERE expires_at<?;"
+)
+
+var (
+	LoginStateDao loginStateDaoInterface
+)
+
+type loginStateDaoInterface interface {
+	CreateLoginState(state LoginState) (*LoginState, apierrors.ApiError)
+	ConsumeLoginState(state string) (*LoginState, apierrors.ApiError)
+	DeleteExpiredLoginStates(before string) apierrors.ApiError
+}
+
+type loginStateDao struct{}
+
+func init() {
+	LoginStateDao = &loginStateDao{}
+}
+
+func (dao *loginStateDao) CreateLoginState(state LoginState) (*LoginState, apierrors.ApiError) {
+	stmt, err := database.DbClient.Prepare(insertLoginState)
+	if err != nil {
+		logrus.Error("error when trying to prepare insert login state statement", err)
+		return nil, apierrors.NewInternalServerApiError("error when trying to start login", error_utils.GetDatabaseGenericError())
+	}
+	defer stmt.Close()
+
+	if _, saveErr := stmt.Exec(state.State, state.CodeVerifier, state.NonceHash, state.RedirectUri, state.DateCreated, state.ExpiresAt); saveErr != nil {
+		logrus.Error("error when trying to insert login state", saveErr)
+		return nil, apierrors.NewInternalServerApiError("error when trying to start login", error_utils.GetDatabaseGenericError())
+	}
+
+	return &state, nil
+}
+
+// ConsumeLoginState returns the login and deletes it, so it can only be finished once. Of two concurrent calls for
+// the same state only one gets the login.
+func (dao *loginStateDao) ConsumeLoginState(state string) (*LoginState, apierrors.ApiError) {
+	stmt, err := database.DbClient.Prepare(getLoginState)
+	if err != nil {
+		logrus.Error("error when trying to prepare get login state statement", err)
+		return nil, apierrors.NewInternalServerApiError("error when trying to get login state", error_utils.GetDatabaseGenericError())
+	}
+	defer stmt.Close()
+
+	var s LoginState
+	if queryErr := stmt.QueryRow(state).Scan(&s.State, &s.CodeVerifier, &s.NonceHash, &s.RedirectUri, &s.DateCreated, &s.ExpiresAt); queryErr != nil {
+		if queryErr == sql.ErrNoRows {
+			return nil, apierrors.NewNotFoundApiError("login state not found")
+		}
+		logrus.Error("error when trying to get login state", queryErr)
+		return nil, apierrors.NewInternalServerApiError("error when trying to get login state", error_utils.GetDatabaseGenericError())
+	}
+
+	deleteStmt, err := database.DbClient.Prepare(deleteLoginState)
+	if err != nil {
+		logrus.Error("error when trying to prepare delete login state statement", err)
+		return nil, apierrors.NewInternalServerApiError("error when trying to get login state", error_utils.GetDatabaseGenericError())
+	}
+	defer deleteStmt.Close()
+
+	result, deleteErr := deleteStmt.Exec(state)
+	if deleteErr != nil {
+		logrus.Error("error when trying to delete login state", deleteErr)
+		return nil, apierrors.NewInternalServerApiError("error when trying to get login state", error_utils.GetDatabaseGenericError())
+	}
+	if deleted, _ := result.RowsAffected(); deleted == 0 {
+		return nil, apierrors.NewNotFoundApiError("login state not found")
+	}
+
+	return &s, nil
+}
+
+func (dao *loginStateDao) DeleteExpiredLoginStates(before string) apierrors.ApiError {
+	stmt, err := database.DbClient.Prepare(deleteExpiredLoginState)
+	if err != nil {
+		logrus.Error("error when trying to prepare delete expired login states statement", err)
+		return apierrors.NewInternalServerApiError("error when trying to delete expired login states", error_utils.GetDatabaseGenericError())
+	}
+	defer stmt.Close()
+
+	if _, deleteErr := stmt.Exec(before); deleteErr != nil {
+		logrus.Error("error when trying to delete expired login states", deleteErr)
+		return apierrors.NewInternalServerApiError("error when trying to delete expired login states", error_utils.GetDatabaseGenericError())
+	}
+	return nil
+}
@@ -0,0 +1,50 @@
+package auth
+
+import (
+	"sync"
+
+	"github.com/lmurature/melist-api/src/api/domain/apierrors"
+)
+
+type loginStateMemoryDao struct {
+	mu     sync.Mutex
+	states map[string]LoginState
+}
+
+// UseMemoryStorage replaces LoginStateDao with an empty in-memory implementation.
+func UseMemoryStorage() {
+	LoginStateDao = &loginStateMemoryDao{states: make(map[string]LoginState)}
+}
+
+func (dao *loginStateMemoryDao) CreateLoginState(state LoginState) (*LoginState, apierrors.ApiError) {
+	dao.mu.Lock()
+	defer dao.mu.Unlock()
+
+	dao.states[state.State] = state
+	return &state, nil
+}
+
+func (dao *loginStateMemoryDao) ConsumeLoginState(state string) (*LoginState, apierrors.ApiError) {
+	dao.mu.Lock()
+	defer dao.mu.Unlock()
+
+	s, ok := dao.states[state]
+	if !ok {
+		return nil, apierrors.NewNotFoundApiError("login state not found")
+	}
+	delete(dao.states, state)
+	return &s, nil
+}
+
+func (dao *loginStateMemoryDao) DeleteExpiredLoginStates(before string) apierrors.ApiError {
+	dao.mu.Lock()
+	defer dao.mu.Unlock()
+
+	for state, s := range dao.states {
+		// dates share config.DbDateLayout, so they sort as strings
+		if s.ExpiresAt < before {
+			delete(dao.states, state)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,42 @@
+package auth
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCodeChallenge(t *testing.T) {
+	// the example of RFC 7636, appendix B
+	state := LoginState{CodeVerifier: "dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"}
+
+	assert.EqualValues(t, "E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM", state.CodeChallenge())
+}
+
+func TestNewLoginState(t *testing.T) {
+	now := time.Date(2021, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	state, err := NewLoginState("https://melist.test/auth/authorized", "nonce-of-the-web-app-1234", now)
+	assert.Nil(t, err)
+	assert.EqualValues(t, 43, len(state.CodeVerifier))
+	assert.True(t, state.MatchesNonce("nonce-of-the-web-app-1234"))
+	assert.False(t, state.MatchesNonce("nonce-of-the-web-app-5678"))
+	assert.False(t, state.MatchesNonce(""))
+	assert.NotContains(t, state.NonceHash, "nonce-of-the-web-app")
+	assert.EqualValues(t, "2021-05-01 12:10:00", state.ExpiresAt)
+	assert.False(t, state.IsExpired(now.Add(9*time.Minute)))
+	assert.True(t, state.IsExpired(now.Add(10*time.Minute)))
+
+	other, err := NewLoginState("https://melist.test/auth/authorized", "nonce-of-the-web-app-1234", now)
+	assert.Nil(t, err)
+	assert.NotEqual(t, state.State, other.State)
+	assert.NotEqual(t, state.CodeVerifier, other.CodeVerifier)
+
+	authorizationUrl, parseErr := url.Parse(state.AuthorizationUrl("MLM"))
+	assert.Nil(t, parseErr)
+	assert.EqualValues(t, state.CodeChallenge(), authorizationUrl.Query().Get("code_challenge"))
+	assert.EqualValues(t, "auth.mercadolibre.com.mx", authorizationUrl.Host)
+	assert.NotContains(t, state.AuthorizationUrl("MLM"), state.CodeVerifier)
+}
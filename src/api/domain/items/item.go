@@ -1,15 +1,22 @@
 package items
 
-import "github.com/lmurature/melist-api/src/api/domain/apierrors"
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/lmurature/melist-api/src/api/domain/apierrors"
+)
 
 type Item struct {
 	Id                string          `json:"id"`
+	SiteId            string          `json:"site_id,omitempty"`
 	Title             string          `json:"title"`
 	Description       string          `json:"description"`
 	CategoryId        string          `json:"category_id"`
 	SellerId          int64           `json:"seller_id"`
 	Price             float32         `json:"price"`
 	OriginalPrice     float32         `json:"original_price"`
+	CurrencyId        string          `json:"currency_id,omitempty"`
 	Status            string          `json:"status"`
 	InitialQuantity   int             `json:"initial_quantity"`
 	AvailableQuantity int             `json:"available_quantity"`
@@ -65,12 +72,14 @@ type ValueStruct struct {
 }
 
 type ItemSearchResponse struct {
-	SiteId         string `json:"site_id"`
-	Query          string `json:"query"`
-	Paging         Paging `json:"paging"`
-	Result         []Item `json:"results"`
-	Sort           Sort   `json:"sort"`
-	AvailableSorts []Sort `json:"available_sorts"`
+	SiteId           string         `json:"site_id"`
+	Query            string         `json:"query"`
+	Paging           Paging         `json:"paging"`
+	Result           []Item         `json:"results"`
+	Sort             Sort           `json:"sort"`
+	AvailableSorts   []Sort         `json:"available_sorts"`
+	Filters          []SearchFilter `json:"filters"`
+	AvailableFilters []SearchFilter `json:"available_filters"`
 }
 
 type Paging struct {
@@ -84,6 +93,24 @@ type Sort struct {
 	Name string `json:"name"`
 }
 
+const (
+	// MultigetMaxIds is how many ids the multiget items endpoint accepts per request.
+	MultigetMaxIds = 20
+)
+
+// ItemResult is the outcome of fetching one item of a batch: either Item or Error is set.
+type ItemResult struct {
+	Id    string
+	Item  *Item
+	Error apierrors.ApiError
+}
+
+// MultigetResponse is one entry of the response of the multiget items endpoint.
+type MultigetResponse struct {
+	Code int             `json:"code"`
+	Body json.RawMessage `json:"body"`
+}
+
 type ItemConcurrent struct {
 	Item      *Item
 	ItemError apierrors.ApiError
@@ -107,3 +134,53 @@ type Category struct {
 func (i *Item) HasActiveDeal() bool {
 	return i.Price < i.OriginalPrice && len(i.DealIds) > 0
 }
+
+// GetVariation returns the variation of the item with the given id, if it has one.
+func (i *Item) GetVariation(variationId int64) *ItemVariation {
+	for v := range i.Variations {
+		if i.Variations[v].Id == variationId {
+			return &i.Variations[v]
+		}
+	}
+	return nil
+}
+
+// ForVariation returns a copy of the item as that variation is sold: its price, stock and pictures, and the variation
+// as the only one of the item. It returns false when the item has no such variation.
+func (i *Item) ForVariation(variationId int64) (*Item, bool) {
+	variation := i.GetVariation(variationId)
+	if variation == nil {
+		return nil, false
+	}
+
+	result := *i
+	result.Variations = []ItemVariation{*variation}
+	result.AvailableQuantity = variation.AvailableQuantity
+	if variation.Price > 0 {
+		result.Price = variation.Price
+	}
+
+	if len(variation.PictureIds) > 0 {
+		result.Pictures = make([]ItemPicture, 0, len(variation.PictureIds))
+		for _, pictureId := range variation.PictureIds {
+			for _, picture := range i.Pictures {
+				if picture.Id == pictureId {
+					result.Pictures = append(result.Pictures, picture)
+				}
+			}
+		}
+	}
+
+	return &result, true
+}
+
+// Name describes the variation by the values of its attributes, such as "Azul, 42".
+func (v ItemVariation) Name() string {
+	values := make([]string, 0, len(v.AttributeCombinations))
+	for _, attribute := range v.AttributeCombinations {
+		if attribute.ValueName != "" {
+			values = append(values, attribute.ValueName)
+		}
+	}
+	return strings.Join(values, ", ")
+}
@@ -18,18 +18,26 @@ var (
 )
 
 type itemDaoInterface interface {
+	WithUnitOfWork(uow *database.UnitOfWork) itemDaoInterface
 	InsertItem(itemId string) apierrors.ApiError
 	GetAllItems() ([]string, apierrors.ApiError)
 }
 
-type itemDao struct{}
+type itemDao struct {
+	uow *database.UnitOfWork
+}
 
 func init() {
 	ItemDao = &itemDao{}
 }
 
+// WithUnitOfWork returns a copy of the dao whose statements run inside the transaction of uow.
+func (dao *itemDao) WithUnitOfWork(uow *database.UnitOfWork) itemDaoInterface {
+	return &itemDao{uow: uow}
+}
+
 func (dao *itemDao) InsertItem(itemId string) apierrors.ApiError {
-	stmt, err := database.DbClient.Prepare(insertItem)
+	stmt, err := database.GetExecutor(dao.uow).Prepare(insertItem)
 	if err != nil {
 		logrus.Error("error when trying to prepare insert item statement", err)
 		return apierrors.NewInternalServerApiError("error when trying to insert item", error_utils.GetDatabaseGenericError())
@@ -46,7 +54,7 @@ func (dao *itemDao) InsertItem(itemId string) apierrors.ApiError {
 }
 
 func (dao *itemDao) GetAllItems() ([]string, apierrors.ApiError) {
-	stmt, err := database.DbClient.Prepare(getAllItems)
+	stmt, err := database.GetExecutor(dao.uow).Prepare(getAllItems)
 	if err != nil {
 		logrus.Error("error when trying to prepare get all items statement", err)
 		return nil, apierrors.NewInternalServerApiError("error when trying to get all items", error_utils.GetDatabaseGenericError())
@@ -0,0 +1,65 @@
+package items
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/lmurature/melist-api/src/api/clients/database"
+	"github.com/lmurature/melist-api/src/api/domain/apierrors"
+	error_utils "github.com/lmurature/melist-api/src/api/utils/error"
+	"github.com/sirupsen/logrus"
+)
+
+type itemMemoryStore struct {
+	mu    sync.RWMutex
+	items map[string]bool
+}
+
+// itemMemoryDao shares its store with every copy returned by WithUnitOfWork.
+type itemMemoryDao struct {
+	*itemMemoryStore
+	uow *database.UnitOfWork
+}
+
+// UseMemoryStorage replaces ItemDao, ItemListDao and ItemHistoryDao with empty in-memory implementations.
+func UseMemoryStorage() {
+	ItemDao = &itemMemoryDao{itemMemoryStore: &itemMemoryStore{items: make(map[string]bool)}}
+	ItemListDao = &itemListMemoryDao{itemListMemoryStore: &itemListMemoryStore{}}
+	ItemHistoryDao = &itemHistoryMemoryDao{}
+}
+
+func (dao *itemMemoryDao) WithUnitOfWork(uow *database.UnitOfWork) itemDaoInterface {
+	return &itemMemoryDao{itemMemoryStore: dao.itemMemoryStore, uow: uow}
+}
+
+func (dao *itemMemoryDao) InsertItem(itemId string) apierrors.ApiError {
+	dao.mu.Lock()
+	defer dao.mu.Unlock()
+
+	if dao.items[itemId] {
+		return apierrors.NewInternalServerApiError("error when trying to save item to items table", error_utils.GetDatabaseGenericError())
+	}
+	dao.items[itemId] = true
+	dao.uow.OnRollback(func() {
+		dao.mu.Lock()
+		defer dao.mu.Unlock()
+		delete(dao.items, itemId)
+	})
+
+	logrus.Info(fmt.Sprintf("successfully added %s to item table", itemId))
+	return nil
+}
+
+func (dao *itemMemoryDao) GetAllItems() ([]string, apierrors.ApiError) {
+	dao.mu.RLock()
+	defer dao.mu.RUnlock()
+
+	result := make([]string, 0, len(dao.items))
+	for itemId := range dao.items {
+		result = append(result, itemId)
+	}
+	sort.Strings(result)
+
+	return result, nil
+}
@@ -3,7 +3,9 @@ package items
 type ItemHistory struct {
 	Id              int64   `json:"id"`
 	ItemId          string  `json:"item_id"`
+	VariationId     int64   `json:"variation_id,omitempty"`
 	Price           float32 `json:"price"`
+	CurrencyId      string  `json:"currency_id,omitempty"`
 	Quantity        int     `json:"quantity"`
 	Status          string  `json:"status"`
 	HasDeal         bool    `json:"has_deal"`
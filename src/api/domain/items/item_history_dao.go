@@ -8,9 +8,9 @@ import (
 )
 
 const (
-	insertItemHistory  = "INSERT INTO item_history(item_id,price,quantity,status,has_deal,date_fetched,reviews_quantity) VALUES(?,?,?,?,?,?,?);"
-	getItemHistory     = "SELECT id,item_id,price,quantity,status,has_deal,date_fetched,reviews_quantity FROM item_history WHERE item_id=? ORDER BY date_fetched ASC;"
-	getLastItemHistory = "SELECT id,item_id,price,quantity,status,has_deal,date_fetched,reviews_quantity FROM item_history WHERE item_id=? ORDER BY date_fetched DESC LIMIT 1;"
+	insertItemHistory  = "INSERT INTO item_history(item_id,variation_id,price,currency_id,quantity,status,has_deal,date_fetched,reviews_quantity) VALUES(?,?,?,?,?,?,?,?,?);"
+	getItemHistory     = "SELECT id,item_id,variation_id,price,COALESCE(currency_id,''),quantity,status,has_deal,date_fetched,reviews_quantity FROM item_history WHERE item_id=? AND variation_id=? ORDER BY date_fetched ASC;"
+	getLastItemHistory = "SELECT id,item_id,variation_id,price,COALESCE(currency_id,''),quantity,status,has_deal,date_fetched,reviews_quantity FROM item_history WHERE item_id=? AND variation_id=? ORDER BY date_fetched DESC LIMIT 1;"
 )
 
 var (
@@ -19,8 +19,8 @@ var (
 
 type itemHistoryDaoInterface interface {
 	InsertItemHistory(itemHistory ItemHistory) (*ItemHistory, apierrors.ApiError)
-	GetLastItemHistory(itemId string) (*ItemHistory, apierrors.ApiError)
-	GetItemHistory(itemId string) ([]ItemHistory, apierrors.ApiError)
+	GetLastItemHistory(itemId string, variationId int64) (*ItemHistory, apierrors.ApiError)
+	GetItemHistory(itemId string, variationId int64) ([]ItemHistory, apierrors.ApiError)
 }
 
 type itemHistoryDao struct{}
@@ -37,7 +37,7 @@ func (dao *itemHistoryDao) InsertItemHistory(history ItemHistory) (*ItemHistory,
 	}
 	defer stmt.Close()
 
-	execResult, execErr := stmt.Exec(history.ItemId, history.Price, history.Quantity, history.Status, history.HasDeal, history.DateFetched, history.ReviewsQuantity)
+	execResult, execErr := stmt.Exec(history.ItemId, history.VariationId, history.Price, history.CurrencyId, history.Quantity, history.Status, history.HasDeal, history.DateFetched, history.ReviewsQuantity)
 	if execErr != nil {
 		logrus.Error("error when trying to save item history", execErr)
 		return nil, apierrors.NewInternalServerApiError("error when trying to save item history", error_utils.GetDatabaseGenericError())
@@ -50,7 +50,7 @@ func (dao *itemHistoryDao) InsertItemHistory(history ItemHistory) (*ItemHistory,
 	return &history, nil
 }
 
-func (dao *itemHistoryDao) GetLastItemHistory(itemId string) (*ItemHistory, apierrors.ApiError) {
+func (dao *itemHistoryDao) GetLastItemHistory(itemId string, variationId int64) (*ItemHistory, apierrors.ApiError) {
 	stmt, err := database.DbClient.Prepare(getLastItemHistory)
 	if err != nil {
 		logrus.Error("error when trying to prepare get item history statement", err)
@@ -58,7 +58,7 @@ func (dao *itemHistoryDao) GetLastItemHistory(itemId string) (*ItemHistory, apie
 	}
 	defer stmt.Close()
 
-	rows, err := stmt.Query(itemId)
+	rows, err := stmt.Query(itemId, variationId)
 	if err != nil {
 		logrus.Error("error while getting item history", err)
 		return nil, apierrors.NewInternalServerApiError("error getting item history", error_utils.GetDatabaseGenericError())
@@ -68,7 +68,7 @@ func (dao *itemHistoryDao) GetLastItemHistory(itemId string) (*ItemHistory, apie
 	var result *ItemHistory = nil
 	for rows.Next() {
 		var history ItemHistory
-		if err := rows.Scan(&history.Id, &history.ItemId, &history.Price,
+		if err := rows.Scan(&history.Id, &history.ItemId, &history.VariationId, &history.Price, &history.CurrencyId,
 			&history.Quantity, &history.Status, &history.HasDeal,
 			&history.DateFetched, &history.ReviewsQuantity); err != nil {
 			logrus.Error("error scaning row item history", err)
@@ -84,7 +84,7 @@ func (dao *itemHistoryDao) GetLastItemHistory(itemId string) (*ItemHistory, apie
 	return result, nil
 }
 
-func (dao *itemHistoryDao) GetItemHistory(itemId string) ([]ItemHistory, apierrors.ApiError) {
+func (dao *itemHistoryDao) GetItemHistory(itemId string, variationId int64) ([]ItemHistory, apierrors.ApiError) {
 	stmt, err := database.DbClient.Prepare(getItemHistory)
 	if err != nil {
 		logrus.Error("error when trying to prepare get item history statement", err)
@@ -92,7 +92,7 @@ func (dao *itemHistoryDao) GetItemHistory(itemId string) ([]ItemHistory, apierro
 	}
 	defer stmt.Close()
 
-	rows, err := stmt.Query(itemId)
+	rows, err := stmt.Query(itemId, variationId)
 	if err != nil {
 		logrus.Error("error while getting item history", err)
 		return nil, apierrors.NewInternalServerApiError("error getting item history", error_utils.GetDatabaseGenericError())
@@ -102,7 +102,7 @@ func (dao *itemHistoryDao) GetItemHistory(itemId string) ([]ItemHistory, apierro
 	result := make([]ItemHistory, 0)
 	for rows.Next() {
 		var history ItemHistory
-		if err := rows.Scan(&history.Id, &history.ItemId, &history.Price,
+		if err := rows.Scan(&history.Id, &history.ItemId, &history.VariationId, &history.Price, &history.CurrencyId,
 			&history.Quantity, &history.Status, &history.HasDeal,
 			&history.DateFetched, &history.ReviewsQuantity); err != nil {
 			logrus.Error("error scaning row item history", err)
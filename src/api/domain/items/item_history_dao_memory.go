@@ -0,0 +1,51 @@
+package items
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/lmurature/melist-api/src/api/domain/apierrors"
+)
+
+type itemHistoryMemoryDao struct {
+	mu      sync.RWMutex
+	lastId  int64
+	history []ItemHistory
+}
+
+func (dao *itemHistoryMemoryDao) InsertItemHistory(history ItemHistory) (*ItemHistory, apierrors.ApiError) {
+	dao.mu.Lock()
+	defer dao.mu.Unlock()
+
+	dao.lastId++
+	history.Id = dao.lastId
+	dao.history = append(dao.history, history)
+
+	return &history, nil
+}
+
+func (dao *itemHistoryMemoryDao) GetLastItemHistory(itemId string, variationId int64) (*ItemHistory, apierrors.ApiError) {
+	itemHistory, _ := dao.GetItemHistory(itemId, variationId)
+	if len(itemHistory) == 0 {
+		return nil, apierrors.NewNotFoundApiError("item history not found")
+	}
+
+	return &itemHistory[len(itemHistory)-1], nil
+}
+
+func (dao *itemHistoryMemoryDao) GetItemHistory(itemId string, variationId int64) ([]ItemHistory, apierrors.ApiError) {
+	dao.mu.RLock()
+	defer dao.mu.RUnlock()
+
+	result := make([]ItemHistory, 0)
+	for _, h := range dao.history {
+		if h.ItemId == itemId && h.VariationId == variationId {
+			result = append(result, h)
+		}
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].DateFetched < result[j].DateFetched
+	})
+
+	return result, nil
+}
@@ -1,24 +1,117 @@
 package items
 
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/lmurature/melist-api/src/api/domain/apierrors"
+)
+
 const (
 	StatusChecked    = "checked"
 	StatusNotChecked = "not_checked"
 )
 
+const (
+	PriorityLow    = "low"
+	PriorityNormal = "normal"
+	PriorityHigh   = "high"
+
+	MaxItemQuantity = 999
+	MaxNoteLength   = 500
+)
+
+var (
+	Priorities = []string{PriorityLow, PriorityNormal, PriorityHigh}
+)
+
 type ItemListDto struct {
-	ItemId      string `json:"item_id"`
-	ListId      int64  `json:"list_id"`
-	Status      string `json:"status"`
-	VariationId int64  `json:"variation_id,omitempty"`
-	MeliItem    *Item  `json:"item,omitempty"`
-	UserId      int64  `json:"user_id,omitempty"`
+	ItemId      string             `json:"item_id"`
+	ListId      int64              `json:"list_id"`
+	Status      string             `json:"status"`
+	VariationId int64              `json:"variation_id,omitempty"`
+	Quantity    int                `json:"quantity"`
+	Note        string             `json:"note,omitempty"`
+	TargetPrice float32            `json:"target_price,omitempty"`
+	Priority    string             `json:"priority"`
+	MeliItem    *Item              `json:"item,omitempty"`
+	UserId      int64              `json:"user_id,omitempty"`
+	Error       apierrors.ApiError `json:"error,omitempty"`
+}
+
+// ItemListUpdate holds the fields of a list item users can change. Fields that are not sent are left as they are, and
+// a target price of 0 removes it.
+type ItemListUpdate struct {
+	Quantity    *int     `json:"quantity"`
+	Note        *string  `json:"note"`
+	TargetPrice *float32 `json:"target_price"`
+	Priority    *string  `json:"priority"`
 }
 
 type ItemListCollection []ItemListDto
 
-func (items ItemListCollection) ContainsItem(itemId string) bool {
-	for _, i := range items {
-		if i.ItemId == itemId {
+// ContainsItem is whether the list has the item with that variation. A variation id of 0 is the item itself, with no
+// variation chosen.
+func (items ItemListCollection) ContainsItem(itemId string, variationId int64) bool {
+	return items.GetItem(itemId, variationId) != nil
+}
+
+// GetItem returns the entry of the item with that variation, if the list has it.
+func (items ItemListCollection) GetItem(itemId string, variationId int64) *ItemListDto {
+	for i := range items {
+		if items[i].ItemId == itemId && items[i].VariationId == variationId {
+			return &items[i]
+		}
+	}
+	return nil
+}
+
+func (u ItemListUpdate) Validate() apierrors.ApiError {
+	causes := apierrors.CauseList{}
+
+	if u.Quantity != nil && (*u.Quantity < 1 || *u.Quantity > MaxItemQuantity) {
+		causes = append(causes, fmt.Sprintf("quantity must be between 1 and %d", MaxItemQuantity))
+	}
+	if u.Note != nil && utf8.RuneCountInString(*u.Note) > MaxNoteLength {
+		causes = append(causes, fmt.Sprintf("note must be at most %d characters long", MaxNoteLength))
+	}
+	if u.TargetPrice != nil && *u.TargetPrice < 0 {
+		causes = append(causes, "target price must not be negative")
+	}
+	if u.Priority != nil && !isValidPriority(*u.Priority) {
+		causes = append(causes, fmt.Sprintf("invalid priority '%s', expected one of %s", *u.Priority, strings.Join(Priorities, ", ")))
+	}
+
+	if len(causes) > 0 {
+		return apierrors.NewValidationApiError("invalid list item", "bad_request", causes)
+	}
+	return nil
+}
+
+func (i *ItemListDto) UpdateFields(update ItemListUpdate) {
+	if update.Quantity != nil {
+		i.Quantity = *update.Quantity
+	}
+	if update.Note != nil {
+		i.Note = strings.TrimSpace(*update.Note)
+	}
+	if update.TargetPrice != nil {
+		i.TargetPrice = *update.TargetPrice
+	}
+	if update.Priority != nil {
+		i.Priority = *update.Priority
+	}
+}
+
+// ReachedTargetPrice is whether the price of the item went from above the target price to the target price or below.
+func (i ItemListDto) ReachedTargetPrice(oldPrice float32, newPrice float32) bool {
+	return i.TargetPrice > 0 && oldPrice > i.TargetPrice && newPrice <= i.TargetPrice
+}
+
+func isValidPriority(priority string) bool {
+	for _, p := range Priorities {
+		if p == priority {
 			return true
 		}
 	}
@@ -9,10 +9,12 @@ import (
 )
 
 const (
-	getItemsFromList   = "SELECT l.list_id, l.item_id, l.status, l.variation_external_id, l.user_id FROM list_item l WHERE l.list_id=?;"
-	insertItemToList   = "INSERT INTO list_item(list_id, item_id, status, variation_external_id,user_id) VALUES(?,?,?,?,?);"
-	removeItemFromList = "DELETE FROM list_item l WHERE l.item_id=? and l.list_id=?;"
-	checkItem          = "UPDATE list_item SET status=? WHERE item_id=? and list_id=?;"
+	getItemsFromList   = "SELECT l.list_id, l.item_id, l.status, l.variation_external_id, l.user_id, l.quantity, l.note, l.target_price, l.priority FROM list_item l WHERE l.list_id=?;"
+	insertItemToList   = "INSERT INTO list_item(list_id, item_id, status, variation_external_id,user_id, quantity, note, target_price, priority) VALUES(?,?,?,?,?,?,?,?,?);"
+	removeItemFromList = "DELETE FROM list_item l WHERE l.item_id=? and l.variation_external_id=? and l.list_id=?;"
+	checkItem          = "UPDATE list_item SET status=? WHERE item_id=? and variation_external_id=? and list_id=?;"
+	updateItemDetails  = "UPDATE list_item SET quantity=?, note=?, target_price=?, priority=? WHERE item_id=? and variation_external_id=? and list_id=?;"
+	removeAllFromList  = "DELETE FROM list_item WHERE list_id=?;"
 )
 
 var (
@@ -20,27 +22,38 @@ var (
 )
 
 type itemListDaoInterface interface {
+	WithUnitOfWork(uow *database.UnitOfWork) itemListDaoInterface
 	InsertItemToList(itemList ItemListDto) (*ItemListDto, apierrors.ApiError)
-	DeleteItemFromList(itemId string, listId int64) apierrors.ApiError
+	DeleteItemFromList(itemId string, variationId int64, listId int64) apierrors.ApiError
 	GetItemsFromList(listId int64) (ItemListCollection, apierrors.ApiError)
-	UpdateItemStatus(itemId string, listId int64, status string) apierrors.ApiError
+	UpdateItemStatus(itemId string, variationId int64, listId int64, status string) apierrors.ApiError
+	UpdateItemDetails(itemList ItemListDto) apierrors.ApiError
+	DeleteAllItemsFromList(listId int64) apierrors.ApiError
 }
 
-type itemListDao struct{}
+type itemListDao struct {
+	uow *database.UnitOfWork
+}
 
 func init() {
 	ItemListDao = &itemListDao{}
 }
 
+// WithUnitOfWork returns a copy of the dao whose statements run inside the transaction of uow.
+func (dao *itemListDao) WithUnitOfWork(uow *database.UnitOfWork) itemListDaoInterface {
+	return &itemListDao{uow: uow}
+}
+
 func (dao *itemListDao) InsertItemToList(itemList ItemListDto) (*ItemListDto, apierrors.ApiError) {
-	stmt, err := database.DbClient.Prepare(insertItemToList)
+	stmt, err := database.GetExecutor(dao.uow).Prepare(insertItemToList)
 	if err != nil {
 		logrus.Error("error when trying to prepare insert item to list statement", err)
 		return nil, apierrors.NewInternalServerApiError("error when trying to insert item to list", error_utils.GetDatabaseGenericError())
 	}
 	defer stmt.Close()
 
-	_, saveErr := stmt.Exec(itemList.ListId, itemList.ItemId, itemList.Status, itemList.VariationId, itemList.UserId)
+	_, saveErr := stmt.Exec(itemList.ListId, itemList.ItemId, itemList.Status, itemList.VariationId, itemList.UserId,
+		itemList.Quantity, itemList.Note, itemList.TargetPrice, itemList.Priority)
 	if saveErr != nil {
 		logrus.Error("error inserting item to list", saveErr)
 		return nil, apierrors.NewInternalServerApiError("error when trying to save item to list", error_utils.GetDatabaseGenericError())
@@ -50,15 +63,15 @@ func (dao *itemListDao) InsertItemToList(itemList ItemListDto) (*ItemListDto, ap
 	return &itemList, nil
 }
 
-func (dao *itemListDao) DeleteItemFromList(itemId string, listId int64) apierrors.ApiError {
-	stmt, err := database.DbClient.Prepare(removeItemFromList)
+func (dao *itemListDao) DeleteItemFromList(itemId string, variationId int64, listId int64) apierrors.ApiError {
+	stmt, err := database.GetExecutor(dao.uow).Prepare(removeItemFromList)
 	if err != nil {
 		logrus.Error("error when trying to prepare delete item from list statement", err)
 		return apierrors.NewInternalServerApiError("error when trying to delete item from list", error_utils.GetDatabaseGenericError())
 	}
 	defer stmt.Close()
 
-	_, deleteErr := stmt.Exec(itemId, listId)
+	_, deleteErr := stmt.Exec(itemId, variationId, listId)
 	if deleteErr != nil {
 		return apierrors.NewInternalServerApiError("error when trying to delete item from list", error_utils.GetDatabaseGenericError())
 	}
@@ -68,7 +81,7 @@ func (dao *itemListDao) DeleteItemFromList(itemId string, listId int64) apierror
 }
 
 func (dao *itemListDao) GetItemsFromList(listId int64) (ItemListCollection, apierrors.ApiError) {
-	stmt, err := database.DbClient.Prepare(getItemsFromList)
+	stmt, err := database.GetExecutor(dao.uow).Prepare(getItemsFromList)
 	if err != nil {
 		logrus.Error("error when trying to prepare get all items from list statement", err)
 		return nil, apierrors.NewInternalServerApiError("error when trying to get all items from list", error_utils.GetDatabaseGenericError())
@@ -86,7 +99,7 @@ func (dao *itemListDao) GetItemsFromList(listId int64) (ItemListCollection, apie
 
 	for rows.Next() {
 		var i ItemListDto
-		if err := rows.Scan(&i.ListId, &i.ItemId, &i.Status, &i.VariationId, &i.UserId); err != nil {
+		if err := rows.Scan(&i.ListId, &i.ItemId, &i.Status, &i.VariationId, &i.UserId, &i.Quantity, &i.Note, &i.TargetPrice, &i.Priority); err != nil {
 			logrus.Error("error when scan item row into item struct", err)
 			return nil, apierrors.NewInternalServerApiError("error when tying to get all items from list", error_utils.GetDatabaseGenericError())
 		}
@@ -96,15 +109,15 @@ func (dao *itemListDao) GetItemsFromList(listId int64) (ItemListCollection, apie
 	return result, nil
 }
 
-func (dao *itemListDao) UpdateItemStatus(itemId string, listId int64, status string) apierrors.ApiError {
-	stmt, err := database.DbClient.Prepare(checkItem)
+func (dao *itemListDao) UpdateItemStatus(itemId string, variationId int64, listId int64, status string) apierrors.ApiError {
+	stmt, err := database.GetExecutor(dao.uow).Prepare(checkItem)
 	if err != nil {
 		logrus.Error("error when trying to prepare get check item from list statement", err)
 		return apierrors.NewInternalServerApiError("error when trying to item from list", error_utils.GetDatabaseGenericError())
 	}
 	defer stmt.Close()
 
-	_, updateErr := stmt.Exec(status, itemId, listId)
+	_, updateErr := stmt.Exec(status, itemId, variationId, listId)
 	if updateErr != nil {
 		logrus.Error("error when trying to check item", updateErr)
 		return apierrors.NewInternalServerApiError("error when trying to check item", error_utils.GetDatabaseGenericError())
@@ -113,3 +126,38 @@ func (dao *itemListDao) UpdateItemStatus(itemId string, listId int64, status str
 	logrus.Info(fmt.Sprintf("successfully updated item %s from list %d", itemId, listId))
 	return nil
 }
+
+func (dao *itemListDao) UpdateItemDetails(itemList ItemListDto) apierrors.ApiError {
+	stmt, err := database.GetExecutor(dao.uow).Prepare(updateItemDetails)
+	if err != nil {
+		logrus.Error("error when trying to prepare update item details statement", err)
+		return apierrors.NewInternalServerApiError("error when trying to update item from list", error_utils.GetDatabaseGenericError())
+	}
+	defer stmt.Close()
+
+	_, updateErr := stmt.Exec(itemList.Quantity, itemList.Note, itemList.TargetPrice, itemList.Priority, itemList.ItemId, itemList.VariationId, itemList.ListId)
+	if updateErr != nil {
+		logrus.Error("error when trying to update item details", updateErr)
+		return apierrors.NewInternalServerApiError("error when trying to update item from list", error_utils.GetDatabaseGenericError())
+	}
+
+	logrus.Info(fmt.Sprintf("successfully updated details of item %s from list %d", itemList.ItemId, itemList.ListId))
+	return nil
+}
+
+func (dao *itemListDao) DeleteAllItemsFromList(listId int64) apierrors.ApiError {
+	stmt, err := database.GetExecutor(dao.uow).Prepare(removeAllFromList)
+	if err != nil {
+		logrus.Error("error when trying to prepare delete all items from list statement", err)
+		return apierrors.NewInternalServerApiError("error when trying to delete all items from list", error_utils.GetDatabaseGenericError())
+	}
+	defer stmt.Close()
+
+	if _, deleteErr := stmt.Exec(listId); deleteErr != nil {
+		logrus.Error("error when trying to delete all items from list", deleteErr)
+		return apierrors.NewInternalServerApiError("error when trying to delete all items from list", error_utils.GetDatabaseGenericError())
+	}
+
+	logrus.Info(fmt.Sprintf("successfully deleted all items from list %d", listId))
+	return nil
+}
@@ -0,0 +1,160 @@
+package items
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/lmurature/melist-api/src/api/clients/database"
+	"github.com/lmurature/melist-api/src/api/domain/apierrors"
+	"github.com/sirupsen/logrus"
+)
+
+type itemListMemoryStore struct {
+	mu    sync.RWMutex
+	items ItemListCollection
+}
+
+type itemListMemoryDao struct {
+	*itemListMemoryStore
+	uow *database.UnitOfWork
+}
+
+func (dao *itemListMemoryDao) WithUnitOfWork(uow *database.UnitOfWork) itemListDaoInterface {
+	return &itemListMemoryDao{itemListMemoryStore: dao.itemListMemoryStore, uow: uow}
+}
+
+func (dao *itemListMemoryDao) InsertItemToList(itemList ItemListDto) (*ItemListDto, apierrors.ApiError) {
+	dao.mu.Lock()
+	defer dao.mu.Unlock()
+
+	itemList.MeliItem = nil
+	dao.items = append(dao.items, itemList)
+	dao.uow.OnRollback(func() {
+		dao.mu.Lock()
+		defer dao.mu.Unlock()
+		dao.removeLocked(itemList.ItemId, itemList.VariationId, itemList.ListId)
+	})
+
+	logrus.Info(fmt.Sprintf("successfully added item %s to list %d", itemList.ItemId, itemList.ListId))
+	return &itemList, nil
+}
+
+func (dao *itemListMemoryDao) DeleteItemFromList(itemId string, variationId int64, listId int64) apierrors.ApiError {
+	dao.mu.Lock()
+	defer dao.mu.Unlock()
+
+	removed := dao.removeLocked(itemId, variationId, listId)
+	dao.uow.OnRollback(func() {
+		dao.mu.Lock()
+		defer dao.mu.Unlock()
+		dao.items = append(dao.items, removed...)
+	})
+
+	logrus.Info(fmt.Sprintf("successfully deleted item %s from list %d", itemId, listId))
+	return nil
+}
+
+func (dao *itemListMemoryDao) GetItemsFromList(listId int64) (ItemListCollection, apierrors.ApiError) {
+	dao.mu.RLock()
+	defer dao.mu.RUnlock()
+
+	result := make(ItemListCollection, 0)
+	for _, i := range dao.items {
+		if i.ListId == listId {
+			result = append(result, i)
+		}
+	}
+
+	return result, nil
+}
+
+func (dao *itemListMemoryDao) UpdateItemStatus(itemId string, variationId int64, listId int64, status string) apierrors.ApiError {
+	dao.mu.Lock()
+	defer dao.mu.Unlock()
+
+	for i := range dao.items {
+		if dao.items[i].ItemId == itemId && dao.items[i].VariationId == variationId && dao.items[i].ListId == listId {
+			previous := dao.items[i].Status
+			dao.items[i].Status = status
+			dao.uow.OnRollback(func() {
+				dao.mu.Lock()
+				defer dao.mu.Unlock()
+				for j := range dao.items {
+					if dao.items[j].ItemId == itemId && dao.items[j].VariationId == variationId && dao.items[j].ListId == listId {
+						dao.items[j].Status = previous
+					}
+				}
+			})
+		}
+	}
+
+	logrus.Info(fmt.Sprintf("successfully updated item %s from list %d", itemId, listId))
+	return nil
+}
+
+func (dao *itemListMemoryDao) UpdateItemDetails(itemList ItemListDto) apierrors.ApiError {
+	dao.mu.Lock()
+	defer dao.mu.Unlock()
+
+	for i := range dao.items {
+		if dao.items[i].ItemId == itemList.ItemId && dao.items[i].VariationId == itemList.VariationId && dao.items[i].ListId == itemList.ListId {
+			previous := dao.items[i]
+			dao.items[i].Quantity = itemList.Quantity
+			dao.items[i].Note = itemList.Note
+			dao.items[i].TargetPrice = itemList.TargetPrice
+			dao.items[i].Priority = itemList.Priority
+			dao.uow.OnRollback(func() {
+				dao.mu.Lock()
+				defer dao.mu.Unlock()
+				for j := range dao.items {
+					if dao.items[j].ItemId == previous.ItemId && dao.items[j].VariationId == previous.VariationId && dao.items[j].ListId == previous.ListId {
+						dao.items[j].Quantity, dao.items[j].Note = previous.Quantity, previous.Note
+						dao.items[j].TargetPrice, dao.items[j].Priority = previous.TargetPrice, previous.Priority
+					}
+				}
+			})
+		}
+	}
+
+	logrus.Info(fmt.Sprintf("successfully updated details of item %s from list %d", itemList.ItemId, itemList.ListId))
+	return nil
+}
+
+func (dao *itemListMemoryDao) DeleteAllItemsFromList(listId int64) apierrors.ApiError {
+	dao.mu.Lock()
+	defer dao.mu.Unlock()
+
+	result := make(ItemListCollection, 0, len(dao.items))
+	removed := make(ItemListCollection, 0)
+	for _, i := range dao.items {
+		if i.ListId != listId {
+			result = append(result, i)
+		} else {
+			removed = append(removed, i)
+		}
+	}
+	dao.items = result
+	dao.uow.OnRollback(func() {
+		dao.mu.Lock()
+		defer dao.mu.Unlock()
+		dao.items = append(dao.items, removed...)
+	})
+
+	logrus.Info(fmt.Sprintf("successfully deleted all items from list %d", listId))
+	return nil
+}
+
+// removeLocked deletes the item with that variation from the list and returns the removed rows. The caller must hold mu.
+func (dao *itemListMemoryDao) removeLocked(itemId string, variationId int64, listId int64) ItemListCollection {
+	result := make(ItemListCollection, 0, len(dao.items))
+	removed := make(ItemListCollection, 0)
+	for _, i := range dao.items {
+		if i.ItemId != itemId || i.VariationId != variationId || i.ListId != listId {
+			result = append(result, i)
+		} else {
+			removed = append(removed, i)
+		}
+	}
+	dao.items = result
+	return removed
+}
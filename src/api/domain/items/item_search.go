@@ -0,0 +1,181 @@
+package items
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/lmurature/melist-api/src/api/domain/apierrors"
+	"github.com/lmurature/melist-api/src/api/domain/sites"
+)
+
+const (
+	SearchFilterCategory      = "category"
+	SearchFilterPrice         = "price"
+	SearchFilterCondition     = "condition"
+	SearchFilterShipping      = "shipping"
+	SearchFilterShippingCost  = "shipping_cost"
+	SearchFilterOfficialStore = "official_store"
+	SearchFilterSellerId      = "seller_id"
+
+	SearchSortRelevance = "relevance"
+	SearchSortPriceAsc  = "price_asc"
+	SearchSortPriceDesc = "price_desc"
+
+	MaxSearchLimit = 50
+)
+
+var (
+	// SearchFilters are the Mercado Libre search filters melist passes through, with the values each one accepts.
+	SearchFilters = map[string]func(value string, siteId string) bool{
+		SearchFilterCategory: func(value string, siteId string) bool {
+			return sites.SiteIdFromId(value) == siteId
+		},
+		SearchFilterPrice: isValidPriceRange,
+		SearchFilterCondition: func(value string, _ string) bool {
+			return value == "new" || value == "used" || value == "not_specified"
+		},
+		SearchFilterShipping: func(value string, _ string) bool {
+			return value == "fulfillment" || value == "mercadoenvios"
+		},
+		SearchFilterShippingCost: func(value string, _ string) bool {
+			return value == "free"
+		},
+		SearchFilterOfficialStore: func(value string, _ string) bool {
+			return value == "all" || isPositiveNumber(value)
+		},
+		SearchFilterSellerId: func(value string, _ string) bool {
+			return isPositiveNumber(value)
+		},
+	}
+
+	SearchSorts = []string{SearchSortRelevance, SearchSortPriceAsc, SearchSortPriceDesc}
+
+	// searchParams are the query parameters of a search request that are not filters: the search itself and the
+	// ones every endpoint accepts, like the locale or the token of clients that cannot set headers.
+	searchParams = map[string]bool{"q": true, "offset": true, "limit": true, "sort": true, "site_id": true,
+		"locale": true, "access_token": true}
+
+	priceRangeRegexp = regexp.MustCompile(`^(\*|\d+(\.\d+)?)-(\*|\d+(\.\d+)?)$`)
+)
+
+// ItemSearch is a search on a Mercado Libre site. A zero Limit and an empty Sort keep the defaults of Mercado Libre.
+type ItemSearch struct {
+	Query   string
+	Offset  int
+	Limit   int
+	Sort    string
+	Filters map[string]string
+}
+
+type SearchFilter struct {
+	Id     string              `json:"id"`
+	Name   string              `json:"name"`
+	Type   string              `json:"type,omitempty"`
+	Values []SearchFilterValue `json:"values"`
+}
+
+type SearchFilterValue struct {
+	Id      string `json:"id"`
+	Name    string `json:"name"`
+	Results int64  `json:"results,omitempty"`
+}
+
+// SearchFiltersFromQuery returns the filters of a search request. Parameters starting with an underscore, such as
+// the "_" cache-buster some HTTP clients add, belong to the client and are not filters either.
+func SearchFiltersFromQuery(query url.Values) map[string]string {
+	filters := make(map[string]string)
+	for key, values := range query {
+		if searchParams[key] || strings.HasPrefix(key, "_") || len(values) == 0 {
+			continue
+		}
+		filters[key] = values[0]
+	}
+	return filters
+}
+
+// Validate returns every problem of the search as the cause list of a single bad request error.
+func (s ItemSearch) Validate(siteId string) apierrors.ApiError {
+	causes := apierrors.CauseList{}
+
+	if s.Offset < 0 {
+		causes = append(causes, "offset must not be negative")
+	}
+	if s.Limit < 0 || s.Limit > MaxSearchLimit {
+		causes = append(causes, fmt.Sprintf("limit must be between 1 and %d", MaxSearchLimit))
+	}
+	if s.Sort != "" && !isValidSort(s.Sort) {
+		causes = append(causes, fmt.Sprintf("invalid sort '%s', expected one of %s", s.Sort, strings.Join(SearchSorts, ", ")))
+	}
+
+	for _, key := range s.filterKeys() {
+		isValid, ok := SearchFilters[key]
+		if !ok {
+			causes = append(causes, fmt.Sprintf("unknown filter '%s'", key))
+			continue
+		}
+		if !isValid(s.Filters[key], siteId) {
+			causes = append(causes, fmt.Sprintf("invalid value '%s' for filter '%s'", s.Filters[key], key))
+		}
+	}
+
+	if len(causes) > 0 {
+		return apierrors.NewValidationApiError("invalid search", "bad_request", causes)
+	}
+	return nil
+}
+
+// QueryParams returns the limit, sort and filters as query parameters to append to a search uri, ordered by name.
+func (s ItemSearch) QueryParams() string {
+	var b strings.Builder
+	if s.Limit > 0 {
+		b.WriteString(fmt.Sprintf("&limit=%d", s.Limit))
+	}
+	if s.Sort != "" {
+		b.WriteString(fmt.Sprintf("&sort=%s", url.QueryEscape(s.Sort)))
+	}
+	for _, key := range s.filterKeys() {
+		b.WriteString(fmt.Sprintf("&%s=%s", url.QueryEscape(key), url.QueryEscape(s.Filters[key])))
+	}
+	return b.String()
+}
+
+func (s ItemSearch) filterKeys() []string {
+	keys := make([]string, 0, len(s.Filters))
+	for key := range s.Filters {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func isValidSort(sortId string) bool {
+	for _, s := range SearchSorts {
+		if s == sortId {
+			return true
+		}
+	}
+	return false
+}
+
+// isValidPriceRange accepts the min-max format of Mercado Libre, where either end can be * for no bound.
+func isValidPriceRange(value string, _ string) bool {
+	match := priceRangeRegexp.FindStringSubmatch(value)
+	if match == nil || (match[1] == "*" && match[3] == "*") {
+		return false
+	}
+	if match[1] == "*" || match[3] == "*" {
+		return true
+	}
+	min, _ := strconv.ParseFloat(match[1], 64)
+	max, _ := strconv.ParseFloat(match[3], 64)
+	return min <= max
+}
+
+func isPositiveNumber(value string) bool {
+	n, err := strconv.ParseInt(value, 10, 64)
+	return err == nil && n > 0
+}
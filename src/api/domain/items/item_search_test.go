@@ -0,0 +1,25 @@
+package items
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/lmurature/melist-api/src/api/domain/sites"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSearchFiltersFromQuery(t *testing.T) {
+	query, _ := url.ParseQuery("q=tv&offset=0&limit=10&sort=price_asc&site_id=MLA&locale=en&access_token=abc&_=1620000000&condition=new&price=100-*")
+
+	filters := SearchFiltersFromQuery(query)
+	assert.EqualValues(t, map[string]string{SearchFilterCondition: "new", SearchFilterPrice: "100-*"}, filters)
+	assert.Nil(t, ItemSearch{Query: "tv", Filters: filters}.Validate(sites.SiteArgentina))
+}
+
+func TestUnknownFiltersAreRejected(t *testing.T) {
+	query, _ := url.ParseQuery("q=tv&colour=red&condition=broken")
+
+	err := ItemSearch{Query: "tv", Filters: SearchFiltersFromQuery(query)}.Validate(sites.SiteArgentina)
+	assert.NotNil(t, err)
+	assert.EqualValues(t, 2, len(err.Cause()))
+}
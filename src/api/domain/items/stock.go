@@ -0,0 +1,30 @@
+package items
+
+// StockConfidence is how much a StockResolution can be trusted, from StockConfidenceNone, when nothing could be
+// resolved, to StockConfidenceHigh, when the quantity is the real stock of the item.
+type StockConfidence int
+
+const (
+	StockConfidenceNone StockConfidence = iota
+	StockConfidenceLow
+	StockConfidenceMedium
+	StockConfidenceHigh
+)
+
+const (
+	StockSourceVariations = "variations"
+	StockSourcePage       = "page"
+	StockSourceListing    = "listing"
+)
+
+// StockResolution is the available quantity of an item according to Source.
+type StockResolution struct {
+	Quantity   int             `json:"quantity"`
+	Confidence StockConfidence `json:"confidence"`
+	Source     string          `json:"source"`
+}
+
+// IsReliable is whether the quantity is good enough to tell users that the stock of an item ran out or is running out.
+func (r StockResolution) IsReliable() bool {
+	return r.Confidence >= StockConfidenceMedium
+}
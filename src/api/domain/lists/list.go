@@ -1,8 +1,10 @@
 package lists
 
 import (
+	"fmt"
+
 	"github.com/lmurature/melist-api/src/api/domain/apierrors"
-	"github.com/lmurature/melist-api/src/api/domain/share"
+	"github.com/lmurature/melist-api/src/api/domain/sites"
 )
 
 const (
@@ -16,7 +18,9 @@ type List struct {
 	Title         string `json:"title"`
 	Description   string `json:"description"`
 	Privacy       string `json:"privacy"`
+	SiteId        string `json:"site_id"`
 	DateCreated   string `json:"date_created"`
+	DateDeleted   string `json:"date_deleted,omitempty"`
 	Notifications int    `json:"notifications,omitempty"`
 }
 
@@ -38,61 +42,16 @@ func (l List) Validate() apierrors.ApiError {
 	return nil
 }
 
-func (l List) ValidateAddItems(callerId int64, configs share.ShareConfigs) apierrors.ApiError {
-	if l.Privacy == PrivacyTypePrivate {
-		if callerId == l.OwnerId {
-			return nil
-		}
-		for _, c := range configs {
-			if c.UserId == callerId && c.ShareType == share.ShareTypeWrite {
-				return nil
-			}
-		}
-	} else {
-		return nil
-	}
-	return apierrors.NewForbiddenApiError("you have no access to this list")
-}
-
-func (l List) ValidateUpdatability(callerId int64) apierrors.ApiError {
-	if l.OwnerId != callerId {
-		return apierrors.NewForbiddenApiError("you have no access to update this list")
+// ValidateItemSite checks that the item belongs to the marketplace of the list, which is Argentina for lists
+// created before lists had a site.
+func (l List) ValidateItemSite(itemId string) apierrors.ApiError {
+	listSiteId := sites.GetSiteIdOrDefault(l.SiteId)
+	if sites.SiteIdFromId(itemId) != listSiteId {
+		return apierrors.NewBadRequestApiError(fmt.Sprintf("item %s does not belong to site %s of the list", itemId, listSiteId))
 	}
 	return nil
 }
 
-func (l List) ValidateCheckItems(callerId int64, configs share.ShareConfigs) apierrors.ApiError {
-	if l.Privacy == PrivacyTypePrivate {
-		if callerId == l.OwnerId {
-			return nil
-		}
-		for _, c := range configs {
-			if c.UserId == callerId && (c.ShareType == share.ShareTypeCheck || c.ShareType == share.ShareTypeWrite) {
-				return nil
-			}
-		}
-	} else {
-		return nil
-	}
-	return apierrors.NewForbiddenApiError("you have no access to this list")
-}
-
-func (l List) ValidateReadability(callerId int64, configs share.ShareConfigs) apierrors.ApiError {
-	if l.Privacy == PrivacyTypePrivate {
-		if callerId == l.OwnerId {
-			return nil
-		}
-		for _, c := range configs {
-			if c.UserId == callerId {
-				return nil
-			}
-		}
-	} else {
-		return nil
-	}
-	return apierrors.NewForbiddenApiError("you have no access to this list")
-}
-
 func (l *List) UpdateFields(updatedList List) {
 	if updatedList.Privacy != "" && (updatedList.Privacy == PrivacyTypePrivate || updatedList.Privacy == PrivacyTypePublic) {
 		l.Privacy = updatedList.Privacy
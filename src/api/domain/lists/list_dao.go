@@ -9,15 +9,22 @@ import (
 )
 
 const (
-	getList                 = "SELECT l.id, l.owner_id, l.title, l.description, l.privacy, l.date_created FROM list l WHERE l.id=?;"
-	insertList              = "INSERT INTO list(owner_id, title, description, privacy, date_created) VALUES(?,?,?,?,?);"
-	updateList              = "UPDATE list SET title=?, description=?, privacy=? WHERE id=?;"
-	getAllPublicLists       = "SELECT l.id, l.owner_id, l.title, l.description, l.privacy, l.date_created FROM list l WHERE l.privacy='public';"
-	getAllListsFromOwner    = "SELECT l.id, l.owner_id, l.title, l.description, l.privacy, l.date_created FROM list l WHERE l.owner_id=?;"
-	getAllUserFavoriteLists = "SELECT l.id, l.owner_id, l.title, l.description, l.privacy, l.date_created FROM list l INNER JOIN user_favorite_list uf ON uf.list_id=l.id WHERE uf.user_id=?;"
-	insertUserFavoriteList  = "INSERT INTO user_favorite_list (user_id, list_id) VALUES(?,?);"
-	deleteUserFavoriteList  = "DELETE FROM user_favorite_list uf WHERE uf.list_id=? AND uf.user_id=?;"
-	getAllLists             = "SELECT l.id, l.owner_id, l.title, l.description, l.privacy, l.date_created FROM list l;"
+	getList                  = "SELECT l.id, l.owner_id, l.title, l.description, l.privacy, l.site_id, l.date_created FROM list l WHERE l.id=? AND l.date_deleted IS NULL;"
+	insertList               = "INSERT INTO list(owner_id, title, description, privacy, site_id, date_created) VALUES(?,?,?,?,?,?);"
+	updateList               = "UPDATE list SET title=?, description=?, privacy=? WHERE id=?;"
+	getAllPublicLists        = "SELECT l.id, l.owner_id, l.title, l.description, l.privacy, l.site_id, l.date_created FROM list l WHERE l.privacy='public' AND l.date_deleted IS NULL;"
+	getAllListsFromOwner     = "SELECT l.id, l.owner_id, l.title, l.description, l.privacy, l.site_id, l.date_created FROM list l WHERE l.owner_id=? AND l.date_deleted IS NULL;"
+	getAllUserFavoriteLists  = "SELECT l.id, l.owner_id, l.title, l.description, l.privacy, l.site_id, l.date_created FROM list l INNER JOIN user_favorite_list uf ON uf.list_id=l.id WHERE uf.user_id=? AND l.date_deleted IS NULL;"
+	insertUserFavoriteList   = "INSERT INTO user_favorite_list (user_id, list_id) VALUES(?,?);"
+	deleteUserFavoriteList   = "DELETE FROM user_favorite_list uf WHERE uf.list_id=? AND uf.user_id=?;"
+	getAllLists              = "SELECT l.id, l.owner_id, l.title, l.description, l.privacy, l.site_id, l.date_created FROM list l WHERE l.date_deleted IS NULL;"
+	getTrashedList           = "SELECT l.id, l.owner_id, l.title, l.description, l.privacy, l.site_id, l.date_created, l.date_deleted FROM list l WHERE l.id=? AND l.date_deleted IS NOT NULL;"
+	getTrashedListsFromOwner = "SELECT l.id, l.owner_id, l.title, l.description, l.privacy, l.site_id, l.date_created, l.date_deleted FROM list l WHERE l.owner_id=? AND l.date_deleted IS NOT NULL ORDER BY l.date_deleted DESC;"
+	getExpiredTrashedLists   = "SELECT l.id, l.owner_id, l.title, l.description, l.privacy, l.site_id, l.date_created, l.date_deleted FROM list l WHERE l.date_deleted IS NOT NULL AND l.date_deleted<=?;"
+	trashList                = "UPDATE list SET date_deleted=? WHERE id=? AND date_deleted IS NULL;"
+	restoreList              = "UPDATE list SET date_deleted=NULL WHERE id=?;"
+	deleteFavoritesByList    = "DELETE FROM user_favorite_list WHERE list_id=?;"
+	deleteList               = "DELETE FROM list WHERE id=?;"
 )
 
 var (
@@ -25,6 +32,7 @@ var (
 )
 
 type listDaoInterface interface {
+	WithUnitOfWork(uow *database.UnitOfWork) listDaoInterface
 	GetList(listId int64) (*List, apierrors.ApiError)
 	CreateList(listDto List) (*List, apierrors.ApiError)
 	UpdateList(listDto List) (*List, apierrors.ApiError)
@@ -34,16 +42,29 @@ type listDaoInterface interface {
 	SaveFavoriteList(listId int64, userId int64) apierrors.ApiError
 	RemoveFavoriteList(listId int64, userId int64) apierrors.ApiError
 	GetAllLists() (Lists, apierrors.ApiError)
+	TrashList(listId int64, dateDeleted string) apierrors.ApiError
+	RestoreList(listId int64) apierrors.ApiError
+	GetTrashedList(listId int64) (*List, apierrors.ApiError)
+	GetTrashedListsFromOwner(ownerId int64) (Lists, apierrors.ApiError)
+	GetExpiredTrashedLists(deletedBefore string) (Lists, apierrors.ApiError)
+	DeleteList(listId int64) apierrors.ApiError
 }
 
-type listDao struct{}
+type listDao struct {
+	uow *database.UnitOfWork
+}
 
 func init() {
 	ListDao = &listDao{}
 }
 
+// WithUnitOfWork returns a copy of the dao whose statements run inside the transaction of uow.
+func (dao *listDao) WithUnitOfWork(uow *database.UnitOfWork) listDaoInterface {
+	return &listDao{uow: uow}
+}
+
 func (dao *listDao) GetList(listId int64) (*List, apierrors.ApiError) {
-	stmt, err := database.DbClient.Prepare(getList)
+	stmt, err := database.GetExecutor(dao.uow).Prepare(getList)
 	if err != nil {
 		logrus.Error("error when trying to prepare get list statement", err)
 		return nil, apierrors.NewInternalServerApiError("error when trying to get list", error_utils.GetDatabaseGenericError())
@@ -54,7 +75,7 @@ func (dao *listDao) GetList(listId int64) (*List, apierrors.ApiError) {
 
 	var listDto List
 	if queryErr := result.Scan(&listDto.Id, &listDto.OwnerId, &listDto.Title, &listDto.Description,
-		&listDto.Privacy, &listDto.DateCreated); queryErr != nil {
+		&listDto.Privacy, &listDto.SiteId, &listDto.DateCreated); queryErr != nil {
 		msg := fmt.Sprintf("list %d not found", listId)
 		logrus.Error(msg, queryErr)
 		return nil, apierrors.NewNotFoundApiError(msg)
@@ -64,14 +85,14 @@ func (dao *listDao) GetList(listId int64) (*List, apierrors.ApiError) {
 }
 
 func (dao *listDao) CreateList(listDto List) (*List, apierrors.ApiError) {
-	stmt, err := database.DbClient.Prepare(insertList)
+	stmt, err := database.GetExecutor(dao.uow).Prepare(insertList)
 	if err != nil {
 		logrus.Error("error when trying to prepare insert list statement", err)
 		return nil, apierrors.NewInternalServerApiError("error when trying to insert list", error_utils.GetDatabaseGenericError())
 	}
 	defer stmt.Close()
 
-	execResult, execErr := stmt.Exec(listDto.OwnerId, listDto.Title, listDto.Description, listDto.Privacy, listDto.DateCreated)
+	execResult, execErr := stmt.Exec(listDto.OwnerId, listDto.Title, listDto.Description, listDto.Privacy, listDto.SiteId, listDto.DateCreated)
 	if execErr != nil {
 		logrus.Error("error when trying to save list", execErr)
 		return nil, apierrors.NewInternalServerApiError("error when trying to save list", error_utils.GetDatabaseGenericError())
@@ -86,7 +107,7 @@ func (dao *listDao) CreateList(listDto List) (*List, apierrors.ApiError) {
 }
 
 func (dao *listDao) UpdateList(listDto List) (*List, apierrors.ApiError) {
-	stmt, err := database.DbClient.Prepare(updateList)
+	stmt, err := database.GetExecutor(dao.uow).Prepare(updateList)
 	if err != nil {
 		logrus.Error("error when trying to prepare update list statement", err)
 		return nil, apierrors.NewInternalServerApiError("error when update to insert list", error_utils.GetDatabaseGenericError())
@@ -104,7 +125,7 @@ func (dao *listDao) UpdateList(listDto List) (*List, apierrors.ApiError) {
 }
 
 func (dao *listDao) GetPublicLists() (Lists, apierrors.ApiError) {
-	stmt, err := database.DbClient.Prepare(getAllPublicLists)
+	stmt, err := database.GetExecutor(dao.uow).Prepare(getAllPublicLists)
 	if err != nil {
 		logrus.Error("error when trying to prepare get public lists statement", err)
 		return nil, apierrors.NewInternalServerApiError("error when trying to get public lists", error_utils.GetDatabaseGenericError())
@@ -122,7 +143,7 @@ func (dao *listDao) GetPublicLists() (Lists, apierrors.ApiError) {
 
 	for rows.Next() {
 		var list List
-		if err := rows.Scan(&list.Id, &list.OwnerId, &list.Title, &list.Description, &list.Privacy, &list.DateCreated); err != nil {
+		if err := rows.Scan(&list.Id, &list.OwnerId, &list.Title, &list.Description, &list.Privacy, &list.SiteId, &list.DateCreated); err != nil {
 			logrus.Error("error when scan list row into list struct", err)
 			return nil, apierrors.NewInternalServerApiError("error when tying to get public lists", error_utils.GetDatabaseGenericError())
 		}
@@ -137,7 +158,7 @@ func (dao *listDao) GetPublicLists() (Lists, apierrors.ApiError) {
 }
 
 func (dao *listDao) GetListsFromOwner(ownerId int64) (Lists, apierrors.ApiError) {
-	stmt, err := database.DbClient.Prepare(getAllListsFromOwner)
+	stmt, err := database.GetExecutor(dao.uow).Prepare(getAllListsFromOwner)
 	if err != nil {
 		logrus.Error("error when trying to prepare get all owner lists statement", err)
 		return nil, apierrors.NewInternalServerApiError("error when trying to get all owner lists", error_utils.GetDatabaseGenericError())
@@ -155,7 +176,7 @@ func (dao *listDao) GetListsFromOwner(ownerId int64) (Lists, apierrors.ApiError)
 
 	for rows.Next() {
 		var list List
-		if err := rows.Scan(&list.Id, &list.OwnerId, &list.Title, &list.Description, &list.Privacy, &list.DateCreated); err != nil {
+		if err := rows.Scan(&list.Id, &list.OwnerId, &list.Title, &list.Description, &list.Privacy, &list.SiteId, &list.DateCreated); err != nil {
 			logrus.Error("error when scan list row into list struct", err)
 			return nil, apierrors.NewInternalServerApiError("error when tying to get all owner lists", error_utils.GetDatabaseGenericError())
 		}
@@ -166,7 +187,7 @@ func (dao *listDao) GetListsFromOwner(ownerId int64) (Lists, apierrors.ApiError)
 }
 
 func (dao *listDao) GetUserFavoriteLists(userId int64) (Lists, apierrors.ApiError) {
-	stmt, err := database.DbClient.Prepare(getAllUserFavoriteLists)
+	stmt, err := database.GetExecutor(dao.uow).Prepare(getAllUserFavoriteLists)
 	if err != nil {
 		logrus.Error("error when trying to prepare get all favorite lists statement", err)
 		return nil, apierrors.NewInternalServerApiError("error when trying to get all favorite lists", error_utils.GetDatabaseGenericError())
@@ -184,7 +205,7 @@ func (dao *listDao) GetUserFavoriteLists(userId int64) (Lists, apierrors.ApiErro
 
 	for rows.Next() {
 		var list List
-		if err := rows.Scan(&list.Id, &list.OwnerId, &list.Title, &list.Description, &list.Privacy, &list.DateCreated); err != nil {
+		if err := rows.Scan(&list.Id, &list.OwnerId, &list.Title, &list.Description, &list.Privacy, &list.SiteId, &list.DateCreated); err != nil {
 			logrus.Error("error when scan list row into list struct", err)
 			return nil, apierrors.NewInternalServerApiError("error when tying to get all favorite lists", error_utils.GetDatabaseGenericError())
 		}
@@ -195,7 +216,7 @@ func (dao *listDao) GetUserFavoriteLists(userId int64) (Lists, apierrors.ApiErro
 }
 
 func (dao *listDao) SaveFavoriteList(listId int64, userId int64) apierrors.ApiError {
-	stmt, err := database.DbClient.Prepare(insertUserFavoriteList)
+	stmt, err := database.GetExecutor(dao.uow).Prepare(insertUserFavoriteList)
 	if err != nil {
 		logrus.Error("error when trying to prepare insert favorite list statement", err)
 		return apierrors.NewInternalServerApiError("error when trying to save favorite list", error_utils.GetDatabaseGenericError())
@@ -212,7 +233,7 @@ func (dao *listDao) SaveFavoriteList(listId int64, userId int64) apierrors.ApiEr
 }
 
 func (dao *listDao) RemoveFavoriteList(listId int64, userId int64) apierrors.ApiError {
-	stmt, err := database.DbClient.Prepare(deleteUserFavoriteList)
+	stmt, err := database.GetExecutor(dao.uow).Prepare(deleteUserFavoriteList)
 	if err != nil {
 		logrus.Error("error when trying to prepare delete favorite list statement", err)
 		return apierrors.NewInternalServerApiError("error when trying to remove favorite list", error_utils.GetDatabaseGenericError())
@@ -229,7 +250,7 @@ func (dao *listDao) RemoveFavoriteList(listId int64, userId int64) apierrors.Api
 }
 
 func (dao *listDao) GetAllLists() (Lists, apierrors.ApiError) {
-	stmt, err := database.DbClient.Prepare(getAllLists)
+	stmt, err := database.GetExecutor(dao.uow).Prepare(getAllLists)
 	if err != nil {
 		logrus.Error("error when trying to prepare get all lists statement", err)
 		return nil, apierrors.NewInternalServerApiError("error when trying to get all lists", error_utils.GetDatabaseGenericError())
@@ -247,7 +268,7 @@ func (dao *listDao) GetAllLists() (Lists, apierrors.ApiError) {
 
 	for rows.Next() {
 		var list List
-		if err := rows.Scan(&list.Id, &list.OwnerId, &list.Title, &list.Description, &list.Privacy, &list.DateCreated); err != nil {
+		if err := rows.Scan(&list.Id, &list.OwnerId, &list.Title, &list.Description, &list.Privacy, &list.SiteId, &list.DateCreated); err != nil {
 			logrus.Error("error when scan list row into list struct", err)
 			return nil, apierrors.NewInternalServerApiError("error when tying to get all lists", error_utils.GetDatabaseGenericError())
 		}
@@ -260,3 +281,118 @@ func (dao *listDao) GetAllLists() (Lists, apierrors.ApiError) {
 
 	return result, nil
 }
+
+// TrashList moves the list to the trash bin. Trashed lists are hidden from every other query but GetTrashedList,
+// GetTrashedListsFromOwner and GetExpiredTrashedLists.
+func (dao *listDao) TrashList(listId int64, dateDeleted string) apierrors.ApiError {
+	stmt, err := database.GetExecutor(dao.uow).Prepare(trashList)
+	if err != nil {
+		logrus.Error("error when trying to prepare trash list statement", err)
+		return apierrors.NewInternalServerApiError("error when trying to delete list", error_utils.GetDatabaseGenericError())
+	}
+	defer stmt.Close()
+
+	if _, execErr := stmt.Exec(dateDeleted, listId); execErr != nil {
+		logrus.Error("error when trying to trash list", execErr)
+		return apierrors.NewInternalServerApiError("error when trying to delete list", error_utils.GetDatabaseGenericError())
+	}
+
+	logrus.Info(fmt.Sprintf("successfully moved list %d to trash", listId))
+	return nil
+}
+
+func (dao *listDao) RestoreList(listId int64) apierrors.ApiError {
+	stmt, err := database.GetExecutor(dao.uow).Prepare(restoreList)
+	if err != nil {
+		logrus.Error("error when trying to prepare restore list statement", err)
+		return apierrors.NewInternalServerApiError("error when trying to restore list", error_utils.GetDatabaseGenericError())
+	}
+	defer stmt.Close()
+
+	if _, execErr := stmt.Exec(listId); execErr != nil {
+		logrus.Error("error when trying to restore list", execErr)
+		return apierrors.NewInternalServerApiError("error when trying to restore list", error_utils.GetDatabaseGenericError())
+	}
+
+	logrus.Info(fmt.Sprintf("successfully restored list %d from trash", listId))
+	return nil
+}
+
+func (dao *listDao) GetTrashedList(listId int64) (*List, apierrors.ApiError) {
+	stmt, err := database.GetExecutor(dao.uow).Prepare(getTrashedList)
+	if err != nil {
+		logrus.Error("error when trying to prepare get trashed list statement", err)
+		return nil, apierrors.NewInternalServerApiError("error when trying to get trashed list", error_utils.GetDatabaseGenericError())
+	}
+	defer stmt.Close()
+
+	result := stmt.QueryRow(listId)
+
+	var listDto List
+	if queryErr := result.Scan(&listDto.Id, &listDto.OwnerId, &listDto.Title, &listDto.Description,
+		&listDto.Privacy, &listDto.SiteId, &listDto.DateCreated, &listDto.DateDeleted); queryErr != nil {
+		msg := fmt.Sprintf("list %d not found in trash", listId)
+		logrus.Error(msg, queryErr)
+		return nil, apierrors.NewNotFoundApiError(msg)
+	}
+
+	return &listDto, nil
+}
+
+func (dao *listDao) GetTrashedListsFromOwner(ownerId int64) (Lists, apierrors.ApiError) {
+	return dao.queryTrashedLists(getTrashedListsFromOwner, ownerId)
+}
+
+func (dao *listDao) GetExpiredTrashedLists(deletedBefore string) (Lists, apierrors.ApiError) {
+	return dao.queryTrashedLists(getExpiredTrashedLists, deletedBefore)
+}
+
+// DeleteList hard deletes the list and its favorites. Rows of other tables referencing the list must be deleted first.
+func (dao *listDao) DeleteList(listId int64) apierrors.ApiError {
+	for _, query := range []string{deleteFavoritesByList, deleteList} {
+		stmt, err := database.GetExecutor(dao.uow).Prepare(query)
+		if err != nil {
+			logrus.Error("error when trying to prepare delete list statement", err)
+			return apierrors.NewInternalServerApiError("error when trying to purge list", error_utils.GetDatabaseGenericError())
+		}
+
+		_, execErr := stmt.Exec(listId)
+		stmt.Close()
+		if execErr != nil {
+			logrus.Error("error when trying to delete list", execErr)
+			return apierrors.NewInternalServerApiError("error when trying to purge list", error_utils.GetDatabaseGenericError())
+		}
+	}
+
+	logrus.Info(fmt.Sprintf("successfully purged list %d", listId))
+	return nil
+}
+
+func (dao *listDao) queryTrashedLists(query string, args ...interface{}) (Lists, apierrors.ApiError) {
+	stmt, err := database.GetExecutor(dao.uow).Prepare(query)
+	if err != nil {
+		logrus.Error("error when trying to prepare get trashed lists statement", err)
+		return nil, apierrors.NewInternalServerApiError("error when trying to get trashed lists", error_utils.GetDatabaseGenericError())
+	}
+	defer stmt.Close()
+
+	rows, err := stmt.Query(args...)
+	if err != nil {
+		logrus.Error("error while getting trashed lists", err)
+		return nil, apierrors.NewInternalServerApiError("error getting trashed lists", error_utils.GetDatabaseGenericError())
+	}
+	defer rows.Close()
+
+	result := make([]List, 0)
+
+	for rows.Next() {
+		var list List
+		if err := rows.Scan(&list.Id, &list.OwnerId, &list.Title, &list.Description, &list.Privacy, &list.SiteId, &list.DateCreated, &list.DateDeleted); err != nil {
+			logrus.Error("error when scan list row into list struct", err)
+			return nil, apierrors.NewInternalServerApiError("error when tying to get trashed lists", error_utils.GetDatabaseGenericError())
+		}
+		result = append(result, list)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,295 @@
+package lists
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/lmurature/melist-api/src/api/clients/database"
+	"github.com/lmurature/melist-api/src/api/domain/apierrors"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	memoryFirstListId = 75618245
+)
+
+type favoriteList struct {
+	userId int64
+	listId int64
+}
+
+type listMemoryStore struct {
+	mu        sync.RWMutex
+	lastId    int64
+	lists     map[int64]List
+	favorites []favoriteList
+}
+
+type listMemoryDao struct {
+	*listMemoryStore
+	uow *database.UnitOfWork
+}
+
+// UseMemoryStorage replaces ListDao with an empty in-memory implementation.
+func UseMemoryStorage() {
+	ListDao = &listMemoryDao{
+		listMemoryStore: &listMemoryStore{
+			lastId: memoryFirstListId - 1,
+			lists:  make(map[int64]List),
+		},
+	}
+}
+
+func (dao *listMemoryDao) WithUnitOfWork(uow *database.UnitOfWork) listDaoInterface {
+	return &listMemoryDao{listMemoryStore: dao.listMemoryStore, uow: uow}
+}
+
+func (dao *listMemoryDao) GetList(listId int64) (*List, apierrors.ApiError) {
+	dao.mu.RLock()
+	defer dao.mu.RUnlock()
+
+	list, ok := dao.lists[listId]
+	if !ok || list.DateDeleted != "" {
+		msg := fmt.Sprintf("list %d not found", listId)
+		logrus.Error(msg)
+		return nil, apierrors.NewNotFoundApiError(msg)
+	}
+
+	return &list, nil
+}
+
+func (dao *listMemoryDao) CreateList(listDto List) (*List, apierrors.ApiError) {
+	dao.mu.Lock()
+	defer dao.mu.Unlock()
+
+	dao.lastId++
+	listDto.Id = dao.lastId
+	listDto.Notifications = 0
+	listDto.DateDeleted = ""
+	dao.lists[listDto.Id] = listDto
+	dao.uow.OnRollback(func() {
+		dao.mu.Lock()
+		defer dao.mu.Unlock()
+		delete(dao.lists, listDto.Id)
+	})
+
+	logrus.Info(fmt.Sprintf("successfully created list %d", listDto.Id))
+	return &listDto, nil
+}
+
+func (dao *listMemoryDao) UpdateList(listDto List) (*List, apierrors.ApiError) {
+	dao.mu.Lock()
+	defer dao.mu.Unlock()
+
+	if actual, ok := dao.lists[listDto.Id]; ok {
+		updated := actual
+		updated.Title = listDto.Title
+		updated.Description = listDto.Description
+		updated.Privacy = listDto.Privacy
+		dao.setLocked(updated, actual)
+	}
+
+	logrus.Info(fmt.Sprintf("successfully updated list %d", listDto.Id))
+	return &listDto, nil
+}
+
+func (dao *listMemoryDao) GetPublicLists() (Lists, apierrors.ApiError) {
+	result := dao.filter(func(l List) bool {
+		return l.Privacy == PrivacyTypePublic && l.DateDeleted == ""
+	})
+
+	if len(result) == 0 {
+		return nil, apierrors.NewNotFoundApiError("no public lists found")
+	}
+
+	return result, nil
+}
+
+func (dao *listMemoryDao) GetListsFromOwner(ownerId int64) (Lists, apierrors.ApiError) {
+	return dao.filter(func(l List) bool {
+		return l.OwnerId == ownerId && l.DateDeleted == ""
+	}), nil
+}
+
+func (dao *listMemoryDao) GetUserFavoriteLists(userId int64) (Lists, apierrors.ApiError) {
+	dao.mu.RLock()
+	defer dao.mu.RUnlock()
+
+	result := make(Lists, 0)
+	for _, f := range dao.favorites {
+		if list, ok := dao.lists[f.listId]; ok && f.userId == userId && list.DateDeleted == "" {
+			result = append(result, list)
+		}
+	}
+
+	return result, nil
+}
+
+func (dao *listMemoryDao) SaveFavoriteList(listId int64, userId int64) apierrors.ApiError {
+	dao.mu.Lock()
+	defer dao.mu.Unlock()
+
+	dao.favorites = append(dao.favorites, favoriteList{userId: userId, listId: listId})
+	dao.uow.OnRollback(func() {
+		dao.mu.Lock()
+		defer dao.mu.Unlock()
+		dao.removeFavoritesLocked(func(f favoriteList) bool {
+			return f.listId == listId && f.userId == userId
+		})
+	})
+
+	return nil
+}
+
+func (dao *listMemoryDao) RemoveFavoriteList(listId int64, userId int64) apierrors.ApiError {
+	dao.mu.Lock()
+	defer dao.mu.Unlock()
+
+	removed := dao.removeFavoritesLocked(func(f favoriteList) bool {
+		return f.listId == listId && f.userId == userId
+	})
+	dao.uow.OnRollback(func() {
+		dao.mu.Lock()
+		defer dao.mu.Unlock()
+		dao.favorites = append(dao.favorites, removed...)
+	})
+
+	return nil
+}
+
+func (dao *listMemoryDao) GetAllLists() (Lists, apierrors.ApiError) {
+	result := dao.filter(func(l List) bool {
+		return l.DateDeleted == ""
+	})
+
+	if len(result) == 0 {
+		return nil, apierrors.NewNotFoundApiError("no lists found")
+	}
+
+	return result, nil
+}
+
+func (dao *listMemoryDao) TrashList(listId int64, dateDeleted string) apierrors.ApiError {
+	dao.mu.Lock()
+	defer dao.mu.Unlock()
+
+	if actual, ok := dao.lists[listId]; ok && actual.DateDeleted == "" {
+		trashed := actual
+		trashed.DateDeleted = dateDeleted
+		dao.setLocked(trashed, actual)
+	}
+
+	logrus.Info(fmt.Sprintf("successfully moved list %d to trash", listId))
+	return nil
+}
+
+func (dao *listMemoryDao) RestoreList(listId int64) apierrors.ApiError {
+	dao.mu.Lock()
+	defer dao.mu.Unlock()
+
+	if actual, ok := dao.lists[listId]; ok {
+		restored := actual
+		restored.DateDeleted = ""
+		dao.setLocked(restored, actual)
+	}
+
+	logrus.Info(fmt.Sprintf("successfully restored list %d from trash", listId))
+	return nil
+}
+
+func (dao *listMemoryDao) GetTrashedList(listId int64) (*List, apierrors.ApiError) {
+	dao.mu.RLock()
+	defer dao.mu.RUnlock()
+
+	list, ok := dao.lists[listId]
+	if !ok || list.DateDeleted == "" {
+		msg := fmt.Sprintf("list %d not found in trash", listId)
+		logrus.Error(msg)
+		return nil, apierrors.NewNotFoundApiError(msg)
+	}
+
+	return &list, nil
+}
+
+func (dao *listMemoryDao) GetTrashedListsFromOwner(ownerId int64) (Lists, apierrors.ApiError) {
+	result := dao.filter(func(l List) bool {
+		return l.OwnerId == ownerId && l.DateDeleted != ""
+	})
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].DateDeleted > result[j].DateDeleted
+	})
+
+	return result, nil
+}
+
+func (dao *listMemoryDao) GetExpiredTrashedLists(deletedBefore string) (Lists, apierrors.ApiError) {
+	return dao.filter(func(l List) bool {
+		return l.DateDeleted != "" && l.DateDeleted <= deletedBefore
+	}), nil
+}
+
+func (dao *listMemoryDao) DeleteList(listId int64) apierrors.ApiError {
+	dao.mu.Lock()
+	defer dao.mu.Unlock()
+
+	removedFavorites := dao.removeFavoritesLocked(func(f favoriteList) bool {
+		return f.listId == listId
+	})
+	list, existed := dao.lists[listId]
+	delete(dao.lists, listId)
+
+	dao.uow.OnRollback(func() {
+		dao.mu.Lock()
+		defer dao.mu.Unlock()
+		if existed {
+			dao.lists[listId] = list
+		}
+		dao.favorites = append(dao.favorites, removedFavorites...)
+	})
+
+	logrus.Info(fmt.Sprintf("successfully purged list %d", listId))
+	return nil
+}
+
+// setLocked stores updated and registers the undo back to previous. The caller must hold mu.
+func (dao *listMemoryDao) setLocked(updated List, previous List) {
+	dao.lists[updated.Id] = updated
+	dao.uow.OnRollback(func() {
+		dao.mu.Lock()
+		defer dao.mu.Unlock()
+		dao.lists[previous.Id] = previous
+	})
+}
+
+// removeFavoritesLocked deletes the favorites matching remove and returns them. The caller must hold mu.
+func (dao *listMemoryDao) removeFavoritesLocked(remove func(f favoriteList) bool) []favoriteList {
+	kept := make([]favoriteList, 0, len(dao.favorites))
+	removed := make([]favoriteList, 0)
+	for _, f := range dao.favorites {
+		if remove(f) {
+			removed = append(removed, f)
+		} else {
+			kept = append(kept, f)
+		}
+	}
+	dao.favorites = kept
+	return removed
+}
+
+func (dao *listMemoryDao) filter(f func(l List) bool) Lists {
+	dao.mu.RLock()
+	defer dao.mu.RUnlock()
+
+	result := make(Lists, 0)
+	for _, l := range dao.lists {
+		if f(l) {
+			result = append(result, l)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Id < result[j].Id
+	})
+
+	return result
+}
@@ -0,0 +1,180 @@
+package lists
+
+import (
+	"fmt"
+
+	"github.com/lmurature/melist-api/src/api/domain/apierrors"
+	"github.com/lmurature/melist-api/src/api/domain/share"
+)
+
+// Roles a user can have in a list. Collaborators take the role of their share type.
+const (
+	RoleOwner         = "owner"
+	RoleAdmin         = share.ShareTypeAdmin
+	RoleWrite         = share.ShareTypeWrite
+	RoleCheck         = share.ShareTypeCheck
+	RoleRead          = share.ShareTypeRead
+	RolePublicVisitor = "public_visitor"
+	RoleNone          = ""
+)
+
+// Actions users can take on a list.
+const (
+	ActionRead          = "read"
+	ActionAddItems      = "add_items"
+	ActionRemoveItems   = "remove_items"
+	ActionCheckItems    = "check_items"
+	ActionShare         = "share"
+	ActionRename        = "rename"
+	ActionChangePrivacy = "change_privacy"
+	ActionDelete        = "delete"
+	ActionViewShares    = "view_shares"
+)
+
+var (
+	// Actions has every action in the order they are reported.
+	Actions = []string{ActionRead, ActionAddItems, ActionRemoveItems, ActionCheckItems, ActionShare, ActionRename,
+		ActionChangePrivacy, ActionDelete, ActionViewShares}
+
+	// policy is what each role is allowed to do. Visitors of public lists can do what any collaborator can, which
+	// collaborators of public lists keep on top of their own role.
+	policy = map[string]map[string]bool{
+		RoleOwner: {ActionRead: true, ActionAddItems: true, ActionRemoveItems: true, ActionCheckItems: true,
+			ActionShare: true, ActionRename: true, ActionChangePrivacy: true, ActionDelete: true, ActionViewShares: true},
+		RoleAdmin: {ActionRead: true, ActionAddItems: true, ActionRemoveItems: true, ActionCheckItems: true,
+			ActionShare: true, ActionRename: true, ActionViewShares: true},
+		RoleWrite:         {ActionRead: true, ActionAddItems: true, ActionRemoveItems: true, ActionCheckItems: true},
+		RoleCheck:         {ActionRead: true, ActionCheckItems: true},
+		RoleRead:          {ActionRead: true},
+		RolePublicVisitor: {ActionRead: true, ActionAddItems: true, ActionRemoveItems: true, ActionCheckItems: true},
+	}
+
+	forbiddenMessages = map[string]string{
+		ActionRead:          "you have no access to this list",
+		ActionAddItems:      "you have no access to add items to this list",
+		ActionRemoveItems:   "you have no access to remove items from this list",
+		ActionCheckItems:    "you have no access to check items of this list",
+		ActionShare:         "you have no access to share this list",
+		ActionRename:        "you have no access to update this list",
+		ActionChangePrivacy: "only the owner of the list can change its privacy",
+		ActionDelete:        "you have no access to delete this list",
+		ActionViewShares:    "you have no access to this list's share config",
+	}
+)
+
+// RoleOf returns the role of userId in the list given its share configs, or RoleNone when the user cannot see it.
+func (l List) RoleOf(userId int64, configs share.ShareConfigs) string {
+	if userId == l.OwnerId {
+		return RoleOwner
+	}
+	for _, c := range configs {
+		if c.UserId == userId {
+			if _, ok := policy[c.ShareType]; ok {
+				return c.ShareType
+			}
+		}
+	}
+	if l.Privacy == PrivacyTypePublic {
+		return RolePublicVisitor
+	}
+	return RoleNone
+}
+
+// Can is whether userId is allowed to take action on the list.
+func (l List) Can(userId int64, action string, configs share.ShareConfigs) bool {
+	role := l.RoleOf(userId, configs)
+	if policy[role][action] {
+		return true
+	}
+	return l.Privacy == PrivacyTypePublic && policy[RolePublicVisitor][action]
+}
+
+// Authorize fails with forbidden when userId is not allowed to take action on the list.
+func (l List) Authorize(userId int64, action string, configs share.ShareConfigs) apierrors.ApiError {
+	if !l.Can(userId, action, configs) {
+		return apierrors.NewForbiddenApiError(forbiddenMessages[action])
+	}
+	return nil
+}
+
+// AllowedActions returns the actions userId is allowed to take on the list, in the order of Actions.
+func (l List) AllowedActions(userId int64, configs share.ShareConfigs) []string {
+	result := make([]string, 0, len(Actions))
+	for _, action := range Actions {
+		if l.Can(userId, action, configs) {
+			result = append(result, action)
+		}
+	}
+	return result
+}
+
+// AuthorizeShare checks that callerId can give userId access to the list as shareType, or take it away when
+// shareType is empty. Admins share the list like its owner, but only the owner grants or takes away admin access.
+// userId is 0 for users invited by email that do not have an account yet, whose invitations AuthorizeInvite checks.
+func (l List) AuthorizeShare(callerId int64, userId int64, shareType string, configs share.ShareConfigs) apierrors.ApiError {
+	if err := l.Authorize(callerId, ActionShare, configs); err != nil {
+		return err
+	}
+	if userId != 0 && userId == l.OwnerId {
+		return apierrors.NewBadRequestApiError(fmt.Sprintf("user %d is the owner of the list", userId))
+	}
+	if l.RoleOf(callerId, configs) == RoleOwner {
+		return nil
+	}
+	if shareType == share.ShareTypeAdmin || (userId != 0 && l.RoleOf(userId, configs) == RoleAdmin) {
+		return apierrors.NewForbiddenApiError("only the owner of the list can manage its admins")
+	}
+	return nil
+}
+
+// AuthorizeInvite checks that callerId can invite email, who does not have an account yet, to the list as shareType.
+// pending are the invitations of the list that were not accepted yet: like admin access, only the owner changes an
+// admin invitation.
+func (l List) AuthorizeInvite(callerId int64, email string, shareType string, configs share.ShareConfigs, pending share.ShareConfigs) apierrors.ApiError {
+	if err := l.AuthorizeShare(callerId, 0, shareType, configs); err != nil {
+		return err
+	}
+	if l.RoleOf(callerId, configs) == RoleOwner {
+		return nil
+	}
+	for _, p := range pending {
+		if p.Email == email && p.ShareType == share.ShareTypeAdmin {
+			return apierrors.NewForbiddenApiError("only the owner of the list can manage its admins")
+		}
+	}
+	return nil
+}
+
+// Permissions is what a user can do in a list. ShareType is kept for clients that only know share types: owners
+// are reported as admins and visitors of public lists as readers.
+type Permissions struct {
+	ListId    int64    `json:"list_id"`
+	UserId    int64    `json:"user_id"`
+	ShareType string   `json:"share_type"`
+	Role      string   `json:"role"`
+	Actions   []string `json:"actions"`
+}
+
+// PermissionsOf returns the permissions of userId in the list, failing with forbidden when the user cannot see it.
+func (l List) PermissionsOf(userId int64, configs share.ShareConfigs) (*Permissions, apierrors.ApiError) {
+	if err := l.Authorize(userId, ActionRead, configs); err != nil {
+		return nil, err
+	}
+
+	role := l.RoleOf(userId, configs)
+	shareType := role
+	switch role {
+	case RoleOwner:
+		shareType = share.ShareTypeAdmin
+	case RolePublicVisitor:
+		shareType = share.ShareTypeRead
+	}
+
+	return &Permissions{
+		ListId:    l.Id,
+		UserId:    userId,
+		ShareType: shareType,
+		Role:      role,
+		Actions:   l.AllowedActions(userId, configs),
+	}, nil
+}
@@ -0,0 +1,161 @@
+package lists
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/lmurature/melist-api/src/api/domain/share"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	ownerId = iota + 1
+	adminId
+	writerId
+	checkerId
+	readerId
+	strangerId
+)
+
+var configs = share.ShareConfigs{
+	{ListId: 1, UserId: adminId, ShareType: share.ShareTypeAdmin},
+	{ListId: 1, UserId: writerId, ShareType: share.ShareTypeWrite},
+	{ListId: 1, UserId: checkerId, ShareType: share.ShareTypeCheck},
+	{ListId: 1, UserId: readerId, ShareType: share.ShareTypeRead},
+}
+
+func TestPolicy(t *testing.T) {
+	collaborate := []string{ActionRead, ActionAddItems, ActionRemoveItems, ActionCheckItems}
+	administrate := []string{ActionRead, ActionAddItems, ActionRemoveItems, ActionCheckItems, ActionShare, ActionRename, ActionViewShares}
+
+	tests := []struct {
+		privacy string
+		userId  int64
+		role    string
+		allowed []string
+	}{
+		{PrivacyTypePrivate, ownerId, RoleOwner, Actions},
+		{PrivacyTypePrivate, adminId, RoleAdmin, administrate},
+		{PrivacyTypePrivate, writerId, RoleWrite, collaborate},
+		{PrivacyTypePrivate, checkerId, RoleCheck, []string{ActionRead, ActionCheckItems}},
+		{PrivacyTypePrivate, readerId, RoleRead, []string{ActionRead}},
+		{PrivacyTypePrivate, strangerId, RoleNone, nil},
+		{PrivacyTypePublic, ownerId, RoleOwner, Actions},
+		{PrivacyTypePublic, adminId, RoleAdmin, administrate},
+		{PrivacyTypePublic, writerId, RoleWrite, collaborate},
+		{PrivacyTypePublic, checkerId, RoleCheck, collaborate},
+		{PrivacyTypePublic, readerId, RoleRead, collaborate},
+		{PrivacyTypePublic, strangerId, RolePublicVisitor, collaborate},
+	}
+
+	for _, tt := range tests {
+		list := List{Id: 1, OwnerId: ownerId, Privacy: tt.privacy}
+		assert.EqualValues(t, tt.role, list.RoleOf(tt.userId, configs), "role of user %d in %s list", tt.userId, tt.privacy)
+
+		for _, action := range Actions {
+			t.Run(fmt.Sprintf("%s/%s/%s", tt.privacy, tt.role, action), func(t *testing.T) {
+				expected := false
+				for _, a := range tt.allowed {
+					expected = expected || a == action
+				}
+
+				assert.EqualValues(t, expected, list.Can(tt.userId, action, configs))
+				err := list.Authorize(tt.userId, action, configs)
+				if expected {
+					assert.Nil(t, err)
+				} else {
+					assert.NotNil(t, err)
+					assert.EqualValues(t, http.StatusForbidden, err.Status())
+				}
+			})
+		}
+	}
+}
+
+func TestUnknownShareTypesGrantNothing(t *testing.T) {
+	list := List{Id: 1, OwnerId: ownerId, Privacy: PrivacyTypePrivate}
+	unknown := share.ShareConfigs{{ListId: 1, UserId: strangerId, ShareType: "superuser"}}
+
+	assert.EqualValues(t, RoleNone, list.RoleOf(strangerId, unknown))
+	assert.Empty(t, list.AllowedActions(strangerId, unknown))
+}
+
+func TestAuthorizeShare(t *testing.T) {
+	list := List{Id: 1, OwnerId: ownerId, Privacy: PrivacyTypePrivate}
+
+	assert.Nil(t, list.AuthorizeShare(ownerId, strangerId, share.ShareTypeAdmin, configs))
+	assert.Nil(t, list.AuthorizeShare(ownerId, adminId, "", configs), "the owner takes away admin access")
+	assert.Nil(t, list.AuthorizeShare(adminId, strangerId, share.ShareTypeWrite, configs))
+	assert.Nil(t, list.AuthorizeShare(adminId, writerId, share.ShareTypeRead, configs))
+	assert.Nil(t, list.AuthorizeShare(adminId, readerId, "", configs))
+	assert.Nil(t, list.AuthorizeShare(adminId, 0, share.ShareTypeCheck, configs), "users invited by email")
+
+	err := list.AuthorizeShare(adminId, strangerId, share.ShareTypeAdmin, configs)
+	assert.NotNil(t, err)
+	assert.EqualValues(t, http.StatusForbidden, err.Status())
+	assert.EqualValues(t, "only the owner of the list can manage its admins", err.Message())
+
+	err = list.AuthorizeShare(adminId, adminId, share.ShareTypeRead, configs)
+	assert.NotNil(t, err)
+	assert.EqualValues(t, http.StatusForbidden, err.Status())
+
+	err = list.AuthorizeShare(adminId, ownerId, "", configs)
+	assert.NotNil(t, err)
+	assert.EqualValues(t, http.StatusBadRequest, err.Status())
+
+	err = list.AuthorizeShare(writerId, strangerId, share.ShareTypeRead, configs)
+	assert.NotNil(t, err)
+	assert.EqualValues(t, http.StatusForbidden, err.Status())
+}
+
+func TestAuthorizeInvite(t *testing.T) {
+	list := List{Id: 1, OwnerId: ownerId, Privacy: PrivacyTypePrivate}
+	pending := share.ShareConfigs{
+		{ListId: 1, Email: "admin@melist.test", ShareType: share.ShareTypeAdmin},
+		{ListId: 1, Email: "writer@melist.test", ShareType: share.ShareTypeWrite},
+	}
+
+	assert.Nil(t, list.AuthorizeInvite(ownerId, "admin@melist.test", share.ShareTypeRead, configs, pending))
+	assert.Nil(t, list.AuthorizeInvite(adminId, "writer@melist.test", share.ShareTypeRead, configs, pending))
+	assert.Nil(t, list.AuthorizeInvite(adminId, "new@melist.test", share.ShareTypeCheck, configs, pending))
+
+	err := list.AuthorizeInvite(adminId, "admin@melist.test", share.ShareTypeRead, configs, pending)
+	assert.NotNil(t, err, "co-admins cannot overwrite an admin invitation")
+	assert.EqualValues(t, http.StatusForbidden, err.Status())
+	assert.EqualValues(t, "only the owner of the list can manage its admins", err.Message())
+
+	err = list.AuthorizeInvite(adminId, "new@melist.test", share.ShareTypeAdmin, configs, pending)
+	assert.NotNil(t, err)
+	assert.EqualValues(t, http.StatusForbidden, err.Status())
+
+	err = list.AuthorizeInvite(writerId, "new@melist.test", share.ShareTypeRead, configs, pending)
+	assert.NotNil(t, err)
+	assert.EqualValues(t, http.StatusForbidden, err.Status())
+}
+
+func TestPermissionsOf(t *testing.T) {
+	list := List{Id: 1, OwnerId: ownerId, Privacy: PrivacyTypePublic}
+
+	permissions, err := list.PermissionsOf(ownerId, configs)
+	assert.Nil(t, err)
+	assert.EqualValues(t, RoleOwner, permissions.Role)
+	assert.EqualValues(t, share.ShareTypeAdmin, permissions.ShareType)
+	assert.EqualValues(t, Actions, permissions.Actions)
+
+	permissions, err = list.PermissionsOf(checkerId, configs)
+	assert.Nil(t, err)
+	assert.EqualValues(t, RoleCheck, permissions.Role)
+	assert.EqualValues(t, share.ShareTypeCheck, permissions.ShareType)
+
+	permissions, err = list.PermissionsOf(strangerId, configs)
+	assert.Nil(t, err)
+	assert.EqualValues(t, RolePublicVisitor, permissions.Role)
+	assert.EqualValues(t, share.ShareTypeRead, permissions.ShareType)
+	assert.EqualValues(t, []string{ActionRead, ActionAddItems, ActionRemoveItems, ActionCheckItems}, permissions.Actions)
+
+	list.Privacy = PrivacyTypePrivate
+	_, err = list.PermissionsOf(strangerId, configs)
+	assert.NotNil(t, err)
+	assert.EqualValues(t, http.StatusForbidden, err.Status())
+}
@@ -0,0 +1,132 @@
+package notifications
+
+import (
+	"bytes"
+	"fmt"
+	"text/template"
+
+	i18n_utils "github.com/lmurature/melist-api/src/api/utils/i18n"
+)
+
+var (
+	// catalogs hold the message of every notification type per locale, rendered from the notification Params.
+	catalogs = map[string]map[string]string{
+		i18n_utils.LocaleEsAR: {
+			TypePriceChange:          "¡El producto {{item .}} tuvo un cambio en su precio! Antes valía {{price . .OldPrice}}, ahora {{price . .NewPrice}}.",
+			TypeDealActivated:        "¡El producto {{item .}} entró en una oferta!",
+			TypeDealEnded:            "El producto {{item .}} ya no está en oferta.",
+			TypeNearEmptyStock:       "El producto {{item .}} se está por quedar sin stock, sólo restan {{.Stock}} unidades disponibles.",
+			TypeEmptyStock:           "El producto {{item .}} se quedó sin stock.",
+			TypeItemStatusChanged:    "El producto {{item .}} ya no se puede comprar. La publicación fue pausada o finalizada.",
+			TypeItemChecked:          "¡El producto {{item .}} fue comprado por {{.Actor}}!",
+			TypeItemUnchecked:        "El producto {{item .}} fue marcado como no comprado por {{.Actor}}",
+			TypeItemAdded:            "¡{{.Actor}} añadió un nuevo producto a la lista!",
+			TypeListFavorited:        "¡{{.Actor}} añadió esta lista a sus favoritos!",
+			TypeItemReviews:          "¡El producto {{item .}} tiene revisiones nuevas por parte de otros usuarios de Mercado Libre!",
+			TypeTargetPriceReached:   "¡El producto {{item .}} llegó al precio que buscabas! Ahora vale {{price . .NewPrice}} y querías pagar {{price . .TargetPrice}}.",
+			TypeAlertPriceDrop:       "¡El precio del producto {{item .}} bajó un {{.Threshold}}% o más! Antes valía {{price . .OldPrice}}, ahora {{price . .NewPrice}}.",
+			TypeAlertPriceBelow:      "¡El producto {{item .}} bajó de {{price . .Threshold}}! Ahora vale {{price . .NewPrice}}.",
+			TypeAlertBackInStock:     "¡El producto {{item .}} volvió a tener stock! Hay {{.Stock}} unidades disponibles.",
+			TypeAlertStockBelow:      "El producto {{item .}} tiene menos de {{.Threshold}} unidades disponibles, sólo restan {{.Stock}}.",
+			TypeAlertLowRatingReview: "El producto {{item .}} recibió una revisión de {{.Rating}} estrellas.",
+			TypeAlertHistoricalLow:   "¡El producto {{item .}} está en su precio más bajo! Ahora vale {{price . .NewPrice}}, el mínimo anterior era {{price . .OldPrice}}.",
+		},
+		i18n_utils.LocalePtBR: {
+			TypePriceChange:          "O preço do produto {{item .}} mudou! Antes custava {{price . .OldPrice}}, agora {{price . .NewPrice}}.",
+			TypeDealActivated:        "O produto {{item .}} entrou em oferta!",
+			TypeDealEnded:            "O produto {{item .}} não está mais em oferta.",
+			TypeNearEmptyStock:       "O produto {{item .}} está quase sem estoque, restam apenas {{.Stock}} unidades disponíveis.",
+			TypeEmptyStock:           "O produto {{item .}} ficou sem estoque.",
+			TypeItemStatusChanged:    "O produto {{item .}} não pode mais ser comprado. O anúncio foi pausado ou finalizado.",
+			TypeItemChecked:          "O produto {{item .}} foi comprado por {{.Actor}}!",
+			TypeItemUnchecked:        "O produto {{item .}} foi marcado como não comprado por {{.Actor}}",
+			TypeItemAdded:            "{{.Actor}} adicionou um novo produto à lista!",
+			TypeListFavorited:        "{{.Actor}} adicionou esta lista aos favoritos!",
+			TypeItemReviews:          "O produto {{item .}} tem novas avaliações de outros usuários do Mercado Livre!",
+			TypeTargetPriceReached:   "O produto {{item .}} chegou ao preço que você queria! Agora custa {{price . .NewPrice}} e você queria pagar {{price . .TargetPrice}}.",
+			TypeAlertPriceDrop:       "O preço do produto {{item .}} caiu {{.Threshold}}% ou mais! Antes custava {{price . .OldPrice}}, agora {{price . .NewPrice}}.",
+			TypeAlertPriceBelow:      "O produto {{item .}} ficou abaixo de {{price . .Threshold}}! Agora custa {{price . .NewPrice}}.",
+			TypeAlertBackInStock:     "O produto {{item .}} voltou a ter estoque! Há {{.Stock}} unidades disponíveis.",
+			TypeAlertStockBelow:      "O produto {{item .}} tem menos de {{.Threshold}} unidades disponíveis, restam apenas {{.Stock}}.",
+			TypeAlertLowRatingReview: "O produto {{item .}} recebeu uma avaliação de {{.Rating}} estrelas.",
+			TypeAlertHistoricalLow:   "O produto {{item .}} está no menor preço! Agora custa {{price . .NewPrice}}, o menor preço anterior era {{price . .OldPrice}}.",
+		},
+		i18n_utils.LocaleEn: {
+			TypePriceChange:          "The price of {{item .}} changed! It was {{price . .OldPrice}}, now it is {{price . .NewPrice}}.",
+			TypeDealActivated:        "{{item .}} is on sale!",
+			TypeDealEnded:            "{{item .}} is no longer on sale.",
+			TypeNearEmptyStock:       "{{item .}} is running out of stock, only {{.Stock}} units left.",
+			TypeEmptyStock:           "{{item .}} is out of stock.",
+			TypeItemStatusChanged:    "{{item .}} can no longer be bought. The listing was paused or closed.",
+			TypeItemChecked:          "{{item .}} was bought by {{.Actor}}!",
+			TypeItemUnchecked:        "{{item .}} was marked as not bought by {{.Actor}}",
+			TypeItemAdded:            "{{.Actor}} added a new product to the list!",
+			TypeListFavorited:        "{{.Actor}} added this list to their favorites!",
+			TypeItemReviews:          "{{item .}} has new reviews from other Mercado Libre users!",
+			TypeTargetPriceReached:   "{{item .}} reached your target price! It is {{price . .NewPrice}} now and you wanted to pay {{price . .TargetPrice}}.",
+			TypeAlertPriceDrop:       "The price of {{item .}} dropped {{.Threshold}}% or more! It was {{price . .OldPrice}}, now it is {{price . .NewPrice}}.",
+			TypeAlertPriceBelow:      "{{item .}} is now under {{price . .Threshold}}! It costs {{price . .NewPrice}}.",
+			TypeAlertBackInStock:     "{{item .}} is back in stock! {{.Stock}} units are available.",
+			TypeAlertStockBelow:      "{{item .}} has fewer than {{.Threshold}} units left, only {{.Stock}} remain.",
+			TypeAlertLowRatingReview: "{{item .}} got a {{.Rating}} star review.",
+			TypeAlertHistoricalLow:   "{{item .}} is at its lowest price! It costs {{price . .NewPrice}}, the previous low was {{price . .OldPrice}}.",
+		},
+	}
+
+	catalogTemplates = make(map[string]map[string]*template.Template)
+)
+
+func init() {
+	funcs := template.FuncMap{
+		// prices are shown in the currency of the item, notifications saved before they had one show the bare amount
+		"price": func(p Params, price float32) string {
+			if p.CurrencyId == "" {
+				return fmt.Sprintf("%.2f", price)
+			}
+			return fmt.Sprintf("%s %.2f", p.CurrencyId, price)
+		},
+		// items checked from a list only carry their id, and items of a variation are named after it too
+		"item": func(p Params) string {
+			name := p.ItemId
+			if p.Title != "" {
+				name = p.Title
+			}
+			if p.Variation != "" {
+				name = fmt.Sprintf("%s (%s)", name, p.Variation)
+			}
+			return name
+		},
+	}
+
+	for locale, messages := range catalogs {
+		catalogTemplates[locale] = make(map[string]*template.Template, len(messages))
+		for notificationType, message := range messages {
+			catalogTemplates[locale][notificationType] = template.Must(template.New(notificationType).Funcs(funcs).Parse(message))
+		}
+	}
+}
+
+// Localize returns a copy of the notification with Message rendered in locale. Notifications saved before they had a
+// type keep their stored message.
+func (n Notification) Localize(locale string) Notification {
+	t, ok := catalogTemplates[i18n_utils.NormalizeLocale(locale)][n.Type]
+	if !ok {
+		return n
+	}
+
+	var message bytes.Buffer
+	if err := t.Execute(&message, n.Params); err != nil {
+		return n
+	}
+	n.Message = message.String()
+	return n
+}
+
+// Localize renders every notification in locale.
+func Localize(notifications []Notification, locale string) []Notification {
+	result := make([]Notification, 0, len(notifications))
+	for _, n := range notifications {
+		result = append(result, n.Localize(locale))
+	}
+	return result
+}
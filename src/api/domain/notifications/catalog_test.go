@@ -0,0 +1,42 @@
+package notifications
+
+import (
+	"testing"
+
+	i18n_utils "github.com/lmurature/melist-api/src/api/utils/i18n"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEveryTypeHasMessageInEveryLocale(t *testing.T) {
+	types := []string{TypePriceChange, TypeDealActivated, TypeDealEnded, TypeNearEmptyStock, TypeEmptyStock,
+		TypeItemStatusChanged, TypeItemChecked, TypeItemUnchecked, TypeItemAdded, TypeListFavorited, TypeItemReviews,
+		TypeTargetPriceReached, TypeAlertPriceDrop, TypeAlertPriceBelow, TypeAlertBackInStock, TypeAlertStockBelow,
+		TypeAlertLowRatingReview, TypeAlertHistoricalLow}
+
+	for _, locale := range i18n_utils.SupportedLocales {
+		assert.EqualValues(t, len(types), len(catalogs[locale]), "locale %s", locale)
+		for _, notificationType := range types {
+			_, ok := catalogTemplates[locale][notificationType]
+			assert.True(t, ok, "locale %s misses %s", locale, notificationType)
+		}
+	}
+}
+
+func TestLocalize(t *testing.T) {
+	checked := NewCheckedItemNotification(1, "MLA2", "lucas")
+	assert.EqualValues(t, "¡El producto MLA2 fue comprado por lucas!", checked.Message)
+	assert.EqualValues(t, "MLA2 was bought by lucas!", checked.Localize("en-US").Message)
+
+	stock := NewNearEmptyStockNotification(1, "MLA1", 3, "Coffee")
+	assert.EqualValues(t, "O produto Coffee está quase sem estoque, restam apenas 3 unidades disponíveis.", stock.Localize("pt-BR").Message)
+
+	price := NewPriceChangeNotification(1, "MLB1", 100, 80.5, "BRL", "Café")
+	assert.EqualValues(t, "O preço do produto Café mudou! Antes custava BRL 100.00, agora BRL 80.50.", price.Localize("pt-BR").Message)
+
+	// notifications saved before prices had a currency
+	price.Params.CurrencyId = ""
+	assert.EqualValues(t, "The price of Café changed! It was 100.00, now it is 80.50.", price.Localize(i18n_utils.LocaleEn).Message)
+
+	legacy := Notification{Message: "mensaje sin tipo"}
+	assert.EqualValues(t, "mensaje sin tipo", legacy.Localize(i18n_utils.LocaleEn).Message)
+}
@@ -0,0 +1,24 @@
+package notifications
+
+// DigestList holds the notifications of one list inside a digest email.
+type DigestList struct {
+	ListId        int64
+	ListTitle     string
+	Notifications []Notification
+}
+
+// GroupByList builds the sections of a digest, keeping lists and notifications in the order they were received.
+func GroupByList(notifications []Notification) []DigestList {
+	result := make([]DigestList, 0)
+	index := make(map[int64]int)
+	for _, n := range notifications {
+		i, ok := index[n.ListId]
+		if !ok {
+			i = len(result)
+			index[n.ListId] = i
+			result = append(result, DigestList{ListId: n.ListId})
+		}
+		result[i].Notifications = append(result[i].Notifications, n)
+	}
+	return result
+}
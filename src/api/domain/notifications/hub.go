@@ -0,0 +1,93 @@
+package notifications
+
+import (
+	"sync"
+)
+
+const (
+	subscriptionBufferSize = 64
+)
+
+var (
+	// NotificationsHub receives every saved notification once its transaction commits.
+	NotificationsHub = NewHub(subscriptionBufferSize)
+)
+
+// Subscription receives the notifications delivered to one user. C is closed when the subscription is
+// cancelled or when the subscriber falls behind, in which case it should resume from the last id it received.
+type Subscription struct {
+	C      <-chan Notification
+	ch     chan Notification
+	userId int64
+	hub    *Hub
+}
+
+// Hub is an in-process pub/sub of notifications keyed by recipient user id.
+type Hub struct {
+	mu          sync.Mutex
+	bufferSize  int
+	subscribers map[int64]map[*Subscription]bool
+}
+
+func NewHub(bufferSize int) *Hub {
+	return &Hub{
+		bufferSize:  bufferSize,
+		subscribers: make(map[int64]map[*Subscription]bool),
+	}
+}
+
+func (h *Hub) Subscribe(userId int64) *Subscription {
+	ch := make(chan Notification, h.bufferSize)
+	s := &Subscription{C: ch, ch: ch, userId: userId, hub: h}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.subscribers[userId] == nil {
+		h.subscribers[userId] = make(map[*Subscription]bool)
+	}
+	h.subscribers[userId][s] = true
+
+	return s
+}
+
+// Publish sends the notification to every subscription of the recipients without blocking. Subscriptions whose
+// buffer is full are dropped so a slow client cannot hold back the others.
+func (h *Hub) Publish(notification Notification, recipients []int64) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for _, userId := range recipients {
+		for s := range h.subscribers[userId] {
+			select {
+			case s.ch <- notification:
+			default:
+				h.removeLocked(s)
+			}
+		}
+	}
+}
+
+// Subscribers returns how many open subscriptions the user has.
+func (h *Hub) Subscribers(userId int64) int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return len(h.subscribers[userId])
+}
+
+func (s *Subscription) Close() {
+	s.hub.mu.Lock()
+	defer s.hub.mu.Unlock()
+	s.hub.removeLocked(s)
+}
+
+// removeLocked closes the subscription channel once. The caller must hold mu.
+func (h *Hub) removeLocked(s *Subscription) {
+	if !h.subscribers[s.userId][s] {
+		return
+	}
+	delete(h.subscribers[s.userId], s)
+	if len(h.subscribers[s.userId]) == 0 {
+		delete(h.subscribers, s.userId)
+	}
+	close(s.ch)
+}
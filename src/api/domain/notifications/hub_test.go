@@ -0,0 +1,39 @@
+package notifications
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHubDeliversOnlyToRecipients(t *testing.T) {
+	hub := NewHub(4)
+	owner := hub.Subscribe(1)
+	stranger := hub.Subscribe(3)
+	defer owner.Close()
+	defer stranger.Close()
+
+	hub.Publish(Notification{Id: 10}, []int64{1, 2})
+
+	n := <-owner.C
+	assert.EqualValues(t, 10, n.Id)
+	assert.EqualValues(t, 0, len(stranger.C))
+}
+
+func TestHubDropsSlowSubscribers(t *testing.T) {
+	hub := NewHub(1)
+	slow := hub.Subscribe(1)
+
+	hub.Publish(Notification{Id: 1}, []int64{1})
+	hub.Publish(Notification{Id: 2}, []int64{1})
+
+	n, open := <-slow.C
+	assert.True(t, open)
+	assert.EqualValues(t, 1, n.Id)
+	_, open = <-slow.C
+	assert.False(t, open)
+	assert.EqualValues(t, 0, hub.Subscribers(1))
+
+	// closing an already dropped subscription must not panic
+	slow.Close()
+}
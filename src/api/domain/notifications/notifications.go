@@ -3,6 +3,7 @@ package notifications
 import (
 	"fmt"
 	date_utils "github.com/lmurature/melist-api/src/api/utils/date"
+	i18n_utils "github.com/lmurature/melist-api/src/api/utils/i18n"
 )
 
 const (
@@ -11,110 +12,200 @@ const (
 	listItemReviewsUrl = "/lists/%d/%s/reviews"
 )
 
+const (
+	TypePriceChange          = "price_change"
+	TypeDealActivated        = "deal_activated"
+	TypeDealEnded            = "deal_ended"
+	TypeNearEmptyStock       = "near_empty_stock"
+	TypeEmptyStock           = "empty_stock"
+	TypeItemStatusChanged    = "item_status_changed"
+	TypeItemChecked          = "item_checked"
+	TypeItemUnchecked        = "item_unchecked"
+	TypeItemAdded            = "item_added"
+	TypeListFavorited        = "list_favorited"
+	TypeItemReviews          = "item_reviews"
+	TypeTargetPriceReached   = "target_price_reached"
+	TypeAlertPriceDrop       = "alert_price_drop"
+	TypeAlertPriceBelow      = "alert_price_below"
+	TypeAlertBackInStock     = "alert_back_in_stock"
+	TypeAlertStockBelow      = "alert_stock_below"
+	TypeAlertLowRatingReview = "alert_low_rating_review"
+	TypeAlertHistoricalLow   = "alert_historical_low"
+)
+
+var (
+	// MailableTypes are the notification types that can be delivered by email as well as in the app.
+	MailableTypes = []string{TypePriceChange, TypeDealActivated, TypeDealEnded, TypeNearEmptyStock,
+		TypeEmptyStock, TypeItemChecked, TypeItemUnchecked, TypeTargetPriceReached, TypeAlertPriceDrop, TypeAlertPriceBelow,
+		TypeAlertBackInStock, TypeAlertStockBelow, TypeAlertLowRatingReview, TypeAlertHistoricalLow}
+)
+
+// Notification is about a list and delivered to all of its users, unless it has a RecipientId, like the alerts a user
+// set for themselves.
 type Notification struct {
-	Id        int64  `json:"id"`
-	ListId    int64  `json:"list_id"`
-	Message   string `json:"message"`
-	Timestamp string `json:"timestamp"`
-	Permalink string `json:"permalink"m `
-	Seen      bool   `json:"seen"`
+	Id          int64  `json:"id"`
+	ListId      int64  `json:"list_id"`
+	Type        string `json:"type"`
+	Params      Params `json:"params"`
+	Message     string `json:"message"`
+	Timestamp   string `json:"timestamp"`
+	Permalink   string `json:"permalink"`
+	Seen        bool   `json:"seen"`
+	RecipientId int64  `json:"recipient_id,omitempty"`
 }
 
-func NewPriceChangeNotification(listId int64, itemId string, oldPrice float32, newPrice float32, title string) *Notification {
-	return &Notification{
-		ListId:    listId,
-		Message:   fmt.Sprintf("¡El producto %s tuvo un cambio en su precio! Antes valía %.2f, ahora %.2f.", title, oldPrice, newPrice),
-		Timestamp: date_utils.GetNowDateFormatted(),
-		Permalink: fmt.Sprintf(listItemUrl, listId, itemId),
-	}
+// Params are the structured values a notification message is rendered from, so clients can build their own text.
+type Params struct {
+	ItemId      string  `json:"item_id,omitempty"`
+	VariationId int64   `json:"variation_id,omitempty"`
+	Variation   string  `json:"variation,omitempty"`
+	Title       string  `json:"title,omitempty"`
+	OldPrice    float32 `json:"old_price,omitempty"`
+	NewPrice    float32 `json:"new_price,omitempty"`
+	TargetPrice float32 `json:"target_price,omitempty"`
+	CurrencyId  string  `json:"currency_id,omitempty"`
+	Stock       int     `json:"stock,omitempty"`
+	Actor       string  `json:"actor,omitempty"`
+	RuleId      int64   `json:"rule_id,omitempty"`
+	Threshold   float32 `json:"threshold,omitempty"`
+	Rating      int     `json:"rating,omitempty"`
+}
+
+// Inbox holds the notifications delivered to a user across all of their lists.
+type Inbox struct {
+	Unread        int            `json:"unread"`
+	UnreadByList  map[int64]int  `json:"unread_by_list"`
+	Notifications []Notification `json:"notifications"`
+}
+
+func NewPriceChangeNotification(listId int64, itemId string, oldPrice float32, newPrice float32, currencyId string, title string) *Notification {
+	return newNotification(listId, TypePriceChange, Params{ItemId: itemId, Title: title, OldPrice: oldPrice, NewPrice: newPrice, CurrencyId: currencyId},
+		fmt.Sprintf(listItemUrl, listId, itemId))
+}
+
+func NewTargetPriceReachedNotification(listId int64, itemId string, newPrice float32, targetPrice float32, currencyId string, title string) *Notification {
+	return newNotification(listId, TypeTargetPriceReached, Params{ItemId: itemId, Title: title, NewPrice: newPrice, TargetPrice: targetPrice, CurrencyId: currencyId},
+		fmt.Sprintf(listItemUrl, listId, itemId))
 }
 
 func NewDealActivatedNotification(listId int64, itemId string, title string) *Notification {
-	return &Notification{
-		ListId:    listId,
-		Message:   fmt.Sprintf("¡El producto %s entró en una oferta!", title),
-		Timestamp: date_utils.GetNowDateFormatted(),
-		Permalink: fmt.Sprintf(listItemUrl, listId, itemId),
-	}
+	return newNotification(listId, TypeDealActivated, Params{ItemId: itemId, Title: title},
+		fmt.Sprintf(listItemUrl, listId, itemId))
 }
 
 func NewDealEndedNotification(listId int64, itemId string, title string) *Notification {
-	return &Notification{
-		ListId:    listId,
-		Message:   fmt.Sprintf("El producto %s ya no está en oferta.", title),
-		Timestamp: date_utils.GetNowDateFormatted(),
-		Permalink: fmt.Sprintf(listItemUrl, listId, itemId),
-	}
+	return newNotification(listId, TypeDealEnded, Params{ItemId: itemId, Title: title},
+		fmt.Sprintf(listItemUrl, listId, itemId))
 }
 
 func NewNearEmptyStockNotification(listId int64, itemId string, currentStock int, title string) *Notification {
-	return &Notification{
-		ListId:    listId,
-		Message:   fmt.Sprintf("El producto %s se está por quedar sin stock, sólo restan %d unidades disponibles.", title, currentStock),
-		Timestamp: date_utils.GetNowDateFormatted(),
-		Permalink: fmt.Sprintf(listItemUrl, listId, itemId),
-	}
+	return newNotification(listId, TypeNearEmptyStock, Params{ItemId: itemId, Title: title, Stock: currentStock},
+		fmt.Sprintf(listItemUrl, listId, itemId))
 }
 
 func NewItemChangedStatusNotification(listId int64, itemId string, title string) *Notification {
-	return &Notification{
-		ListId:    listId,
-		Message:   fmt.Sprintf("El producto %s ya no se puede comprar. La publicación fue pausada o finalizada.", title),
-		Timestamp: date_utils.GetNowDateFormatted(),
-		Permalink: fmt.Sprintf(listItemUrl, listId, itemId),
-	}
+	return newNotification(listId, TypeItemStatusChanged, Params{ItemId: itemId, Title: title},
+		fmt.Sprintf(listItemUrl, listId, itemId))
 }
 
 func NewEmptyStockNotification(listId int64, itemId string, title string) *Notification {
-	return &Notification{
-		ListId:    listId,
-		Message:   fmt.Sprintf("El producto %s se quedó sin stock.", title),
-		Timestamp: date_utils.GetNowDateFormatted(),
-		Permalink: fmt.Sprintf(listItemUrl, listId, itemId),
-	}
+	return newNotification(listId, TypeEmptyStock, Params{ItemId: itemId, Title: title},
+		fmt.Sprintf(listItemUrl, listId, itemId))
 }
 
 func NewCheckedItemNotification(listId int64, itemId string, checkerUser string) *Notification {
-	return &Notification{
-		ListId:    listId,
-		Message:   fmt.Sprintf("¡El producto %s fue comprado por %s!", itemId, checkerUser),
-		Timestamp: date_utils.GetNowDateFormatted(),
-		Permalink: fmt.Sprintf(listItemUrl, listId, itemId),
-	}
+	return newNotification(listId, TypeItemChecked, Params{ItemId: itemId, Actor: checkerUser},
+		fmt.Sprintf(listItemUrl, listId, itemId))
 }
 
 func NewUncheckedItemNotification(listId int64, itemId string, checkerUser string) *Notification {
-	return &Notification{
-		ListId:    listId,
-		Message:   fmt.Sprintf("El producto %s fue marcado como no comprado por %s", itemId, checkerUser),
-		Timestamp: date_utils.GetNowDateFormatted(),
-		Permalink: fmt.Sprintf(listItemUrl, listId, itemId),
-	}
+	return newNotification(listId, TypeItemUnchecked, Params{ItemId: itemId, Actor: checkerUser},
+		fmt.Sprintf(listItemUrl, listId, itemId))
 }
 
 func NewAddedItemToListNotification(listId int64, itemId string, adderUser string) *Notification {
-	return &Notification{
-		ListId:    listId,
-		Message:   fmt.Sprintf("¡%s añadió un nuevo producto a la lista!", adderUser),
-		Timestamp: date_utils.GetNowDateFormatted(),
-		Permalink: fmt.Sprintf(listItemUrl, listId, itemId),
-	}
+	return newNotification(listId, TypeItemAdded, Params{ItemId: itemId, Actor: adderUser},
+		fmt.Sprintf(listItemUrl, listId, itemId))
 }
 
 func NewUserAddedListToFavorites(listId int64, favoriteUser string) *Notification {
-	return &Notification{
-		ListId:    listId,
-		Message:   fmt.Sprintf("¡%s añadió esta lista a sus favoritos!", favoriteUser),
-		Timestamp: date_utils.GetNowDateFormatted(),
-		Permalink: fmt.Sprintf(listUrl, listId),
-	}
+	return newNotification(listId, TypeListFavorited, Params{Actor: favoriteUser},
+		fmt.Sprintf(listUrl, listId))
 }
 
 func NewReviewItemNotification(listId int64, itemId string, title string) *Notification {
-	return &Notification{
+	return newNotification(listId, TypeItemReviews, Params{ItemId: itemId, Title: title},
+		fmt.Sprintf(listItemReviewsUrl, listId, itemId))
+}
+
+func NewPriceDropAlertNotification(listId int64, itemId string, oldPrice float32, newPrice float32, percentage float32, currencyId string, title string) *Notification {
+	return newNotification(listId, TypeAlertPriceDrop, Params{ItemId: itemId, Title: title, OldPrice: oldPrice, NewPrice: newPrice, Threshold: percentage, CurrencyId: currencyId},
+		fmt.Sprintf(listItemUrl, listId, itemId))
+}
+
+func NewPriceBelowAlertNotification(listId int64, itemId string, newPrice float32, price float32, currencyId string, title string) *Notification {
+	return newNotification(listId, TypeAlertPriceBelow, Params{ItemId: itemId, Title: title, NewPrice: newPrice, Threshold: price, CurrencyId: currencyId},
+		fmt.Sprintf(listItemUrl, listId, itemId))
+}
+
+func NewBackInStockAlertNotification(listId int64, itemId string, currentStock int, title string) *Notification {
+	return newNotification(listId, TypeAlertBackInStock, Params{ItemId: itemId, Title: title, Stock: currentStock},
+		fmt.Sprintf(listItemUrl, listId, itemId))
+}
+
+func NewStockBelowAlertNotification(listId int64, itemId string, currentStock int, units float32, title string) *Notification {
+	return newNotification(listId, TypeAlertStockBelow, Params{ItemId: itemId, Title: title, Stock: currentStock, Threshold: units},
+		fmt.Sprintf(listItemUrl, listId, itemId))
+}
+
+func NewLowRatingReviewAlertNotification(listId int64, itemId string, rating int, title string) *Notification {
+	return newNotification(listId, TypeAlertLowRatingReview, Params{ItemId: itemId, Title: title, Rating: rating},
+		fmt.Sprintf(listItemReviewsUrl, listId, itemId))
+}
+
+func NewHistoricalLowAlertNotification(listId int64, itemId string, previousLow float32, newPrice float32, currencyId string, title string) *Notification {
+	return newNotification(listId, TypeAlertHistoricalLow, Params{ItemId: itemId, Title: title, OldPrice: previousLow, NewPrice: newPrice, CurrencyId: currencyId},
+		fmt.Sprintf(listItemUrl, listId, itemId))
+}
+
+// ForVariation names the variation of the item the notification is about and renders the message again with it.
+func (n *Notification) ForVariation(variationId int64, variation string) *Notification {
+	n.Params.VariationId = variationId
+	n.Params.Variation = variation
+	n.Message = n.Localize(i18n_utils.DefaultLocale).Message
+	return n
+}
+
+// newNotification keeps Message in the default locale for clients that do not send one; it is rendered again in
+// the locale of each reader with Localize.
+func newNotification(listId int64, notificationType string, params Params, permalink string) *Notification {
+	n := &Notification{
 		ListId:    listId,
-		Message:   fmt.Sprintf("¡El producto %s tiene revisiones nuevas por parte de otros usuarios de Mercado Libre!", title),
+		Type:      notificationType,
+		Params:    params,
 		Timestamp: date_utils.GetNowDateFormatted(),
-		Permalink: fmt.Sprintf(listItemReviewsUrl, listId, itemId),
+		Permalink: permalink,
+	}
+	n.Message = n.Localize(i18n_utils.DefaultLocale).Message
+	return n
+}
+
+// VisibleTo leaves out the notifications meant for other users than userId.
+func VisibleTo(notifications []Notification, userId int64) []Notification {
+	result := make([]Notification, 0, len(notifications))
+	for _, n := range notifications {
+		if n.RecipientId == 0 || n.RecipientId == userId {
+			result = append(result, n)
+		}
+	}
+	return result
+}
+
+func IsMailableType(notificationType string) bool {
+	for _, t := range MailableTypes {
+		if t == notificationType {
+			return true
+		}
 	}
+	return false
 }
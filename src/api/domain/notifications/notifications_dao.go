@@ -1,15 +1,35 @@
 package notifications
 
 import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
 	"github.com/lmurature/melist-api/src/api/clients/database"
+	"github.com/lmurature/melist-api/src/api/config"
 	"github.com/lmurature/melist-api/src/api/domain/apierrors"
 	error_utils "github.com/lmurature/melist-api/src/api/utils/error"
 	"github.com/sirupsen/logrus"
 )
 
 const (
-	insertNotification   = "INSERT INTO list_notifications(list_id,message,timestamp,seen,permalink) VALUES(?,?,?,?,?);"
-	getListNotifications = "SELECT id,list_id,message,timestamp,seen,permalink FROM list_notifications WHERE list_id=? ORDER BY timestamp DESC;"
+	insertNotification         = "INSERT INTO list_notifications(list_id,type,params,message,timestamp,seen,permalink,recipient_id) VALUES(?,?,?,?,?,?,?,?);"
+	getListNotifications       = "SELECT id,list_id,type,params,message,timestamp,seen,permalink,recipient_id FROM list_notifications WHERE list_id=? ORDER BY timestamp DESC;"
+	deleteNotifications        = "DELETE FROM list_notifications WHERE list_id=?;"
+	insertDeliveries           = "INSERT INTO notification_delivery(notification_id, user_id) SELECT ?, l.owner_id FROM list l WHERE l.id=? UNION SELECT ?, s.user_id FROM share_config s WHERE s.list_id=?;"
+	insertDelivery             = "INSERT INTO notification_delivery(notification_id, user_id) VALUES(?,?);"
+	deleteDeliveriesByList     = "DELETE d FROM notification_delivery d INNER JOIN list_notifications n ON d.notification_id=n.id WHERE n.list_id=?;"
+	getUserNotifications       = "SELECT n.id, n.list_id, n.type, n.params, n.message, n.timestamp, d.seen, n.permalink, n.recipient_id FROM notification_delivery d INNER JOIN list_notifications n ON d.notification_id=n.id INNER JOIN list l ON n.list_id=l.id WHERE d.user_id=? AND l.date_deleted IS NULL ORDER BY n.timestamp DESC, n.id DESC;"
+	getUserUnreadNotifications = "SELECT n.id, n.list_id, n.type, n.params, n.message, n.timestamp, d.seen, n.permalink, n.recipient_id FROM notification_delivery d INNER JOIN list_notifications n ON d.notification_id=n.id INNER JOIN list l ON n.list_id=l.id WHERE d.user_id=? AND d.seen=0 AND l.date_deleted IS NULL ORDER BY n.timestamp DESC, n.id DESC;"
+	getUserNotificationsAfter  = "SELECT n.id, n.list_id, n.type, n.params, n.message, n.timestamp, d.seen, n.permalink, n.recipient_id FROM notification_delivery d INNER JOIN list_notifications n ON d.notification_id=n.id INNER JOIN list l ON n.list_id=l.id WHERE d.user_id=? AND n.id>? AND l.date_deleted IS NULL ORDER BY n.id ASC;"
+	getRecipients              = "SELECT d.user_id FROM notification_delivery d WHERE d.notification_id=?;"
+	countUnreadByList          = "SELECT n.list_id, COUNT(*) FROM notification_delivery d INNER JOIN list_notifications n ON d.notification_id=n.id WHERE d.user_id=? AND d.seen=0 GROUP BY n.list_id;"
+	getDelivery                = "SELECT d.seen FROM notification_delivery d WHERE d.notification_id=? AND d.user_id=?;"
+	markDeliverySeen           = "UPDATE notification_delivery SET seen=1, date_seen=? WHERE notification_id=? AND user_id=? AND seen=0;"
+	markAllDeliveriesSeen      = "UPDATE notification_delivery SET seen=1, date_seen=? WHERE user_id=? AND seen=0;"
+	getUsersWithPendingMails   = "SELECT DISTINCT d.user_id FROM notification_delivery d INNER JOIN list_notifications n ON d.notification_id=n.id INNER JOIN list l ON n.list_id=l.id WHERE d.date_mailed IS NULL AND d.seen=0 AND d.mail_attempts<? AND l.date_deleted IS NULL;"
+	getPendingMails            = "SELECT n.id, n.list_id, n.type, n.params, n.message, n.timestamp, d.seen, n.permalink, n.recipient_id FROM notification_delivery d INNER JOIN list_notifications n ON d.notification_id=n.id INNER JOIN list l ON n.list_id=l.id WHERE d.user_id=? AND d.date_mailed IS NULL AND d.seen=0 AND d.mail_attempts<? AND l.date_deleted IS NULL ORDER BY n.id ASC;"
+	markDeliveryMailed         = "UPDATE notification_delivery SET date_mailed=? WHERE notification_id=? AND user_id=?;"
+	incrementMailAttempts      = "UPDATE notification_delivery SET mail_attempts=mail_attempts+1 WHERE notification_id=? AND user_id=?;"
 )
 
 var (
@@ -17,26 +37,59 @@ var (
 )
 
 type notificationsDaoInterface interface {
+	WithUnitOfWork(uow *database.UnitOfWork) notificationsDaoInterface
 	SaveNotification(notification Notification) (*Notification, apierrors.ApiError)
 	GetListNotifications(listId int64) ([]Notification, apierrors.ApiError)
+	DeleteListNotifications(listId int64) apierrors.ApiError
+	GetUserNotifications(userId int64, onlyUnread bool) ([]Notification, apierrors.ApiError)
+	GetUserNotificationsAfter(userId int64, afterId int64) ([]Notification, apierrors.ApiError)
+	CountUnreadByList(userId int64) (map[int64]int, apierrors.ApiError)
+	MarkAsSeen(notificationId int64, userId int64, dateSeen string) apierrors.ApiError
+	MarkAllAsSeen(userId int64, dateSeen string) apierrors.ApiError
+	GetUsersWithPendingMails() ([]int64, apierrors.ApiError)
+	GetPendingMails(userId int64) ([]Notification, apierrors.ApiError)
+	MarkAsMailed(notificationIds []int64, userId int64, dateMailed string) apierrors.ApiError
+	RecordMailFailure(notificationIds []int64, userId int64) apierrors.ApiError
 }
 
-type notificationsDao struct{}
+type notificationsDao struct {
+	uow *database.UnitOfWork
+}
 
 func init() {
 	NotificationsDao = &notificationsDao{}
 }
 
+// WithUnitOfWork returns a copy of the dao whose statements run inside the transaction of uow.
+func (n *notificationsDao) WithUnitOfWork(uow *database.UnitOfWork) notificationsDaoInterface {
+	return &notificationsDao{uow: uow}
+}
+
+// SaveNotification stores the notification and delivers it to the list owner and every user the list is shared with,
+// or only to its RecipientId when it has one.
+// Without a unit of work both writes run in a transaction of their own. Recipients are notified through
+// NotificationsHub once the transaction commits.
 func (n *notificationsDao) SaveNotification(notification Notification) (*Notification, apierrors.ApiError) {
-	stmt, err := database.DbClient.Prepare(insertNotification)
+	if n.uow == nil {
+		var result *Notification
+		err := database.RunInTransaction(func(uow *database.UnitOfWork) apierrors.ApiError {
+			var saveErr apierrors.ApiError
+			result, saveErr = n.WithUnitOfWork(uow).SaveNotification(notification)
+			return saveErr
+		})
+		return result, err
+	}
+
+	stmt, err := database.GetExecutor(n.uow).Prepare(insertNotification)
 	if err != nil {
 		logrus.Error("error when trying to prepare insert notification statement", err)
 		return nil, apierrors.NewInternalServerApiError("error when trying to insert notification", error_utils.GetDatabaseGenericError())
 	}
 	defer stmt.Close()
 
-	result, saveErr := stmt.Exec(notification.ListId, notification.Message,
-		notification.Timestamp, notification.Seen, notification.Permalink)
+	params, _ := json.Marshal(notification.Params)
+	result, saveErr := stmt.Exec(notification.ListId, notification.Type, string(params), notification.Message,
+		notification.Timestamp, notification.Seen, notification.Permalink, notification.RecipientId)
 	if saveErr != nil {
 		logrus.Error("error when trying to insert notification", saveErr)
 		return nil, apierrors.NewInternalServerApiError("error when trying to insert notification", error_utils.GetDatabaseGenericError())
@@ -45,18 +98,212 @@ func (n *notificationsDao) SaveNotification(notification Notification) (*Notific
 	id, _ := result.LastInsertId()
 	notification.Id = id
 
+	deliveryQuery, deliveryArgs := insertDeliveries, []interface{}{id, notification.ListId, id, notification.ListId}
+	if notification.RecipientId != 0 {
+		deliveryQuery, deliveryArgs = insertDelivery, []interface{}{id, notification.RecipientId}
+	}
+
+	deliveryStmt, err := database.GetExecutor(n.uow).Prepare(deliveryQuery)
+	if err != nil {
+		logrus.Error("error when trying to prepare insert notification deliveries statement", err)
+		return nil, apierrors.NewInternalServerApiError("error when trying to deliver notification", error_utils.GetDatabaseGenericError())
+	}
+	defer deliveryStmt.Close()
+
+	if _, deliveryErr := deliveryStmt.Exec(deliveryArgs...); deliveryErr != nil {
+		logrus.Error("error when trying to insert notification deliveries", deliveryErr)
+		return nil, apierrors.NewInternalServerApiError("error when trying to deliver notification", error_utils.GetDatabaseGenericError())
+	}
+
+	recipients, recipientsErr := n.getRecipients(id)
+	if recipientsErr != nil {
+		return nil, recipientsErr
+	}
+	n.uow.OnCommit(func() {
+		NotificationsHub.Publish(notification, recipients)
+	})
+
 	return &notification, nil
 }
 
 func (n *notificationsDao) GetListNotifications(listId int64) ([]Notification, apierrors.ApiError) {
-	stmt, err := database.DbClient.Prepare(getListNotifications)
+	return n.queryNotifications(getListNotifications, listId)
+}
+
+func (n *notificationsDao) DeleteListNotifications(listId int64) apierrors.ApiError {
+	for _, query := range []string{deleteDeliveriesByList, deleteNotifications} {
+		stmt, err := database.GetExecutor(n.uow).Prepare(query)
+		if err != nil {
+			logrus.Error("error when trying to prepare delete notifications statement", err)
+			return apierrors.NewInternalServerApiError("error when trying to delete notifications", error_utils.GetDatabaseGenericError())
+		}
+
+		_, deleteErr := stmt.Exec(listId)
+		stmt.Close()
+		if deleteErr != nil {
+			logrus.Error("error when trying to delete notifications", deleteErr)
+			return apierrors.NewInternalServerApiError("error when trying to delete notifications", error_utils.GetDatabaseGenericError())
+		}
+	}
+
+	return nil
+}
+
+// GetUserNotifications returns the inbox of the user, where Seen is the state of the user's own delivery.
+func (n *notificationsDao) GetUserNotifications(userId int64, onlyUnread bool) ([]Notification, apierrors.ApiError) {
+	if onlyUnread {
+		return n.queryNotifications(getUserUnreadNotifications, userId)
+	}
+	return n.queryNotifications(getUserNotifications, userId)
+}
+
+// GetUserNotificationsAfter returns the notifications delivered to the user with an id greater than afterId,
+// oldest first, so a stream can resume where it stopped.
+func (n *notificationsDao) GetUserNotificationsAfter(userId int64, afterId int64) ([]Notification, apierrors.ApiError) {
+	return n.queryNotifications(getUserNotificationsAfter, userId, afterId)
+}
+
+func (n *notificationsDao) CountUnreadByList(userId int64) (map[int64]int, apierrors.ApiError) {
+	stmt, err := database.GetExecutor(n.uow).Prepare(countUnreadByList)
+	if err != nil {
+		logrus.Error("error when trying to prepare count unread notifications statement", err)
+		return nil, apierrors.NewInternalServerApiError("error when trying to count unread notifications", error_utils.GetDatabaseGenericError())
+	}
+	defer stmt.Close()
+
+	rows, err := stmt.Query(userId)
+	if err != nil {
+		logrus.Error("error while counting unread notifications", err)
+		return nil, apierrors.NewInternalServerApiError("error counting unread notifications", error_utils.GetDatabaseGenericError())
+	}
+	defer rows.Close()
+
+	result := make(map[int64]int)
+	for rows.Next() {
+		var listId int64
+		var count int
+		if err := rows.Scan(&listId, &count); err != nil {
+			logrus.Error("error scaning unread notifications count", err)
+			return nil, apierrors.NewInternalServerApiError("error counting unread notifications", error_utils.GetDatabaseGenericError())
+		}
+		result[listId] = count
+	}
+
+	return result, nil
+}
+
+func (n *notificationsDao) MarkAsSeen(notificationId int64, userId int64, dateSeen string) apierrors.ApiError {
+	stmt, err := database.GetExecutor(n.uow).Prepare(getDelivery)
+	if err != nil {
+		logrus.Error("error when trying to prepare get notification delivery statement", err)
+		return apierrors.NewInternalServerApiError("error when trying to mark notification as seen", error_utils.GetDatabaseGenericError())
+	}
+	defer stmt.Close()
+
+	var seen bool
+	if queryErr := stmt.QueryRow(notificationId, userId).Scan(&seen); queryErr != nil {
+		if queryErr == sql.ErrNoRows {
+			return apierrors.NewNotFoundApiError(fmt.Sprintf("notification %d not found", notificationId))
+		}
+		logrus.Error("error when trying to get notification delivery", queryErr)
+		return apierrors.NewInternalServerApiError("error when trying to mark notification as seen", error_utils.GetDatabaseGenericError())
+	}
+
+	return n.exec(markDeliverySeen, "error when trying to mark notification as seen", dateSeen, notificationId, userId)
+}
+
+func (n *notificationsDao) MarkAllAsSeen(userId int64, dateSeen string) apierrors.ApiError {
+	return n.exec(markAllDeliveriesSeen, "error when trying to mark notifications as seen", dateSeen, userId)
+}
+
+// GetUsersWithPendingMails returns the users with unseen notifications that were not mailed nor skipped yet, leaving
+// out the ones that failed to be mailed config.MailMaxAttempts times.
+func (n *notificationsDao) GetUsersWithPendingMails() ([]int64, apierrors.ApiError) {
+	return n.queryUserIds(getUsersWithPendingMails, "error when trying to get pending mails", config.MailMaxAttempts)
+}
+
+// GetPendingMails returns the unseen notifications of the user that were not mailed nor skipped yet, oldest first,
+// leaving out the ones that failed to be mailed config.MailMaxAttempts times.
+func (n *notificationsDao) GetPendingMails(userId int64) ([]Notification, apierrors.ApiError) {
+	return n.queryNotifications(getPendingMails, userId, config.MailMaxAttempts)
+}
+
+func (n *notificationsDao) MarkAsMailed(notificationIds []int64, userId int64, dateMailed string) apierrors.ApiError {
+	for _, notificationId := range notificationIds {
+		if err := n.exec(markDeliveryMailed, "error when trying to mark notification as mailed", dateMailed, notificationId, userId); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RecordMailFailure counts a failed attempt to mail the notifications to the user.
+func (n *notificationsDao) RecordMailFailure(notificationIds []int64, userId int64) apierrors.ApiError {
+	for _, notificationId := range notificationIds {
+		if err := n.exec(incrementMailAttempts, "error when trying to record mail failure", notificationId, userId); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (n *notificationsDao) getRecipients(notificationId int64) ([]int64, apierrors.ApiError) {
+	return n.queryUserIds(getRecipients, "error when trying to deliver notification", notificationId)
+}
+
+func (n *notificationsDao) queryUserIds(query string, errorMessage string, args ...interface{}) ([]int64, apierrors.ApiError) {
+	stmt, err := database.GetExecutor(n.uow).Prepare(query)
+	if err != nil {
+		logrus.Error("error when trying to prepare notification users statement", err)
+		return nil, apierrors.NewInternalServerApiError(errorMessage, error_utils.GetDatabaseGenericError())
+	}
+	defer stmt.Close()
+
+	rows, err := stmt.Query(args...)
+	if err != nil {
+		logrus.Error(errorMessage, err)
+		return nil, apierrors.NewInternalServerApiError(errorMessage, error_utils.GetDatabaseGenericError())
+	}
+	defer rows.Close()
+
+	result := make([]int64, 0)
+	for rows.Next() {
+		var userId int64
+		if err := rows.Scan(&userId); err != nil {
+			logrus.Error("error scaning notification user id", err)
+			return nil, apierrors.NewInternalServerApiError(errorMessage, error_utils.GetDatabaseGenericError())
+		}
+		result = append(result, userId)
+	}
+
+	return result, nil
+}
+
+func (n *notificationsDao) exec(query string, errorMessage string, args ...interface{}) apierrors.ApiError {
+	stmt, err := database.GetExecutor(n.uow).Prepare(query)
+	if err != nil {
+		logrus.Error("error when trying to prepare notification statement", err)
+		return apierrors.NewInternalServerApiError(errorMessage, error_utils.GetDatabaseGenericError())
+	}
+	defer stmt.Close()
+
+	if _, execErr := stmt.Exec(args...); execErr != nil {
+		logrus.Error(errorMessage, execErr)
+		return apierrors.NewInternalServerApiError(errorMessage, error_utils.GetDatabaseGenericError())
+	}
+
+	return nil
+}
+
+func (n *notificationsDao) queryNotifications(query string, args ...interface{}) ([]Notification, apierrors.ApiError) {
+	stmt, err := database.GetExecutor(n.uow).Prepare(query)
 	if err != nil {
 		logrus.Error("error when trying to prepare get notification statement", err)
 		return nil, apierrors.NewInternalServerApiError("error when trying to get notifications", error_utils.GetDatabaseGenericError())
 	}
 	defer stmt.Close()
 
-	rows, err := stmt.Query(listId)
+	rows, err := stmt.Query(args...)
 	if err != nil {
 		logrus.Error("error while getting notifications", err)
 		return nil, apierrors.NewInternalServerApiError("error getting notifications", error_utils.GetDatabaseGenericError())
@@ -66,10 +313,16 @@ func (n *notificationsDao) GetListNotifications(listId int64) ([]Notification, a
 	result := make([]Notification, 0)
 	for rows.Next() {
 		var n Notification
-		if err := rows.Scan(&n.Id, &n.ListId, &n.Message, &n.Timestamp, &n.Seen, &n.Permalink); err != nil {
+		var params sql.NullString
+		if err := rows.Scan(&n.Id, &n.ListId, &n.Type, &params, &n.Message, &n.Timestamp, &n.Seen, &n.Permalink, &n.RecipientId); err != nil {
 			logrus.Error("error scaning notification into notification struct", err)
 			return nil, apierrors.NewInternalServerApiError("error getting notifications", error_utils.GetDatabaseGenericError())
 		}
+		if params.Valid {
+			if err := json.Unmarshal([]byte(params.String), &n.Params); err != nil {
+				logrus.Error(fmt.Sprintf("error unmarshaling params of notification %d", n.Id), err)
+			}
+		}
 		result = append(result, n)
 	}
 
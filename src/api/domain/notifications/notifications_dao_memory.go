@@ -0,0 +1,399 @@
+package notifications
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/lmurature/melist-api/src/api/clients/database"
+	"github.com/lmurature/melist-api/src/api/config"
+	"github.com/lmurature/melist-api/src/api/domain/apierrors"
+	"github.com/lmurature/melist-api/src/api/domain/lists"
+	"github.com/lmurature/melist-api/src/api/domain/share"
+)
+
+type delivery struct {
+	notificationId int64
+	userId         int64
+	seen           bool
+	dateSeen       string
+	dateMailed     string
+	mailAttempts   int
+}
+
+type notificationsMemoryStore struct {
+	mu            sync.RWMutex
+	lastId        int64
+	notifications []Notification
+	deliveries    []delivery
+}
+
+type notificationsMemoryDao struct {
+	*notificationsMemoryStore
+	uow *database.UnitOfWork
+}
+
+// UseMemoryStorage replaces NotificationsDao with an empty in-memory implementation.
+func UseMemoryStorage() {
+	NotificationsDao = &notificationsMemoryDao{notificationsMemoryStore: &notificationsMemoryStore{}}
+}
+
+func (n *notificationsMemoryDao) WithUnitOfWork(uow *database.UnitOfWork) notificationsDaoInterface {
+	return &notificationsMemoryDao{notificationsMemoryStore: n.notificationsMemoryStore, uow: uow}
+}
+
+func (n *notificationsMemoryDao) SaveNotification(notification Notification) (*Notification, apierrors.ApiError) {
+	recipients := []int64{notification.RecipientId}
+	if notification.RecipientId == 0 {
+		recipients = listRecipients(notification.ListId)
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	n.lastId++
+	notification.Id = n.lastId
+	n.notifications = append(n.notifications, notification)
+	for _, userId := range recipients {
+		n.deliveries = append(n.deliveries, delivery{notificationId: notification.Id, userId: userId})
+	}
+
+	n.uow.OnRollback(func() {
+		n.mu.Lock()
+		defer n.mu.Unlock()
+		n.removeLocked(func(notif Notification) bool {
+			return notif.Id == notification.Id
+		})
+	})
+	n.uow.OnCommit(func() {
+		NotificationsHub.Publish(notification, recipients)
+	})
+
+	return &notification, nil
+}
+
+func (n *notificationsMemoryDao) GetListNotifications(listId int64) ([]Notification, apierrors.ApiError) {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+
+	result := make([]Notification, 0)
+	for _, notification := range n.notifications {
+		if notification.ListId == listId {
+			result = append(result, notification)
+		}
+	}
+	sortNotifications(result)
+
+	return result, nil
+}
+
+func (n *notificationsMemoryDao) DeleteListNotifications(listId int64) apierrors.ApiError {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	removed, removedDeliveries := n.removeLocked(func(notification Notification) bool {
+		return notification.ListId == listId
+	})
+	n.uow.OnRollback(func() {
+		n.mu.Lock()
+		defer n.mu.Unlock()
+		n.notifications = append(n.notifications, removed...)
+		n.deliveries = append(n.deliveries, removedDeliveries...)
+	})
+
+	return nil
+}
+
+// GetUserNotifications skips notifications of trashed lists the same way the join of the mysql implementation does.
+func (n *notificationsMemoryDao) GetUserNotifications(userId int64, onlyUnread bool) ([]Notification, apierrors.ApiError) {
+	n.mu.RLock()
+	byId := make(map[int64]Notification, len(n.notifications))
+	for _, notification := range n.notifications {
+		byId[notification.Id] = notification
+	}
+
+	candidates := make([]Notification, 0)
+	for _, d := range n.deliveries {
+		if d.userId != userId || (onlyUnread && d.seen) {
+			continue
+		}
+		notification := byId[d.notificationId]
+		notification.Seen = d.seen
+		candidates = append(candidates, notification)
+	}
+	n.mu.RUnlock()
+
+	result := withoutTrashedLists(candidates)
+	sortNotifications(result)
+
+	return result, nil
+}
+
+func (n *notificationsMemoryDao) GetUserNotificationsAfter(userId int64, afterId int64) ([]Notification, apierrors.ApiError) {
+	n.mu.RLock()
+	byId := make(map[int64]Notification, len(n.notifications))
+	for _, notification := range n.notifications {
+		byId[notification.Id] = notification
+	}
+
+	candidates := make([]Notification, 0)
+	for _, d := range n.deliveries {
+		if d.userId == userId && d.notificationId > afterId {
+			notification := byId[d.notificationId]
+			notification.Seen = d.seen
+			candidates = append(candidates, notification)
+		}
+	}
+	n.mu.RUnlock()
+
+	result := withoutTrashedLists(candidates)
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Id < result[j].Id
+	})
+
+	return result, nil
+}
+
+func (n *notificationsMemoryDao) CountUnreadByList(userId int64) (map[int64]int, apierrors.ApiError) {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+
+	listIds := make(map[int64]int64, len(n.notifications))
+	for _, notification := range n.notifications {
+		listIds[notification.Id] = notification.ListId
+	}
+
+	result := make(map[int64]int)
+	for _, d := range n.deliveries {
+		if d.userId == userId && !d.seen {
+			result[listIds[d.notificationId]]++
+		}
+	}
+
+	return result, nil
+}
+
+func (n *notificationsMemoryDao) MarkAsSeen(notificationId int64, userId int64, dateSeen string) apierrors.ApiError {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	for i := range n.deliveries {
+		if n.deliveries[i].notificationId == notificationId && n.deliveries[i].userId == userId {
+			if !n.deliveries[i].seen {
+				n.markSeenLocked(i, dateSeen)
+			}
+			return nil
+		}
+	}
+
+	return apierrors.NewNotFoundApiError(fmt.Sprintf("notification %d not found", notificationId))
+}
+
+func (n *notificationsMemoryDao) MarkAllAsSeen(userId int64, dateSeen string) apierrors.ApiError {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	for i := range n.deliveries {
+		if n.deliveries[i].userId == userId && !n.deliveries[i].seen {
+			n.markSeenLocked(i, dateSeen)
+		}
+	}
+
+	return nil
+}
+
+func (n *notificationsMemoryDao) GetUsersWithPendingMails() ([]int64, apierrors.ApiError) {
+	n.mu.RLock()
+	userIds := make([]int64, 0)
+	seen := make(map[int64]bool)
+	for _, d := range n.deliveries {
+		if d.dateMailed == "" && !d.seen && d.mailAttempts < config.MailMaxAttempts && !seen[d.userId] {
+			seen[d.userId] = true
+			userIds = append(userIds, d.userId)
+		}
+	}
+	n.mu.RUnlock()
+
+	result := make([]int64, 0, len(userIds))
+	for _, userId := range userIds {
+		if pending, _ := n.GetPendingMails(userId); len(pending) > 0 {
+			result = append(result, userId)
+		}
+	}
+
+	return result, nil
+}
+
+func (n *notificationsMemoryDao) GetPendingMails(userId int64) ([]Notification, apierrors.ApiError) {
+	n.mu.RLock()
+	byId := make(map[int64]Notification, len(n.notifications))
+	for _, notification := range n.notifications {
+		byId[notification.Id] = notification
+	}
+
+	candidates := make([]Notification, 0)
+	for _, d := range n.deliveries {
+		if d.userId == userId && d.dateMailed == "" && !d.seen && d.mailAttempts < config.MailMaxAttempts {
+			candidates = append(candidates, byId[d.notificationId])
+		}
+	}
+	n.mu.RUnlock()
+
+	result := withoutTrashedLists(candidates)
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Id < result[j].Id
+	})
+
+	return result, nil
+}
+
+func (n *notificationsMemoryDao) MarkAsMailed(notificationIds []int64, userId int64, dateMailed string) apierrors.ApiError {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	mailed := make(map[int64]bool, len(notificationIds))
+	for _, id := range notificationIds {
+		mailed[id] = true
+	}
+
+	for i := range n.deliveries {
+		if n.deliveries[i].userId == userId && mailed[n.deliveries[i].notificationId] {
+			n.deliveries[i].dateMailed = dateMailed
+		}
+	}
+	n.uow.OnRollback(func() {
+		n.mu.Lock()
+		defer n.mu.Unlock()
+		for i := range n.deliveries {
+			if n.deliveries[i].userId == userId && mailed[n.deliveries[i].notificationId] {
+				n.deliveries[i].dateMailed = ""
+			}
+		}
+	})
+
+	return nil
+}
+
+func (n *notificationsMemoryDao) RecordMailFailure(notificationIds []int64, userId int64) apierrors.ApiError {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	failed := make(map[int64]bool, len(notificationIds))
+	for _, id := range notificationIds {
+		failed[id] = true
+	}
+
+	for i := range n.deliveries {
+		if n.deliveries[i].userId == userId && failed[n.deliveries[i].notificationId] {
+			n.deliveries[i].mailAttempts++
+		}
+	}
+	n.uow.OnRollback(func() {
+		n.mu.Lock()
+		defer n.mu.Unlock()
+		for i := range n.deliveries {
+			if n.deliveries[i].userId == userId && failed[n.deliveries[i].notificationId] {
+				n.deliveries[i].mailAttempts--
+			}
+		}
+	})
+
+	return nil
+}
+
+// markSeenLocked marks the delivery at index i as seen and registers its undo. The caller must hold mu.
+func (n *notificationsMemoryDao) markSeenLocked(i int, dateSeen string) {
+	notificationId, userId := n.deliveries[i].notificationId, n.deliveries[i].userId
+	n.deliveries[i].seen = true
+	n.deliveries[i].dateSeen = dateSeen
+	n.uow.OnRollback(func() {
+		n.mu.Lock()
+		defer n.mu.Unlock()
+		for j := range n.deliveries {
+			if n.deliveries[j].notificationId == notificationId && n.deliveries[j].userId == userId {
+				n.deliveries[j].seen = false
+				n.deliveries[j].dateSeen = ""
+			}
+		}
+	})
+}
+
+// removeLocked deletes the notifications matching remove along with their deliveries. The caller must hold mu.
+func (n *notificationsMemoryDao) removeLocked(remove func(notification Notification) bool) ([]Notification, []delivery) {
+	removedIds := make(map[int64]bool)
+	kept := make([]Notification, 0, len(n.notifications))
+	removed := make([]Notification, 0)
+	for _, notification := range n.notifications {
+		if remove(notification) {
+			removed = append(removed, notification)
+			removedIds[notification.Id] = true
+		} else {
+			kept = append(kept, notification)
+		}
+	}
+	n.notifications = kept
+
+	keptDeliveries := make([]delivery, 0, len(n.deliveries))
+	removedDeliveries := make([]delivery, 0)
+	for _, d := range n.deliveries {
+		if removedIds[d.notificationId] {
+			removedDeliveries = append(removedDeliveries, d)
+		} else {
+			keptDeliveries = append(keptDeliveries, d)
+		}
+	}
+	n.deliveries = keptDeliveries
+
+	return removed, removedDeliveries
+}
+
+func withoutTrashedLists(notifications []Notification) []Notification {
+	activeLists := make(map[int64]bool)
+	result := make([]Notification, 0, len(notifications))
+	for _, notification := range notifications {
+		active, ok := activeLists[notification.ListId]
+		if !ok {
+			_, err := lists.ListDao.GetList(notification.ListId)
+			active = err == nil
+			activeLists[notification.ListId] = active
+		}
+		if active {
+			result = append(result, notification)
+		}
+	}
+	return result
+}
+
+// listRecipients returns the owner of the list plus every user it is shared with.
+func listRecipients(listId int64) []int64 {
+	recipients := make([]int64, 0)
+	seen := make(map[int64]bool)
+	add := func(userId int64) {
+		if !seen[userId] {
+			seen[userId] = true
+			recipients = append(recipients, userId)
+		}
+	}
+
+	if list, err := lists.ListDao.GetList(listId); err == nil {
+		add(list.OwnerId)
+	} else if list, err := lists.ListDao.GetTrashedList(listId); err == nil {
+		add(list.OwnerId)
+	}
+
+	configs, _ := share.ShareConfigDao.GetAllShareConfigsByList(listId)
+	for _, c := range configs {
+		add(c.UserId)
+	}
+
+	return recipients
+}
+
+func sortNotifications(notifications []Notification) {
+	sort.Slice(notifications, func(i, j int) bool {
+		if notifications[i].Timestamp == notifications[j].Timestamp {
+			return notifications[i].Id > notifications[j].Id
+		}
+		return notifications[i].Timestamp > notifications[j].Timestamp
+	})
+}
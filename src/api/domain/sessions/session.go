@@ -0,0 +1,98 @@
+package sessions
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"time"
+
+	"github.com/lmurature/melist-api/src/api/config"
+	"github.com/lmurature/melist-api/src/api/domain/apierrors"
+)
+
+const (
+	TokenType = "Bearer"
+
+	tokenBytes = 32
+)
+
+// Session is a login of a user to Melist. Clients authenticate with opaque tokens that are only stored hashed, so
+// the Mercado Libre tokens of the user never leave the server.
+type Session struct {
+	Id               int64  `json:"id"`
+	UserId           int64  `json:"user_id"`
+	AccessTokenHash  string `json:"-"`
+	RefreshTokenHash string `json:"-"`
+	AccessExpiresAt  string `json:"access_expires_at"`
+	RefreshExpiresAt string `json:"refresh_expires_at"`
+	DateCreated      string `json:"date_created"`
+	Revoked          bool   `json:"revoked"`
+}
+
+// Tokens are the secrets of a Session, which are only known when the session is created.
+type Tokens struct {
+	AccessToken  string
+	RefreshToken string
+}
+
+// New returns a session of the user that starts at now, and its tokens.
+func New(userId int64, now time.Time) (*Session, *Tokens, apierrors.ApiError) {
+	accessToken, err := newToken()
+	if err != nil {
+		return nil, nil, apierrors.NewInternalServerApiError("error when trying to generate session token", err)
+	}
+	refreshToken, err := newToken()
+	if err != nil {
+		return nil, nil, apierrors.NewInternalServerApiError("error when trying to generate session token", err)
+	}
+
+	now = now.UTC()
+	session := &Session{
+		UserId:           userId,
+		AccessTokenHash:  HashToken(accessToken),
+		RefreshTokenHash: HashToken(refreshToken),
+		AccessExpiresAt:  now.Add(config.SessionAccessTokenTtl).Format(config.DbDateLayout),
+		RefreshExpiresAt: now.Add(config.SessionRefreshTokenTtl).Format(config.DbDateLayout),
+		DateCreated:      now.Format(config.DbDateLayout),
+	}
+	return session, &Tokens{AccessToken: accessToken, RefreshToken: refreshToken}, nil
+}
+
+// HashToken is what sessions store of a token: enough to find the session of a token but not to use it.
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// IsAccessValid is whether the access token of the session authenticates requests at now.
+func (s Session) IsAccessValid(now time.Time) bool {
+	return !s.Revoked && isBefore(now, s.AccessExpiresAt)
+}
+
+// IsRefreshValid is whether the refresh token of the session can be exchanged for a new session at now.
+func (s Session) IsRefreshValid(now time.Time) bool {
+	return !s.Revoked && isBefore(now, s.RefreshExpiresAt)
+}
+
+// ExpiresIn is the number of seconds the access token of the session is valid for from now.
+func (s Session) ExpiresIn(now time.Time) int64 {
+	expiresAt, err := time.Parse(config.DbDateLayout, s.AccessExpiresAt)
+	if err != nil || !now.Before(expiresAt) {
+		return 0
+	}
+	return int64(expiresAt.Sub(now) / time.Second)
+}
+
+func isBefore(now time.Time, date string) bool {
+	expiresAt, err := time.Parse(config.DbDateLayout, date)
+	return err == nil && now.UTC().Before(expiresAt)
+}
+
+func newToken() (string, error) {
+	b := make([]byte, tokenBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
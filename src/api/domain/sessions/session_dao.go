@@ -0,0 +1,129 @@
+package sessions
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/lmurature/melist-api/src/api/clients/database"
+	"github.com/lmurature/melist-api/src/api/domain/apierrors"
+	error_utils "github.com/lmurature/melist-api/src/api/utils/error"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	insertSession            = "INSERT INTO session(user_id, access_token_hash, refresh_token_hash, access_expires_at, refresh_expires_at, date_created, revoked) VALUES(?,?,?,?,?,?,?);"
+	getSessionByAccessToken  = "SELECT s.id, s.user_id, s.access_token_hash, s.refresh_token_hash, s.access_expires_at, s.refresh_expires_at, s.date_created, s.revoked FROM session s WHERE s.access_token_hash=?;"
+	getSessionByRefreshToken = "SELECT s.id, s.user_id, s.access_token_hash, s.refresh_token_hash, s.access_expires_at, s.refresh_expires_at, s.date_created, s.revoked FROM session s WHERE s.refresh_token_hash=?;"
+	revokeSession            = "UPDATE session SET revoked=1 WHERE id=? AND revoked=0;"
+	revokeUserSessions       = "UPDATE session SET revoked=1 WHERE user_id=?;"
+	deleteExpiredSessions    = "DELETE FROM session WHERE refresh_expires_at<?;"
+)
+
+var (
+	SessionDao sessionDaoInterface
+)
+
+type sessionDaoInterface interface {
+	WithUnitOfWork(uow *database.UnitOfWork) sessionDaoInterface
+	CreateSession(session Session) (*Session, apierrors.ApiError)
+	GetSessionByAccessToken(accessTokenHash string) (*Session, apierrors.ApiError)
+	GetSessionByRefreshToken(refreshTokenHash string) (*Session, apierrors.ApiError)
+	RevokeSession(sessionId int64) (bool, apierrors.ApiError)
+	RevokeUserSessions(userId int64) apierrors.ApiError
+	DeleteExpiredSessions(before string) apierrors.ApiError
+}
+
+type sessionDao struct {
+	uow *database.UnitOfWork
+}
+
+func init() {
+	SessionDao = &sessionDao{}
+}
+
+// WithUnitOfWork returns a copy of the dao whose statements run inside the transaction of uow.
+func (dao *sessionDao) WithUnitOfWork(uow *database.UnitOfWork) sessionDaoInterface {
+	return &sessionDao{uow: uow}
+}
+
+func (dao *sessionDao) CreateSession(session Session) (*Session, apierrors.ApiError) {
+	stmt, err := database.GetExecutor(dao.uow).Prepare(insertSession)
+	if err != nil {
+		logrus.Error("error when trying to prepare insert session statement", err)
+		return nil, apierrors.NewInternalServerApiError("error when trying to create session", error_utils.GetDatabaseGenericError())
+	}
+	defer stmt.Close()
+
+	result, saveErr := stmt.Exec(session.UserId, session.AccessTokenHash, session.RefreshTokenHash, session.AccessExpiresAt, session.RefreshExpiresAt, session.DateCreated, session.Revoked)
+	if saveErr != nil {
+		logrus.Error("error when trying to insert session", saveErr)
+		return nil, apierrors.NewInternalServerApiError("error when trying to create session", error_utils.GetDatabaseGenericError())
+	}
+
+	session.Id, _ = result.LastInsertId()
+	logrus.Info(fmt.Sprintf("successfully created session %d of user %d", session.Id, session.UserId))
+	return &session, nil
+}
+
+func (dao *sessionDao) GetSessionByAccessToken(accessTokenHash string) (*Session, apierrors.ApiError) {
+	return dao.getSession(getSessionByAccessToken, accessTokenHash)
+}
+
+func (dao *sessionDao) GetSessionByRefreshToken(refreshTokenHash string) (*Session, apierrors.ApiError) {
+	return dao.getSession(getSessionByRefreshToken, refreshTokenHash)
+}
+
+// RevokeSession revokes the session and returns whether this call did it. Of two concurrent calls for the same
+// session only one gets true, the other waits for its transaction and finds the session revoked already.
+func (dao *sessionDao) RevokeSession(sessionId int64) (bool, apierrors.ApiError) {
+	revoked, err := dao.exec(revokeSession, sessionId)
+	return revoked > 0, err
+}
+
+func (dao *sessionDao) RevokeUserSessions(userId int64) apierrors.ApiError {
+	_, err := dao.exec(revokeUserSessions, userId)
+	return err
+}
+
+func (dao *sessionDao) DeleteExpiredSessions(before string) apierrors.ApiError {
+	_, err := dao.exec(deleteExpiredSessions, before)
+	return err
+}
+
+func (dao *sessionDao) getSession(query string, tokenHash string) (*Session, apierrors.ApiError) {
+	stmt, err := database.GetExecutor(dao.uow).Prepare(query)
+	if err != nil {
+		logrus.Error("error when trying to prepare get session statement", err)
+		return nil, apierrors.NewInternalServerApiError("error when trying to get session", error_utils.GetDatabaseGenericError())
+	}
+	defer stmt.Close()
+
+	var s Session
+	if queryErr := stmt.QueryRow(tokenHash).Scan(&s.Id, &s.UserId, &s.AccessTokenHash, &s.RefreshTokenHash, &s.AccessExpiresAt, &s.RefreshExpiresAt, &s.DateCreated, &s.Revoked); queryErr != nil {
+		if queryErr == sql.ErrNoRows {
+			return nil, apierrors.NewNotFoundApiError("session not found")
+		}
+		logrus.Error("error when trying to get session", queryErr)
+		return nil, apierrors.NewInternalServerApiError("error when trying to get session", error_utils.GetDatabaseGenericError())
+	}
+
+	return &s, nil
+}
+
+// exec runs the statement and returns the number of sessions it changed.
+func (dao *sessionDao) exec(query string, args ...interface{}) (int64, apierrors.ApiError) {
+	stmt, err := database.GetExecutor(dao.uow).Prepare(query)
+	if err != nil {
+		logrus.Error("error when trying to prepare update sessions statement", err)
+		return 0, apierrors.NewInternalServerApiError("error when trying to update sessions", error_utils.GetDatabaseGenericError())
+	}
+	defer stmt.Close()
+
+	result, updateErr := stmt.Exec(args...)
+	if updateErr != nil {
+		logrus.Error("error when trying to update sessions", updateErr)
+		return 0, apierrors.NewInternalServerApiError("error when trying to update sessions", error_utils.GetDatabaseGenericError())
+	}
+	changed, _ := result.RowsAffected()
+	return changed, nil
+}
@@ -0,0 +1,124 @@
+package sessions
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/lmurature/melist-api/src/api/clients/database"
+	"github.com/lmurature/melist-api/src/api/domain/apierrors"
+	"github.com/sirupsen/logrus"
+)
+
+type sessionMemoryStore struct {
+	mu       sync.RWMutex
+	lastId   int64
+	sessions map[int64]Session
+}
+
+type sessionMemoryDao struct {
+	*sessionMemoryStore
+	uow *database.UnitOfWork
+}
+
+// UseMemoryStorage replaces SessionDao with an empty in-memory implementation.
+func UseMemoryStorage() {
+	SessionDao = &sessionMemoryDao{sessionMemoryStore: &sessionMemoryStore{sessions: make(map[int64]Session)}}
+}
+
+func (dao *sessionMemoryDao) WithUnitOfWork(uow *database.UnitOfWork) sessionDaoInterface {
+	return &sessionMemoryDao{sessionMemoryStore: dao.sessionMemoryStore, uow: uow}
+}
+
+func (dao *sessionMemoryDao) CreateSession(session Session) (*Session, apierrors.ApiError) {
+	dao.mu.Lock()
+	defer dao.mu.Unlock()
+
+	dao.lastId++
+	session.Id = dao.lastId
+	dao.sessions[session.Id] = session
+	dao.uow.OnRollback(func() {
+		dao.mu.Lock()
+		defer dao.mu.Unlock()
+		delete(dao.sessions, session.Id)
+	})
+
+	logrus.Info(fmt.Sprintf("successfully created session %d of user %d", session.Id, session.UserId))
+	return &session, nil
+}
+
+func (dao *sessionMemoryDao) GetSessionByAccessToken(accessTokenHash string) (*Session, apierrors.ApiError) {
+	return dao.find(func(s Session) bool { return s.AccessTokenHash == accessTokenHash })
+}
+
+func (dao *sessionMemoryDao) GetSessionByRefreshToken(refreshTokenHash string) (*Session, apierrors.ApiError) {
+	return dao.find(func(s Session) bool { return s.RefreshTokenHash == refreshTokenHash })
+}
+
+func (dao *sessionMemoryDao) RevokeSession(sessionId int64) (bool, apierrors.ApiError) {
+	return dao.revoke(func(s Session) bool { return s.Id == sessionId }) > 0, nil
+}
+
+func (dao *sessionMemoryDao) RevokeUserSessions(userId int64) apierrors.ApiError {
+	dao.revoke(func(s Session) bool { return s.UserId == userId })
+	return nil
+}
+
+func (dao *sessionMemoryDao) DeleteExpiredSessions(before string) apierrors.ApiError {
+	dao.mu.Lock()
+	defer dao.mu.Unlock()
+
+	deleted := make([]Session, 0)
+	for id, s := range dao.sessions {
+		// dates share config.DbDateLayout, so they sort as strings
+		if s.RefreshExpiresAt < before {
+			deleted = append(deleted, s)
+			delete(dao.sessions, id)
+		}
+	}
+	dao.uow.OnRollback(func() {
+		dao.mu.Lock()
+		defer dao.mu.Unlock()
+		for _, s := range deleted {
+			dao.sessions[s.Id] = s
+		}
+	})
+	return nil
+}
+
+func (dao *sessionMemoryDao) find(f func(s Session) bool) (*Session, apierrors.ApiError) {
+	dao.mu.RLock()
+	defer dao.mu.RUnlock()
+
+	for _, s := range dao.sessions {
+		if f(s) {
+			return &s, nil
+		}
+	}
+	return nil, apierrors.NewNotFoundApiError("session not found")
+}
+
+// revoke revokes the sessions that match f and are not revoked yet, returning how many it revoked.
+func (dao *sessionMemoryDao) revoke(f func(s Session) bool) int {
+	dao.mu.Lock()
+	defer dao.mu.Unlock()
+
+	revoked := make([]int64, 0)
+	for id, s := range dao.sessions {
+		if f(s) && !s.Revoked {
+			s.Revoked = true
+			dao.sessions[id] = s
+			revoked = append(revoked, id)
+		}
+	}
+	dao.uow.OnRollback(func() {
+		dao.mu.Lock()
+		defer dao.mu.Unlock()
+		for _, id := range revoked {
+			if s, ok := dao.sessions[id]; ok {
+				s.Revoked = false
+				dao.sessions[id] = s
+			}
+		}
+	})
+	return len(revoked)
+}
@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"github.com/lmurature/melist-api/src/api/domain/apierrors"
 	"github.com/lmurature/melist-api/src/api/domain/users"
+	i18n_utils "github.com/lmurature/melist-api/src/api/utils/i18n"
+	"net/http"
 )
 
 const (
@@ -28,22 +30,32 @@ func (s ShareConfig) Validate() apierrors.ApiError {
 		return apierrors.NewBadRequestApiError(fmt.Sprintf("share type cant be empty for user id %d", s.UserId))
 	}
 
-	if s.ShareType != ShareTypeWrite && s.ShareType != ShareTypeRead && s.ShareType != ShareTypeCheck {
-		return apierrors.NewBadRequestApiError(fmt.Sprintf("share type must be 'read' 'write' or 'check' for user id %d", s.UserId))
+	if s.ShareType != ShareTypeAdmin && s.ShareType != ShareTypeWrite && s.ShareType != ShareTypeRead && s.ShareType != ShareTypeCheck {
+		return apierrors.NewBadRequestApiError(fmt.Sprintf("share type must be 'admin' 'read' 'write' or 'check' for user id %d", s.UserId))
 	}
 
 	return nil
 }
 
-func GetFormattedShareType(sType string) string {
-	switch sType {
-	case ShareTypeWrite:
-		return "Modificador"
-	case ShareTypeCheck:
-		return "Comprador"
-	case ShareTypeRead:
-		return "Lector"
-	default:
-		return ""
+// GetListShareConfigs returns the share configs of the list, which has none when it was not shared. Lists authorize
+// their users against these configs.
+func GetListShareConfigs(listId int64) (ShareConfigs, apierrors.ApiError) {
+	configs, err := ShareConfigDao.GetAllShareConfigsByList(listId)
+	if err != nil && err.Status() != http.StatusNotFound {
+		return nil, err
 	}
+	return configs, nil
+}
+
+var (
+	formattedShareTypes = map[string]map[string]string{
+		i18n_utils.LocaleEsAR: {ShareTypeAdmin: "Administrador", ShareTypeWrite: "Modificador", ShareTypeCheck: "Comprador", ShareTypeRead: "Lector"},
+		i18n_utils.LocalePtBR: {ShareTypeAdmin: "Administrador", ShareTypeWrite: "Editor", ShareTypeCheck: "Comprador", ShareTypeRead: "Leitor"},
+		i18n_utils.LocaleEn:   {ShareTypeAdmin: "Admin", ShareTypeWrite: "Editor", ShareTypeCheck: "Buyer", ShareTypeRead: "Reader"},
+	}
+)
+
+// GetFormattedShareType returns the name of the role in locale, or an empty string for unknown share types.
+func GetFormattedShareType(sType string, locale string) string {
+	return formattedShareTypes[i18n_utils.NormalizeLocale(locale)][sType]
 }
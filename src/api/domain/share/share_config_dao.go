@@ -18,6 +18,8 @@ const (
 	updateShareConfigType         = "UPDATE share_config SET `type`=? WHERE (user_id=? AND list_id=?);"
 	deleteShareConfig             = "DELETE FROM share_config s WHERE (s.user_id=? AND s.list_id=?);"
 	deleteFutureCollaboration     = "DELETE FROM future_colaborator fc WHERE (fc.user_email=? AND fc.list_id=?)"
+	deleteShareConfigsByList      = "DELETE FROM share_config WHERE list_id=?;"
+	deleteFutureCollabsByList     = "DELETE FROM future_colaborator WHERE list_id=?;"
 )
 
 var (
@@ -25,6 +27,7 @@ var (
 )
 
 type shareConfigDaoInterface interface {
+	WithUnitOfWork(uow *database.UnitOfWork) shareConfigDaoInterface
 	CreateShareConfig(conf ShareConfig) (*ShareConfig, apierrors.ApiError)
 	GetAllShareConfigsByUser(userId int64) (ShareConfigs, apierrors.ApiError)
 	GetAllShareConfigsByList(listId int64) (ShareConfigs, apierrors.ApiError)
@@ -34,16 +37,24 @@ type shareConfigDaoInterface interface {
 	GetAllFutureListCollaborationByEmail(email string) (ShareConfigs, apierrors.ApiError)
 	GetAllFutureListCollaborationByList(listId int64) (ShareConfigs, apierrors.ApiError)
 	DeleteFutureCollaborationConfig(email string, listId int64) apierrors.ApiError
+	DeleteListShareConfigs(listId int64) apierrors.ApiError
 }
 
-type shareConfigDao struct{}
+type shareConfigDao struct {
+	uow *database.UnitOfWork
+}
 
 func init() {
 	ShareConfigDao = &shareConfigDao{}
 }
 
+// WithUnitOfWork returns a copy of the dao whose statements run inside the transaction of uow.
+func (dao *shareConfigDao) WithUnitOfWork(uow *database.UnitOfWork) shareConfigDaoInterface {
+	return &shareConfigDao{uow: uow}
+}
+
 func (dao *shareConfigDao) CreateShareConfig(conf ShareConfig) (*ShareConfig, apierrors.ApiError) {
-	stmt, err := database.DbClient.Prepare(insertShareConfig)
+	stmt, err := database.GetExecutor(dao.uow).Prepare(insertShareConfig)
 	if err != nil {
 		logrus.Error("error when trying to prepare insert share config statement", err)
 		return nil, apierrors.NewInternalServerApiError("error when trying to insert share config", errors.New("database error"))
@@ -60,7 +71,7 @@ func (dao *shareConfigDao) CreateShareConfig(conf ShareConfig) (*ShareConfig, ap
 }
 
 func (dao *shareConfigDao) CreateEmailShareConfig(conf ShareConfig) (*ShareConfig, apierrors.ApiError) {
-	stmt, err := database.DbClient.Prepare(insertEmailShareConfig)
+	stmt, err := database.GetExecutor(dao.uow).Prepare(insertEmailShareConfig)
 	if err != nil {
 		logrus.Error("error when trying to prepare insert share config statement", err)
 		return nil, apierrors.NewInternalServerApiError("error when trying to insert share config", errors.New("database error"))
@@ -77,7 +88,7 @@ func (dao *shareConfigDao) CreateEmailShareConfig(conf ShareConfig) (*ShareConfi
 }
 
 func (dao *shareConfigDao) GetAllShareConfigsByUser(userId int64) (ShareConfigs, apierrors.ApiError) {
-	stmt, err := database.DbClient.Prepare(getShareConfigsByUser)
+	stmt, err := database.GetExecutor(dao.uow).Prepare(getShareConfigsByUser)
 	if err != nil {
 		logrus.Error("error when trying to prepare get share config by user statement", err)
 		return nil, apierrors.NewInternalServerApiError("error when trying to get share configs", errors.New("database error"))
@@ -106,7 +117,7 @@ func (dao *shareConfigDao) GetAllShareConfigsByUser(userId int64) (ShareConfigs,
 }
 
 func (dao *shareConfigDao) GetAllFutureListCollaborationByEmail(email string) (ShareConfigs, apierrors.ApiError) {
-	stmt, err := database.DbClient.Prepare(getFutureCollaborationsByUser)
+	stmt, err := database.GetExecutor(dao.uow).Prepare(getFutureCollaborationsByUser)
 	if err != nil {
 		logrus.Error("error when trying to prepare get email share config by email statement", err)
 		return nil, apierrors.NewInternalServerApiError("error when trying to get email configs", errors.New("database error"))
@@ -135,7 +146,7 @@ func (dao *shareConfigDao) GetAllFutureListCollaborationByEmail(email string) (S
 }
 
 func (dao *shareConfigDao) GetAllFutureListCollaborationByList(listId int64) (ShareConfigs, apierrors.ApiError) {
-	stmt, err := database.DbClient.Prepare(getFutureCollaborationsByList)
+	stmt, err := database.GetExecutor(dao.uow).Prepare(getFutureCollaborationsByList)
 	if err != nil {
 		logrus.Error("error when trying to prepare get future share config by list statement", err)
 		return nil, apierrors.NewInternalServerApiError("error when trying to get future list configs", errors.New("database error"))
@@ -164,7 +175,7 @@ func (dao *shareConfigDao) GetAllFutureListCollaborationByList(listId int64) (Sh
 }
 
 func (dao *shareConfigDao) GetAllShareConfigsByList(listId int64) (ShareConfigs, apierrors.ApiError) {
-	stmt, err := database.DbClient.Prepare(getShareConfigsByList)
+	stmt, err := database.GetExecutor(dao.uow).Prepare(getShareConfigsByList)
 	if err != nil {
 		logrus.Error("error when trying to prepare get share config by list statement", err)
 		return nil, apierrors.NewInternalServerApiError("error when trying to get share configs", errors.New("database error"))
@@ -199,7 +210,7 @@ func (dao *shareConfigDao) GetAllShareConfigsByList(listId int64) (ShareConfigs,
 }
 
 func (dao *shareConfigDao) UpdateShareConfig(conf ShareConfig) (*ShareConfig, apierrors.ApiError) {
-	stmt, err := database.DbClient.Prepare(updateShareConfigType)
+	stmt, err := database.GetExecutor(dao.uow).Prepare(updateShareConfigType)
 	if err != nil {
 		logrus.Error("error when trying to prepare update share config statement", err)
 		return nil, apierrors.NewInternalServerApiError("error when trying to update share configs", errors.New("database error"))
@@ -215,7 +226,7 @@ func (dao *shareConfigDao) UpdateShareConfig(conf ShareConfig) (*ShareConfig, ap
 }
 
 func (dao *shareConfigDao) DeleteShareConfig(userId int64, listId int64) apierrors.ApiError {
-	stmt, err := database.DbClient.Prepare(deleteShareConfig)
+	stmt, err := database.GetExecutor(dao.uow).Prepare(deleteShareConfig)
 	if err != nil {
 		logrus.Error("error when trying to prepare delete share config statement", err)
 		return apierrors.NewInternalServerApiError("error when trying to delete share config", errors.New("database error"))
@@ -231,7 +242,7 @@ func (dao *shareConfigDao) DeleteShareConfig(userId int64, listId int64) apierro
 }
 
 func (dao *shareConfigDao) DeleteFutureCollaborationConfig(email string, listId int64) apierrors.ApiError {
-	stmt, err := database.DbClient.Prepare(deleteFutureCollaboration)
+	stmt, err := database.GetExecutor(dao.uow).Prepare(deleteFutureCollaboration)
 	if err != nil {
 		logrus.Error("error when trying to prepare delete future collaboration config statement", err)
 		return apierrors.NewInternalServerApiError("error when trying to delete future collaboration config", errors.New("database error"))
@@ -245,3 +256,23 @@ func (dao *shareConfigDao) DeleteFutureCollaborationConfig(email string, listId
 
 	return nil
 }
+
+// DeleteListShareConfigs deletes every share config and future collaboration of the list.
+func (dao *shareConfigDao) DeleteListShareConfigs(listId int64) apierrors.ApiError {
+	for _, query := range []string{deleteShareConfigsByList, deleteFutureCollabsByList} {
+		stmt, err := database.GetExecutor(dao.uow).Prepare(query)
+		if err != nil {
+			logrus.Error("error when trying to prepare delete list share configs statement", err)
+			return apierrors.NewInternalServerApiError("error when trying to delete list share configs", errors.New("database error"))
+		}
+
+		_, deleteErr := stmt.Exec(listId)
+		stmt.Close()
+		if deleteErr != nil {
+			logrus.Error("error when trying to delete list share configs", deleteErr)
+			return apierrors.NewInternalServerApiError("error when trying to delete list share configs", errors.New("database error"))
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,229 @@
+package share
+
+import (
+	"sync"
+
+	"github.com/lmurature/melist-api/src/api/clients/database"
+	"github.com/lmurature/melist-api/src/api/domain/apierrors"
+	"github.com/lmurature/melist-api/src/api/domain/users"
+)
+
+type shareConfigMemoryStore struct {
+	mu                  sync.RWMutex
+	configs             ShareConfigs
+	futureCollaborators ShareConfigs
+}
+
+type shareConfigMemoryDao struct {
+	*shareConfigMemoryStore
+	uow *database.UnitOfWork
+}
+
+// UseMemoryStorage replaces ShareConfigDao with an empty in-memory implementation.
+func UseMemoryStorage() {
+	ShareConfigDao = &shareConfigMemoryDao{shareConfigMemoryStore: &shareConfigMemoryStore{}}
+}
+
+func (dao *shareConfigMemoryDao) WithUnitOfWork(uow *database.UnitOfWork) shareConfigDaoInterface {
+	return &shareConfigMemoryDao{shareConfigMemoryStore: dao.shareConfigMemoryStore, uow: uow}
+}
+
+func (dao *shareConfigMemoryDao) CreateShareConfig(conf ShareConfig) (*ShareConfig, apierrors.ApiError) {
+	dao.mu.Lock()
+	defer dao.mu.Unlock()
+
+	dao.configs = append(dao.configs, ShareConfig{UserId: conf.UserId, ListId: conf.ListId, ShareType: conf.ShareType})
+	dao.uow.OnRollback(func() {
+		dao.mu.Lock()
+		defer dao.mu.Unlock()
+		dao.configs, _ = removeConfigs(dao.configs, func(c ShareConfig) bool {
+			return c.UserId == conf.UserId && c.ListId == conf.ListId
+		})
+	})
+
+	return &conf, nil
+}
+
+func (dao *shareConfigMemoryDao) CreateEmailShareConfig(conf ShareConfig) (*ShareConfig, apierrors.ApiError) {
+	dao.mu.Lock()
+	defer dao.mu.Unlock()
+
+	dao.futureCollaborators = append(dao.futureCollaborators, ShareConfig{Email: conf.Email, ListId: conf.ListId, ShareType: conf.ShareType})
+	dao.uow.OnRollback(func() {
+		dao.mu.Lock()
+		defer dao.mu.Unlock()
+		dao.futureCollaborators, _ = removeConfigs(dao.futureCollaborators, func(c ShareConfig) bool {
+			return c.Email == conf.Email && c.ListId == conf.ListId
+		})
+	})
+
+	return &conf, nil
+}
+
+func (dao *shareConfigMemoryDao) GetAllShareConfigsByUser(userId int64) (ShareConfigs, apierrors.ApiError) {
+	dao.mu.RLock()
+	defer dao.mu.RUnlock()
+
+	result := make(ShareConfigs, 0)
+	for _, c := range dao.configs {
+		if c.UserId == userId {
+			result = append(result, c)
+		}
+	}
+
+	return result, nil
+}
+
+func (dao *shareConfigMemoryDao) GetAllFutureListCollaborationByEmail(email string) (ShareConfigs, apierrors.ApiError) {
+	dao.mu.RLock()
+	defer dao.mu.RUnlock()
+
+	result := make(ShareConfigs, 0)
+	for _, c := range dao.futureCollaborators {
+		if c.Email == email {
+			result = append(result, c)
+		}
+	}
+
+	return result, nil
+}
+
+func (dao *shareConfigMemoryDao) GetAllFutureListCollaborationByList(listId int64) (ShareConfigs, apierrors.ApiError) {
+	dao.mu.RLock()
+	defer dao.mu.RUnlock()
+
+	result := make(ShareConfigs, 0)
+	for _, c := range dao.futureCollaborators {
+		if c.ListId == listId {
+			result = append(result, c)
+		}
+	}
+
+	return result, nil
+}
+
+// GetAllShareConfigsByList joins every share config of the list with its user, dropping configs of unknown users
+// the same way the inner join of the mysql implementation does.
+func (dao *shareConfigMemoryDao) GetAllShareConfigsByList(listId int64) (ShareConfigs, apierrors.ApiError) {
+	dao.mu.RLock()
+	configs := make(ShareConfigs, 0)
+	for _, c := range dao.configs {
+		if c.ListId == listId {
+			configs = append(configs, c)
+		}
+	}
+	dao.mu.RUnlock()
+
+	result := make(ShareConfigs, 0)
+	for _, c := range configs {
+		user, err := users.UserDao.GetUser(c.UserId)
+		if err != nil {
+			continue
+		}
+		c.UserData = &users.MelistUser{
+			FirstName: user.FirstName,
+			LastName:  user.LastName,
+			Email:     user.Email,
+			Nickname:  user.Nickname,
+		}
+		result = append(result, c)
+	}
+
+	if len(result) == 0 {
+		return nil, apierrors.NewNotFoundApiError("no share configs found for list")
+	}
+
+	return result, nil
+}
+
+func (dao *shareConfigMemoryDao) UpdateShareConfig(conf ShareConfig) (*ShareConfig, apierrors.ApiError) {
+	dao.mu.Lock()
+	defer dao.mu.Unlock()
+
+	for i := range dao.configs {
+		if dao.configs[i].UserId == conf.UserId && dao.configs[i].ListId == conf.ListId {
+			previous := dao.configs[i].ShareType
+			dao.configs[i].ShareType = conf.ShareType
+			dao.uow.OnRollback(func() {
+				dao.mu.Lock()
+				defer dao.mu.Unlock()
+				for j := range dao.configs {
+					if dao.configs[j].UserId == conf.UserId && dao.configs[j].ListId == conf.ListId {
+						dao.configs[j].ShareType = previous
+					}
+				}
+			})
+		}
+	}
+
+	return &conf, nil
+}
+
+func (dao *shareConfigMemoryDao) DeleteShareConfig(userId int64, listId int64) apierrors.ApiError {
+	dao.mu.Lock()
+	defer dao.mu.Unlock()
+
+	var removed ShareConfigs
+	dao.configs, removed = removeConfigs(dao.configs, func(c ShareConfig) bool {
+		return c.UserId == userId && c.ListId == listId
+	})
+	dao.uow.OnRollback(func() {
+		dao.mu.Lock()
+		defer dao.mu.Unlock()
+		dao.configs = append(dao.configs, removed...)
+	})
+
+	return nil
+}
+
+func (dao *shareConfigMemoryDao) DeleteFutureCollaborationConfig(email string, listId int64) apierrors.ApiError {
+	dao.mu.Lock()
+	defer dao.mu.Unlock()
+
+	var removed ShareConfigs
+	dao.futureCollaborators, removed = removeConfigs(dao.futureCollaborators, func(c ShareConfig) bool {
+		return c.Email == email && c.ListId == listId
+	})
+	dao.uow.OnRollback(func() {
+		dao.mu.Lock()
+		defer dao.mu.Unlock()
+		dao.futureCollaborators = append(dao.futureCollaborators, removed...)
+	})
+
+	return nil
+}
+
+func (dao *shareConfigMemoryDao) DeleteListShareConfigs(listId int64) apierrors.ApiError {
+	dao.mu.Lock()
+	defer dao.mu.Unlock()
+
+	byList := func(c ShareConfig) bool {
+		return c.ListId == listId
+	}
+
+	var removedConfigs, removedFuture ShareConfigs
+	dao.configs, removedConfigs = removeConfigs(dao.configs, byList)
+	dao.futureCollaborators, removedFuture = removeConfigs(dao.futureCollaborators, byList)
+	dao.uow.OnRollback(func() {
+		dao.mu.Lock()
+		defer dao.mu.Unlock()
+		dao.configs = append(dao.configs, removedConfigs...)
+		dao.futureCollaborators = append(dao.futureCollaborators, removedFuture...)
+	})
+
+	return nil
+}
+
+// removeConfigs splits configs into the ones to keep and the ones matching remove.
+func removeConfigs(configs ShareConfigs, remove func(c ShareConfig) bool) (ShareConfigs, ShareConfigs) {
+	kept := make(ShareConfigs, 0, len(configs))
+	removed := make(ShareConfigs, 0)
+	for _, c := range configs {
+		if remove(c) {
+			removed = append(removed, c)
+		} else {
+			kept = append(kept, c)
+		}
+	}
+	return kept, removed
+}
@@ -0,0 +1,49 @@
+package share
+
+import (
+	"testing"
+
+	"github.com/lmurature/melist-api/src/api/clients/database"
+	"github.com/lmurature/melist-api/src/api/domain/apierrors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryDaoUndoesWritesOnRollback(t *testing.T) {
+	database.DbClient = nil
+	UseMemoryStorage()
+	ShareConfigDao.CreateShareConfig(ShareConfig{UserId: 1, ListId: 10, ShareType: ShareTypeRead})
+	ShareConfigDao.CreateEmailShareConfig(ShareConfig{Email: "user@melist.com", ListId: 10, ShareType: ShareTypeWrite})
+
+	err := database.RunInTransaction(func(uow *database.UnitOfWork) apierrors.ApiError {
+		dao := ShareConfigDao.WithUnitOfWork(uow)
+		dao.UpdateShareConfig(ShareConfig{UserId: 1, ListId: 10, ShareType: ShareTypeWrite})
+		dao.CreateShareConfig(ShareConfig{UserId: 2, ListId: 10, ShareType: ShareTypeWrite})
+		dao.DeleteFutureCollaborationConfig("user@melist.com", 10)
+		return apierrors.NewBadRequestApiError("invalid share config")
+	})
+	assert.NotNil(t, err)
+
+	configs, _ := ShareConfigDao.GetAllShareConfigsByUser(1)
+	assert.EqualValues(t, 1, len(configs))
+	assert.EqualValues(t, ShareTypeRead, configs[0].ShareType)
+
+	configs, _ = ShareConfigDao.GetAllShareConfigsByUser(2)
+	assert.EqualValues(t, 0, len(configs))
+
+	future, _ := ShareConfigDao.GetAllFutureListCollaborationByEmail("user@melist.com")
+	assert.EqualValues(t, 1, len(future))
+}
+
+func TestMemoryDaoKeepsWritesOnCommit(t *testing.T) {
+	database.DbClient = nil
+	UseMemoryStorage()
+
+	err := database.RunInTransaction(func(uow *database.UnitOfWork) apierrors.ApiError {
+		_, err := ShareConfigDao.WithUnitOfWork(uow).CreateShareConfig(ShareConfig{UserId: 2, ListId: 10, ShareType: ShareTypeWrite})
+		return err
+	})
+	assert.Nil(t, err)
+
+	configs, _ := ShareConfigDao.GetAllShareConfigsByUser(2)
+	assert.EqualValues(t, 1, len(configs))
+}
@@ -0,0 +1,83 @@
+package sites
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/lmurature/melist-api/src/api/domain/apierrors"
+	i18n_utils "github.com/lmurature/melist-api/src/api/utils/i18n"
+)
+
+const (
+	SiteArgentina = "MLA"
+	SiteBrazil    = "MLB"
+	SiteMexico    = "MLM"
+	SiteChile     = "MLC"
+	SiteColombia  = "MCO"
+	SiteUruguay   = "MLU"
+	SitePeru      = "MPE"
+
+	// DefaultSiteId is used for users and lists created before sites existed.
+	DefaultSiteId = SiteArgentina
+)
+
+// Site is a Mercado Libre marketplace. Users authorize Melist at AuthUrl, the domain of the site.
+type Site struct {
+	Id         string `json:"id"`
+	Name       string `json:"name"`
+	CurrencyId string `json:"currency_id"`
+	Locale     string `json:"locale"`
+	AuthUrl    string `json:"-"`
+}
+
+var (
+	Sites = []Site{
+		{Id: SiteArgentina, Name: "Argentina", CurrencyId: "ARS", Locale: i18n_utils.LocaleEsAR, AuthUrl: "https://auth.mercadolibre.com.ar"},
+		{Id: SiteBrazil, Name: "Brasil", CurrencyId: "BRL", Locale: i18n_utils.LocalePtBR, AuthUrl: "https://auth.mercadolivre.com.br"},
+		{Id: SiteMexico, Name: "México", CurrencyId: "MXN", Locale: i18n_utils.LocaleEsAR, AuthUrl: "https://auth.mercadolibre.com.mx"},
+		{Id: SiteChile, Name: "Chile", CurrencyId: "CLP", Locale: i18n_utils.LocaleEsAR, AuthUrl: "https://auth.mercadolibre.cl"},
+		{Id: SiteColombia, Name: "Colombia", CurrencyId: "COP", Locale: i18n_utils.LocaleEsAR, AuthUrl: "https://auth.mercadolibre.com.co"},
+		{Id: SiteUruguay, Name: "Uruguay", CurrencyId: "UYU", Locale: i18n_utils.LocaleEsAR, AuthUrl: "https://auth.mercadolibre.com.uy"},
+		{Id: SitePeru, Name: "Perú", CurrencyId: "PEN", Locale: i18n_utils.LocaleEsAR, AuthUrl: "https://auth.mercadolibre.com.pe"},
+	}
+)
+
+func GetSite(siteId string) (*Site, bool) {
+	for _, s := range Sites {
+		if s.Id == siteId {
+			return &s, true
+		}
+	}
+	return nil, false
+}
+
+func ValidateSiteId(siteId string) apierrors.ApiError {
+	if _, ok := GetSite(siteId); !ok {
+		ids := make([]string, 0, len(Sites))
+		for _, s := range Sites {
+			ids = append(ids, s.Id)
+		}
+		return apierrors.NewBadRequestApiError(fmt.Sprintf("invalid site id '%s', expected one of %s", siteId, strings.Join(ids, ", ")))
+	}
+	return nil
+}
+
+// SiteIdFromId returns the site of a Mercado Libre item or category id such as MLB1055, which always starts with
+// the site id, or an empty string when the prefix is not a known site.
+func SiteIdFromId(id string) string {
+	if len(id) < 3 {
+		return ""
+	}
+	if _, ok := GetSite(strings.ToUpper(id[:3])); ok {
+		return strings.ToUpper(id[:3])
+	}
+	return ""
+}
+
+// GetSiteIdOrDefault returns siteId, or DefaultSiteId when it is empty.
+func GetSiteIdOrDefault(siteId string) string {
+	if siteId == "" {
+		return DefaultSiteId
+	}
+	return siteId
+}
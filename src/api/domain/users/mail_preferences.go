@@ -0,0 +1,77 @@
+package users
+
+import (
+	"time"
+
+	"github.com/lmurature/melist-api/src/api/config"
+	i18n_utils "github.com/lmurature/melist-api/src/api/utils/i18n"
+)
+
+const (
+	MailFrequencyInstant = "instant"
+	MailFrequencyDaily   = "daily"
+	MailFrequencyWeekly  = "weekly"
+	MailFrequencyOff     = "off"
+)
+
+// MailPreferences decides which notifications of a user are also delivered by email and how often.
+type MailPreferences struct {
+	UserId         int64    `json:"user_id"`
+	Frequency      string   `json:"frequency"`
+	Locale         string   `json:"locale"`
+	DisabledTypes  []string `json:"disabled_types"`
+	DateLastDigest string   `json:"date_last_digest,omitempty"`
+}
+
+// DefaultMailPreferences are used for users that never changed their preferences: email delivery is opt-in.
+func DefaultMailPreferences(userId int64) *MailPreferences {
+	return &MailPreferences{
+		UserId:        userId,
+		Frequency:     MailFrequencyOff,
+		Locale:        i18n_utils.DefaultLocale,
+		DisabledTypes: make([]string, 0),
+	}
+}
+
+func IsValidMailFrequency(frequency string) bool {
+	switch frequency {
+	case MailFrequencyInstant, MailFrequencyDaily, MailFrequencyWeekly, MailFrequencyOff:
+		return true
+	}
+	return false
+}
+
+func (p MailPreferences) IsDigest() bool {
+	return p.Frequency == MailFrequencyDaily || p.Frequency == MailFrequencyWeekly
+}
+
+func (p MailPreferences) IsTypeEnabled(notificationType string) bool {
+	for _, t := range p.DisabledTypes {
+		if t == notificationType {
+			return false
+		}
+	}
+	return true
+}
+
+// IsDigestDue tells if a full digest period went by since the last digest was sent.
+func (p MailPreferences) IsDigestDue(now time.Time) bool {
+	if !p.IsDigest() {
+		return false
+	}
+	if p.DateLastDigest == "" {
+		return true
+	}
+
+	last, err := time.Parse(config.DbDateLayout, p.DateLastDigest)
+	if err != nil {
+		return true
+	}
+
+	period := 24 * time.Hour
+	if p.Frequency == MailFrequencyWeekly {
+		period = 7 * period
+	}
+
+	return !now.Before(last.Add(period))
+}
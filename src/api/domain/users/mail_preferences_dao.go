@@ -0,0 +1,90 @@
+package users
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/lmurature/melist-api/src/api/clients/database"
+	"github.com/lmurature/melist-api/src/api/domain/apierrors"
+	error_utils "github.com/lmurature/melist-api/src/api/utils/error"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	getMailPreferences  = "SELECT p.user_id, p.frequency, p.locale, p.disabled_types, p.date_last_digest FROM mail_preferences p WHERE p.user_id=?;"
+	saveMailPreferences = "INSERT INTO mail_preferences(user_id, frequency, locale, disabled_types, date_last_digest) VALUES(?,?,?,?,?) ON DUPLICATE KEY UPDATE frequency=VALUES(frequency), locale=VALUES(locale), disabled_types=VALUES(disabled_types), date_last_digest=VALUES(date_last_digest);"
+)
+
+var (
+	MailPreferencesDao mailPreferencesDaoInterface
+)
+
+type mailPreferencesDaoInterface interface {
+	GetMailPreferences(userId int64) (*MailPreferences, apierrors.ApiError)
+	SaveMailPreferences(preferences MailPreferences) (*MailPreferences, apierrors.ApiError)
+}
+
+type mailPreferencesDao struct{}
+
+func init() {
+	MailPreferencesDao = &mailPreferencesDao{}
+}
+
+func (dao *mailPreferencesDao) GetMailPreferences(userId int64) (*MailPreferences, apierrors.ApiError) {
+	stmt, err := database.DbClient.Prepare(getMailPreferences)
+	if err != nil {
+		logrus.Error("error when trying to prepare get mail preferences statement", err)
+		return nil, apierrors.NewInternalServerApiError("error when trying to get mail preferences", error_utils.GetDatabaseGenericError())
+	}
+	defer stmt.Close()
+
+	var p MailPreferences
+	var disabledTypes string
+	var dateLastDigest sql.NullString
+	if queryErr := stmt.QueryRow(userId).Scan(&p.UserId, &p.Frequency, &p.Locale, &disabledTypes, &dateLastDigest); queryErr != nil {
+		if queryErr == sql.ErrNoRows {
+			return nil, apierrors.NewNotFoundApiError(fmt.Sprintf("mail preferences of user %d not found", userId))
+		}
+		logrus.Error("error when trying to get mail preferences", queryErr)
+		return nil, apierrors.NewInternalServerApiError("error when trying to get mail preferences", error_utils.GetDatabaseGenericError())
+	}
+
+	p.DisabledTypes = splitTypes(disabledTypes)
+	p.DateLastDigest = dateLastDigest.String
+
+	return &p, nil
+}
+
+func (dao *mailPreferencesDao) SaveMailPreferences(preferences MailPreferences) (*MailPreferences, apierrors.ApiError) {
+	stmt, err := database.DbClient.Prepare(saveMailPreferences)
+	if err != nil {
+		logrus.Error("error when trying to prepare save mail preferences statement", err)
+		return nil, apierrors.NewInternalServerApiError("error when trying to save mail preferences", error_utils.GetDatabaseGenericError())
+	}
+	defer stmt.Close()
+
+	var dateLastDigest interface{}
+	if preferences.DateLastDigest != "" {
+		dateLastDigest = preferences.DateLastDigest
+	}
+
+	_, saveErr := stmt.Exec(preferences.UserId, preferences.Frequency, preferences.Locale, strings.Join(preferences.DisabledTypes, ","), dateLastDigest)
+	if saveErr != nil {
+		logrus.Error("error when trying to save mail preferences", saveErr)
+		return nil, apierrors.NewInternalServerApiError("error when trying to save mail preferences", error_utils.GetDatabaseGenericError())
+	}
+
+	logrus.Info(fmt.Sprintf("successfully saved mail preferences of user %d", preferences.UserId))
+	return &preferences, nil
+}
+
+func splitTypes(types string) []string {
+	result := make([]string, 0)
+	for _, t := range strings.Split(types, ",") {
+		if t != "" {
+			result = append(result, t)
+		}
+	}
+	return result
+}
@@ -0,0 +1,39 @@
+package users
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/lmurature/melist-api/src/api/domain/apierrors"
+	"github.com/sirupsen/logrus"
+)
+
+type mailPreferencesMemoryDao struct {
+	mu          sync.RWMutex
+	preferences map[int64]MailPreferences
+}
+
+func (dao *mailPreferencesMemoryDao) GetMailPreferences(userId int64) (*MailPreferences, apierrors.ApiError) {
+	dao.mu.RLock()
+	defer dao.mu.RUnlock()
+
+	p, ok := dao.preferences[userId]
+	if !ok {
+		return nil, apierrors.NewNotFoundApiError(fmt.Sprintf("mail preferences of user %d not found", userId))
+	}
+	p.DisabledTypes = append(make([]string, 0, len(p.DisabledTypes)), p.DisabledTypes...)
+
+	return &p, nil
+}
+
+func (dao *mailPreferencesMemoryDao) SaveMailPreferences(preferences MailPreferences) (*MailPreferences, apierrors.ApiError) {
+	dao.mu.Lock()
+	defer dao.mu.Unlock()
+
+	stored := preferences
+	stored.DisabledTypes = append(make([]string, 0, len(preferences.DisabledTypes)), preferences.DisabledTypes...)
+	dao.preferences[preferences.UserId] = stored
+
+	logrus.Info(fmt.Sprintf("successfully saved mail preferences of user %d", preferences.UserId))
+	return &preferences, nil
+}
@@ -6,6 +6,7 @@ type User struct {
 	LastName    string `json:"last_name"`
 	Nickname    string `json:"nickname"`
 	Email       string `json:"email"`
+	SiteId      string `json:"site_id"`
 }
 
 type MelistUser struct {
@@ -14,7 +15,11 @@ type MelistUser struct {
 	LastName     string `json:"last_name"`
 	Nickname     string `json:"nickname"`
 	Email        string `json:"email"`
+	SiteId       string `json:"site_id,omitempty"`
 	DateCreated  string `json:"date_created,omitempty"`
-	AccessToken  string `json:"access_token,omitempty"`
-	RefreshToken string `json:"refresh_token,omitempty"`
+	AccessToken  string `json:"-"`
+	RefreshToken string `json:"-"`
+
+	// TokenExpiresAt is when AccessToken expires. The tokens are encrypted, see tokens_service.
+	TokenExpiresAt string `json:"-"`
 }
@@ -10,10 +10,13 @@ import (
 )
 
 const (
-	getUser     = "SELECT u.id, u.first_name, u.last_name, u.nickname, u.email, u.date_created, u.access_token, u.refresh_token FROM user u WHERE u.id=?;"
-	insertUser  = "INSERT INTO user(id, first_name, last_name, email, nickname, date_created, access_token, refresh_token) VALUES(?,?,?,?,?,?,?,?);"
+	getUser     = "SELECT u.id, u.first_name, u.last_name, u.nickname, u.email, u.site_id, u.date_created, u.access_token, u.refresh_token, IFNULL(u.token_expires_at, '') FROM user u WHERE u.id=?;"
+	insertUser  = "INSERT INTO user(id, first_name, last_name, email, nickname, site_id, date_created, access_token, refresh_token) VALUES(?,?,?,?,?,?,?,?,?);"
 	findByEmail = "SELECT u.id, u.first_name, u.last_name, u.nickname, u.email, u.date_created FROM user u WHERE u.email=?;"
-	updateUser  = "UPDATE user SET first_name=?, last_name=?, email=?, nickname=?, access_token=?, refresh_token=? WHERE id=?"
+	updateUser  = "UPDATE user SET first_name=?, last_name=?, email=?, nickname=?, site_id=? WHERE id=?"
+	updateUserTokens = "UPDATE user SET access_token=?, refresh_token=?, token_expires_at=NULLIF(?, '') WHERE id=?;"
+	getUsersWithTokens = "SELECT u.id, u.access_token, u.refresh_token, IFNULL(u.token_expires_at, '') FROM user u WHERE u.refresh_token IS NOT NULL AND u.refresh_token<>'';"
+	getUsersWithExpiringTokens = "SELECT u.id, u.access_token, u.refresh_token, IFNULL(u.token_expires_at, '') FROM user u WHERE u.refresh_token IS NOT NULL AND u.refresh_token<>'' AND (u.token_expires_at IS NULL OR u.token_expires_at<?);"
 	searchUser = "SELECT u.id, u.first_name, u.last_name, u.nickname, u.email FROM user u WHERE u.email LIKE CONCAT('%', ?, '%') OR u.first_name LIKE CONCAT('%', ?, '%') OR u.last_name LIKE CONCAT('%', ?, '%') OR u.nickname LIKE CONCAT('%', ?, '%');"
 )
 
@@ -26,6 +29,9 @@ type userDaoInterface interface {
 	CreateUser(user MelistUser) (*MelistUser, apierrors.ApiError)
 	GetByEmail(email string) (*MelistUser, apierrors.ApiError)
 	UpdateUser(user MelistUser) (*MelistUser, apierrors.ApiError)
+	UpdateUserTokens(userId int64, accessToken string, refreshToken string, expiresAt string) apierrors.ApiError
+	GetUsersWithTokens() ([]MelistUser, apierrors.ApiError)
+	GetUsersWithExpiringTokens(expiresBefore string) ([]MelistUser, apierrors.ApiError)
 	SearchUsers(query string) ([]MelistUser, apierrors.ApiError)
 }
 
@@ -46,7 +52,7 @@ func (dao *userDao) GetUser(userId int64) (*MelistUser, apierrors.ApiError) {
 	result := stmt.QueryRow(userId)
 
 	var u MelistUser
-	if queryErr := result.Scan(&u.Id, &u.FirstName, &u.LastName, &u.Nickname, &u.Email, &u.DateCreated, &u.AccessToken, &u.RefreshToken); queryErr != nil {
+	if queryErr := result.Scan(&u.Id, &u.FirstName, &u.LastName, &u.Nickname, &u.Email, &u.SiteId, &u.DateCreated, &u.AccessToken, &u.RefreshToken, &u.TokenExpiresAt); queryErr != nil {
 		logrus.Error("user not found", queryErr)
 		return nil, apierrors.NewNotFoundApiError("user not found")
 	}
@@ -62,7 +68,7 @@ func (dao *userDao) CreateUser(user MelistUser) (*MelistUser, apierrors.ApiError
 	}
 	defer stmt.Close()
 
-	_, saveErr := stmt.Exec(user.Id, user.FirstName, user.LastName, user.Email, user.Nickname, user.DateCreated, user.AccessToken, user.RefreshToken)
+	_, saveErr := stmt.Exec(user.Id, user.FirstName, user.LastName, user.Email, user.Nickname, user.SiteId, user.DateCreated, user.AccessToken, user.RefreshToken)
 	if saveErr != nil {
 		if !strings.Contains(saveErr.Error(), "Duplicate entry") {
 			logrus.Error("error when trying to save user", saveErr)
@@ -101,7 +107,7 @@ func (dao *userDao) UpdateUser(user MelistUser) (*MelistUser, apierrors.ApiError
 	}
 	defer stmt.Close()
 
-	_, updateErr := stmt.Exec(user.FirstName, user.LastName, user.Email, user.Nickname, user.AccessToken, user.RefreshToken, user.Id)
+	_, updateErr := stmt.Exec(user.FirstName, user.LastName, user.Email, user.Nickname, user.SiteId, user.Id)
 	if updateErr != nil {
 		logrus.Error("error when trying to update user", updateErr)
 		return nil, apierrors.NewInternalServerApiError("error when trying to update user", error_utils.GetDatabaseGenericError())
@@ -111,6 +117,62 @@ func (dao *userDao) UpdateUser(user MelistUser) (*MelistUser, apierrors.ApiError
 	return &user, nil
 }
 
+// UpdateUserTokens replaces the Mercado Libre tokens of the user, which the caller already encrypted.
+func (dao *userDao) UpdateUserTokens(userId int64, accessToken string, refreshToken string, expiresAt string) apierrors.ApiError {
+	stmt, err := database.DbClient.Prepare(updateUserTokens)
+	if err != nil {
+		logrus.Error("error when trying to prepare update user tokens statement", err)
+		return apierrors.NewInternalServerApiError("error when trying to update user tokens", error_utils.GetDatabaseGenericError())
+	}
+	defer stmt.Close()
+
+	if _, updateErr := stmt.Exec(accessToken, refreshToken, expiresAt, userId); updateErr != nil {
+		logrus.Error("error when trying to update user tokens", updateErr)
+		return apierrors.NewInternalServerApiError("error when trying to update user tokens", error_utils.GetDatabaseGenericError())
+	}
+
+	return nil
+}
+
+// GetUsersWithTokens returns the id and Mercado Libre tokens of every user that authorized melist.
+func (dao *userDao) GetUsersWithTokens() ([]MelistUser, apierrors.ApiError) {
+	return dao.queryUserTokens(getUsersWithTokens)
+}
+
+// GetUsersWithExpiringTokens returns the id and Mercado Libre tokens of the users whose access token expires before
+// expiresBefore or whose expiry is unknown.
+func (dao *userDao) GetUsersWithExpiringTokens(expiresBefore string) ([]MelistUser, apierrors.ApiError) {
+	return dao.queryUserTokens(getUsersWithExpiringTokens, expiresBefore)
+}
+
+func (dao *userDao) queryUserTokens(query string, args ...interface{}) ([]MelistUser, apierrors.ApiError) {
+	stmt, err := database.DbClient.Prepare(query)
+	if err != nil {
+		logrus.Error("error when trying to prepare get user tokens statement", err)
+		return nil, apierrors.NewInternalServerApiError("error when trying to get user tokens", error_utils.GetDatabaseGenericError())
+	}
+	defer stmt.Close()
+
+	rows, err := stmt.Query(args...)
+	if err != nil {
+		logrus.Error("error while getting user tokens", err)
+		return nil, apierrors.NewInternalServerApiError("error when trying to get user tokens", error_utils.GetDatabaseGenericError())
+	}
+	defer rows.Close()
+
+	result := make([]MelistUser, 0)
+	for rows.Next() {
+		var u MelistUser
+		if err := rows.Scan(&u.Id, &u.AccessToken, &u.RefreshToken, &u.TokenExpiresAt); err != nil {
+			logrus.Error("error scanning user tokens into melist user structure", err)
+			return nil, apierrors.NewInternalServerApiError("error when trying to get user tokens", error_utils.GetDatabaseGenericError())
+		}
+		result = append(result, u)
+	}
+
+	return result, nil
+}
+
 func (dao *userDao) SearchUsers(query string) ([]MelistUser, apierrors.ApiError) {
 	stmt, err := database.DbClient.Prepare(searchUser)
 	if err != nil {
@@ -0,0 +1,151 @@
+package users
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/lmurature/melist-api/src/api/domain/apierrors"
+	error_utils "github.com/lmurature/melist-api/src/api/utils/error"
+	"github.com/sirupsen/logrus"
+)
+
+type userMemoryDao struct {
+	mu    sync.RWMutex
+	users map[int64]MelistUser
+}
+
+// UseMemoryStorage replaces UserDao and MailPreferencesDao with empty in-memory implementations.
+func UseMemoryStorage() {
+	UserDao = &userMemoryDao{users: make(map[int64]MelistUser)}
+	MailPreferencesDao = &mailPreferencesMemoryDao{preferences: make(map[int64]MailPreferences)}
+}
+
+func (dao *userMemoryDao) GetUser(userId int64) (*MelistUser, apierrors.ApiError) {
+	dao.mu.RLock()
+	defer dao.mu.RUnlock()
+
+	u, ok := dao.users[userId]
+	if !ok {
+		return nil, apierrors.NewNotFoundApiError("user not found")
+	}
+
+	return &u, nil
+}
+
+func (dao *userMemoryDao) CreateUser(user MelistUser) (*MelistUser, apierrors.ApiError) {
+	dao.mu.Lock()
+	defer dao.mu.Unlock()
+
+	if _, ok := dao.users[user.Id]; ok {
+		return nil, apierrors.NewInternalServerApiError("error when trying to save user", error_utils.GetDatabaseGenericError())
+	}
+	dao.users[user.Id] = user
+
+	logrus.Info(fmt.Sprintf("successfully registered user %d", user.Id))
+	return &user, nil
+}
+
+func (dao *userMemoryDao) GetByEmail(email string) (*MelistUser, apierrors.ApiError) {
+	dao.mu.RLock()
+	defer dao.mu.RUnlock()
+
+	for _, u := range dao.sortedUsers() {
+		if u.Email == email {
+			return &MelistUser{Id: u.Id, FirstName: u.FirstName, LastName: u.LastName,
+				Nickname: u.Nickname, Email: u.Email, DateCreated: u.DateCreated}, nil
+		}
+	}
+
+	return nil, apierrors.NewNotFoundApiError("email not found in user table")
+}
+
+func (dao *userMemoryDao) UpdateUser(user MelistUser) (*MelistUser, apierrors.ApiError) {
+	dao.mu.Lock()
+	defer dao.mu.Unlock()
+
+	if actual, ok := dao.users[user.Id]; ok {
+		actual.FirstName = user.FirstName
+		actual.LastName = user.LastName
+		actual.Email = user.Email
+		actual.Nickname = user.Nickname
+		actual.SiteId = user.SiteId
+		dao.users[user.Id] = actual
+	}
+
+	logrus.Info(fmt.Sprintf("successfully updated user %d", user.Id))
+	return &user, nil
+}
+
+func (dao *userMemoryDao) UpdateUserTokens(userId int64, accessToken string, refreshToken string, expiresAt string) apierrors.ApiError {
+	dao.mu.Lock()
+	defer dao.mu.Unlock()
+
+	if actual, ok := dao.users[userId]; ok {
+		actual.AccessToken = accessToken
+		actual.RefreshToken = refreshToken
+		actual.TokenExpiresAt = expiresAt
+		dao.users[userId] = actual
+	}
+	return nil
+}
+
+func (dao *userMemoryDao) GetUsersWithTokens() ([]MelistUser, apierrors.ApiError) {
+	dao.mu.RLock()
+	defer dao.mu.RUnlock()
+
+	result := make([]MelistUser, 0)
+	for _, u := range dao.sortedUsers() {
+		if u.RefreshToken != "" {
+			result = append(result, MelistUser{Id: u.Id, AccessToken: u.AccessToken, RefreshToken: u.RefreshToken,
+				TokenExpiresAt: u.TokenExpiresAt})
+		}
+	}
+	return result, nil
+}
+
+func (dao *userMemoryDao) GetUsersWithExpiringTokens(expiresBefore string) ([]MelistUser, apierrors.ApiError) {
+	all, _ := dao.GetUsersWithTokens()
+
+	result := make([]MelistUser, 0)
+	for _, u := range all {
+		// the date layout sorts like the dates it holds
+		if u.TokenExpiresAt == "" || u.TokenExpiresAt < expiresBefore {
+			result = append(result, u)
+		}
+	}
+	return result, nil
+}
+
+func (dao *userMemoryDao) SearchUsers(query string) ([]MelistUser, apierrors.ApiError) {
+	dao.mu.RLock()
+	defer dao.mu.RUnlock()
+
+	q := strings.ToLower(query)
+	result := make([]MelistUser, 0)
+	for _, u := range dao.sortedUsers() {
+		if strings.Contains(strings.ToLower(u.Email), q) || strings.Contains(strings.ToLower(u.FirstName), q) ||
+			strings.Contains(strings.ToLower(u.LastName), q) || strings.Contains(strings.ToLower(u.Nickname), q) {
+			result = append(result, MelistUser{Id: u.Id, FirstName: u.FirstName, LastName: u.LastName,
+				Nickname: u.Nickname, Email: u.Email})
+		}
+	}
+
+	if len(result) == 0 {
+		return nil, apierrors.NewNotFoundApiError("Users not found")
+	}
+
+	return result, nil
+}
+
+func (dao *userMemoryDao) sortedUsers() []MelistUser {
+	result := make([]MelistUser, 0, len(dao.users))
+	for _, u := range dao.users {
+		result = append(result, u)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Id < result[j].Id
+	})
+	return result
+}
@@ -25,12 +25,14 @@ func Authenticate(c *gin.Context) {
 	if len(splitToken) != 2 {
 		err := apierrors.NewBadRequestApiError("authorization token (Bearer) is needed to access this endpoint")
 		c.JSON(err.Status(), err)
+		c.Abort()
 		return
 	}
 
 	token := splitToken[1]
 
-	user, err := auth_service.AuthService.ValidateAccessToken(token)
+	// the token is a Melist session token, so requests are authenticated without asking Mercado Libre
+	session, err := auth_service.AuthService.ValidateAccessToken(token)
 
 	if err != nil {
 		apierror := apierrors.NewForbiddenApiError("access token not found")
@@ -40,7 +42,18 @@ func Authenticate(c *gin.Context) {
 		return
 	}
 
-	c.Set("token", token)
-	c.Set("user_id", user.Id)
+	c.Set("session", *session)
+	c.Set("user_id", session.UserId)
 	c.Next()
 }
+
+// AuthenticateStream is Authenticate for EventSource and WebSocket clients, which cannot set headers: the token
+// may also be sent in the access_token query parameter.
+func AuthenticateStream(c *gin.Context) {
+	if c.Request.Header.Get("Authorization") == "" {
+		if token := c.Query("access_token"); token != "" {
+			c.Request.Header.Set("Authorization", "Bearer "+token)
+		}
+	}
+	Authenticate(c)
+}
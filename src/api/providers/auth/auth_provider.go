@@ -3,7 +3,7 @@ package auth_provider
 import (
 	"encoding/json"
 	"errors"
-	"github.com/lmurature/golang-restclient/rest"
+	"github.com/lmurature/melist-api/src/api/clients/restclient"
 	"github.com/lmurature/melist-api/src/api/config"
 	"github.com/lmurature/melist-api/src/api/domain/apierrors"
 	"github.com/lmurature/melist-api/src/api/domain/auth"
@@ -16,24 +16,29 @@ const (
 )
 
 var (
-	authenticationRestClient = rest.RequestBuilder{
-		BaseURL:        http_utils.BaseUrlMeli,
-		Timeout:        5 * time.Second,
-		DisableCache:   true,
-		DisableTimeout: false,
-	}
+	authenticationRestClient = restclient.New(restclient.Options{
+		BaseURL:      http_utils.BaseUrlMeli,
+		Timeout:      5 * time.Second,
+		DisableCache: true,
+	})
 )
 
-func CreateUserAccessToken(code string) (*auth.MeliAuthResponse, apierrors.ApiError) {
+// CreateUserAccessToken exchanges the authorization code of a login for the tokens of the user. redirectUri and
+// codeVerifier must be the ones the login was started with.
+func CreateUserAccessToken(code string, redirectUri string, codeVerifier string) (*auth.MeliAuthResponse, apierrors.ApiError) {
 	requestBody := auth.MeliAuthRequest{
 		GrantType:    auth.GrantTypeAuthorizationCode,
 		ClientId:     config.AppId,
 		ClientSecret: config.SecretKey,
 		Code:         code,
-		RedirectUri:  config.RedirectUri,
+		RedirectUri:  redirectUri,
+		CodeVerifier: codeVerifier,
 	}
 
-	response := authenticationRestClient.Post(uriAuthenticateUser, requestBody)
+	response, restErr := authenticationRestClient.Post(uriAuthenticateUser, requestBody)
+	if restErr != nil {
+		return nil, restErr
+	}
 
 	if response == nil || response.Response == nil {
 		err := errors.New("invalid restclient response")
@@ -66,7 +71,10 @@ func RefreshAccessToken(refreshToken string) (*auth.MeliAuthResponse, apierrors.
 		RefreshToken: refreshToken,
 	}
 
-	response := authenticationRestClient.Post(uriAuthenticateUser, requestBody)
+	response, restErr := authenticationRestClient.Post(uriAuthenticateUser, requestBody)
+	if restErr != nil {
+		return nil, restErr
+	}
 
 	if response == nil || response.Response == nil {
 		err := errors.New("invalid restclient response")
@@ -1,58 +1,61 @@
 package items_provider
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
-	"github.com/lmurature/golang-restclient/rest"
+	"github.com/lmurature/melist-api/src/api/clients/restclient"
 	"github.com/lmurature/melist-api/src/api/domain/apierrors"
 	"github.com/lmurature/melist-api/src/api/domain/items"
+	fanout_utils "github.com/lmurature/melist-api/src/api/utils/fanout"
 	http_utils "github.com/lmurature/melist-api/src/api/utils/http"
-	"strconv"
 	"strings"
 	"time"
 )
 
 const (
-	uriSearchItems        = "/sites/MLA/search?q=%s&offset=%d"
+	uriSearchItems        = "/sites/%s/search?q=%s&offset=%d"
 	uriGetItem            = "/items/%s"
+	uriGetItems           = "/items?ids=%s"
+	uriItemsAttributes    = "&attributes=%s"
 	uriGetItemDescription = "/items/%s/description"
 	uriGetItemReviews     = "/reviews/item/%s?catalog_product_id=%s&limit=200&order=desc&order_criteria=dateCreated"
-	uriGetCategoryTrends  = "/trends/MLA/%s"
+	uriGetCategoryTrends  = "/trends/%s/%s"
 	uriGetCategory        = "/categories/%s"
 )
 
 var (
-	itemsRestClient = rest.RequestBuilder{
-		BaseURL:        http_utils.BaseUrlMeli,
-		Timeout:        15 * time.Second,
-		DisableTimeout: false,
-	}
-
-	reviewsRestClient = rest.RequestBuilder{
-		BaseURL:        http_utils.BaseUrlMeli,
-		Timeout:        5 * time.Second,
-		DisableCache:   true,
-		DisableTimeout: false,
-	}
-
-	vipRestClient = rest.RequestBuilder{
-		Timeout:        5 * time.Second,
-		DisableCache:   true,
-		DisableTimeout: false,
-	}
-
-	categoryRestClient = rest.RequestBuilder{
-		BaseURL:        http_utils.BaseUrlMeli,
-		Timeout:        15 * time.Second,
-		DisableTimeout: false,
-		DisableCache:   false,
-	}
+	itemsRestClient = restclient.New(restclient.Options{
+		BaseURL: http_utils.BaseUrlMeli,
+		Timeout: 15 * time.Second,
+	})
+
+	reviewsRestClient = restclient.New(restclient.Options{
+		BaseURL:      http_utils.BaseUrlMeli,
+		Timeout:      5 * time.Second,
+		DisableCache: true,
+	})
+
+	vipRestClient = restclient.New(restclient.Options{
+		Timeout:      5 * time.Second,
+		DisableCache: true,
+	})
+
+	multigetFanOut = fanout_utils.Options{Concurrency: 4}
+
+	categoryRestClient = restclient.New(restclient.Options{
+		BaseURL: http_utils.BaseUrlMeli,
+		Timeout: 15 * time.Second,
+	})
 )
 
-func SearchItemsByQuery(query string, offset int) (*items.ItemSearchResponse, apierrors.ApiError) {
-	uri := fmt.Sprintf(uriSearchItems, query, offset)
-	response := itemsRestClient.Get(uri)
+func searchItemsByQuery(siteId string, search items.ItemSearch) (*items.ItemSearchResponse, apierrors.ApiError) {
+	uri := fmt.Sprintf(uriSearchItems, siteId, search.Query, search.Offset) + search.QueryParams()
+	response, restErr := itemsRestClient.Get(uri)
+	if restErr != nil {
+		return nil, restErr
+	}
 
 	if response == nil || response.Response == nil {
 		err := errors.New("invalid restclient response")
@@ -77,9 +80,12 @@ func SearchItemsByQuery(query string, offset int) (*items.ItemSearchResponse, ap
 	return &itemsResult, nil
 }
 
-func GetItemById(itemId string) (*items.Item, apierrors.ApiError) {
+func getItemById(itemId string) (*items.Item, apierrors.ApiError) {
 	uri := fmt.Sprintf(uriGetItem, itemId)
-	response := itemsRestClient.Get(uri)
+	response, restErr := itemsRestClient.Get(uri)
+	if restErr != nil {
+		return nil, restErr
+	}
 
 	if response == nil || response.Response == nil {
 		err := errors.New("invalid restclient response")
@@ -104,9 +110,103 @@ func GetItemById(itemId string) (*items.Item, apierrors.ApiError) {
 	return &item, nil
 }
 
-func GetItemDescription(itemId string) (*items.ItemDescription, apierrors.ApiError) {
+// GetItemsByIds fetches the items with the multiget endpoint in chunks of items.MultigetMaxIds ids, returning one
+// result per id in the same order. When attributes are given, only those fields of the items are returned.
+func GetItemsByIds(ids []string, attributes []string) []items.ItemResult {
+	chunks := make([][]string, 0, len(ids)/items.MultigetMaxIds+1)
+	for start := 0; start < len(ids); start += items.MultigetMaxIds {
+		end := start + items.MultigetMaxIds
+		if end > len(ids) {
+			end = len(ids)
+		}
+		chunks = append(chunks, ids[start:end])
+	}
+
+	chunkResults := fanout_utils.Run(context.Background(), len(chunks), multigetFanOut, func(ctx context.Context, i int) (interface{}, apierrors.ApiError) {
+		return getItemsChunk(chunks[i], attributes)
+	})
+
+	result := make([]items.ItemResult, 0, len(ids))
+	for _, r := range chunkResults {
+		if r.Err != nil {
+			for _, id := range chunks[r.Index] {
+				result = append(result, items.ItemResult{Id: id, Error: r.Err})
+			}
+			continue
+		}
+		result = append(result, r.Value.([]items.ItemResult)...)
+	}
+
+	return result
+}
+
+func getItemsChunk(ids []string, attributes []string) ([]items.ItemResult, apierrors.ApiError) {
+	uri := fmt.Sprintf(uriGetItems, strings.Join(ids, ","))
+	if len(attributes) > 0 {
+		uri += fmt.Sprintf(uriItemsAttributes, strings.Join(attributes, ","))
+	}
+	response, restErr := itemsRestClient.Get(uri)
+	if restErr != nil {
+		return nil, restErr
+	}
+
+	if response == nil || response.Response == nil {
+		err := errors.New("invalid restclient response")
+		msg := fmt.Sprintf("invalid restclient response getting items %s", strings.Join(ids, ","))
+		return nil, apierrors.NewInternalServerApiError(msg, err)
+	}
+
+	if response.StatusCode > 299 {
+		apiErr, err := apierrors.NewApiErrorFromBytes(response.Bytes())
+		if err != nil {
+			return nil, apierrors.NewInternalServerApiError("error when trying to unmarshal apierror items response", err)
+		}
+		return nil, apiErr
+	}
+
+	var entries []items.MultigetResponse
+	if err := json.Unmarshal(response.Bytes(), &entries); err != nil {
+		return nil, apierrors.NewInternalServerApiError("error trying to unmarshal multiget items response", err)
+	}
+	if len(entries) != len(ids) {
+		msg := fmt.Sprintf("multiget returned %d items for %d ids", len(entries), len(ids))
+		return nil, apierrors.NewInternalServerApiError(msg, errors.New("unexpected multiget response"))
+	}
+
+	// entries come in the order of the requested ids
+	result := make([]items.ItemResult, len(ids))
+	for i, entry := range entries {
+		result[i] = items.ItemResult{Id: ids[i]}
+		if entry.Code > 299 {
+			apiErr, err := apierrors.NewApiErrorFromBytes(entry.Body)
+			if err != nil || apiErr.Status() == 0 {
+				apiErr = apierrors.NewApiError(fmt.Sprintf("error getting item %s", ids[i]), "multiget_error", entry.Code, apierrors.CauseList{})
+			}
+			result[i].Error = apiErr
+			continue
+		}
+
+		var item items.Item
+		if err := json.Unmarshal(entry.Body, &item); err != nil {
+			msg := fmt.Sprintf("error trying to unmarshal response into item %s structure", ids[i])
+			result[i].Error = apierrors.NewInternalServerApiError(msg, err)
+			continue
+		}
+		if item.Id == "" {
+			item.Id = ids[i]
+		}
+		result[i].Item = &item
+	}
+
+	return result, nil
+}
+
+func getItemDescription(itemId string) (*items.ItemDescription, apierrors.ApiError) {
 	uri := fmt.Sprintf(uriGetItemDescription, itemId)
-	response := itemsRestClient.Get(uri)
+	response, restErr := itemsRestClient.Get(uri)
+	if restErr != nil {
+		return nil, restErr
+	}
 
 	if response == nil || response.Response == nil {
 		err := errors.New("invalid restclient response")
@@ -131,9 +231,12 @@ func GetItemDescription(itemId string) (*items.ItemDescription, apierrors.ApiErr
 	return &description, nil
 }
 
-func GetItemReviews(itemId string, catalogProductId string) (*items.ItemReviewsResponse, apierrors.ApiError) {
+func getItemReviews(itemId string, catalogProductId string) (*items.ItemReviewsResponse, apierrors.ApiError) {
 	uri := fmt.Sprintf(uriGetItemReviews, itemId, catalogProductId)
-	response := reviewsRestClient.Get(uri)
+	response, restErr := reviewsRestClient.Get(uri)
+	if restErr != nil {
+		return nil, restErr
+	}
 
 	if response == nil || response.Response == nil {
 		err := errors.New("invalid restclient response")
@@ -158,9 +261,12 @@ func GetItemReviews(itemId string, catalogProductId string) (*items.ItemReviewsR
 	return &result, nil
 }
 
-func GetCategoryTrends(categoryId string) (*items.CategoryTrends, apierrors.ApiError) {
-	uri := fmt.Sprintf(uriGetCategoryTrends, categoryId)
-	response := itemsRestClient.Get(uri)
+func getCategoryTrends(siteId string, categoryId string) (*items.CategoryTrends, apierrors.ApiError) {
+	uri := fmt.Sprintf(uriGetCategoryTrends, siteId, categoryId)
+	response, restErr := itemsRestClient.Get(uri)
+	if restErr != nil {
+		return nil, restErr
+	}
 
 	if response == nil || response.Response == nil {
 		err := errors.New("invalid restclient response")
@@ -185,39 +291,33 @@ func GetCategoryTrends(categoryId string) (*items.CategoryTrends, apierrors.ApiE
 	return &result, nil
 }
 
-func GetRealQuantity(permalink string) (*int64, apierrors.ApiError) {
-	response := vipRestClient.Get(permalink)
+// getItemPage downloads the page of the item that buyers see.
+func getItemPage(permalink string) ([]byte, apierrors.ApiError) {
+	response, restErr := vipRestClient.Get(permalink)
+	if restErr != nil {
+		return nil, restErr
+	}
 
 	if response == nil || response.Response == nil {
-		return nil, apierrors.NewInternalServerApiError("nil resp", errors.New("nil resp"))
+		err := errors.New("invalid restclient response")
+		msg := fmt.Sprintf("invalid restclient response getting item page %s", permalink)
+		return nil, apierrors.NewInternalServerApiError(msg, err)
 	}
 
 	if response.StatusCode > 299 {
-		apiErr, err := apierrors.NewApiErrorFromBytes(response.Bytes())
-		if err != nil {
-			return nil, apierrors.NewInternalServerApiError("error when trying to unmarshal apierror category trends response", err)
-		}
-		return nil, apiErr
+		msg := fmt.Sprintf("error getting item page %s", permalink)
+		return nil, apierrors.NewApiError(msg, "item_page_error", response.StatusCode, apierrors.CauseList{})
 	}
 
-	var result = response.String()
-
-	values := strings.Split(result, "\"availableStock\":")
-	if len(values) > 1 {
-		quantityString := strings.Split(values[1], ",")
-		quantity, err := strconv.ParseInt(quantityString[0], 10, 32)
-		if err != nil {
-			return nil, apierrors.NewInternalServerApiError("error getting int from string", err)
-		}
-		return &quantity, nil
-	}
-
-	return nil, nil
+	return response.Bytes(), nil
 }
 
-func GetCategory(categoryId string) (*items.Category, apierrors.ApiError) {
+func getCategory(categoryId string) (*items.Category, apierrors.ApiError) {
 	uri := fmt.Sprintf(uriGetCategory, categoryId)
-	response := categoryRestClient.Get(uri)
+	response, restErr := categoryRestClient.Get(uri)
+	if restErr != nil {
+		return nil, restErr
+	}
 
 	if response == nil || response.Response == nil {
 		err := errors.New("invalid restclient response")
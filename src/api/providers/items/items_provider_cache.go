@@ -0,0 +1,114 @@
+package items_provider
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/lmurature/melist-api/src/api/clients/cache"
+	"github.com/lmurature/melist-api/src/api/config"
+	"github.com/lmurature/melist-api/src/api/domain/apierrors"
+	"github.com/lmurature/melist-api/src/api/domain/items"
+)
+
+var (
+	providerCache = newProviderCache()
+
+	searchPolicy      = cache.Policy{Name: "search", TTL: time.Minute, StaleTTL: time.Minute}
+	itemPolicy        = cache.Policy{Name: "item", TTL: time.Minute, StaleTTL: 4 * time.Minute}
+	descriptionPolicy = cache.Policy{Name: "description", TTL: time.Hour, StaleTTL: 23 * time.Hour}
+	reviewsPolicy     = cache.Policy{Name: "reviews", TTL: 10 * time.Minute, StaleTTL: 50 * time.Minute}
+	trendsPolicy      = cache.Policy{Name: "trends", TTL: time.Hour, StaleTTL: 5 * time.Hour}
+	categoryPolicy    = cache.Policy{Name: "category", TTL: 24 * time.Hour, StaleTTL: 6 * 24 * time.Hour}
+)
+
+func newProviderCache() *cache.Cache {
+	if !config.ProviderCacheEnabled {
+		return cache.New(cache.NopStore{})
+	}
+	return cache.New(cache.NewMemoryStore(config.ProviderCacheMaxEntries, config.ProviderCacheMaxBytes))
+}
+
+// UseCacheStore replaces the cache of Mercado Libre responses with an empty one kept in store, which can be shared
+// by several instances of the API.
+func UseCacheStore(store cache.Store) {
+	providerCache = cache.New(store)
+}
+
+// CacheStats returns the hits, misses and errors of the cache of every kind of resource.
+func CacheStats() map[string]cache.Stats {
+	return providerCache.Stats()
+}
+
+// FlushCache drops every cached response.
+func FlushCache() {
+	providerCache.Flush()
+}
+
+// SearchItemsByQuery searches the site. The query of the search must already be escaped.
+func SearchItemsByQuery(siteId string, search items.ItemSearch) (*items.ItemSearchResponse, apierrors.ApiError) {
+	key := fmt.Sprintf(uriSearchItems, siteId, search.Query, search.Offset) + search.QueryParams()
+
+	var result items.ItemSearchResponse
+	if err := providerCache.Fetch(key, searchPolicy, &result, func() (interface{}, apierrors.ApiError) {
+		return searchItemsByQuery(siteId, search)
+	}); err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+func GetItemById(itemId string) (*items.Item, apierrors.ApiError) {
+	var result items.Item
+	if err := providerCache.Fetch(fmt.Sprintf(uriGetItem, itemId), itemPolicy, &result, func() (interface{}, apierrors.ApiError) {
+		return getItemById(itemId)
+	}); err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+func GetItemDescription(itemId string) (*items.ItemDescription, apierrors.ApiError) {
+	var result items.ItemDescription
+	if err := providerCache.Fetch(fmt.Sprintf(uriGetItemDescription, itemId), descriptionPolicy, &result, func() (interface{}, apierrors.ApiError) {
+		return getItemDescription(itemId)
+	}); err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+func GetItemReviews(itemId string, catalogProductId string) (*items.ItemReviewsResponse, apierrors.ApiError) {
+	var result items.ItemReviewsResponse
+	if err := providerCache.Fetch(fmt.Sprintf(uriGetItemReviews, itemId, catalogProductId), reviewsPolicy, &result, func() (interface{}, apierrors.ApiError) {
+		return getItemReviews(itemId, catalogProductId)
+	}); err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+func GetCategoryTrends(siteId string, categoryId string) (*items.CategoryTrends, apierrors.ApiError) {
+	var result items.CategoryTrends
+	if err := providerCache.Fetch(fmt.Sprintf(uriGetCategoryTrends, siteId, categoryId), trendsPolicy, &result, func() (interface{}, apierrors.ApiError) {
+		return getCategoryTrends(siteId, categoryId)
+	}); err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+func GetCategory(categoryId string) (*items.Category, apierrors.ApiError) {
+	var result items.Category
+	if err := providerCache.Fetch(fmt.Sprintf(uriGetCategory, categoryId), categoryPolicy, &result, func() (interface{}, apierrors.ApiError) {
+		return getCategory(categoryId)
+	}); err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
@@ -1,20 +1,25 @@
 package items_provider
 
 import (
+	"fmt"
 	"github.com/lmurature/golang-restclient/rest"
+	"github.com/lmurature/melist-api/src/api/clients/restclient"
+	"github.com/lmurature/melist-api/src/api/domain/items"
 	"github.com/stretchr/testify/assert"
 	"net/http"
 	"os"
+	"strings"
 	"testing"
 )
 
 func TestMain(m *testing.M) {
 	rest.StartMockupServer()
+	restclient.DisableResilience()
 	os.Exit(m.Run())
 }
 
 func TestConstants(t *testing.T) {
-	assert.EqualValues(t, "/sites/MLA/search?q=%s&offset=%d", uriSearchItems)
+	assert.EqualValues(t, "/sites/%s/search?q=%s&offset=%d", uriSearchItems)
 	assert.EqualValues(t, "/items/%s", uriGetItem)
 }
 
@@ -26,7 +31,7 @@ func TestSearchItemsInvalidRestClientResponse(t *testing.T) {
 		RespHTTPCode: -1,
 	})
 
-	searchResult, err := SearchItemsByQuery("Computadora", 0)
+	searchResult, err := SearchItemsByQuery("MLA", items.ItemSearch{Query: "Computadora"})
 
 	assert.Nil(t, searchResult)
 	assert.NotNil(t, err)
@@ -43,7 +48,7 @@ func TestSearchItemsInvalidApiErrorResponse(t *testing.T) {
 		RespBody:     `{---`,
 	})
 
-	searchResult, err := SearchItemsByQuery("Computadora", 0)
+	searchResult, err := SearchItemsByQuery("MLA", items.ItemSearch{Query: "Computadora"})
 
 	assert.Nil(t, searchResult)
 	assert.NotNil(t, err)
@@ -60,7 +65,7 @@ func TestSearchItemsErrorFromBytes(t *testing.T) {
 		RespBody:     `{"message": "internal server error trying to search items", "status": 500}`,
 	})
 
-	searchResult, err := SearchItemsByQuery("Computadora", 0)
+	searchResult, err := SearchItemsByQuery("MLA", items.ItemSearch{Query: "Computadora"})
 
 	assert.Nil(t, searchResult)
 	assert.NotNil(t, err)
@@ -77,7 +82,7 @@ func TestSearchItemsErrorUnmarshal(t *testing.T) {
 		RespBody:     `{---`,
 	})
 
-	searchResult, err := SearchItemsByQuery("Computadora", 0)
+	searchResult, err := SearchItemsByQuery("MLA", items.ItemSearch{Query: "Computadora"})
 
 	assert.Nil(t, searchResult)
 	assert.NotNil(t, err)
@@ -94,7 +99,7 @@ func TestSearchItemsNoError(t *testing.T) {
 		RespBody:     `{"site_id":"MLA","query":"Computadora","paging":{"total":20027,"offset":0,"limit":50},"results":[{"id":"MLA907751590","title":"Estabilizador De Tensión Lyonn Tca Series 1200nv 1200va Con Entrada Y Salida De 220v Ca  Negro","descriptions":null,"category_id":"MLA1719","seller_id":0,"price":2564,"status":"","initial_quantity":0,"available_quantity":11,"condition":"new","sold_quantity":314,"attributes":[{"id":"BRAND","name":"Marca","value_id":"15747","value_name":"Lyonn"},{"id":"ITEM_CONDITION","name":"Condición del ítem","value_id":"2230284","value_name":"Nuevo"},{"id":"LINE","name":"Línea","value_id":"338326","value_name":"TCA Series"},{"id":"MODEL","name":"Modelo","value_id":"9729806","value_name":"1200NV"},{"id":"PEAK_POWER","name":"Potencia pico","value_id":"260601","value_name":"1200VA","value_struct":{"number":1200,"unit":"VA"}},{"id":"RATED_POWER","name":"Potencia nominal","value_id":"8900723","value_name":"1200 VA","value_struct":{"number":1200,"unit":"VA"}},{"id":"WEIGHT","name":"Peso","value_id":"7726408","value_name":"1.54 kg","value_struct":{"number":1.54,"unit":"kg"}}],"sub_status":null,"permalink":"https://www.mercadolibre.com.ar/estabilizador-de-tension-lyonn-tca-series-1200nv-1200va-con-entrada-y-salida-de-220v-ca-negro/p/MLA6208662"},{"id":"MLA873398163","title":"Memoria Ram Fury Ddr4 Gamer 8gb 1x8gb Hyperx Hx426c16fb3/8","descriptions":null,"category_id":"MLA1694","seller_id":0,"price":6319,"status":"","initial_quantity":0,"available_quantity":2672,"condition":"new","sold_quantity":4341,"attributes":[{"id":"BRAND","name":"Marca","value_id":"448156","value_name":"HyperX"},{"id":"ITEM_CONDITION","name":"Condición del ítem","value_id":"2230284","value_name":"Nuevo"},{"id":"LINE","name":"Línea","value_id":"10087029","value_name":"Fury DDR4"},{"id":"MODEL","name":"Modelo","value_id":"7790422","value_name":"HX426C16FB3/8"},{"id":"PACKAGE_LENGTH","name":"Largo del paquete","value_name":"13.6 cm","value_struct":{"number":13.6,"unit":"cm"}},{"id":"PACKAGE_WEIGHT","name":"Peso del paquete","value_name":"60 g","value_struct":{"number":60,"unit":"g"}}],"sub_status":null,"permalink":"https://www.mercadolibre.com.ar/memoria-ram-fury-ddr4-gamer-8gb-1x8gb-hyperx-hx426c16fb38/p/MLA15178125"},{"id":"MLA879276614","title":"Computadora Cpu Intel Amd Doble Nucleo 8 Gb 500 Gb","descriptions":null,"category_id":"MLA1649","seller_id":0,"price":26590,"status":"","initial_quantity":0,"available_quantity":1,"condition":"new","sold_quantity":150,"attributes":[{"id":"BRAND","name":"Marca","value_id":"18034","value_name":"AMD"},{"id":"ITEM_CONDITION","name":"Condición del ítem","value_id":"2230284","value_name":"Nuevo"},{"id":"MODEL","name":"Modelo","value_name":"AMD E6010"},{"id":"PACKAGE_LENGTH","name":"Largo del paquete","value_name":"45.8 cm","value_struct":{"number":45.8,"unit":"cm"}},{"id":"PACKAGE_WEIGHT","name":"Peso del paquete","value_name":"5880 g","value_struct":{"number":5880,"unit":"g"}}],"sub_status":null,"permalink":"https://articulo.mercadolibre.com.ar/MLA-879276614-computadora-cpu-intel-amd-doble-nucleo-8-gb-500-gb-_JM"}],"sort":{"id":"relevance","name":"Más relevantes"},"available_sorts":[{"id":"price_asc","name":"Menor precio"},{"id":"price_desc","name":"Mayor precio"}]}`,
 	})
 
-	searchResult, err := SearchItemsByQuery("Computadora", 0)
+	searchResult, err := SearchItemsByQuery("MLA", items.ItemSearch{Query: "Computadora"})
 
 	assert.Nil(t, err)
 	assert.NotNil(t, searchResult)
@@ -192,3 +197,63 @@ func TestGetItemNoError(t *testing.T) {
 	assert.EqualValues(t, 9, item.AvailableQuantity)
 	assert.EqualValues(t, 1, item.SoldQuantity)
 }
+
+func TestGetItemsByIdsSplitsIdsInChunks(t *testing.T) {
+	ids := make([]string, 25)
+	firstChunk := make([]string, 0, 20)
+	for i := range ids {
+		ids[i] = fmt.Sprintf("MLA%d", i+1)
+		if i < 20 {
+			firstChunk = append(firstChunk, fmt.Sprintf(`{"code":200,"body":{"id":"%s"}}`, ids[i]))
+		}
+	}
+
+	rest.FlushMockups()
+	rest.AddMockups(&rest.Mock{
+		URL:          "https://api.mercadolibre.com/items?ids=" + strings.Join(ids[:20], ",") + "&attributes=id,title",
+		HTTPMethod:   http.MethodGet,
+		RespHTTPCode: http.StatusOK,
+		RespBody:     "[" + strings.Join(firstChunk, ",") + "]",
+	}, &rest.Mock{
+		URL:          "https://api.mercadolibre.com/items?ids=MLA21,MLA22,MLA23,MLA24,MLA25&attributes=id,title",
+		HTTPMethod:   http.MethodGet,
+		RespHTTPCode: http.StatusOK,
+		RespBody: `[{"code":200,"body":{"id":"MLA21"}},
+			{"code":404,"body":{"message":"Item with id MLA22 not found","error":"not_found","status":404,"cause":[]}},
+			{"code":200,"body":{"id":"MLA23"}},{"code":200,"body":{"id":"MLA24"}},{"code":403,"body":"forbidden"}]`,
+	})
+
+	result := GetItemsByIds(ids, []string{"id", "title"})
+
+	assert.EqualValues(t, 25, len(result))
+	for i, r := range result {
+		assert.EqualValues(t, ids[i], r.Id)
+		if r.Error == nil {
+			assert.EqualValues(t, ids[i], r.Item.Id)
+		}
+	}
+	assert.Nil(t, result[21].Item)
+	assert.EqualValues(t, http.StatusNotFound, result[21].Error.Status())
+	assert.EqualValues(t, "Item with id MLA22 not found", result[21].Error.Message())
+	assert.EqualValues(t, http.StatusForbidden, result[24].Error.Status())
+	assert.EqualValues(t, "multiget_error", result[24].Error.Code())
+}
+
+func TestGetItemsByIdsChunkFailure(t *testing.T) {
+	rest.FlushMockups()
+	rest.AddMockups(&rest.Mock{
+		URL:          "https://api.mercadolibre.com/items?ids=MLA1,MLA2",
+		HTTPMethod:   http.MethodGet,
+		RespHTTPCode: http.StatusInternalServerError,
+		RespBody:     `{"message":"meli is down","error":"internal_server_error","status":500,"cause":[]}`,
+	})
+
+	result := GetItemsByIds([]string{"MLA1", "MLA2"}, nil)
+
+	assert.EqualValues(t, 2, len(result))
+	for _, r := range result {
+		assert.Nil(t, r.Item)
+		assert.EqualValues(t, http.StatusInternalServerError, r.Error.Status())
+		assert.EqualValues(t, "meli is down", r.Error.Message())
+	}
+}
@@ -0,0 +1,183 @@
+package items_provider
+
+import (
+	"bytes"
+	"encoding/json"
+	"regexp"
+	"strings"
+
+	"github.com/lmurature/melist-api/src/api/domain/apierrors"
+	"github.com/lmurature/melist-api/src/api/domain/items"
+)
+
+var (
+	DefaultStockResolver = NewStockResolver(VariationsStockStrategy{}, PageStockStrategy{}, ListingStockStrategy{})
+
+	scriptPattern = regexp.MustCompile(`(?is)<script[^>]*>(.*?)</script>`)
+)
+
+// StockStrategy resolves the stock of an item from one source. It returns a nil resolution when the source knows
+// nothing about the stock of the item.
+type StockStrategy interface {
+	Resolve(item items.Item) (*items.StockResolution, apierrors.ApiError)
+}
+
+// StockResolver asks its strategies in order and keeps the most confident resolution, stopping at the first one
+// with StockConfidenceHigh. Strategies that fail are skipped.
+type StockResolver struct {
+	strategies []StockStrategy
+}
+
+func NewStockResolver(strategies ...StockStrategy) *StockResolver {
+	return &StockResolver{strategies: strategies}
+}
+
+func (r *StockResolver) Resolve(item items.Item) items.StockResolution {
+	best := items.StockResolution{Confidence: items.StockConfidenceNone}
+	for _, strategy := range r.strategies {
+		resolution, err := strategy.Resolve(item)
+		if err != nil || resolution == nil {
+			continue
+		}
+		if resolution.Confidence > best.Confidence {
+			best = *resolution
+		}
+		if best.Confidence == items.StockConfidenceHigh {
+			break
+		}
+	}
+	return best
+}
+
+// ResolveStock resolves the stock of the item with DefaultStockResolver.
+func ResolveStock(item items.Item) items.StockResolution {
+	return DefaultStockResolver.Resolve(item)
+}
+
+// VariationsStockStrategy adds up the available quantity of the variations of the item, which Mercado Libre reports
+// per variation.
+type VariationsStockStrategy struct{}
+
+func (VariationsStockStrategy) Resolve(item items.Item) (*items.StockResolution, apierrors.ApiError) {
+	if len(item.Variations) == 0 {
+		return nil, nil
+	}
+
+	quantity := 0
+	for _, variation := range item.Variations {
+		quantity += variation.AvailableQuantity
+	}
+	return &items.StockResolution{Quantity: quantity, Confidence: items.StockConfidenceHigh, Source: items.StockSourceVariations}, nil
+}
+
+// PageStockStrategy reads the stock from the structured data embedded in the page of the item.
+type PageStockStrategy struct{}
+
+func (PageStockStrategy) Resolve(item items.Item) (*items.StockResolution, apierrors.ApiError) {
+	if item.Permalink == "" {
+		return nil, nil
+	}
+
+	page, err := getItemPage(item.Permalink)
+	if err != nil {
+		return nil, err
+	}
+	return parseStockFromPage(page), nil
+}
+
+// ListingStockStrategy trusts the available quantity of the item, which Mercado Libre rounds for public requests.
+type ListingStockStrategy struct{}
+
+func (ListingStockStrategy) Resolve(item items.Item) (*items.StockResolution, apierrors.ApiError) {
+	return &items.StockResolution{Quantity: item.AvailableQuantity, Confidence: items.StockConfidenceLow, Source: items.StockSourceListing}, nil
+}
+
+// parseStockFromPage looks for the stock in the JSON of the scripts of the page: the availableStock of the state the
+// page is rendered from or, failing that, the schema.org availability of the offer, which only tells out of stock.
+func parseStockFromPage(page []byte) *items.StockResolution {
+	availability := ""
+	for _, script := range scriptPattern.FindAllSubmatch(page, -1) {
+		start := bytes.IndexAny(script[1], "{[")
+		if start < 0 {
+			continue
+		}
+		data := script[1][start:]
+
+		for _, value := range findJsonValues(data, "availableStock") {
+			if number, ok := value.(json.Number); ok {
+				if quantity, err := number.Int64(); err == nil && quantity >= 0 {
+					return &items.StockResolution{Quantity: int(quantity), Confidence: items.StockConfidenceHigh, Source: items.StockSourcePage}
+				}
+			}
+		}
+		for _, value := range findJsonValues(data, "availability") {
+			if text, ok := value.(string); ok && availability == "" {
+				availability = text
+			}
+		}
+	}
+
+	if strings.HasSuffix(availability, "OutOfStock") || strings.HasSuffix(availability, "SoldOut") {
+		return &items.StockResolution{Quantity: 0, Confidence: items.StockConfidenceMedium, Source: items.StockSourcePage}
+	}
+	return nil
+}
+
+type jsonContainer struct {
+	object    bool
+	expectKey bool
+	key       string
+}
+
+// findJsonValues walks the first JSON value of data and returns, in document order, the scalar values of the fields
+// named key at any depth. Anything after that value, such as the end of a script statement, is ignored, and so is
+// whatever follows a syntax error.
+func findJsonValues(data []byte, key string) []interface{} {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var result []interface{}
+	var stack []*jsonContainer
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return result
+		}
+
+		var parent *jsonContainer
+		if len(stack) > 0 {
+			parent = stack[len(stack)-1]
+		}
+
+		if delim, ok := token.(json.Delim); ok {
+			switch delim {
+			case '{', '[':
+				if parent != nil && parent.object {
+					parent.expectKey = true
+				}
+				stack = append(stack, &jsonContainer{object: delim == '{', expectKey: delim == '{'})
+			default:
+				stack = stack[:len(stack)-1]
+				if len(stack) == 0 {
+					return result
+				}
+			}
+			continue
+		}
+
+		if parent == nil {
+			return result
+		}
+		if parent.object && parent.expectKey {
+			parent.key, _ = token.(string)
+			parent.expectKey = false
+			continue
+		}
+		if parent.object {
+			if parent.key == key {
+				result = append(result, token)
+			}
+			parent.expectKey = true
+		}
+	}
+}
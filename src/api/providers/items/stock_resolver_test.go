@@ -0,0 +1,101 @@
+package items_provider
+
+import (
+	"io/ioutil"
+	"net/http"
+	"path/filepath"
+	"testing"
+
+	"github.com/lmurature/golang-restclient/rest"
+	"github.com/lmurature/melist-api/src/api/domain/apierrors"
+	"github.com/lmurature/melist-api/src/api/domain/items"
+	"github.com/stretchr/testify/assert"
+)
+
+func readFixture(t *testing.T, name string) []byte {
+	page, err := ioutil.ReadFile(filepath.Join("testdata", name))
+	assert.Nil(t, err)
+	return page
+}
+
+func TestParseStockFromPage(t *testing.T) {
+	tests := []struct {
+		fixture  string
+		expected *items.StockResolution
+	}{
+		{"vip_preloaded_state.html", &items.StockResolution{Quantity: 12, Confidence: items.StockConfidenceHigh, Source: items.StockSourcePage}},
+		{"vip_window_state.html", &items.StockResolution{Quantity: 3, Confidence: items.StockConfidenceHigh, Source: items.StockSourcePage}},
+		{"vip_ld_json_out_of_stock.html", &items.StockResolution{Quantity: 0, Confidence: items.StockConfidenceMedium, Source: items.StockSourcePage}},
+		{"vip_markup_changed.html", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.fixture, func(t *testing.T) {
+			assert.EqualValues(t, tt.expected, parseStockFromPage(readFixture(t, tt.fixture)))
+		})
+	}
+}
+
+func TestResolveStockPrefersVariations(t *testing.T) {
+	rest.FlushMockups()
+	item := items.Item{
+		Id:                "MLA1",
+		AvailableQuantity: 50,
+		Permalink:         "https://articulo.mercadolibre.com.ar/MLA-1",
+		Variations:        []items.ItemVariation{{Id: 1, AvailableQuantity: 2}, {Id: 2, AvailableQuantity: 0}},
+	}
+
+	resolution := ResolveStock(item)
+
+	assert.EqualValues(t, items.StockResolution{Quantity: 2, Confidence: items.StockConfidenceHigh, Source: items.StockSourceVariations}, resolution)
+	assert.True(t, resolution.IsReliable())
+}
+
+func TestResolveStockFromPage(t *testing.T) {
+	rest.FlushMockups()
+	rest.AddMockups(&rest.Mock{
+		URL:          "https://articulo.mercadolibre.com.ar/MLA-1",
+		HTTPMethod:   http.MethodGet,
+		RespHTTPCode: http.StatusOK,
+		RespBody:     string(readFixture(t, "vip_preloaded_state.html")),
+	})
+
+	resolution := ResolveStock(items.Item{Id: "MLA1", AvailableQuantity: 50, Permalink: "https://articulo.mercadolibre.com.ar/MLA-1"})
+
+	assert.EqualValues(t, items.StockResolution{Quantity: 12, Confidence: items.StockConfidenceHigh, Source: items.StockSourcePage}, resolution)
+}
+
+func TestResolveStockFallsBackToListing(t *testing.T) {
+	rest.FlushMockups()
+	rest.AddMockups(&rest.Mock{
+		URL:          "https://articulo.mercadolibre.com.ar/MLA-1",
+		HTTPMethod:   http.MethodGet,
+		RespHTTPCode: http.StatusOK,
+		RespBody:     string(readFixture(t, "vip_markup_changed.html")),
+	}, &rest.Mock{
+		URL:          "https://articulo.mercadolibre.com.ar/MLA-2",
+		HTTPMethod:   http.MethodGet,
+		RespHTTPCode: http.StatusForbidden,
+		RespBody:     "<html>captcha</html>",
+	})
+
+	for _, permalink := range []string{"https://articulo.mercadolibre.com.ar/MLA-1", "https://articulo.mercadolibre.com.ar/MLA-2", ""} {
+		resolution := ResolveStock(items.Item{Id: "MLA1", AvailableQuantity: 1, Permalink: permalink})
+
+		assert.EqualValues(t, items.StockResolution{Quantity: 1, Confidence: items.StockConfidenceLow, Source: items.StockSourceListing}, resolution)
+		assert.False(t, resolution.IsReliable())
+	}
+}
+
+type failingStockStrategy struct{}
+
+func (failingStockStrategy) Resolve(item items.Item) (*items.StockResolution, apierrors.ApiError) {
+	return nil, apierrors.NewInternalServerApiError("page is down", nil)
+}
+
+func TestStockResolverWithoutResolution(t *testing.T) {
+	resolution := NewStockResolver(failingStockStrategy{}, VariationsStockStrategy{}).Resolve(items.Item{Id: "MLA1"})
+
+	assert.EqualValues(t, items.StockConfidenceNone, resolution.Confidence)
+	assert.False(t, resolution.IsReliable())
+}
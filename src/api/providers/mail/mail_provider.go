@@ -1,42 +1,87 @@
 package mail
 
 import (
+	"bytes"
 	"fmt"
 	"github.com/lmurature/melist-api/src/api/config"
 	"github.com/lmurature/melist-api/src/api/domain/apierrors"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	netmail "net/mail"
 	"net/smtp"
-	"strings"
+	"net/textproto"
 )
 
-var auth smtp.Auth
+// Send renders msg in the given locale and mails it to emailAddress as a multipart message with an html body and
+// its plain text alternative.
+func Send(emailAddress string, locale string, msg Message) apierrors.ApiError {
+	rendered, err := Render(locale, msg)
+	if err != nil {
+		return apierrors.NewInternalServerApiError(fmt.Sprintf("error while trying to render %s mail", msg.templateName()), err)
+	}
+
+	sender, err := netmail.ParseAddress(config.MailSender)
+	if err != nil {
+		return apierrors.NewInternalServerApiError("invalid mail sender", err)
+	}
+
+	body, err := buildMessage(sender.String(), emailAddress, rendered)
+	if err != nil {
+		return apierrors.NewInternalServerApiError(fmt.Sprintf("error while trying to build %s mail", msg.templateName()), err)
+	}
 
-func init() {
-	mail, pass := config.EmailAddress, config.EmailPassword
-	auth = smtp.PlainAuth("", mail, pass, config.SmtpHost)
+	// a local sink does not advertise AUTH, so credentials are only used when they are configured
+	var auth smtp.Auth
+	if config.EmailAddress != "" {
+		auth = smtp.PlainAuth("", config.EmailAddress, config.EmailPassword, config.SmtpHost)
+	}
+
+	if err := smtp.SendMail(config.SmtpAddress, auth, sender.Address, []string{emailAddress}, body); err != nil {
+		return apierrors.NewInternalServerApiError(fmt.Sprintf("error while trying to mail %s to %s", msg.templateName(), emailAddress), err)
+	}
+
+	return nil
 }
 
-func SendMail(emailAddress string,
-	shareType string, inviterFirstName string,
-	inviterLastName string, listTitle string, authUrl string) {
-	msg := []byte(fmt.Sprintf("To: %s\r\n"+
-		"Subject: ¡Te invitaron a colaborar en una lista Melist!\r\n"+
-		"\r\n"+
-		"¡Hola!\n\n\nEl usuario %s %s te invitó a colaborar en su lista %s otorgandote acceso de %s.\n\n\nPara ingresar, debés registrarte en la plataforma utilizando el siguiente link: %s \n\n\n¡Feliz listado!\r\n",
-		emailAddress, strings.ToTitle(inviterFirstName), strings.ToTitle(inviterLastName), listTitle, shareType, authUrl))
-	err := smtp.SendMail(
-		config.SmtpAddress,
-		auth,
-		"melistapplication@gmail.com",
-		[]string{emailAddress},
-		msg,
-	)
+func buildMessage(from string, to string, rendered *Rendered) ([]byte, error) {
+	var body bytes.Buffer
+	parts := multipart.NewWriter(&body)
 
-	if err != nil {
-		e := apierrors.NewInternalServerApiError(fmt.Sprintf("Error while trying to mail [%s, %s, %s, %s, %s]",
-			emailAddress, inviterFirstName, inviterLastName, shareType, authUrl), err)
-		fmt.Println(e)
-		return
+	for _, part := range []struct {
+		contentType string
+		content     string
+	}{
+		{"text/plain; charset=utf-8", rendered.Text},
+		{"text/html; charset=utf-8", rendered.Html},
+	} {
+		header := textproto.MIMEHeader{}
+		header.Set("Content-Type", part.contentType)
+		header.Set("Content-Transfer-Encoding", "quoted-printable")
+		w, err := parts.CreatePart(header)
+		if err != nil {
+			return nil, err
+		}
+		qp := quotedprintable.NewWriter(w)
+		if _, err := qp.Write([]byte(part.content)); err != nil {
+			return nil, err
+		}
+		if err := qp.Close(); err != nil {
+			return nil, err
+		}
+	}
+	if err := parts.Close(); err != nil {
+		return nil, err
 	}
 
-	fmt.Println("Successfully mailed user ", emailAddress)
+	var msg bytes.Buffer
+	fmt.Fprintf(&msg, "From: %s\r\n", from)
+	fmt.Fprintf(&msg, "To: %s\r\n", to)
+	fmt.Fprintf(&msg, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", rendered.Subject))
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	fmt.Fprintf(&msg, "Content-Type: multipart/alternative; boundary=%q\r\n", parts.Boundary())
+	msg.WriteString("\r\n")
+	msg.Write(body.Bytes())
+
+	return msg.Bytes(), nil
 }
@@ -0,0 +1,63 @@
+package mail
+
+const (
+	templateInvitation   = "invitation"
+	templateNotification = "notification"
+	templateDigest       = "digest"
+	templateWelcome      = "welcome"
+)
+
+// Message is the data of one kind of email. Each message type is rendered with the template of the same name.
+type Message interface {
+	templateName() string
+}
+
+// Invitation asks someone without a Melist account to collaborate on a list.
+type Invitation struct {
+	InviterFirstName string
+	InviterLastName  string
+	ListTitle        string
+	ShareType        string
+	Url              string
+}
+
+// Notification mails a single list notification to a user with instant delivery.
+type Notification struct {
+	ListTitle string
+	Message   string
+	Url       string
+}
+
+// Digest mails the notifications received since the last digest grouped by list.
+type Digest struct {
+	Weekly bool
+	Lists  []DigestList
+}
+
+type DigestList struct {
+	Title    string
+	Url      string
+	Messages []string
+}
+
+// Welcome greets a user the first time they log in.
+type Welcome struct {
+	FirstName string
+	Url       string
+}
+
+func (Invitation) templateName() string {
+	return templateInvitation
+}
+
+func (Notification) templateName() string {
+	return templateNotification
+}
+
+func (Digest) templateName() string {
+	return templateDigest
+}
+
+func (Welcome) templateName() string {
+	return templateWelcome
+}
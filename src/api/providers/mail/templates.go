@@ -0,0 +1,98 @@
+package mail
+
+import (
+	"bytes"
+	"fmt"
+	htmltemplate "html/template"
+	"strings"
+	texttemplate "text/template"
+
+	i18n_utils "github.com/lmurature/melist-api/src/api/utils/i18n"
+)
+
+const (
+	htmlLayout = `<!DOCTYPE html>
+<html lang="{{locale}}">
+<head><meta charset="utf-8"><title>Melist</title></head>
+<body style="font-family: Arial, sans-serif; color: #333333;">
+<h1 style="color: #3483fa;">Melist</h1>
+{{template "content" .}}
+</body>
+</html>
+`
+)
+
+// templateSource is one email of a locale: a text/template subject and plain text body plus an html/template body
+// that is rendered inside htmlLayout.
+type templateSource struct {
+	Subject string
+	Text    string
+	Html    string
+}
+
+type mailTemplate struct {
+	subject *texttemplate.Template
+	text    *texttemplate.Template
+	html    *htmltemplate.Template
+}
+
+// Rendered is an email ready to be sent, with the plain text alternative of its html body.
+type Rendered struct {
+	Subject string
+	Text    string
+	Html    string
+}
+
+var (
+	templateSets = make(map[string]map[string]*mailTemplate)
+)
+
+func init() {
+	registerLocale(i18n_utils.LocaleEsAR, esARTemplates)
+	registerLocale(i18n_utils.LocalePtBR, ptBRTemplates)
+	registerLocale(i18n_utils.LocaleEn, enTemplates)
+}
+
+// registerLocale parses every template of the locale and panics on errors, so a broken template fails at startup.
+func registerLocale(locale string, sources map[string]templateSource) {
+	funcs := map[string]interface{}{
+		"upper":  strings.ToTitle,
+		"locale": func() string { return locale },
+	}
+
+	set := make(map[string]*mailTemplate, len(sources))
+	for name, source := range sources {
+		html := htmltemplate.Must(htmltemplate.New("layout").Funcs(funcs).Parse(htmlLayout))
+		set[name] = &mailTemplate{
+			subject: texttemplate.Must(texttemplate.New(name + ".subject").Funcs(funcs).Parse(source.Subject)),
+			text:    texttemplate.Must(texttemplate.New(name + ".text").Funcs(funcs).Parse(source.Text)),
+			html:    htmltemplate.Must(html.New("content").Parse(source.Html)),
+		}
+	}
+	templateSets[locale] = set
+}
+
+// Render renders msg with the templates of locale.
+func Render(locale string, msg Message) (*Rendered, error) {
+	t, ok := templateSets[i18n_utils.NormalizeLocale(locale)][msg.templateName()]
+	if !ok {
+		return nil, fmt.Errorf("mail template %s not found", msg.templateName())
+	}
+
+	var subject, text, html bytes.Buffer
+	if err := t.subject.Execute(&subject, msg); err != nil {
+		return nil, err
+	}
+	if err := t.text.Execute(&text, msg); err != nil {
+		return nil, err
+	}
+	if err := t.html.ExecuteTemplate(&html, "layout", msg); err != nil {
+		return nil, err
+	}
+
+	return &Rendered{
+		Subject: strings.TrimSpace(subject.String()),
+		Text:    text.String(),
+		Html:    html.String(),
+	}, nil
+}
@@ -0,0 +1,71 @@
+package mail
+
+var enTemplates = map[string]templateSource{
+	templateInvitation: {
+		Subject: `You were invited to collaborate on a Melist list!`,
+		Text: `Hi!
+
+{{upper .InviterFirstName}} {{upper .InviterLastName}} invited you to collaborate on their list {{.ListTitle}} with {{.ShareType}} access.
+
+To join, sign up on the platform with the following link: {{.Url}}
+
+Happy listing!
+`,
+		Html: `<p>Hi!</p>
+<p><strong>{{upper .InviterFirstName}} {{upper .InviterLastName}}</strong> invited you to collaborate on their list <strong>{{.ListTitle}}</strong> with {{.ShareType}} access.</p>
+<p>To join, <a href="{{.Url}}">sign up on the platform</a>.</p>
+<p>Happy listing!</p>`,
+	},
+	templateNotification: {
+		Subject: `News on your list {{.ListTitle}}`,
+		Text: `Hi!
+
+There is news on your list {{.ListTitle}}:
+
+{{.Message}}
+
+See it at {{.Url}}
+
+Happy listing!
+`,
+		Html: `<p>Hi!</p>
+<p>There is news on your list <strong>{{.ListTitle}}</strong>:</p>
+<p>{{.Message}}</p>
+<p><a href="{{.Url}}">See it on Melist</a></p>
+<p>Happy listing!</p>`,
+	},
+	templateDigest: {
+		Subject: `{{if .Weekly}}Your weekly Melist digest{{else}}Your daily Melist digest{{end}}`,
+		Text: `Hi!
+
+This is what happened on your lists:
+{{range .Lists}}
+{{.Title}} ({{.Url}})
+{{range .Messages}}  - {{.}}
+{{end}}{{end}}
+Happy listing!
+`,
+		Html: `<p>Hi!</p>
+<p>This is what happened on your lists:</p>
+{{range .Lists}}<h2><a href="{{.Url}}">{{.Title}}</a></h2>
+<ul>
+{{range .Messages}}<li>{{.}}</li>
+{{end}}</ul>
+{{end}}<p>Happy listing!</p>`,
+	},
+	templateWelcome: {
+		Subject: `Welcome to Melist!`,
+		Text: `Hi {{.FirstName}}!
+
+Your Melist account is ready. Create your lists, share them with anyone you like and we will let you know when prices, deals or stock of your products change.
+
+Get started at {{.Url}}
+
+Happy listing!
+`,
+		Html: `<p>Hi {{.FirstName}}!</p>
+<p>Your Melist account is ready. Create your lists, share them with anyone you like and we will let you know when prices, deals or stock of your products change.</p>
+<p><a href="{{.Url}}">Get started</a></p>
+<p>Happy listing!</p>`,
+	},
+}
@@ -0,0 +1,71 @@
+package mail
+
+var esARTemplates = map[string]templateSource{
+	templateInvitation: {
+		Subject: `¡Te invitaron a colaborar en una lista Melist!`,
+		Text: `¡Hola!
+
+El usuario {{upper .InviterFirstName}} {{upper .InviterLastName}} te invitó a colaborar en su lista {{.ListTitle}} otorgándote acceso de {{.ShareType}}.
+
+Para ingresar, debés registrarte en la plataforma utilizando el siguiente link: {{.Url}}
+
+¡Feliz listado!
+`,
+		Html: `<p>¡Hola!</p>
+<p>El usuario <strong>{{upper .InviterFirstName}} {{upper .InviterLastName}}</strong> te invitó a colaborar en su lista <strong>{{.ListTitle}}</strong> otorgándote acceso de {{.ShareType}}.</p>
+<p>Para ingresar, debés <a href="{{.Url}}">registrarte en la plataforma</a>.</p>
+<p>¡Feliz listado!</p>`,
+	},
+	templateNotification: {
+		Subject: `Novedades en tu lista {{.ListTitle}}`,
+		Text: `¡Hola!
+
+Hay novedades en tu lista {{.ListTitle}}:
+
+{{.Message}}
+
+Podés verlo en {{.Url}}
+
+¡Feliz listado!
+`,
+		Html: `<p>¡Hola!</p>
+<p>Hay novedades en tu lista <strong>{{.ListTitle}}</strong>:</p>
+<p>{{.Message}}</p>
+<p><a href="{{.Url}}">Ver en Melist</a></p>
+<p>¡Feliz listado!</p>`,
+	},
+	templateDigest: {
+		Subject: `{{if .Weekly}}Tu resumen semanal de Melist{{else}}Tu resumen diario de Melist{{end}}`,
+		Text: `¡Hola!
+
+Estas son las novedades de tus listas:
+{{range .Lists}}
+{{.Title}} ({{.Url}})
+{{range .Messages}}  - {{.}}
+{{end}}{{end}}
+¡Feliz listado!
+`,
+		Html: `<p>¡Hola!</p>
+<p>Estas son las novedades de tus listas:</p>
+{{range .Lists}}<h2><a href="{{.Url}}">{{.Title}}</a></h2>
+<ul>
+{{range .Messages}}<li>{{.}}</li>
+{{end}}</ul>
+{{end}}<p>¡Feliz listado!</p>`,
+	},
+	templateWelcome: {
+		Subject: `¡Te damos la bienvenida a Melist!`,
+		Text: `¡Hola {{.FirstName}}!
+
+Tu cuenta de Melist ya está lista. Creá tus listas, compartilas con quien quieras y te avisamos cuando cambien los precios, las ofertas o el stock de tus productos.
+
+Empezá en {{.Url}}
+
+¡Feliz listado!
+`,
+		Html: `<p>¡Hola {{.FirstName}}!</p>
+<p>Tu cuenta de Melist ya está lista. Creá tus listas, compartilas con quien quieras y te avisamos cuando cambien los precios, las ofertas o el stock de tus productos.</p>
+<p><a href="{{.Url}}">Empezá ahora</a></p>
+<p>¡Feliz listado!</p>`,
+	},
+}
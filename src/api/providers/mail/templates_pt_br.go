@@ -0,0 +1,71 @@
+package mail
+
+var ptBRTemplates = map[string]templateSource{
+	templateInvitation: {
+		Subject: `Você foi convidado para colaborar em uma lista Melist!`,
+		Text: `Olá!
+
+O usuário {{upper .InviterFirstName}} {{upper .InviterLastName}} convidou você para colaborar na lista {{.ListTitle}} com acesso de {{.ShareType}}.
+
+Para entrar, cadastre-se na plataforma usando o seguinte link: {{.Url}}
+
+Boas listas!
+`,
+		Html: `<p>Olá!</p>
+<p>O usuário <strong>{{upper .InviterFirstName}} {{upper .InviterLastName}}</strong> convidou você para colaborar na lista <strong>{{.ListTitle}}</strong> com acesso de {{.ShareType}}.</p>
+<p>Para entrar, <a href="{{.Url}}">cadastre-se na plataforma</a>.</p>
+<p>Boas listas!</p>`,
+	},
+	templateNotification: {
+		Subject: `Novidades na sua lista {{.ListTitle}}`,
+		Text: `Olá!
+
+Há novidades na sua lista {{.ListTitle}}:
+
+{{.Message}}
+
+Veja em {{.Url}}
+
+Boas listas!
+`,
+		Html: `<p>Olá!</p>
+<p>Há novidades na sua lista <strong>{{.ListTitle}}</strong>:</p>
+<p>{{.Message}}</p>
+<p><a href="{{.Url}}">Ver no Melist</a></p>
+<p>Boas listas!</p>`,
+	},
+	templateDigest: {
+		Subject: `{{if .Weekly}}Seu resumo semanal do Melist{{else}}Seu resumo diário do Melist{{end}}`,
+		Text: `Olá!
+
+Estas são as novidades das suas listas:
+{{range .Lists}}
+{{.Title}} ({{.Url}})
+{{range .Messages}}  - {{.}}
+{{end}}{{end}}
+Boas listas!
+`,
+		Html: `<p>Olá!</p>
+<p>Estas são as novidades das suas listas:</p>
+{{range .Lists}}<h2><a href="{{.Url}}">{{.Title}}</a></h2>
+<ul>
+{{range .Messages}}<li>{{.}}</li>
+{{end}}</ul>
+{{end}}<p>Boas listas!</p>`,
+	},
+	templateWelcome: {
+		Subject: `Boas-vindas ao Melist!`,
+		Text: `Olá, {{.FirstName}}!
+
+Sua conta do Melist está pronta. Crie suas listas, compartilhe com quem quiser e avisaremos quando os preços, as ofertas ou o estoque dos seus produtos mudarem.
+
+Comece em {{.Url}}
+
+Boas listas!
+`,
+		Html: `<p>Olá, {{.FirstName}}!</p>
+<p>Sua conta do Melist está pronta. Crie suas listas, compartilhe com quem quiser e avisaremos quando os preços, as ofertas ou o estoque dos seus produtos mudarem.</p>
+<p><a href="{{.Url}}">Comece agora</a></p>
+<p>Boas listas!</p>`,
+	},
+}
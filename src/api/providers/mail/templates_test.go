@@ -0,0 +1,91 @@
+package mail
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/lmurature/melist-api/src/api/config"
+	"github.com/lmurature/melist-api/src/mailsink"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	update = flag.Bool("update", false, "rewrite the golden files with the current output")
+
+	goldenMessages = map[string]Message{
+		templateInvitation: Invitation{
+			InviterFirstName: "Lucas",
+			InviterLastName:  "Murature",
+			ListTitle:        "Cumpleaños <Martina>",
+			ShareType:        "Lector",
+			Url:              "https://melist-app.herokuapp.com/lists/75618245",
+		},
+		templateNotification: Notification{
+			ListTitle: "Supermercado",
+			Message:   "¡El producto Café tuvo un cambio en su precio! Antes valía 100.00, ahora 80.00.",
+			Url:       "https://melist-app.herokuapp.com/lists/75618245/MLA1",
+		},
+		templateDigest: Digest{
+			Weekly: true,
+			Lists: []DigestList{
+				{
+					Title:    "Supermercado",
+					Url:      "https://melist-app.herokuapp.com/lists/75618245",
+					Messages: []string{"¡El producto Café entró en una oferta!", "¡El producto MLA2 fue comprado por lucas!"},
+				},
+				{
+					Title:    "Regalos",
+					Url:      "https://melist-app.herokuapp.com/lists/75618246",
+					Messages: []string{"El producto Reloj se quedó sin stock."},
+				},
+			},
+		},
+		templateWelcome: Welcome{FirstName: "Lucas", Url: "https://melist-app.herokuapp.com"},
+	}
+)
+
+func TestTemplatesMatchGoldenFiles(t *testing.T) {
+	for locale, set := range templateSets {
+		assert.EqualValues(t, len(goldenMessages), len(set), "locale %s must define every template", locale)
+
+		for name, msg := range goldenMessages {
+			rendered, err := Render(locale, msg)
+			assert.Nil(t, err)
+
+			actual := fmt.Sprintf("Subject: %s\n\n--- text ---\n%s\n--- html ---\n%s", rendered.Subject, rendered.Text, rendered.Html)
+			golden := filepath.Join("testdata", locale, name+".golden")
+			if *update {
+				assert.Nil(t, os.MkdirAll(filepath.Dir(golden), 0755))
+				assert.Nil(t, ioutil.WriteFile(golden, []byte(actual), 0644))
+			}
+
+			expected, err := ioutil.ReadFile(golden)
+			assert.Nil(t, err, "missing golden file %s, run go test with -update", golden)
+			assert.EqualValues(t, string(expected), actual, "%s does not match", golden)
+		}
+	}
+}
+
+func TestSendMultipartMessage(t *testing.T) {
+	sink, err := mailsink.Listen("127.0.0.1:0")
+	assert.Nil(t, err)
+	go sink.Serve()
+	defer sink.Close()
+	config.SmtpAddress = sink.Addr()
+	config.EmailAddress = ""
+
+	assert.Nil(t, Send("martina@melist.com", "pt-BR", goldenMessages[templateInvitation]))
+
+	messages := sink.Messages()
+	assert.EqualValues(t, 1, len(messages))
+	assert.EqualValues(t, "melistapplication@gmail.com", messages[0].From)
+	assert.EqualValues(t, []string{"martina@melist.com"}, messages[0].To)
+	assert.EqualValues(t, "Você foi convidado para colaborar em uma lista Melist!", messages[0].Subject())
+	assert.True(t, strings.Contains(messages[0].Text(), "O usuário LUCAS MURATURE convidou você"))
+	assert.True(t, strings.Contains(messages[0].Html(), "<strong>Cumpleaños &lt;Martina&gt;</strong>"))
+}
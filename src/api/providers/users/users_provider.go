@@ -4,10 +4,9 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
-	"net/http"
 	"time"
 
-	"github.com/lmurature/golang-restclient/rest"
+	"github.com/lmurature/melist-api/src/api/clients/restclient"
 	"github.com/lmurature/melist-api/src/api/domain/apierrors"
 	"github.com/lmurature/melist-api/src/api/domain/users"
 	http_utils "github.com/lmurature/melist-api/src/api/utils/http"
@@ -16,22 +15,23 @@ import (
 const (
 	getUserUri   = "/users/%d"
 	getMyUserUri = "/users/me"
-	BEARER       = "Bearer %s"
 )
 
 var (
-	usersRestClient = rest.RequestBuilder{
-		BaseURL:        http_utils.BaseUrlMeli,
-		Timeout:        5 * time.Second,
-		DisableCache:   true,
-		DisableTimeout: false,
-	}
+	usersRestClient = restclient.New(restclient.Options{
+		BaseURL:      http_utils.BaseUrlMeli,
+		Timeout:      5 * time.Second,
+		DisableCache: true,
+	})
 )
 
 func GetUserInformation(userId int64) (*users.User, apierrors.ApiError) {
 	uri := fmt.Sprintf(getUserUri, userId)
 
-	response := usersRestClient.Get(uri)
+	response, restErr := usersRestClient.Get(uri)
+	if restErr != nil {
+		return nil, restErr
+	}
 
 	if response == nil || response.Response == nil {
 		msg := fmt.Sprintf("invalid restclient response while trying to get information for user %d", userId)
@@ -56,14 +56,13 @@ func GetUserInformation(userId int64) (*users.User, apierrors.ApiError) {
 	return &user, nil
 }
 
+// GetUserInformationMe returns the user the access token belongs to. The token only goes in the headers of this
+// request, so concurrent calls with tokens of different users never see each other's.
 func GetUserInformationMe(accessToken string) (*users.User, apierrors.ApiError) {
-	usersRestClient.Headers = make(http.Header)
-	usersRestClient.Headers.Add("Authorization", fmt.Sprintf(BEARER, accessToken))
-	defer usersRestClient.Headers.Del("Authorization")
-
-	uri := fmt.Sprintf(getMyUserUri)
-
-	response := usersRestClient.Get(uri)
+	response, restErr := usersRestClient.Get(getMyUserUri, restclient.WithBearerToken(accessToken))
+	if restErr != nil {
+		return nil, restErr
+	}
 
 	if response == nil || response.Response == nil {
 		msg := "invalid restclient response while trying to get information for my user"
@@ -1,15 +1,22 @@
 package users_provider
 
 import (
+	"fmt"
 	"github.com/lmurature/golang-restclient/rest"
+	"github.com/lmurature/melist-api/src/api/clients/restclient"
 	"github.com/stretchr/testify/assert"
 	"net/http"
+	"net/http/httptest"
 	"os"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 )
 
 func TestMain(m *testing.M) {
 	rest.StartMockupServer()
+	restclient.DisableResilience()
 	os.Exit(m.Run())
 }
 
@@ -196,3 +203,40 @@ func TestGetUserInformationMeNoError(t *testing.T) {
 	assert.EqualValues(t, "pepe", user.Nickname)
 }
 
+// TestGetUserInformationMeConcurrently sends the /users/me requests of many users at once to a fake Mercado Libre that
+// answers with the user of the bearer token, so a token sent with the request of another user shows up as a wrong
+// user. Run it with -race to also catch shared writes.
+func TestGetUserInformationMeConcurrently(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		// give other requests time to be sent while this one is in flight
+		time.Sleep(time.Millisecond)
+		fmt.Fprintf(w, `{"id": %s, "nickname": "user-%s"}`, strings.TrimPrefix(token, "token-"), token)
+	}))
+	defer server.Close()
+
+	rest.StopMockupServer()
+	previousClient := usersRestClient
+	usersRestClient = restclient.New(restclient.Options{BaseURL: server.URL, Timeout: 5 * time.Second, DisableCache: true})
+	defer func() {
+		usersRestClient = previousClient
+		rest.StartMockupServer()
+	}()
+
+	const callers = 50
+	const calls = 10
+	var wg sync.WaitGroup
+	for i := 1; i <= callers; i++ {
+		wg.Add(1)
+		go func(userId int64) {
+			defer wg.Done()
+			for j := 0; j < calls; j++ {
+				user, err := GetUserInformationMe(fmt.Sprintf("token-%d", userId))
+				if assert.Nil(t, err) {
+					assert.EqualValues(t, userId, user.Id)
+				}
+			}
+		}(int64(i))
+	}
+	wg.Wait()
+}
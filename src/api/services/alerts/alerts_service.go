@@ -0,0 +1,109 @@
+package alerts_service
+
+import (
+	"fmt"
+	"github.com/lmurature/melist-api/src/api/domain/alerts"
+	"github.com/lmurature/melist-api/src/api/domain/apierrors"
+	"github.com/lmurature/melist-api/src/api/domain/items"
+	"github.com/lmurature/melist-api/src/api/domain/lists"
+	"github.com/lmurature/melist-api/src/api/domain/share"
+	date_utils "github.com/lmurature/melist-api/src/api/utils/date"
+)
+
+type alertsService struct{}
+
+type alertsServiceInterface interface {
+	CreateRule(rule alerts.AlertRule, callerId int64) (*alerts.AlertRule, apierrors.ApiError)
+	GetListRules(listId int64, callerId int64) (alerts.AlertRules, apierrors.ApiError)
+	GetMyRules(callerId int64) (alerts.AlertRules, apierrors.ApiError)
+	DeleteRule(ruleId int64, callerId int64) apierrors.ApiError
+}
+
+var (
+	AlertsService alertsServiceInterface
+)
+
+func init() {
+	AlertsService = &alertsService{}
+}
+
+// CreateRule sets an alert for the caller on a list they can read, watching every item of the list or only one.
+func (s *alertsService) CreateRule(rule alerts.AlertRule, callerId int64) (*alerts.AlertRule, apierrors.ApiError) {
+	if err := rule.Validate(); err != nil {
+		return nil, err
+	}
+
+	if err := validateReadability(rule.ListId, callerId); err != nil {
+		return nil, err
+	}
+
+	if rule.ItemId != "" {
+		listItems, err := items.ItemListDao.GetItemsFromList(rule.ListId)
+		if err != nil {
+			return nil, err
+		}
+		if !listItems.ContainsItem(rule.ItemId, rule.VariationId) {
+			return nil, apierrors.NewNotFoundApiError(fmt.Sprintf("item %s not found in list %d", rule.ItemId, rule.ListId))
+		}
+	}
+
+	if rule.Type == alerts.RuleTypeBackInStock || rule.Type == alerts.RuleTypeHistoricalLow {
+		rule.Threshold = 0
+	}
+	rule.Id = 0
+	rule.UserId = callerId
+	rule.DateCreated = date_utils.GetNowDateFormatted()
+
+	return alerts.AlertRuleDao.CreateRule(rule)
+}
+
+// GetListRules returns the rules the caller set on the list. Rules are private to the user that set them.
+func (s *alertsService) GetListRules(listId int64, callerId int64) (alerts.AlertRules, apierrors.ApiError) {
+	if err := validateReadability(listId, callerId); err != nil {
+		return nil, err
+	}
+
+	rules, err := alerts.AlertRuleDao.GetListRules(listId)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(alerts.AlertRules, 0, len(rules))
+	for _, r := range rules {
+		if r.UserId == callerId {
+			result = append(result, r)
+		}
+	}
+	return result, nil
+}
+
+func (s *alertsService) GetMyRules(callerId int64) (alerts.AlertRules, apierrors.ApiError) {
+	return alerts.AlertRuleDao.GetUserRules(callerId)
+}
+
+func (s *alertsService) DeleteRule(ruleId int64, callerId int64) apierrors.ApiError {
+	rule, err := alerts.AlertRuleDao.GetRule(ruleId)
+	if err != nil {
+		return err
+	}
+
+	if rule.UserId != callerId {
+		return apierrors.NewForbiddenApiError("you can only delete your own alert rules")
+	}
+
+	return alerts.AlertRuleDao.DeleteRule(ruleId)
+}
+
+func validateReadability(listId int64, callerId int64) apierrors.ApiError {
+	list, err := lists.ListDao.GetList(listId)
+	if err != nil {
+		return err
+	}
+
+	configs, err := share.GetListShareConfigs(listId)
+	if err != nil {
+		return err
+	}
+
+	return list.Authorize(callerId, lists.ActionRead, configs)
+}
@@ -0,0 +1,70 @@
+package alerts_service
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/lmurature/melist-api/src/api/domain/alerts"
+	"github.com/lmurature/melist-api/src/api/domain/items"
+	"github.com/lmurature/melist-api/src/api/domain/lists"
+	test_utils "github.com/lmurature/melist-api/src/api/utils/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupList(t *testing.T) *lists.List {
+	list := test_utils.SetupSharedList(t)
+	_, err := items.ItemListDao.InsertItemToList(items.ItemListDto{ItemId: "MLA1", ListId: list.Id, Status: items.StatusNotChecked, UserId: test_utils.OwnerId})
+	assert.Nil(t, err)
+
+	return list
+}
+
+func TestCreateRule(t *testing.T) {
+	list := setupList(t)
+
+	rule, err := AlertsService.CreateRule(alerts.AlertRule{Id: 99, UserId: test_utils.OwnerId, ListId: list.Id, ItemId: "MLA1", Type: alerts.RuleTypeBackInStock, Threshold: 3}, test_utils.CollaboratorId)
+	assert.Nil(t, err)
+	assert.EqualValues(t, 1, rule.Id)
+	assert.EqualValues(t, test_utils.CollaboratorId, rule.UserId)
+	assert.EqualValues(t, 0, rule.Threshold)
+	assert.NotEmpty(t, rule.DateCreated)
+
+	_, err = AlertsService.CreateRule(alerts.AlertRule{ListId: list.Id, Type: alerts.RuleTypePriceDrop, Threshold: 10}, test_utils.StrangerId)
+	assert.NotNil(t, err)
+	assert.EqualValues(t, http.StatusForbidden, err.Status())
+
+	_, err = AlertsService.CreateRule(alerts.AlertRule{ListId: list.Id, ItemId: "MLA2", Type: alerts.RuleTypePriceDrop, Threshold: 10}, test_utils.OwnerId)
+	assert.NotNil(t, err)
+	assert.EqualValues(t, http.StatusNotFound, err.Status())
+
+	_, err = AlertsService.CreateRule(alerts.AlertRule{ListId: list.Id, Type: alerts.RuleTypePriceDrop}, test_utils.OwnerId)
+	assert.NotNil(t, err)
+	assert.EqualValues(t, http.StatusBadRequest, err.Status())
+}
+
+func TestRulesArePrivate(t *testing.T) {
+	list := setupList(t)
+
+	ownerRule, err := AlertsService.CreateRule(alerts.AlertRule{ListId: list.Id, Type: alerts.RuleTypePriceBelow, Threshold: 500}, test_utils.OwnerId)
+	assert.Nil(t, err)
+	_, err = AlertsService.CreateRule(alerts.AlertRule{ListId: list.Id, Type: alerts.RuleTypeHistoricalLow}, test_utils.CollaboratorId)
+	assert.Nil(t, err)
+
+	rules, err := AlertsService.GetListRules(list.Id, test_utils.CollaboratorId)
+	assert.Nil(t, err)
+	assert.EqualValues(t, 1, len(rules))
+	assert.EqualValues(t, alerts.RuleTypeHistoricalLow, rules[0].Type)
+
+	err = AlertsService.DeleteRule(ownerRule.Id, test_utils.CollaboratorId)
+	assert.NotNil(t, err)
+	assert.EqualValues(t, http.StatusForbidden, err.Status())
+
+	assert.Nil(t, AlertsService.DeleteRule(ownerRule.Id, test_utils.OwnerId))
+	rules, err = AlertsService.GetMyRules(test_utils.OwnerId)
+	assert.Nil(t, err)
+	assert.EqualValues(t, 0, len(rules))
+
+	err = AlertsService.DeleteRule(ownerRule.Id, test_utils.OwnerId)
+	assert.NotNil(t, err)
+	assert.EqualValues(t, http.StatusNotFound, err.Status())
+}
@@ -2,33 +2,103 @@ package auth_service
 
 import (
 	"fmt"
+	"github.com/lmurature/melist-api/src/api/clients/database"
+	"github.com/lmurature/melist-api/src/api/config"
 	"github.com/lmurature/melist-api/src/api/domain/apierrors"
 	"github.com/lmurature/melist-api/src/api/domain/auth"
+	"github.com/lmurature/melist-api/src/api/domain/sessions"
 	"github.com/lmurature/melist-api/src/api/domain/share"
+	"github.com/lmurature/melist-api/src/api/domain/sites"
 	"github.com/lmurature/melist-api/src/api/domain/users"
 	auth_provider "github.com/lmurature/melist-api/src/api/providers/auth"
+	"github.com/lmurature/melist-api/src/api/providers/mail"
+	tokens_service "github.com/lmurature/melist-api/src/api/services/tokens"
 	users_service "github.com/lmurature/melist-api/src/api/services/users"
+	i18n_utils "github.com/lmurature/melist-api/src/api/utils/i18n"
 	"github.com/sirupsen/logrus"
+	"net/http"
+	"time"
 )
 
 type authService struct{}
 
 type authServiceInterface interface {
-	AuthenticateUser(code string) (*auth.MeliAuthResponse, apierrors.ApiError)
-	RefreshAuthentication(refreshToken string) (*auth.MeliAuthResponse, apierrors.ApiError)
-	ValidateAccessToken(accessToken string) (*users.User, apierrors.ApiError)
+	StartLogin(redirectUri string, siteId string, nonce string) (*auth.LoginResponse, apierrors.ApiError)
+	AuthenticateUser(code string, state string, nonce string) (*auth.SessionResponse, apierrors.ApiError)
+	RefreshAuthentication(refreshToken string) (*auth.SessionResponse, apierrors.ApiError)
+	ValidateAccessToken(accessToken string) (*sessions.Session, apierrors.ApiError)
+	Logout(session sessions.Session, everywhere bool) apierrors.ApiError
 }
 
 var (
 	AuthService authServiceInterface
+
+	now = time.Now
 )
 
 func init() {
 	AuthService = &authService{}
 }
 
-func (s *authService) AuthenticateUser(code string) (*auth.MeliAuthResponse, apierrors.ApiError) {
-	result, err := auth_provider.CreateUserAccessToken(code)
+// StartLogin stores a new login that goes back to redirectUri, or to the first of config.RedirectUris when empty, and
+// returns where to send the user to authorize Melist in the site siteId, sites.DefaultSiteId when empty. Only the client
+// that generated nonce can finish the login.
+func (s *authService) StartLogin(redirectUri string, siteId string, nonce string) (*auth.LoginResponse, apierrors.ApiError) {
+	if len(nonce) < auth.LoginNonceMinLength {
+		return nil, apierrors.NewBadRequestApiError(fmt.Sprintf("a nonce of at least %d characters is required", auth.LoginNonceMinLength))
+	}
+	if redirectUri == "" && len(config.RedirectUris) > 0 {
+		redirectUri = config.RedirectUris[0]
+	}
+	if !isAllowedRedirectUri(redirectUri) {
+		return nil, apierrors.NewBadRequestApiError(fmt.Sprintf("redirect uri '%s' is not allowed", redirectUri))
+	}
+	siteId = sites.GetSiteIdOrDefault(siteId)
+	if err := sites.ValidateSiteId(siteId); err != nil {
+		return nil, err
+	}
+
+	loginState, err := auth.NewLoginState(redirectUri, nonce, now())
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := auth.LoginStateDao.CreateLoginState(*loginState); err != nil {
+		return nil, err
+	}
+
+	return &auth.LoginResponse{
+		AuthorizationUrl: loginState.AuthorizationUrl(siteId),
+		State:            loginState.State,
+		RedirectUri:      loginState.RedirectUri,
+	}, nil
+}
+
+// AuthenticateUser finishes the login of state with the authorization code Mercado Libre sent the user back with.
+// A login can only be finished once, before it expires and by the client that started it with nonce, so a victim
+// sent to the callback of a login started by someone else is not logged into their account.
+func (s *authService) AuthenticateUser(code string, state string, nonce string) (*auth.SessionResponse, apierrors.ApiError) {
+	if code == "" || state == "" {
+		return nil, apierrors.NewBadRequestApiError("authorization code and state are required")
+	}
+
+	loginState, err := auth.LoginStateDao.ConsumeLoginState(state)
+	if err != nil {
+		if err.Status() == http.StatusNotFound {
+			return nil, apierrors.NewForbiddenApiError("invalid login state")
+		}
+		return nil, err
+	}
+
+	if loginState.IsExpired(now()) {
+		return nil, apierrors.NewForbiddenApiError("login expired")
+	}
+
+	if !loginState.MatchesNonce(nonce) {
+		return nil, apierrors.NewForbiddenApiError("login was started by another client")
+	}
+
+	result, err := auth_provider.CreateUserAccessToken(code, loginState.RedirectUri, loginState.CodeVerifier)
 	if err != nil {
 		logrus.Error("error getting user authentication access token", err)
 		return nil, err
@@ -42,49 +112,187 @@ func (s *authService) AuthenticateUser(code string) (*auth.MeliAuthResponse, api
 		return nil, err
 	}
 
-	if err := users_service.UsersService.SaveUserToDb(*authenticatedUser, result.AccessToken, result.RefreshToken); err != nil {
+	if err := users_service.UsersService.SaveUserToDb(*authenticatedUser); err != nil {
 		// save user gives error 'cause it already exist. This should occur only if client loses refresh token.
 
-		if err := users_service.UsersService.UpdateUserDb(*authenticatedUser, result.AccessToken, result.RefreshToken); err != nil {
+		if err := users_service.UsersService.UpdateUserDb(*authenticatedUser); err != nil {
 			return nil, err
 		}
-
 	} else {
-		// parse all user email requests to collaborate to list
-		userFutureConfigs, err := share.ShareConfigDao.GetAllFutureListCollaborationByEmail(authenticatedUser.Email)
-		if err != nil {
-			return nil, err
+		go sendWelcomeMail(*authenticatedUser)
+	}
+
+	if err := tokens_service.TokensService.SaveTokens(authenticatedUser.Id, *result); err != nil {
+		return nil, err
+	}
+
+	// parse all user email requests to collaborate to list. They are converted on every login, so a conversion
+	// that was rolled back is retried the next time the user logs in.
+	if err := convertFutureCollaborations(*authenticatedUser); err != nil {
+		return nil, err
+	}
+
+	return createSession(authenticatedUser.Id, nil)
+}
+
+func isAllowedRedirectUri(redirectUri string) bool {
+	for _, uri := range config.RedirectUris {
+		if redirectUri != "" && uri == redirectUri {
+			return true
 		}
+	}
+	return false
+}
 
+func sendWelcomeMail(user users.User) {
+	if user.Email == "" {
+		return
+	}
+
+	if err := mail.Send(user.Email, i18n_utils.DefaultLocale, mail.Welcome{FirstName: user.FirstName, Url: config.AppBaseUrl}); err != nil {
+		logrus.Error(fmt.Sprintf("error while trying to mail welcome to user %d", user.Id), err)
+	}
+}
+
+func convertFutureCollaborations(user users.User) apierrors.ApiError {
+	userFutureConfigs, err := share.ShareConfigDao.GetAllFutureListCollaborationByEmail(user.Email)
+	if err != nil {
+		return err
+	}
+
+	if len(userFutureConfigs) == 0 {
+		return nil
+	}
+
+	return database.RunInTransaction(func(uow *database.UnitOfWork) apierrors.ApiError {
+		shareConfigDao := share.ShareConfigDao.WithUnitOfWork(uow)
 		for _, fc := range userFutureConfigs {
-			_, err := share.ShareConfigDao.CreateShareConfig(share.ShareConfig{
+			if _, err := shareConfigDao.CreateShareConfig(share.ShareConfig{
 				ListId:    fc.ListId,
-				UserId:    authenticatedUser.Id,
+				UserId:    user.Id,
 				ShareType: fc.ShareType,
-			})
-			if err == nil {
-				logrus.Info(fmt.Sprintf("successfully added future collaboration as proper collaboration to user %s. Deleting future collaboration register", authenticatedUser.Email))
-				err := share.ShareConfigDao.DeleteFutureCollaborationConfig(authenticatedUser.Email, fc.ListId)
-				if err == nil {
-					logrus.Info(fmt.Sprintf("successfully deleted future collaboration %s.", authenticatedUser.Email))
-				}
+			}); err != nil {
+				return err
+			}
+
+			if err := shareConfigDao.DeleteFutureCollaborationConfig(user.Email, fc.ListId); err != nil {
+				return err
 			}
 		}
+
+		uow.OnCommit(func() {
+			logrus.Info(fmt.Sprintf("successfully converted %d future collaborations of user %s", len(userFutureConfigs), user.Email))
+		})
+		return nil
+	})
+}
+
+// RefreshAuthentication rotates the session of refreshToken: the session is revoked and a new one is returned. A
+// refresh token can only be used once, so using a revoked one means it leaked and every session of the user is revoked.
+func (s *authService) RefreshAuthentication(refreshToken string) (*auth.SessionResponse, apierrors.ApiError) {
+	if refreshToken == "" {
+		return nil, apierrors.NewBadRequestApiError("refresh token should not be empty")
+	}
+
+	session, err := sessions.SessionDao.GetSessionByRefreshToken(sessions.HashToken(refreshToken))
+	if err != nil {
+		if err.Status() == http.StatusNotFound {
+			return nil, apierrors.NewUnauthorizedApiError("invalid refresh token")
+		}
+		return nil, err
+	}
+
+	if session.Revoked {
+		return nil, revokeReusedSession(*session)
+	}
+
+	if !session.IsRefreshValid(now()) {
+		return nil, apierrors.NewUnauthorizedApiError("refresh token expired")
 	}
 
+	var result *auth.SessionResponse
+	reused := false
+	if err := database.RunInTransaction(func(uow *database.UnitOfWork) apierrors.ApiError {
+		revoked, err := sessions.SessionDao.WithUnitOfWork(uow).RevokeSession(session.Id)
+		if err != nil {
+			return err
+		}
+		if !revoked {
+			// a concurrent refresh with the same token rotated the session first
+			reused = true
+			return apierrors.NewUnauthorizedApiError("invalid refresh token")
+		}
+
+		result, err = createSession(session.UserId, uow)
+		return err
+	}); err != nil {
+		if reused {
+			return nil, revokeReusedSession(*session)
+		}
+		return nil, err
+	}
+
+	logrus.Info(fmt.Sprintf("successfully refreshed session %d of user %d", session.Id, session.UserId))
 	return result, nil
 }
 
-func (s *authService) RefreshAuthentication(refreshToken string) (*auth.MeliAuthResponse, apierrors.ApiError) {
-	result, err := auth_provider.RefreshAccessToken(refreshToken)
+// ValidateAccessToken returns the session of a Melist access token if the token can still be used.
+func (s *authService) ValidateAccessToken(accessToken string) (*sessions.Session, apierrors.ApiError) {
+	session, err := sessions.SessionDao.GetSessionByAccessToken(sessions.HashToken(accessToken))
 	if err != nil {
-		logrus.Error("error refreshing user authentication access token", err)
 		return nil, err
 	}
-	logrus.Info(fmt.Sprintf("successfully refreshed token for user %d", result.UserId))
-	return result, nil
+
+	if !session.IsAccessValid(now()) {
+		return nil, apierrors.NewUnauthorizedApiError("access token expired")
+	}
+
+	return session, nil
+}
+
+// Logout revokes the session, or every session of its user when everywhere is true.
+func (s *authService) Logout(session sessions.Session, everywhere bool) apierrors.ApiError {
+	if everywhere {
+		if err := sessions.SessionDao.RevokeUserSessions(session.UserId); err != nil {
+			return err
+		}
+		logrus.Info(fmt.Sprintf("successfully logged out every session of user %d", session.UserId))
+		return nil
+	}
+
+	if _, err := sessions.SessionDao.RevokeSession(session.Id); err != nil {
+		return err
+	}
+	logrus.Info(fmt.Sprintf("successfully logged out session %d of user %d", session.Id, session.UserId))
+	return nil
 }
 
-func (s *authService) ValidateAccessToken(accessToken string) (*users.User, apierrors.ApiError) {
-	return users_service.UsersService.GetMyUser(accessToken)
+// revokeReusedSession handles a refresh token that was used more than once, which means it leaked: every session of
+// the user is revoked, including the ones rotated from it.
+func revokeReusedSession(session sessions.Session) apierrors.ApiError {
+	logrus.Warn(fmt.Sprintf("revoked refresh token of session %d was used, revoking every session of user %d", session.Id, session.UserId))
+	if err := sessions.SessionDao.RevokeUserSessions(session.UserId); err != nil {
+		return err
+	}
+	return apierrors.NewUnauthorizedApiError("invalid refresh token")
+}
+
+func createSession(userId int64, uow *database.UnitOfWork) (*auth.SessionResponse, apierrors.ApiError) {
+	session, tokens, err := sessions.New(userId, now())
+	if err != nil {
+		return nil, err
+	}
+
+	session, err = sessions.SessionDao.WithUnitOfWork(uow).CreateSession(*session)
+	if err != nil {
+		return nil, err
+	}
+
+	return &auth.SessionResponse{
+		AccessToken:  tokens.AccessToken,
+		TokenType:    sessions.TokenType,
+		ExpiresIn:    session.ExpiresIn(now()),
+		UserId:       userId,
+		RefreshToken: tokens.RefreshToken,
+	}, nil
 }
@@ -0,0 +1,289 @@
+package auth_service
+
+import (
+	"net/http"
+	"net/url"
+	"os"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/lmurature/golang-restclient/rest"
+	"github.com/lmurature/melist-api/src/api/config"
+	"github.com/lmurature/melist-api/src/api/domain/apierrors"
+	"github.com/lmurature/melist-api/src/api/domain/auth"
+	"github.com/lmurature/melist-api/src/api/domain/sessions"
+	"github.com/lmurature/melist-api/src/api/domain/users"
+	"github.com/lmurature/melist-api/src/api/storage"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	userId = 1
+	nonce  = "nonce-of-the-web-app-1234"
+)
+
+func TestMain(m *testing.M) {
+	rest.StartMockupServer()
+	config.TokenEncryptionKey = "test-key"
+	config.RedirectUris = []string{"https://melist.test/auth/authorized", "http://localhost:3000/auth/authorized"}
+	os.Exit(m.Run())
+}
+
+// setup logs the user in with the clock and returns the tokens of their first session.
+func setup(t *testing.T, clock *time.Time) (string, string) {
+	storage.UseMemory()
+	users.UserDao.CreateUser(users.MelistUser{Id: userId, Nickname: "pepe"})
+
+	previousNow := now
+	now = func() time.Time { return *clock }
+	t.Cleanup(func() { now = previousNow })
+
+	rest.FlushMockups()
+	rest.AddMockups(&rest.Mock{
+		URL:          "https://api.mercadolibre.com/oauth/token",
+		HTTPMethod:   http.MethodPost,
+		RespHTTPCode: http.StatusOK,
+		RespBody:     `{"access_token": "APP_USR-meli-access", "token_type": "bearer", "expires_in": 21600, "user_id": 1, "refresh_token": "TG-meli-refresh"}`,
+	}, &rest.Mock{
+		URL:          "https://api.mercadolibre.com/users/me",
+		HTTPMethod:   http.MethodGet,
+		RespHTTPCode: http.StatusOK,
+		RespBody:     `{"id": 1, "nickname": "pepe"}`,
+	})
+
+	response := login(t)
+	return response.AccessToken, response.RefreshToken
+}
+
+func login(t *testing.T) *auth.SessionResponse {
+	started, err := AuthService.StartLogin("", "", nonce)
+	assert.Nil(t, err)
+
+	response, err := AuthService.AuthenticateUser("code", started.State, nonce)
+	assert.Nil(t, err)
+	return response
+}
+
+func TestAuthenticateUserIssuesSession(t *testing.T) {
+	clock := time.Date(2021, 5, 1, 12, 0, 0, 0, time.UTC)
+	accessToken, refreshToken := setup(t, &clock)
+
+	assert.NotEqual(t, "APP_USR-meli-access", accessToken)
+	assert.NotEqual(t, "TG-meli-refresh", refreshToken)
+
+	user, err := users.UserDao.GetUser(userId)
+	assert.Nil(t, err)
+	assert.NotContains(t, user.AccessToken, "APP_USR-meli-access")
+	assert.NotContains(t, user.RefreshToken, "TG-meli-refresh")
+	assert.NotEmpty(t, user.TokenExpiresAt)
+
+	session, err := AuthService.ValidateAccessToken(accessToken)
+	assert.Nil(t, err)
+	assert.EqualValues(t, userId, session.UserId)
+	assert.NotEqual(t, accessToken, session.AccessTokenHash)
+
+	_, err = AuthService.ValidateAccessToken(refreshToken)
+	assert.NotNil(t, err)
+}
+
+func TestAccessTokenExpires(t *testing.T) {
+	clock := time.Date(2021, 5, 1, 12, 0, 0, 0, time.UTC)
+	accessToken, refreshToken := setup(t, &clock)
+
+	clock = clock.Add(2 * time.Hour)
+	_, err := AuthService.ValidateAccessToken(accessToken)
+	assert.NotNil(t, err)
+	assert.EqualValues(t, http.StatusUnauthorized, err.Status())
+
+	response, err := AuthService.RefreshAuthentication(refreshToken)
+	assert.Nil(t, err)
+	assert.EqualValues(t, 3600, response.ExpiresIn)
+	assert.EqualValues(t, sessions.TokenType, response.TokenType)
+
+	_, err = AuthService.ValidateAccessToken(response.AccessToken)
+	assert.Nil(t, err)
+
+	clock = clock.Add(31 * 24 * time.Hour)
+	_, err = AuthService.RefreshAuthentication(response.RefreshToken)
+	assert.NotNil(t, err)
+	assert.EqualValues(t, "refresh token expired", err.Message())
+}
+
+func TestRefreshAuthenticationRotatesSession(t *testing.T) {
+	clock := time.Date(2021, 5, 1, 12, 0, 0, 0, time.UTC)
+	accessToken, refreshToken := setup(t, &clock)
+
+	response, err := AuthService.RefreshAuthentication(refreshToken)
+	assert.Nil(t, err)
+	assert.NotEqual(t, accessToken, response.AccessToken)
+	assert.NotEqual(t, refreshToken, response.RefreshToken)
+
+	_, err = AuthService.ValidateAccessToken(accessToken)
+	assert.NotNil(t, err, "the rotated session is revoked")
+	_, err = AuthService.ValidateAccessToken(response.AccessToken)
+	assert.Nil(t, err)
+
+	// reusing a refresh token means it leaked, so every session of the user is revoked
+	_, err = AuthService.RefreshAuthentication(refreshToken)
+	assert.NotNil(t, err)
+	assert.EqualValues(t, http.StatusUnauthorized, err.Status())
+	_, err = AuthService.ValidateAccessToken(response.AccessToken)
+	assert.NotNil(t, err)
+
+	_, err = AuthService.RefreshAuthentication("unknown")
+	assert.NotNil(t, err)
+	assert.EqualValues(t, "invalid refresh token", err.Message())
+}
+
+func TestConcurrentRefreshesRotateOnce(t *testing.T) {
+	clock := time.Date(2021, 5, 1, 12, 0, 0, 0, time.UTC)
+	_, refreshToken := setup(t, &clock)
+	results := make([]*auth.SessionResponse, 20)
+	errs := make([]apierrors.ApiError, len(results))
+
+	// the clock is first read once the session is found, so every refresh sees it not revoked yet
+	var found sync.WaitGroup
+	found.Add(len(results))
+	var calls int32
+	previousNow := now
+	now = func() time.Time {
+		if atomic.AddInt32(&calls, 1) <= int32(len(results)) {
+			found.Done()
+			found.Wait()
+		}
+		return clock
+	}
+	t.Cleanup(func() { now = previousNow })
+
+	var wg sync.WaitGroup
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i], errs[i] = AuthService.RefreshAuthentication(refreshToken)
+		}(i)
+	}
+	wg.Wait()
+
+	refreshed := 0
+	for i := range results {
+		if errs[i] == nil {
+			refreshed++
+		} else {
+			assert.EqualValues(t, http.StatusUnauthorized, errs[i].Status())
+		}
+	}
+	assert.EqualValues(t, 1, refreshed, "a refresh token only gets one new session")
+}
+
+func TestLogout(t *testing.T) {
+	clock := time.Date(2021, 5, 1, 12, 0, 0, 0, time.UTC)
+	accessToken, _ := setup(t, &clock)
+	other := login(t)
+
+	session, err := AuthService.ValidateAccessToken(accessToken)
+	assert.Nil(t, err)
+	assert.Nil(t, AuthService.Logout(*session, false))
+
+	_, err = AuthService.ValidateAccessToken(accessToken)
+	assert.NotNil(t, err)
+	session, err = AuthService.ValidateAccessToken(other.AccessToken)
+	assert.Nil(t, err)
+
+	assert.Nil(t, AuthService.Logout(*session, true))
+	_, err = AuthService.ValidateAccessToken(other.AccessToken)
+	assert.NotNil(t, err)
+	_, err = AuthService.RefreshAuthentication(other.RefreshToken)
+	assert.NotNil(t, err)
+}
+
+func TestStartLogin(t *testing.T) {
+	clock := time.Date(2021, 5, 1, 12, 0, 0, 0, time.UTC)
+	setup(t, &clock)
+
+	response, err := AuthService.StartLogin("", "", nonce)
+	assert.Nil(t, err)
+	assert.EqualValues(t, "https://melist.test/auth/authorized", response.RedirectUri)
+	assert.NotEmpty(t, response.State)
+
+	authorizationUrl, parseErr := url.Parse(response.AuthorizationUrl)
+	assert.Nil(t, parseErr)
+	assert.EqualValues(t, "auth.mercadolibre.com.ar", authorizationUrl.Host)
+	assert.EqualValues(t, "/authorization", authorizationUrl.Path)
+	query := authorizationUrl.Query()
+	assert.EqualValues(t, "code", query.Get("response_type"))
+	assert.EqualValues(t, response.State, query.Get("state"))
+	assert.EqualValues(t, response.RedirectUri, query.Get("redirect_uri"))
+	assert.EqualValues(t, auth.CodeChallengeMethodS256, query.Get("code_challenge_method"))
+	assert.NotEmpty(t, query.Get("code_challenge"))
+
+	response, err = AuthService.StartLogin("http://localhost:3000/auth/authorized", "", nonce)
+	assert.Nil(t, err)
+	assert.EqualValues(t, "http://localhost:3000/auth/authorized", response.RedirectUri)
+
+	_, err = AuthService.StartLogin("https://evil.test/auth/authorized", "", nonce)
+	assert.NotNil(t, err)
+	assert.EqualValues(t, http.StatusBadRequest, err.Status())
+
+	response, err = AuthService.StartLogin("", "MLB", nonce)
+	assert.Nil(t, err)
+	authorizationUrl, _ = url.Parse(response.AuthorizationUrl)
+	assert.EqualValues(t, "auth.mercadolivre.com.br", authorizationUrl.Host, "users authorize melist in the domain of their site")
+
+	_, err = AuthService.StartLogin("", "XXX", nonce)
+	assert.NotNil(t, err)
+	assert.EqualValues(t, http.StatusBadRequest, err.Status())
+
+	_, err = AuthService.StartLogin("", "", "short")
+	assert.NotNil(t, err)
+	assert.EqualValues(t, http.StatusBadRequest, err.Status())
+}
+
+func TestAuthenticateUserChecksLoginState(t *testing.T) {
+	clock := time.Date(2021, 5, 1, 12, 0, 0, 0, time.UTC)
+	setup(t, &clock)
+
+	_, err := AuthService.AuthenticateUser("code", "", nonce)
+	assert.NotNil(t, err)
+	assert.EqualValues(t, http.StatusBadRequest, err.Status())
+
+	_, err = AuthService.AuthenticateUser("code", "forged", nonce)
+	assert.NotNil(t, err)
+	assert.EqualValues(t, "invalid login state", err.Message())
+
+	started, _ := AuthService.StartLogin("", "", nonce)
+	_, err = AuthService.AuthenticateUser("code", started.State, nonce)
+	assert.Nil(t, err)
+	_, err = AuthService.AuthenticateUser("code", started.State, nonce)
+	assert.NotNil(t, err, "a login can only be finished once")
+	assert.EqualValues(t, http.StatusForbidden, err.Status())
+
+	started, _ = AuthService.StartLogin("", "", nonce)
+	clock = clock.Add(11 * time.Minute)
+	_, err = AuthService.AuthenticateUser("code", started.State, nonce)
+	assert.NotNil(t, err)
+	assert.EqualValues(t, "login expired", err.Message())
+}
+
+func TestAuthenticateUserChecksLoginNonce(t *testing.T) {
+	clock := time.Date(2021, 5, 1, 12, 0, 0, 0, time.UTC)
+	setup(t, &clock)
+
+	// the attacker starts a login and sends the callback with their code and state to the victim, whose web app
+	// finishes it with its own nonce
+	started, _ := AuthService.StartLogin("", "", nonce)
+	_, err := AuthService.AuthenticateUser("code", started.State, "nonce-of-the-victim-web-app")
+	assert.NotNil(t, err)
+	assert.EqualValues(t, http.StatusForbidden, err.Status())
+	assert.EqualValues(t, "login was started by another client", err.Message())
+
+	_, err = AuthService.AuthenticateUser("code", started.State, nonce)
+	assert.NotNil(t, err, "a rejected login cannot be retried")
+
+	started, _ = AuthService.StartLogin("", "", nonce)
+	_, err = AuthService.AuthenticateUser("code", started.State, "")
+	assert.NotNil(t, err)
+	assert.EqualValues(t, http.StatusForbidden, err.Status())
+}
@@ -1,34 +1,50 @@
 package items_service
 
 import (
+	"context"
 	"fmt"
+	"github.com/lmurature/melist-api/src/api/config"
 	"github.com/lmurature/melist-api/src/api/domain/apierrors"
 	"github.com/lmurature/melist-api/src/api/domain/items"
+	"github.com/lmurature/melist-api/src/api/domain/sites"
 	items_provider "github.com/lmurature/melist-api/src/api/providers/items"
+	fanout_utils "github.com/lmurature/melist-api/src/api/utils/fanout"
 	"github.com/sirupsen/logrus"
 )
 
 type itemsService struct{}
 
 type itemsServiceInterface interface {
-	SearchItems(query string, offset int) (*items.ItemSearchResponse, apierrors.ApiError)
+	SearchItems(siteId string, search items.ItemSearch) (*items.ItemSearchResponse, apierrors.ApiError)
 	GetItemWithDescription(itemId string) (*items.Item, apierrors.ApiError)
-	GetItemHistory(itemId string) ([]items.ItemHistory, apierrors.ApiError)
+	GetItemsWithDescription(ctx context.Context, itemIds []string) []items.ItemResult
+	GetItemsByIds(itemIds []string, attributes []string) []items.ItemResult
+	GetItemHistory(itemId string, variationId int64) ([]items.ItemHistory, apierrors.ApiError)
 	GetItemReviews(itemId string, catalogProductId string) (*items.ItemReviewsResponse, apierrors.ApiError)
-	GetCategoryTrends(categoryId string) (*items.CategoryTrends, apierrors.ApiError)
+	GetCategoryTrends(siteId string, categoryId string) (*items.CategoryTrends, apierrors.ApiError)
 	GetItem(itemId string) (*items.Item, apierrors.ApiError)
 }
 
 var (
 	ItemsService itemsServiceInterface
+
+	detailsFanOut = fanout_utils.Options{Concurrency: config.ItemsFanOutConcurrency, Timeout: config.ItemsFanOutTimeout}
 )
 
 func init() {
 	ItemsService = &itemsService{}
 }
 
-func (s *itemsService) SearchItems(query string, offset int) (*items.ItemSearchResponse, apierrors.ApiError) {
-	result, err := items_provider.SearchItemsByQuery(query, offset)
+func (s *itemsService) SearchItems(siteId string, search items.ItemSearch) (*items.ItemSearchResponse, apierrors.ApiError) {
+	if err := sites.ValidateSiteId(siteId); err != nil {
+		return nil, err
+	}
+
+	if err := search.Validate(siteId); err != nil {
+		return nil, err
+	}
+
+	result, err := items_provider.SearchItemsByQuery(siteId, search)
 	if err != nil {
 		return nil, err
 	}
@@ -66,13 +82,7 @@ func (s *itemsService) GetItemWithDescription(itemId string) (*items.Item, apier
 		err = result.Error
 
 		if result.Item != nil {
-			category, _ := items_provider.GetCategory(result.Item.CategoryId)
-			if category != nil && len(category.PathFromRoot) > 0 {
-				result.Item.RootCategory = category.PathFromRoot[0]["name"]
-			} else {
-				result.Item.RootCategory = "Otros"
-			}
-
+			result.Item.RootCategory = getRootCategory(result.Item.CategoryId)
 			meliItem = result.Item
 		} else if result.Description != nil {
 			desc = result.Description
@@ -90,12 +100,48 @@ func (s *itemsService) GetItemWithDescription(itemId string) (*items.Item, apier
 	return meliItem, nil
 }
 
+// GetItemsWithDescription fetches the items with as few multiget requests as possible and then adds their
+// description and root category, which are fetched with a bounded fan-out.
+func (s *itemsService) GetItemsWithDescription(ctx context.Context, itemIds []string) []items.ItemResult {
+	results := items_provider.GetItemsByIds(itemIds, nil)
+
+	details := fanout_utils.Run(ctx, len(results), detailsFanOut, func(ctx context.Context, i int) (interface{}, apierrors.ApiError) {
+		if results[i].Error != nil {
+			return nil, results[i].Error
+		}
+
+		description, err := items_provider.GetItemDescription(results[i].Id)
+		if err != nil {
+			return nil, err
+		}
+
+		item := *results[i].Item
+		item.Description = description.PlainText
+		item.RootCategory = getRootCategory(item.CategoryId)
+		return &item, nil
+	})
+
+	for _, d := range details {
+		if d.Err != nil {
+			results[d.Index] = items.ItemResult{Id: results[d.Index].Id, Error: d.Err}
+			continue
+		}
+		results[d.Index].Item = d.Value.(*items.Item)
+	}
+
+	return results
+}
+
+func (s *itemsService) GetItemsByIds(itemIds []string, attributes []string) []items.ItemResult {
+	return items_provider.GetItemsByIds(itemIds, attributes)
+}
+
 func (s *itemsService) GetItem(itemId string) (*items.Item, apierrors.ApiError) {
 	return items_provider.GetItemById(itemId)
 }
 
-func (s *itemsService) GetItemHistory(itemId string) ([]items.ItemHistory, apierrors.ApiError) {
-	return items.ItemHistoryDao.GetItemHistory(itemId)
+func (s *itemsService) GetItemHistory(itemId string, variationId int64) ([]items.ItemHistory, apierrors.ApiError) {
+	return items.ItemHistoryDao.GetItemHistory(itemId, variationId)
 }
 
 func (s *itemsService) GetItemReviews(itemId string, catalogProductId string) (*items.ItemReviewsResponse, apierrors.ApiError) {
@@ -108,6 +154,25 @@ func (s *itemsService) GetItemReviews(itemId string, catalogProductId string) (*
 	return result, nil
 }
 
-func (s *itemsService) GetCategoryTrends(categoryId string) (*items.CategoryTrends, apierrors.ApiError) {
-	return items_provider.GetCategoryTrends(categoryId)
-}
\ No newline at end of file
+// GetCategoryTrends looks the category up in the site it belongs to, which must match siteId when both are known.
+func (s *itemsService) GetCategoryTrends(siteId string, categoryId string) (*items.CategoryTrends, apierrors.ApiError) {
+	categorySiteId := sites.SiteIdFromId(categoryId)
+	if siteId == "" {
+		siteId = sites.GetSiteIdOrDefault(categorySiteId)
+	}
+	if err := sites.ValidateSiteId(siteId); err != nil {
+		return nil, err
+	}
+	if categorySiteId != "" && categorySiteId != siteId {
+		return nil, apierrors.NewBadRequestApiError(fmt.Sprintf("category %s does not belong to site %s", categoryId, siteId))
+	}
+
+	return items_provider.GetCategoryTrends(siteId, categoryId)
+}
+func getRootCategory(categoryId string) string {
+	category, _ := items_provider.GetCategory(categoryId)
+	if category != nil && len(category.PathFromRoot) > 0 {
+		return category.PathFromRoot[0]["name"]
+	}
+	return "Otros"
+}
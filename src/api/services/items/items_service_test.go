@@ -2,6 +2,7 @@ package items_service
 
 import (
 	"github.com/lmurature/golang-restclient/rest"
+	"github.com/lmurature/melist-api/src/api/domain/items"
 	"github.com/stretchr/testify/assert"
 	"net/http"
 	"os"
@@ -24,7 +25,7 @@ func TestSearchItemsErrorSearch(t *testing.T) {
 
 	service := itemsService{}
 
-	result, err :=  service.SearchItems("Computadora", 0)
+	result, err :=  service.SearchItems("MLA", items.ItemSearch{Query: "Computadora"})
 
 	assert.Nil(t, result)
 	assert.NotNil(t, err)
@@ -43,7 +44,7 @@ func TestSearchItemsNoError(t *testing.T) {
 
 	service := itemsService{}
 
-	result, err :=  service.SearchItems("Computadora", 0)
+	result, err :=  service.SearchItems("MLA", items.ItemSearch{Query: "Computadora"})
 	assert.Nil(t, err)
 	assert.NotNil(t, result)
 	assert.EqualValues(t, "MLA", result.SiteId)
@@ -54,3 +55,115 @@ func TestSearchItemsNoError(t *testing.T) {
 	assert.EqualValues(t, 3, len(result.Result))
 	assert.EqualValues(t, "MLA907751590", result.Result[0].Id)
 }
+
+func TestSearchItemsInvalidSite(t *testing.T) {
+	service := itemsService{}
+
+	result, err := service.SearchItems("XXX", items.ItemSearch{Query: "Computadora"})
+
+	assert.Nil(t, result)
+	assert.NotNil(t, err)
+	assert.EqualValues(t, http.StatusBadRequest, err.Status())
+}
+
+func TestSearchItemsOnAnotherSite(t *testing.T) {
+	rest.FlushMockups()
+	rest.AddMockups(&rest.Mock{
+		URL:          "https://api.mercadolibre.com/sites/MLB/search?q=Computador&offset=0",
+		HTTPMethod:   http.MethodGet,
+		RespHTTPCode: http.StatusOK,
+		RespBody:     `{"site_id":"MLB","query":"Computador","paging":{"total":1,"offset":0,"limit":50},"results":[{"id":"MLB1","title":"Computador","category_id":"MLB1649","price":1999,"currency_id":"BRL"}]}`,
+	})
+
+	service := itemsService{}
+
+	result, err := service.SearchItems("MLB", items.ItemSearch{Query: "Computador"})
+	assert.Nil(t, err)
+	assert.NotNil(t, result)
+	assert.EqualValues(t, "MLB", result.SiteId)
+	assert.EqualValues(t, "BRL", result.Result[0].CurrencyId)
+}
+
+func TestGetCategoryTrendsUsesTheSiteOfTheCategory(t *testing.T) {
+	rest.FlushMockups()
+	rest.AddMockups(&rest.Mock{
+		URL:          "https://api.mercadolibre.com/trends/MLM/MLM1055",
+		HTTPMethod:   http.MethodGet,
+		RespHTTPCode: http.StatusOK,
+		RespBody:     `[{"keyword":"iphone 12","url":"https://listado.mercadolibre.com.mx/iphone-12"}]`,
+	})
+
+	service := itemsService{}
+
+	result, err := service.GetCategoryTrends("", "MLM1055")
+	assert.Nil(t, err)
+	assert.NotNil(t, result)
+
+	result, err = service.GetCategoryTrends("MLA", "MLM1055")
+	assert.Nil(t, result)
+	assert.NotNil(t, err)
+	assert.EqualValues(t, http.StatusBadRequest, err.Status())
+	assert.EqualValues(t, "category MLM1055 does not belong to site MLA", err.Message())
+}
+
+func TestSearchItemsWithFilters(t *testing.T) {
+	rest.FlushMockups()
+	rest.AddMockups(&rest.Mock{
+		URL:          "https://api.mercadolibre.com/sites/MLA/search?q=Computadora&offset=50&limit=10&sort=price_asc&category=MLA1649&condition=new&price=1000-50000&shipping_cost=free",
+		HTTPMethod:   http.MethodGet,
+		RespHTTPCode: http.StatusOK,
+		RespBody:     `{"site_id":"MLA","query":"Computadora","paging":{"total":61,"offset":50,"limit":10},"results":[{"id":"MLA879276614","title":"Computadora Cpu Intel Amd Doble Nucleo 8 Gb 500 Gb","category_id":"MLA1649","price":26590,"condition":"new"}],"sort":{"id":"price_asc","name":"Menor precio"},"available_sorts":[{"id":"relevance","name":"Más relevantes"},{"id":"price_desc","name":"Mayor precio"}],"filters":[{"id":"category","name":"Categorías","type":"text","values":[{"id":"MLA1649","name":"PC de Escritorio"}]}],"available_filters":[{"id":"official_store","name":"Tiendas oficiales","type":"text","values":[{"id":"all","name":"Todas las tiendas oficiales","results":12}]}]}`,
+	})
+
+	service := itemsService{}
+
+	result, err := service.SearchItems("MLA", items.ItemSearch{
+		Query:  "Computadora",
+		Offset: 50,
+		Limit:  10,
+		Sort:   items.SearchSortPriceAsc,
+		Filters: map[string]string{
+			items.SearchFilterShippingCost: "free",
+			items.SearchFilterCategory:     "MLA1649",
+			items.SearchFilterPrice:        "1000-50000",
+			items.SearchFilterCondition:    "new",
+		},
+	})
+	assert.Nil(t, err)
+	assert.NotNil(t, result)
+	assert.EqualValues(t, "price_asc", result.Sort.Id)
+	assert.EqualValues(t, 1, len(result.Filters))
+	assert.EqualValues(t, "MLA1649", result.Filters[0].Values[0].Id)
+	assert.EqualValues(t, 1, len(result.AvailableFilters))
+	assert.EqualValues(t, "official_store", result.AvailableFilters[0].Id)
+	assert.EqualValues(t, 12, result.AvailableFilters[0].Values[0].Results)
+}
+
+func TestSearchItemsInvalidFilters(t *testing.T) {
+	service := itemsService{}
+
+	result, err := service.SearchItems("MLA", items.ItemSearch{
+		Query: "Computadora",
+		Limit: 200,
+		Sort:  "cheapest",
+		Filters: map[string]string{
+			items.SearchFilterCategory: "MLB1649",
+			items.SearchFilterPrice:    "500-100",
+			items.SearchFilterSellerId: "abc",
+			"color":                    "red",
+		},
+	})
+
+	assert.Nil(t, result)
+	assert.NotNil(t, err)
+	assert.EqualValues(t, http.StatusBadRequest, err.Status())
+	assert.EqualValues(t, "invalid search", err.Message())
+	assert.EqualValues(t, []interface{}{
+		"limit must be between 1 and 50",
+		"invalid sort 'cheapest', expected one of relevance, price_asc, price_desc",
+		"invalid value 'MLB1649' for filter 'category'",
+		"unknown filter 'color'",
+		"invalid value '500-100' for filter 'price'",
+		"invalid value 'abc' for filter 'seller_id'",
+	}, []interface{}(err.Cause()))
+}
@@ -1,12 +1,16 @@
 package lists
 
 import (
+	"context"
 	"fmt"
+	"github.com/lmurature/melist-api/src/api/clients/database"
+	"github.com/lmurature/melist-api/src/api/domain/alerts"
 	"github.com/lmurature/melist-api/src/api/domain/apierrors"
 	"github.com/lmurature/melist-api/src/api/domain/items"
 	"github.com/lmurature/melist-api/src/api/domain/lists"
 	"github.com/lmurature/melist-api/src/api/domain/notifications"
 	"github.com/lmurature/melist-api/src/api/domain/share"
+	"github.com/lmurature/melist-api/src/api/domain/sites"
 	items_service "github.com/lmurature/melist-api/src/api/services/items"
 	users_service "github.com/lmurature/melist-api/src/api/services/users"
 	date_utils "github.com/lmurature/melist-api/src/api/utils/date"
@@ -15,6 +19,18 @@ import (
 	"net/http"
 )
 
+// ItemsInfo is how GetItemsFromList completes the items of a list with their Mercado Libre data.
+type ItemsInfo int
+
+const (
+	// InfoNone returns the items as they are stored.
+	InfoNone ItemsInfo = iota
+	// InfoPartial returns every item, marking with an error the ones that could not be fetched.
+	InfoPartial
+	// InfoStrict fails the whole list when any item cannot be fetched.
+	InfoStrict
+)
+
 type listsService struct{}
 
 type listsServiceInterface interface {
@@ -27,18 +43,23 @@ type listsServiceInterface interface {
 	GetMyLists(ownerId int64) (lists.Lists, apierrors.ApiError)
 	GetMySharedLists(userId int64, shareType string) (lists.Lists, apierrors.ApiError)
 	AddItemToList(itemId string, variationId int64, listId int64, callerId int64) apierrors.ApiError
-	GetItemsFromList(listId int64, callerId int64, info bool) (items.ItemListCollection, apierrors.ApiError)
-	DeleteItemFromList(itemId string, listId int64, callerId int64) (items.ItemListCollection, apierrors.ApiError)
-	CheckItem(itemId string, listId int64, callerId int64) (items.ItemListCollection, apierrors.ApiError)
-	UncheckItem(itemId string, listId int64, callerId int64) (items.ItemListCollection, apierrors.ApiError)
+	GetItemsFromList(ctx context.Context, listId int64, callerId int64, info ItemsInfo) (items.ItemListCollection, apierrors.ApiError)
+	DeleteItemFromList(itemId string, variationId int64, listId int64, callerId int64) (items.ItemListCollection, apierrors.ApiError)
+	CheckItem(itemId string, variationId int64, listId int64, callerId int64) (items.ItemListCollection, apierrors.ApiError)
+	UncheckItem(itemId string, variationId int64, listId int64, callerId int64) (items.ItemListCollection, apierrors.ApiError)
+	UpdateListItem(itemId string, variationId int64, listId int64, callerId int64, update items.ItemListUpdate) (*items.ItemListDto, apierrors.ApiError)
 	GetUserFavoriteLists(userId int64) (lists.Lists, apierrors.ApiError)
 	MakeFavoriteList(listId int64, userId int64) apierrors.ApiError
 	RemoveFavoriteList(listId int64, userId int64) apierrors.ApiError
-	GetUserPermissions(listId int64, callerId int64) (*share.ShareConfig, apierrors.ApiError)
+	GetUserPermissions(listId int64, callerId int64) (*lists.Permissions, apierrors.ApiError)
 	RevokeAccessToUser(listId int64, callerId int64, userId int64) (share.ShareConfigs, apierrors.ApiError)
-	GetListNotifications(listId int64, callerId int64) ([]notifications.Notification, apierrors.ApiError)
-	GetListItemStatus(itemId string, listId int64, callerId int64) (*items.ItemListDto, apierrors.ApiError)
+	GetListNotifications(listId int64, callerId int64, locale string) ([]notifications.Notification, apierrors.ApiError)
+	GetListItemStatus(itemId string, variationId int64, listId int64, callerId int64) (*items.ItemListDto, apierrors.ApiError)
 	GetAllLists() (lists.Lists, apierrors.ApiError)
+	DeleteList(listId int64, callerId int64) apierrors.ApiError
+	RestoreList(listId int64, callerId int64) (*lists.List, apierrors.ApiError)
+	GetTrashedLists(ownerId int64) (lists.Lists, apierrors.ApiError)
+	PurgeList(listId int64) apierrors.ApiError
 }
 
 var (
@@ -54,6 +75,16 @@ func (l listsService) CreateList(list lists.List) (*lists.List, apierrors.ApiErr
 		return nil, err
 	}
 
+	if list.SiteId == "" {
+		list.SiteId = sites.DefaultSiteId
+		if owner, err := users_service.UsersService.GetUserFromDb(list.OwnerId); err == nil {
+			list.SiteId = sites.GetSiteIdOrDefault(owner.SiteId)
+		}
+	}
+	if err := sites.ValidateSiteId(list.SiteId); err != nil {
+		return nil, err
+	}
+
 	list.DateCreated = date_utils.GetNowDateFormatted()
 
 	result, err := lists.ListDao.CreateList(list)
@@ -70,10 +101,22 @@ func (l listsService) UpdateList(updatedList lists.List, callerId int64) (*lists
 		return nil, err
 	}
 
-	if err := actualList.ValidateUpdatability(callerId); err != nil {
+	configs, err := share.GetListShareConfigs(updatedList.Id)
+	if err != nil {
 		return nil, err
 	}
 
+	if err := actualList.Authorize(callerId, lists.ActionRename, configs); err != nil {
+		return nil, err
+	}
+
+	// making a list public shows it to everyone, so admins can edit it but only its owner decides who sees it
+	if updatedList.Privacy != "" && updatedList.Privacy != actualList.Privacy {
+		if err := actualList.Authorize(callerId, lists.ActionChangePrivacy, configs); err != nil {
+			return nil, err
+		}
+	}
+
 	actualList.UpdateFields(updatedList)
 
 	result, err := lists.ListDao.UpdateList(*actualList)
@@ -90,14 +133,12 @@ func (l listsService) GetList(listId int64, callerId int64) (*lists.List, apierr
 		return nil, err
 	}
 
-	configs, err := share.ShareConfigDao.GetAllShareConfigsByList(listId)
+	configs, err := share.GetListShareConfigs(listId)
 	if err != nil {
-		if err.Status() != http.StatusNotFound {
-			return nil, err
-		}
+		return nil, err
 	}
 
-	if err := list.ValidateReadability(callerId, configs); err != nil {
+	if err := list.Authorize(callerId, lists.ActionRead, configs); err != nil {
 		return nil, err
 	}
 
@@ -110,7 +151,12 @@ func (l listsService) GiveAccessToUsers(listId int64, callerId int64, config sha
 		return nil, err
 	}
 
-	if err := list.ValidateUpdatability(callerId); err != nil {
+	actualConfigs, err := share.GetListShareConfigs(listId)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := list.Authorize(callerId, lists.ActionShare, actualConfigs); err != nil {
 		return nil, err
 	}
 
@@ -126,39 +172,38 @@ func (l listsService) GiveAccessToUsers(listId int64, callerId int64, config sha
 		return nil, apierrors.NewApiError("invalid request", "invalid request for sharing access to users", http.StatusBadRequest, errorCauseList)
 	}
 
-	actualConfigs, err := share.ShareConfigDao.GetAllShareConfigsByList(listId)
-	if err != nil {
-		if err.Status() != http.StatusNotFound {
+	for _, c := range config {
+		if err := list.AuthorizeShare(callerId, c.UserId, c.ShareType, actualConfigs); err != nil {
 			return nil, err
 		}
 	}
 
-	errorSaving := make(apierrors.CauseList, 0)
-	for i := range config {
+	err = database.RunInTransaction(func(uow *database.UnitOfWork) apierrors.ApiError {
+		shareConfigDao := share.ShareConfigDao.WithUnitOfWork(uow)
+		for i := range config {
 
-		var dbErr apierrors.ApiError
-		if slice.ShareConfigUserExists(actualConfigs, config[i].UserId) {
-			_, dbErr = share.ShareConfigDao.UpdateShareConfig(config[i])
-		} else {
-			_, dbErr = share.ShareConfigDao.CreateShareConfig(config[i])
-		}
+			var dbErr apierrors.ApiError
+			if slice.ShareConfigUserExists(actualConfigs, config[i].UserId) {
+				_, dbErr = shareConfigDao.UpdateShareConfig(config[i])
+			} else {
+				_, dbErr = shareConfigDao.CreateShareConfig(config[i])
+			}
 
-		if dbErr != nil {
-			logrus.Error("error while trying to save share config", err)
-			errorSaving = append(errorSaving, err.Message())
+			if dbErr != nil {
+				logrus.Error("error while trying to save share config", dbErr)
+				return apierrors.NewApiError("error while trying to save share configs", "database error",
+					http.StatusInternalServerError, apierrors.CauseList{dbErr.Message()})
+			}
 		}
-
-	}
-
-	if len(errorSaving) > 0 {
-		return nil, apierrors.NewApiError("error while trying to save share configs", "database error", http.StatusInternalServerError, errorSaving)
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	updatedConfigs, err := share.ShareConfigDao.GetAllShareConfigsByList(listId)
+	updatedConfigs, err := share.GetListShareConfigs(listId)
 	if err != nil {
-		if err.Status() != http.StatusNotFound {
-			return nil, err
-		}
+		return nil, err
 	}
 
 	return updatedConfigs, nil
@@ -174,12 +219,13 @@ func (l listsService) GetMyLists(ownerId int64) (lists.Lists, apierrors.ApiError
 		return nil, err
 	}
 
+	unread, err := notifications.NotificationsDao.CountUnreadByList(ownerId)
+	if err != nil {
+		return nil, err
+	}
+
 	for i := range lists {
-		listNotif, err := notifications.NotificationsDao.GetListNotifications(lists[i].Id)
-		if err != nil {
-			return nil, err
-		}
-		lists[i].Notifications = len(listNotif)
+		lists[i].Notifications = unread[lists[i].Id]
 	}
 
 	return lists, nil
@@ -196,18 +242,23 @@ func (l listsService) GetMySharedLists(userId int64, shareType string) (lists.Li
 		if shareType == "" || shareType == c.ShareType {
 			listDto, err := lists.ListDao.GetList(c.ListId)
 			if err != nil {
+				if err.Status() == http.StatusNotFound {
+					// the list is in its owner's trash bin
+					continue
+				}
 				return nil, err
 			}
 			result = append(result, *listDto)
 		}
 	}
 
+	unread, err := notifications.NotificationsDao.CountUnreadByList(userId)
+	if err != nil {
+		return nil, err
+	}
+
 	for i := range result {
-		listNotif, err := notifications.NotificationsDao.GetListNotifications(result[i].Id)
-		if err != nil {
-			return nil, err
-		}
-		result[i].Notifications = len(listNotif)
+		result[i].Notifications = unread[result[i].Id]
 	}
 
 	return result, nil
@@ -219,15 +270,19 @@ func (l listsService) GetListShareConfigs(listId int64, callerId int64) (share.S
 		return nil, err
 	}
 
-	if list.OwnerId != callerId {
-		return nil, apierrors.NewUnauthorizedApiError("you have no access to this list's share config")
+	configList, err := share.GetListShareConfigs(listId)
+	if err != nil {
+		return nil, err
 	}
 
-	configList, err := share.ShareConfigDao.GetAllShareConfigsByList(listId)
-	if err != nil {
+	if err := list.Authorize(callerId, lists.ActionViewShares, configList); err != nil {
 		return nil, err
 	}
 
+	if len(configList) == 0 {
+		return nil, apierrors.NewNotFoundApiError("no share configs found for list")
+	}
+
 	return configList, nil
 }
 
@@ -237,14 +292,16 @@ func (l listsService) AddItemToList(itemId string, variationId int64, listId int
 		return err
 	}
 
-	actualConfigs, err := share.ShareConfigDao.GetAllShareConfigsByList(listId)
+	actualConfigs, err := share.GetListShareConfigs(listId)
 	if err != nil {
-		if err.Status() != http.StatusNotFound {
-			return err
-		}
+		return err
 	}
 
-	if err := list.ValidateAddItems(callerId, actualConfigs); err != nil {
+	if err := list.Authorize(callerId, lists.ActionAddItems, actualConfigs); err != nil {
+		return err
+	}
+
+	if err := list.ValidateItemSite(itemId); err != nil {
 		return err
 	}
 
@@ -254,53 +311,66 @@ func (l listsService) AddItemToList(itemId string, variationId int64, listId int
 		return err
 	}
 
-	if itemCollection.ContainsItem(itemId) {
+	if itemCollection.ContainsItem(itemId, variationId) {
+		if variationId != 0 {
+			return apierrors.NewBadRequestApiError(fmt.Sprintf("item %s with variation %d is already in the list", itemId, variationId))
+		}
 		return apierrors.NewBadRequestApiError(fmt.Sprintf("item %s is already in the list", itemId))
 	}
 
-	// insert into item table
-	items.ItemDao.InsertItem(itemId)
+	variationName, err := getVariationName(itemId, variationId)
+	if err != nil {
+		return err
+	}
+
+	userData, err := users_service.UsersService.GetMeliUser(callerId)
+	if err != nil {
+		return err
+	}
 
 	itemListDto := items.ItemListDto{
 		ItemId:      itemId,
 		ListId:      listId,
 		Status:      items.StatusNotChecked,
 		VariationId: variationId,
+		Quantity:    1,
+		Priority:    items.PriorityNormal,
 		UserId:      callerId,
 	}
 
-	_, err = items.ItemListDao.InsertItemToList(itemListDto)
-	if err != nil {
-		return err
-	}
+	return database.RunInTransaction(func(uow *database.UnitOfWork) apierrors.ApiError {
+		// insert into item table, it fails without side effects if another list already has the item
+		items.ItemDao.WithUnitOfWork(uow).InsertItem(itemId)
 
-	userData, err := users_service.UsersService.GetMeliUser(callerId)
-	if err != nil {
-		return err
-	}
+		if _, err := items.ItemListDao.WithUnitOfWork(uow).InsertItemToList(itemListDto); err != nil {
+			return err
+		}
 
-	result, err := notifications.NotificationsDao.SaveNotification(*notifications.NewAddedItemToListNotification(listId, itemId, userData.Nickname))
-	if err == nil {
-		logrus.Info(fmt.Sprintf("successfully notificated item %s on list %d (%v)", itemId, listId, result))
-	}
+		notification := notifications.NewAddedItemToListNotification(listId, itemId, userData.Nickname).ForVariation(variationId, variationName)
+		result, err := notifications.NotificationsDao.WithUnitOfWork(uow).SaveNotification(*notification)
+		if err != nil {
+			return err
+		}
 
-	return nil
+		uow.OnCommit(func() {
+			logrus.Info(fmt.Sprintf("successfully notificated item %s on list %d (%v)", itemId, listId, result))
+		})
+		return nil
+	})
 }
 
-func (l listsService) GetItemsFromList(listId int64, callerId int64, info bool) (items.ItemListCollection, apierrors.ApiError) {
+func (l listsService) GetItemsFromList(ctx context.Context, listId int64, callerId int64, info ItemsInfo) (items.ItemListCollection, apierrors.ApiError) {
 	list, err := lists.ListDao.GetList(listId)
 	if err != nil {
 		return nil, err
 	}
 
-	actualConfigs, err := share.ShareConfigDao.GetAllShareConfigsByList(listId)
+	actualConfigs, err := share.GetListShareConfigs(listId)
 	if err != nil {
-		if err.Status() != http.StatusNotFound {
-			return nil, err
-		}
+		return nil, err
 	}
 
-	if err := list.ValidateReadability(callerId, actualConfigs); err != nil {
+	if err := list.Authorize(callerId, lists.ActionRead, actualConfigs); err != nil {
 		return nil, err
 	}
 
@@ -309,77 +379,92 @@ func (l listsService) GetItemsFromList(listId int64, callerId int64, info bool)
 		return nil, err
 	}
 
-	if info {
-		input := make(chan items.ItemConcurrent, len(itemListCollection))
-		defer close(input)
+	if info == InfoNone {
+		return itemListCollection, nil
+	}
 
-		for i := range itemListCollection {
-			go func(id string, index int, output chan items.ItemConcurrent) {
-				item, err := items_service.ItemsService.GetItemWithDescription(id)
-				output <- items.ItemConcurrent{
-					Item:      item,
-					ItemError: err,
-					ListIndex: index,
-				}
-			}(itemListCollection[i].ItemId, i, input)
+	itemIds := make([]string, len(itemListCollection))
+	for i := range itemListCollection {
+		itemIds[i] = itemListCollection[i].ItemId
+	}
+
+	results := items_service.ItemsService.GetItemsWithDescription(ctx, itemIds)
+	for i, result := range results {
+		item, err := result.Item, result.Error
+		if err == nil && itemListCollection[i].VariationId != 0 {
+			item, err = forVariation(item, itemListCollection[i].VariationId)
 		}
 
-		for i := 0; i < len(itemListCollection); i++ {
-			result := <-input
-			if result.ItemError != nil {
-				return nil, result.ItemError
+		if err != nil {
+			if info == InfoStrict {
+				return nil, err
 			}
-
-			itemListCollection[result.ListIndex].MeliItem = result.Item
+			logrus.Warn(fmt.Sprintf("error getting item %s of list %d", result.Id, listId), err)
+			itemListCollection[i].Error = err
+			continue
 		}
+		itemListCollection[i].MeliItem = item
 	}
 
 	return itemListCollection, nil
 }
 
-func (l listsService) DeleteItemFromList(itemId string, listId int64, callerId int64) (items.ItemListCollection, apierrors.ApiError) {
+// forVariation returns the item as the variation chosen in the list is sold, failing when the seller removed it.
+func forVariation(item *items.Item, variationId int64) (*items.Item, apierrors.ApiError) {
+	result, ok := item.ForVariation(variationId)
+	if !ok {
+		return nil, apierrors.NewNotFoundApiError(fmt.Sprintf("variation %d of item %s not found", variationId, item.Id))
+	}
+	return result, nil
+}
+
+func (l listsService) DeleteItemFromList(itemId string, variationId int64, listId int64, callerId int64) (items.ItemListCollection, apierrors.ApiError) {
 	list, err := lists.ListDao.GetList(listId)
 	if err != nil {
 		return nil, err
 	}
 
-	actualConfigs, err := share.ShareConfigDao.GetAllShareConfigsByList(listId)
+	actualConfigs, err := share.GetListShareConfigs(listId)
 	if err != nil {
-		if err.Status() != http.StatusNotFound {
-			return nil, err
-		}
+		return nil, err
+	}
+
+	if err := list.Authorize(callerId, lists.ActionRemoveItems, actualConfigs); err != nil {
+		return nil, err
 	}
 
-	if err := list.ValidateAddItems(callerId, actualConfigs); err != nil {
+	if err := validateListHasItem(listId, itemId, variationId); err != nil {
 		return nil, err
 	}
 
-	err = items.ItemListDao.DeleteItemFromList(itemId, listId)
+	err = items.ItemListDao.DeleteItemFromList(itemId, variationId, listId)
 	if err != nil {
 		return nil, err
 	}
 
-	return l.GetItemsFromList(listId, callerId, true)
+	return l.GetItemsFromList(context.Background(), listId, callerId, InfoPartial)
 }
 
-func (l listsService) CheckItem(itemId string, listId int64, callerId int64) (items.ItemListCollection, apierrors.ApiError) {
+func (l listsService) CheckItem(itemId string, variationId int64, listId int64, callerId int64) (items.ItemListCollection, apierrors.ApiError) {
 	list, err := lists.ListDao.GetList(listId)
 	if err != nil {
 		return nil, err
 	}
 
-	actualConfigs, err := share.ShareConfigDao.GetAllShareConfigsByList(listId)
+	actualConfigs, err := share.GetListShareConfigs(listId)
 	if err != nil {
-		if err.Status() != http.StatusNotFound {
-			return nil, err
-		}
+		return nil, err
+	}
+
+	if err := list.Authorize(callerId, lists.ActionCheckItems, actualConfigs); err != nil {
+		return nil, err
 	}
 
-	if err := list.ValidateCheckItems(callerId, actualConfigs); err != nil {
+	if err := validateListHasItem(listId, itemId, variationId); err != nil {
 		return nil, err
 	}
 
-	if err := items.ItemListDao.UpdateItemStatus(itemId, listId, items.StatusChecked); err != nil {
+	if err := items.ItemListDao.UpdateItemStatus(itemId, variationId, listId, items.StatusChecked); err != nil {
 		return nil, err
 	}
 
@@ -388,32 +473,41 @@ func (l listsService) CheckItem(itemId string, listId int64, callerId int64) (it
 		return nil, err
 	}
 
-	result, err := notifications.NotificationsDao.SaveNotification(*notifications.NewCheckedItemNotification(listId, itemId, userData.Nickname))
+	variationName, err := getVariationName(itemId, variationId)
+	if err != nil {
+		// the item is checked already, its notification only misses the name of the variation
+		logrus.Warn(fmt.Sprintf("error getting variation %d of item %s for its notification", variationId, itemId), err)
+	}
+
+	notification := notifications.NewCheckedItemNotification(listId, itemId, userData.Nickname).ForVariation(variationId, variationName)
+	result, err := notifications.NotificationsDao.SaveNotification(*notification)
 	if err == nil {
 		logrus.Info(fmt.Sprintf("successfully notificated checked item %s on list %d (%v)", itemId, listId, result))
 	}
 
-	return l.GetItemsFromList(listId, callerId, true)
+	return l.GetItemsFromList(context.Background(), listId, callerId, InfoPartial)
 }
 
-func (l listsService) UncheckItem(itemId string, listId int64, callerId int64) (items.ItemListCollection, apierrors.ApiError) {
+func (l listsService) UncheckItem(itemId string, variationId int64, listId int64, callerId int64) (items.ItemListCollection, apierrors.ApiError) {
 	list, err := lists.ListDao.GetList(listId)
 	if err != nil {
 		return nil, err
 	}
 
-	actualConfigs, err := share.ShareConfigDao.GetAllShareConfigsByList(listId)
+	actualConfigs, err := share.GetListShareConfigs(listId)
 	if err != nil {
-		if err.Status() != http.StatusNotFound {
-			return nil, err
-		}
+		return nil, err
+	}
+
+	if err := list.Authorize(callerId, lists.ActionCheckItems, actualConfigs); err != nil {
+		return nil, err
 	}
 
-	if err := list.ValidateCheckItems(callerId, actualConfigs); err != nil {
+	if err := validateListHasItem(listId, itemId, variationId); err != nil {
 		return nil, err
 	}
 
-	if err := items.ItemListDao.UpdateItemStatus(itemId, listId, items.StatusNotChecked); err != nil {
+	if err := items.ItemListDao.UpdateItemStatus(itemId, variationId, listId, items.StatusNotChecked); err != nil {
 		return nil, err
 	}
 
@@ -422,12 +516,58 @@ func (l listsService) UncheckItem(itemId string, listId int64, callerId int64) (
 		return nil, err
 	}
 
-	result, err := notifications.NotificationsDao.SaveNotification(*notifications.NewUncheckedItemNotification(listId, itemId, userData.Nickname))
+	variationName, err := getVariationName(itemId, variationId)
+	if err != nil {
+		// the item is unchecked already, its notification only misses the name of the variation
+		logrus.Warn(fmt.Sprintf("error getting variation %d of item %s for its notification", variationId, itemId), err)
+	}
+
+	notification := notifications.NewUncheckedItemNotification(listId, itemId, userData.Nickname).ForVariation(variationId, variationName)
+	result, err := notifications.NotificationsDao.SaveNotification(*notification)
 	if err == nil {
 		logrus.Info(fmt.Sprintf("successfully notificated unchecked item %s on list %d (%v)", itemId, listId, result))
 	}
 
-	return l.GetItemsFromList(listId, callerId, true)
+	return l.GetItemsFromList(context.Background(), listId, callerId, InfoPartial)
+}
+
+// UpdateListItem changes the quantity, note, target price or priority of an item of the list. Those who can add items
+// to the list can change them.
+func (l listsService) UpdateListItem(itemId string, variationId int64, listId int64, callerId int64, update items.ItemListUpdate) (*items.ItemListDto, apierrors.ApiError) {
+	if err := update.Validate(); err != nil {
+		return nil, err
+	}
+
+	list, err := lists.ListDao.GetList(listId)
+	if err != nil {
+		return nil, err
+	}
+
+	actualConfigs, err := share.GetListShareConfigs(listId)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := list.Authorize(callerId, lists.ActionAddItems, actualConfigs); err != nil {
+		return nil, err
+	}
+
+	listItems, err := items.ItemListDao.GetItemsFromList(listId)
+	if err != nil {
+		return nil, err
+	}
+
+	listItem := listItems.GetItem(itemId, variationId)
+	if listItem == nil {
+		return nil, apierrors.NewNotFoundApiError(fmt.Sprintf("item %s not found in list %d", itemId, listId))
+	}
+
+	listItem.UpdateFields(update)
+	if err := items.ItemListDao.UpdateItemDetails(*listItem); err != nil {
+		return nil, err
+	}
+
+	return listItem, nil
 }
 
 func (l listsService) GetUserFavoriteLists(userId int64) (lists.Lists, apierrors.ApiError) {
@@ -475,42 +615,18 @@ func (l listsService) RemoveFavoriteList(listId int64, userId int64) apierrors.A
 	return lists.ListDao.RemoveFavoriteList(listId, userId)
 }
 
-func (l listsService) GetUserPermissions(listId int64, callerId int64) (*share.ShareConfig, apierrors.ApiError) {
+func (l listsService) GetUserPermissions(listId int64, callerId int64) (*lists.Permissions, apierrors.ApiError) {
 	list, err := lists.ListDao.GetList(listId)
 	if err != nil {
 		return nil, err
 	}
 
-	if list.OwnerId == callerId {
-		return &share.ShareConfig{
-			ListId:    listId,
-			UserId:    callerId,
-			ShareType: share.ShareTypeAdmin,
-		}, nil
-	}
-
-	shareConfigs, err := share.ShareConfigDao.GetAllShareConfigsByList(listId)
+	configs, err := share.GetListShareConfigs(listId)
 	if err != nil {
-		if err.Status() != http.StatusNotFound {
-			return nil, err
-		}
-	}
-
-	for _, conf := range shareConfigs {
-		if conf.UserId == callerId {
-			return &conf, nil
-		}
+		return nil, err
 	}
 
-	if list.Privacy == lists.PrivacyTypePublic {
-		return &share.ShareConfig{
-			UserId:    callerId,
-			ListId:    listId,
-			ShareType: share.ShareTypeRead,
-		}, nil
-	} else {
-		return nil, apierrors.NewForbiddenApiError("you have no access to this list")
-	}
+	return list.PermissionsOf(callerId, configs)
 }
 
 func (l listsService) RevokeAccessToUser(listId int64, callerId int64, userId int64) (share.ShareConfigs, apierrors.ApiError) {
@@ -519,12 +635,12 @@ func (l listsService) RevokeAccessToUser(listId int64, callerId int64, userId in
 		return nil, err
 	}
 
-	if err := list.ValidateUpdatability(callerId); err != nil {
+	shareConfigs, err := share.GetListShareConfigs(listId)
+	if err != nil {
 		return nil, err
 	}
 
-	shareConfigs, err := share.ShareConfigDao.GetAllShareConfigsByList(listId)
-	if err != nil {
+	if err := list.AuthorizeShare(callerId, userId, "", shareConfigs); err != nil {
 		return nil, err
 	}
 
@@ -546,41 +662,133 @@ func (l listsService) RevokeAccessToUser(listId int64, callerId int64, userId in
 	return append(shareConfigs[:deletedIndex], shareConfigs[deletedIndex+1:]...), nil
 }
 
-func (l listsService) GetListNotifications(listId int64, callerId int64) ([]notifications.Notification, apierrors.ApiError) {
+func (l listsService) GetListNotifications(listId int64, callerId int64, locale string) ([]notifications.Notification, apierrors.ApiError) {
 	list, err := lists.ListDao.GetList(listId)
 	if err != nil {
 		return nil, err
 	}
 
-	configs, err := share.ShareConfigDao.GetAllShareConfigsByList(listId)
+	configs, err := share.GetListShareConfigs(listId)
 	if err != nil {
-		if err.Status() != http.StatusNotFound {
-			return nil, err
-		}
+		return nil, err
+	}
+
+	if err := list.Authorize(callerId, lists.ActionRead, configs); err != nil {
+		return nil, err
 	}
 
-	if err := list.ValidateReadability(callerId, configs); err != nil {
+	result, err := notifications.NotificationsDao.GetListNotifications(listId)
+	if err != nil {
 		return nil, err
 	}
 
-	return notifications.NotificationsDao.GetListNotifications(listId)
+	return notifications.Localize(notifications.VisibleTo(result, callerId), locale), nil
 }
 
-func (l listsService) GetListItemStatus(itemId string, listId int64, callerId int64) (*items.ItemListDto, apierrors.ApiError) {
-	listItems, err := l.GetItemsFromList(listId, callerId, false)
+func (l listsService) GetListItemStatus(itemId string, variationId int64, listId int64, callerId int64) (*items.ItemListDto, apierrors.ApiError) {
+	listItems, err := l.GetItemsFromList(context.Background(), listId, callerId, InfoNone)
 	if err != nil {
 		return nil, err
 	}
 
-	for _, li := range listItems {
-		if li.ItemId == itemId {
-			return &li, nil
-		}
+	if li := listItems.GetItem(itemId, variationId); li != nil {
+		return li, nil
 	}
 
 	return nil, apierrors.NewNotFoundApiError(fmt.Sprintf("item %s not found in list %d", itemId, listId))
 }
 
+// getVariationName returns the name of the variation of the item, which is empty for items added without a variation,
+// failing with bad request when the item has no such variation.
+func getVariationName(itemId string, variationId int64) (string, apierrors.ApiError) {
+	if variationId == 0 {
+		return "", nil
+	}
+
+	item, err := items_service.ItemsService.GetItem(itemId)
+	if err != nil {
+		return "", err
+	}
+
+	variation := item.GetVariation(variationId)
+	if variation == nil {
+		return "", apierrors.NewBadRequestApiError(fmt.Sprintf("item %s has no variation %d", itemId, variationId))
+	}
+	return variation.Name(), nil
+}
+
+// validateListHasItem fails with not found when the list does not have the item with that variation.
+func validateListHasItem(listId int64, itemId string, variationId int64) apierrors.ApiError {
+	listItems, err := items.ItemListDao.GetItemsFromList(listId)
+	if err != nil {
+		return err
+	}
+
+	if !listItems.ContainsItem(itemId, variationId) {
+		return apierrors.NewNotFoundApiError(fmt.Sprintf("item %s not found in list %d", itemId, listId))
+	}
+	return nil
+}
+
 func (l listsService) GetAllLists() (lists.Lists, apierrors.ApiError) {
 	return lists.ListDao.GetAllLists()
 }
+
+// DeleteList moves the list to its owner's trash bin, from where it can be restored until it is purged.
+func (l listsService) DeleteList(listId int64, callerId int64) apierrors.ApiError {
+	list, err := lists.ListDao.GetList(listId)
+	if err != nil {
+		return err
+	}
+
+	if err := list.Authorize(callerId, lists.ActionDelete, nil); err != nil {
+		return err
+	}
+
+	return lists.ListDao.TrashList(listId, date_utils.GetNowDateFormatted())
+}
+
+func (l listsService) RestoreList(listId int64, callerId int64) (*lists.List, apierrors.ApiError) {
+	list, err := lists.ListDao.GetTrashedList(listId)
+	if err != nil {
+		return nil, err
+	}
+
+	// only the owner deletes the list, so only the owner gets it back
+	if err := list.Authorize(callerId, lists.ActionDelete, nil); err != nil {
+		return nil, err
+	}
+
+	if err := lists.ListDao.RestoreList(listId); err != nil {
+		return nil, err
+	}
+
+	return lists.ListDao.GetList(listId)
+}
+
+func (l listsService) GetTrashedLists(ownerId int64) (lists.Lists, apierrors.ApiError) {
+	return lists.ListDao.GetTrashedListsFromOwner(ownerId)
+}
+
+// PurgeList hard deletes a trashed list together with every row that references it.
+func (l listsService) PurgeList(listId int64) apierrors.ApiError {
+	if _, err := lists.ListDao.GetTrashedList(listId); err != nil {
+		return err
+	}
+
+	return database.RunInTransaction(func(uow *database.UnitOfWork) apierrors.ApiError {
+		if err := items.ItemListDao.WithUnitOfWork(uow).DeleteAllItemsFromList(listId); err != nil {
+			return err
+		}
+		if err := share.ShareConfigDao.WithUnitOfWork(uow).DeleteListShareConfigs(listId); err != nil {
+			return err
+		}
+		if err := notifications.NotificationsDao.WithUnitOfWork(uow).DeleteListNotifications(listId); err != nil {
+			return err
+		}
+		if err := alerts.AlertRuleDao.WithUnitOfWork(uow).DeleteListRules(listId); err != nil {
+			return err
+		}
+		return lists.ListDao.WithUnitOfWork(uow).DeleteList(listId)
+	})
+}
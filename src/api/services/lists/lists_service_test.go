@@ -0,0 +1,477 @@
+package lists
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"testing"
+
+	"github.com/lmurature/golang-restclient/rest"
+	"github.com/lmurature/melist-api/src/api/domain/items"
+	"github.com/lmurature/melist-api/src/api/domain/lists"
+	"github.com/lmurature/melist-api/src/api/domain/notifications"
+	"github.com/lmurature/melist-api/src/api/domain/share"
+	"github.com/lmurature/melist-api/src/api/domain/sites"
+	"github.com/lmurature/melist-api/src/api/domain/users"
+	items_provider "github.com/lmurature/melist-api/src/api/providers/items"
+	test_utils "github.com/lmurature/melist-api/src/api/utils/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMain(m *testing.M) {
+	rest.StartMockupServer()
+	os.Exit(m.Run())
+}
+
+func addItemMockups() {
+	rest.FlushMockups()
+	rest.AddMockups(&rest.Mock{
+		URL:          "https://api.mercadolibre.com/users/1",
+		HTTPMethod:   http.MethodGet,
+		RespHTTPCode: http.StatusOK,
+		RespBody:     `{"id": 1, "nickname": "owner"}`,
+	}, &rest.Mock{
+		URL:          "https://api.mercadolibre.com/users/2",
+		HTTPMethod:   http.MethodGet,
+		RespHTTPCode: http.StatusOK,
+		RespBody:     `{"id": 2, "nickname": "collaborator"}`,
+	}, &rest.Mock{
+		URL:          "https://api.mercadolibre.com/items/MLA1",
+		HTTPMethod:   http.MethodGet,
+		RespHTTPCode: http.StatusOK,
+		RespBody:     `{"id":"MLA1","title":"Test item - DO NOT BUY","category_id":"MLA1000","price":500,"available_quantity":9,"status":"active"}`,
+	}, &rest.Mock{
+		URL:          "https://api.mercadolibre.com/items?ids=MLA1",
+		HTTPMethod:   http.MethodGet,
+		RespHTTPCode: http.StatusOK,
+		RespBody:     `[{"code":200,"body":{"id":"MLA1","title":"Test item - DO NOT BUY","category_id":"MLA1000","price":500,"available_quantity":9,"status":"active"}}]`,
+	}, &rest.Mock{
+		URL:          "https://api.mercadolibre.com/items/MLA1/description",
+		HTTPMethod:   http.MethodGet,
+		RespHTTPCode: http.StatusOK,
+		RespBody:     `{"plain_text":"this is the description"}`,
+	}, &rest.Mock{
+		URL:          "https://api.mercadolibre.com/categories/MLA1000",
+		HTTPMethod:   http.MethodGet,
+		RespHTTPCode: http.StatusOK,
+		RespBody:     `{"id":"MLA1000","name":"Electrónica","path_from_root":[{"id":"MLA1000","name":"Electrónica"}]}`,
+	})
+}
+
+func TestCreateListInvalid(t *testing.T) {
+	test_utils.SetupUsers()
+
+	result, err := ListsService.CreateList(lists.List{OwnerId: test_utils.OwnerId, Title: "groceries", Privacy: "secret"})
+
+	assert.Nil(t, result)
+	assert.NotNil(t, err)
+	assert.EqualValues(t, http.StatusBadRequest, err.Status())
+}
+
+func TestCreateListTakesTheSiteOfItsOwner(t *testing.T) {
+	test_utils.SetupUsers()
+	users.UserDao.UpdateUser(users.MelistUser{Id: test_utils.OwnerId, Nickname: "owner", Email: "owner@melist.com", SiteId: sites.SiteBrazil})
+
+	list, err := ListsService.CreateList(lists.List{OwnerId: test_utils.OwnerId, Title: "mercado", Privacy: lists.PrivacyTypePrivate})
+	assert.Nil(t, err)
+	assert.EqualValues(t, sites.SiteBrazil, list.SiteId)
+
+	list, err = ListsService.CreateList(lists.List{OwnerId: test_utils.OwnerId, Title: "súper", Privacy: lists.PrivacyTypePrivate, SiteId: sites.SiteMexico})
+	assert.Nil(t, err)
+	assert.EqualValues(t, sites.SiteMexico, list.SiteId)
+
+	list, err = ListsService.CreateList(lists.List{OwnerId: test_utils.OwnerId, Title: "groceries", Privacy: lists.PrivacyTypePrivate, SiteId: "XXX"})
+	assert.Nil(t, list)
+	assert.NotNil(t, err)
+	assert.EqualValues(t, http.StatusBadRequest, err.Status())
+}
+
+func TestAddItemFromAnotherSite(t *testing.T) {
+	test_utils.SetupUsers()
+	addItemMockups()
+
+	list, err := ListsService.CreateList(lists.List{OwnerId: test_utils.OwnerId, Title: "mercado", Privacy: lists.PrivacyTypePrivate, SiteId: sites.SiteBrazil})
+	assert.Nil(t, err)
+
+	err = ListsService.AddItemToList("MLA1", 0, list.Id, test_utils.OwnerId)
+	assert.NotNil(t, err)
+	assert.EqualValues(t, http.StatusBadRequest, err.Status())
+	assert.EqualValues(t, "item MLA1 does not belong to site MLB of the list", err.Message())
+}
+
+func TestGetItemsFromListMarksItemsThatCannotBeFetched(t *testing.T) {
+	test_utils.SetupUsers()
+	addItemMockups()
+	items_provider.FlushCache()
+	rest.AddMockups(&rest.Mock{
+		URL:          "https://api.mercadolibre.com/items?ids=MLA1,MLA2",
+		HTTPMethod:   http.MethodGet,
+		RespHTTPCode: http.StatusOK,
+		RespBody:     `[{"code":200,"body":{"id":"MLA1","title":"Test item - DO NOT BUY","category_id":"MLA1000","price":500}},{"code":404,"body":{"message":"Item with id MLA2 not found","error":"not_found","status":404,"cause":[]}}]`,
+	})
+
+	list, err := ListsService.CreateList(lists.List{OwnerId: test_utils.OwnerId, Title: "groceries", Privacy: lists.PrivacyTypePublic})
+	assert.Nil(t, err)
+	assert.Nil(t, ListsService.AddItemToList("MLA1", 0, list.Id, test_utils.CollaboratorId))
+	assert.Nil(t, ListsService.AddItemToList("MLA2", 0, list.Id, test_utils.CollaboratorId))
+
+	listItems, err := ListsService.GetItemsFromList(context.Background(), list.Id, test_utils.OwnerId, InfoPartial)
+	assert.Nil(t, err)
+	assert.EqualValues(t, 2, len(listItems))
+	for _, item := range listItems {
+		if item.ItemId == "MLA1" {
+			assert.Nil(t, item.Error)
+			assert.EqualValues(t, "Test item - DO NOT BUY", item.MeliItem.Title)
+		} else {
+			assert.Nil(t, item.MeliItem)
+			assert.NotNil(t, item.Error)
+			assert.EqualValues(t, http.StatusNotFound, item.Error.Status())
+		}
+	}
+
+	listItems, err = ListsService.GetItemsFromList(context.Background(), list.Id, test_utils.OwnerId, InfoStrict)
+	assert.Nil(t, listItems)
+	assert.NotNil(t, err)
+	assert.EqualValues(t, "Item with id MLA2 not found", err.Message())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	listItems, err = ListsService.GetItemsFromList(ctx, list.Id, test_utils.OwnerId, InfoPartial)
+	assert.Nil(t, err)
+	assert.EqualValues(t, 2, len(listItems))
+	assert.NotNil(t, listItems[0].Error)
+	assert.NotNil(t, listItems[1].Error)
+}
+
+func TestPrivateListIsNotReadableByStrangers(t *testing.T) {
+	test_utils.SetupUsers()
+
+	list, err := ListsService.CreateList(lists.List{OwnerId: test_utils.OwnerId, Title: "groceries", Privacy: lists.PrivacyTypePrivate})
+	assert.Nil(t, err)
+
+	result, err := ListsService.GetList(list.Id, test_utils.StrangerId)
+	assert.Nil(t, result)
+	assert.NotNil(t, err)
+	assert.EqualValues(t, http.StatusForbidden, err.Status())
+
+	result, err = ListsService.GetList(list.Id, test_utils.OwnerId)
+	assert.Nil(t, err)
+	assert.EqualValues(t, "groceries", result.Title)
+}
+
+func TestSharedListItemsAndNotifications(t *testing.T) {
+	test_utils.SetupUsers()
+	addItemMockups()
+
+	list, err := ListsService.CreateList(lists.List{OwnerId: test_utils.OwnerId, Title: "groceries", Privacy: lists.PrivacyTypePrivate})
+	assert.Nil(t, err)
+
+	configs, err := ListsService.GiveAccessToUsers(list.Id, test_utils.OwnerId, share.ShareConfigs{{UserId: test_utils.CollaboratorId, ShareType: share.ShareTypeWrite}})
+	assert.Nil(t, err)
+	assert.EqualValues(t, 1, len(configs))
+	assert.EqualValues(t, "collaborator", configs[0].UserData.Nickname)
+
+	err = ListsService.AddItemToList("MLA1", 0, list.Id, test_utils.StrangerId)
+	assert.NotNil(t, err)
+	assert.EqualValues(t, http.StatusForbidden, err.Status())
+
+	err = ListsService.AddItemToList("MLA1", 0, list.Id, test_utils.CollaboratorId)
+	assert.Nil(t, err)
+
+	err = ListsService.AddItemToList("MLA1", 0, list.Id, test_utils.CollaboratorId)
+	assert.NotNil(t, err)
+	assert.EqualValues(t, "item MLA1 is already in the list", err.Message())
+
+	listItems, err := ListsService.CheckItem("MLA1", 0, list.Id, test_utils.CollaboratorId)
+	assert.Nil(t, err)
+	assert.EqualValues(t, 1, len(listItems))
+	assert.EqualValues(t, items.StatusChecked, listItems[0].Status)
+	assert.EqualValues(t, test_utils.CollaboratorId, listItems[0].UserId)
+	assert.EqualValues(t, "this is the description", listItems[0].MeliItem.Description)
+	assert.EqualValues(t, "Electrónica", listItems[0].MeliItem.RootCategory)
+
+	ownedLists, err := ListsService.GetMyLists(test_utils.OwnerId)
+	assert.Nil(t, err)
+	assert.EqualValues(t, 1, len(ownedLists))
+	assert.EqualValues(t, 2, ownedLists[0].Notifications)
+
+	sharedLists, err := ListsService.GetMySharedLists(test_utils.CollaboratorId, share.ShareTypeWrite)
+	assert.Nil(t, err)
+	assert.EqualValues(t, 1, len(sharedLists))
+	assert.EqualValues(t, list.Id, sharedLists[0].Id)
+}
+
+func TestFavoriteLists(t *testing.T) {
+	test_utils.SetupUsers()
+	addItemMockups()
+
+	list, err := ListsService.CreateList(lists.List{OwnerId: test_utils.OwnerId, Title: "gifts", Privacy: lists.PrivacyTypePublic})
+	assert.Nil(t, err)
+
+	err = ListsService.MakeFavoriteList(list.Id, test_utils.CollaboratorId)
+	assert.Nil(t, err)
+
+	favorites, err := ListsService.GetUserFavoriteLists(test_utils.CollaboratorId)
+	assert.Nil(t, err)
+	assert.EqualValues(t, 1, len(favorites))
+	assert.EqualValues(t, "gifts", favorites[0].Title)
+
+	err = ListsService.RemoveFavoriteList(list.Id, test_utils.CollaboratorId)
+	assert.Nil(t, err)
+
+	favorites, err = ListsService.GetUserFavoriteLists(test_utils.CollaboratorId)
+	assert.Nil(t, err)
+	assert.EqualValues(t, 0, len(favorites))
+}
+
+func TestDeletedListGoesToTrashAndCanBeRestored(t *testing.T) {
+	test_utils.SetupUsers()
+
+	list, err := ListsService.CreateList(lists.List{OwnerId: test_utils.OwnerId, Title: "gifts", Privacy: lists.PrivacyTypePublic})
+	assert.Nil(t, err)
+	_, err = ListsService.GiveAccessToUsers(list.Id, test_utils.OwnerId, share.ShareConfigs{{UserId: test_utils.CollaboratorId, ShareType: share.ShareTypeRead}})
+	assert.Nil(t, err)
+	assert.Nil(t, lists.ListDao.SaveFavoriteList(list.Id, test_utils.StrangerId))
+
+	err = ListsService.DeleteList(list.Id, test_utils.CollaboratorId)
+	assert.NotNil(t, err)
+	assert.EqualValues(t, http.StatusForbidden, err.Status())
+
+	err = ListsService.DeleteList(list.Id, test_utils.OwnerId)
+	assert.Nil(t, err)
+
+	_, err = ListsService.GetList(list.Id, test_utils.OwnerId)
+	assert.EqualValues(t, http.StatusNotFound, err.Status())
+	owned, _ := ListsService.GetMyLists(test_utils.OwnerId)
+	assert.EqualValues(t, 0, len(owned))
+	shared, err := ListsService.GetMySharedLists(test_utils.CollaboratorId, "")
+	assert.Nil(t, err)
+	assert.EqualValues(t, 0, len(shared))
+	_, err = ListsService.SearchPublicLists()
+	assert.EqualValues(t, http.StatusNotFound, err.Status())
+	favorites, _ := ListsService.GetUserFavoriteLists(test_utils.StrangerId)
+	assert.EqualValues(t, 0, len(favorites))
+
+	trash, err := ListsService.GetTrashedLists(test_utils.OwnerId)
+	assert.Nil(t, err)
+	assert.EqualValues(t, 1, len(trash))
+	assert.NotEmpty(t, trash[0].DateDeleted)
+
+	_, err = ListsService.RestoreList(list.Id, test_utils.StrangerId)
+	assert.EqualValues(t, http.StatusForbidden, err.Status())
+
+	restored, err := ListsService.RestoreList(list.Id, test_utils.OwnerId)
+	assert.Nil(t, err)
+	assert.EqualValues(t, "gifts", restored.Title)
+	assert.Empty(t, restored.DateDeleted)
+
+	favorites, _ = ListsService.GetUserFavoriteLists(test_utils.StrangerId)
+	assert.EqualValues(t, 1, len(favorites))
+	shared, _ = ListsService.GetMySharedLists(test_utils.CollaboratorId, "")
+	assert.EqualValues(t, 1, len(shared))
+}
+
+func TestCoAdminsManageTheListButDoNotDeleteIt(t *testing.T) {
+	test_utils.SetupUsers()
+
+	list, err := ListsService.CreateList(lists.List{OwnerId: test_utils.OwnerId, Title: "groceries", Privacy: lists.PrivacyTypePrivate})
+	assert.Nil(t, err)
+	_, err = ListsService.GiveAccessToUsers(list.Id, test_utils.OwnerId, share.ShareConfigs{{UserId: test_utils.CollaboratorId, ShareType: share.ShareTypeAdmin}})
+	assert.Nil(t, err)
+
+	permissions, err := ListsService.GetUserPermissions(list.Id, test_utils.CollaboratorId)
+	assert.Nil(t, err)
+	assert.EqualValues(t, lists.RoleAdmin, permissions.Role)
+	assert.NotContains(t, permissions.Actions, lists.ActionDelete)
+
+	updated, err := ListsService.UpdateList(lists.List{Id: list.Id, Title: "weekly groceries"}, test_utils.CollaboratorId)
+	assert.Nil(t, err)
+	assert.EqualValues(t, "weekly groceries", updated.Title)
+
+	_, err = ListsService.UpdateList(lists.List{Id: list.Id, Title: "public groceries", Privacy: lists.PrivacyTypePublic}, test_utils.CollaboratorId)
+	assert.NotNil(t, err)
+	assert.EqualValues(t, http.StatusForbidden, err.Status())
+	assert.EqualValues(t, "only the owner of the list can change its privacy", err.Message())
+
+	unchanged, _ := lists.ListDao.GetList(list.Id)
+	assert.EqualValues(t, lists.PrivacyTypePrivate, unchanged.Privacy)
+	assert.EqualValues(t, "weekly groceries", unchanged.Title)
+
+	_, err = ListsService.UpdateList(lists.List{Id: list.Id, Title: "groceries", Privacy: lists.PrivacyTypePrivate}, test_utils.CollaboratorId)
+	assert.Nil(t, err, "sending the privacy the list already has is not a change")
+
+	_, err = ListsService.GiveAccessToUsers(list.Id, test_utils.CollaboratorId, share.ShareConfigs{{UserId: test_utils.StrangerId, ShareType: share.ShareTypeAdmin}})
+	assert.NotNil(t, err)
+	assert.EqualValues(t, http.StatusForbidden, err.Status())
+
+	_, err = ListsService.GiveAccessToUsers(list.Id, test_utils.CollaboratorId, share.ShareConfigs{{UserId: test_utils.StrangerId, ShareType: share.ShareTypeWrite}})
+	assert.Nil(t, err)
+
+	configs, err := ListsService.GetListShareConfigs(list.Id, test_utils.CollaboratorId)
+	assert.Nil(t, err)
+	assert.EqualValues(t, 2, len(configs))
+
+	_, err = ListsService.GetListShareConfigs(list.Id, test_utils.StrangerId)
+	assert.NotNil(t, err)
+	assert.EqualValues(t, http.StatusForbidden, err.Status())
+
+	_, err = ListsService.RevokeAccessToUser(list.Id, test_utils.StrangerId, test_utils.CollaboratorId)
+	assert.NotNil(t, err)
+	assert.EqualValues(t, http.StatusForbidden, err.Status())
+
+	err = ListsService.DeleteList(list.Id, test_utils.CollaboratorId)
+	assert.NotNil(t, err)
+	assert.EqualValues(t, http.StatusForbidden, err.Status())
+
+	configs, err = ListsService.RevokeAccessToUser(list.Id, test_utils.OwnerId, test_utils.CollaboratorId)
+	assert.Nil(t, err)
+	assert.EqualValues(t, 1, len(configs))
+}
+
+func TestPurgeListDeletesDependentRows(t *testing.T) {
+	test_utils.SetupUsers()
+	addItemMockups()
+
+	list, err := ListsService.CreateList(lists.List{OwnerId: test_utils.OwnerId, Title: "groceries", Privacy: lists.PrivacyTypePrivate})
+	assert.Nil(t, err)
+	_, err = ListsService.GiveAccessToUsers(list.Id, test_utils.OwnerId, share.ShareConfigs{{UserId: test_utils.CollaboratorId, ShareType: share.ShareTypeWrite}})
+	assert.Nil(t, err)
+	assert.Nil(t, ListsService.AddItemToList("MLA1", 0, list.Id, test_utils.CollaboratorId))
+
+	err = ListsService.PurgeList(list.Id)
+	assert.NotNil(t, err)
+	assert.EqualValues(t, http.StatusNotFound, err.Status())
+
+	assert.Nil(t, ListsService.DeleteList(list.Id, test_utils.OwnerId))
+	assert.Nil(t, ListsService.PurgeList(list.Id))
+
+	_, err = lists.ListDao.GetTrashedList(list.Id)
+	assert.EqualValues(t, http.StatusNotFound, err.Status())
+	listItems, _ := items.ItemListDao.GetItemsFromList(list.Id)
+	assert.EqualValues(t, 0, len(listItems))
+	configs, _ := share.ShareConfigDao.GetAllShareConfigsByUser(test_utils.CollaboratorId)
+	assert.EqualValues(t, 0, len(configs))
+	listNotifications, _ := notifications.NotificationsDao.GetListNotifications(list.Id)
+	assert.EqualValues(t, 0, len(listNotifications))
+}
+
+func TestItemVariationsAreListedApart(t *testing.T) {
+	test_utils.SetupUsers()
+	addItemMockups()
+	items_provider.FlushCache()
+	variations := `"variations":[{"id":11,"price":450,"available_quantity":2,"picture_ids":["P2"],"attribute_combinations":[{"id":"COLOR","value_name":"Azul"}]},{"id":12,"price":0,"available_quantity":0,"attribute_combinations":[{"id":"COLOR","value_name":"Rojo"}]}]`
+	rest.AddMockups(&rest.Mock{
+		URL:          "https://api.mercadolibre.com/items/MLA3",
+		HTTPMethod:   http.MethodGet,
+		RespHTTPCode: http.StatusOK,
+		RespBody:     `{"id":"MLA3","title":"Zapatillas","price":500,"available_quantity":2,"pictures":[{"id":"P1"},{"id":"P2"}],` + variations + `}`,
+	}, &rest.Mock{
+		URL:          "https://api.mercadolibre.com/items?ids=MLA3,MLA3",
+		HTTPMethod:   http.MethodGet,
+		RespHTTPCode: http.StatusOK,
+		RespBody:     `[{"code":200,"body":{"id":"MLA3","title":"Zapatillas","price":500,"available_quantity":2,"pictures":[{"id":"P1"},{"id":"P2"}],` + variations + `}},{"code":200,"body":{"id":"MLA3","title":"Zapatillas","price":500,"available_quantity":2,"pictures":[{"id":"P1"},{"id":"P2"}],` + variations + `}}]`,
+	}, &rest.Mock{
+		URL:          "https://api.mercadolibre.com/items/MLA3/description",
+		HTTPMethod:   http.MethodGet,
+		RespHTTPCode: http.StatusOK,
+		RespBody:     `{"plain_text":"zapatillas de running"}`,
+	})
+
+	list, err := ListsService.CreateList(lists.List{OwnerId: test_utils.OwnerId, Title: "ropa", Privacy: lists.PrivacyTypePrivate})
+	assert.Nil(t, err)
+
+	err = ListsService.AddItemToList("MLA3", 13, list.Id, test_utils.OwnerId)
+	assert.NotNil(t, err)
+	assert.EqualValues(t, http.StatusBadRequest, err.Status())
+
+	assert.Nil(t, ListsService.AddItemToList("MLA3", 11, list.Id, test_utils.OwnerId))
+	assert.Nil(t, ListsService.AddItemToList("MLA3", 12, list.Id, test_utils.OwnerId))
+	err = ListsService.AddItemToList("MLA3", 11, list.Id, test_utils.OwnerId)
+	assert.NotNil(t, err)
+	assert.EqualValues(t, "item MLA3 with variation 11 is already in the list", err.Message())
+
+	listItems, err := ListsService.CheckItem("MLA3", 11, list.Id, test_utils.OwnerId)
+	assert.Nil(t, err)
+	assert.EqualValues(t, 2, len(listItems))
+	assert.EqualValues(t, items.StatusChecked, listItems.GetItem("MLA3", 11).Status)
+	assert.EqualValues(t, items.StatusNotChecked, listItems.GetItem("MLA3", 12).Status)
+
+	_, err = ListsService.UncheckItem("MLA3", 0, list.Id, test_utils.OwnerId)
+	assert.NotNil(t, err)
+	assert.EqualValues(t, http.StatusNotFound, err.Status())
+
+	listItems, err = ListsService.GetItemsFromList(context.Background(), list.Id, test_utils.OwnerId, InfoPartial)
+	assert.Nil(t, err)
+	blue := listItems.GetItem("MLA3", 11).MeliItem
+	assert.EqualValues(t, 450, blue.Price)
+	assert.EqualValues(t, 2, blue.AvailableQuantity)
+	assert.EqualValues(t, []items.ItemPicture{{Id: "P2"}}, blue.Pictures)
+	red := listItems.GetItem("MLA3", 12).MeliItem
+	assert.EqualValues(t, 500, red.Price)
+	assert.EqualValues(t, 0, red.AvailableQuantity)
+
+	listNotifications, err := ListsService.GetListNotifications(list.Id, test_utils.OwnerId, "en")
+	assert.Nil(t, err)
+	messages := make([]string, 0, len(listNotifications))
+	for _, notification := range listNotifications {
+		messages = append(messages, notification.Message)
+	}
+	assert.Contains(t, messages, "owner added a new product to the list!")
+	assert.Contains(t, messages, "MLA3 (Azul) was bought by owner!")
+}
+
+func TestUpdateListItem(t *testing.T) {
+	test_utils.SetupUsers()
+	addItemMockups()
+
+	list, err := ListsService.CreateList(lists.List{OwnerId: test_utils.OwnerId, Title: "groceries", Privacy: lists.PrivacyTypePrivate})
+	assert.Nil(t, err)
+	_, err = ListsService.GiveAccessToUsers(list.Id, test_utils.OwnerId, share.ShareConfigs{{UserId: test_utils.CollaboratorId, ShareType: share.ShareTypeCheck}})
+	assert.Nil(t, err)
+	assert.Nil(t, ListsService.AddItemToList("MLA1", 0, list.Id, test_utils.OwnerId))
+
+	status, err := ListsService.GetListItemStatus("MLA1", 0, list.Id, test_utils.OwnerId)
+	assert.Nil(t, err)
+	assert.EqualValues(t, 1, status.Quantity)
+	assert.EqualValues(t, items.PriorityNormal, status.Priority)
+
+	quantity, note, targetPrice, priority := 3, "  the blue one ", float32(450), items.PriorityHigh
+	update := items.ItemListUpdate{Quantity: &quantity, Note: &note, TargetPrice: &targetPrice, Priority: &priority}
+
+	_, err = ListsService.UpdateListItem("MLA1", 0, list.Id, test_utils.CollaboratorId, update)
+	assert.NotNil(t, err)
+	assert.EqualValues(t, http.StatusForbidden, err.Status())
+
+	_, err = ListsService.UpdateListItem("MLA2", 0, list.Id, test_utils.OwnerId, update)
+	assert.NotNil(t, err)
+	assert.EqualValues(t, http.StatusNotFound, err.Status())
+
+	listItem, err := ListsService.UpdateListItem("MLA1", 0, list.Id, test_utils.OwnerId, update)
+	assert.Nil(t, err)
+	assert.EqualValues(t, 3, listItem.Quantity)
+	assert.EqualValues(t, "the blue one", listItem.Note)
+	assert.EqualValues(t, 450, listItem.TargetPrice)
+	assert.EqualValues(t, items.PriorityHigh, listItem.Priority)
+
+	// fields that are not sent are left as they are
+	quantity = 1
+	listItem, err = ListsService.UpdateListItem("MLA1", 0, list.Id, test_utils.OwnerId, items.ItemListUpdate{Quantity: &quantity})
+	assert.Nil(t, err)
+	status, err = ListsService.GetListItemStatus("MLA1", 0, list.Id, test_utils.OwnerId)
+	assert.Nil(t, err)
+	assert.EqualValues(t, *listItem, *status)
+	assert.EqualValues(t, 1, status.Quantity)
+	assert.EqualValues(t, "the blue one", status.Note)
+	assert.EqualValues(t, 450, status.TargetPrice)
+}
+
+func TestUpdateListItemInvalid(t *testing.T) {
+	test_utils.SetupUsers()
+
+	quantity, targetPrice, priority := 0, float32(-1), "urgent"
+	_, err := ListsService.UpdateListItem("MLA1", 0, 1, test_utils.OwnerId, items.ItemListUpdate{Quantity: &quantity, TargetPrice: &targetPrice, Priority: &priority})
+
+	assert.NotNil(t, err)
+	assert.EqualValues(t, http.StatusBadRequest, err.Status())
+	assert.EqualValues(t, 3, len(err.Cause()))
+}
@@ -0,0 +1,206 @@
+package mail_service
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/lmurature/melist-api/src/api/config"
+	"github.com/lmurature/melist-api/src/api/domain/apierrors"
+	"github.com/lmurature/melist-api/src/api/domain/lists"
+	"github.com/lmurature/melist-api/src/api/domain/notifications"
+	"github.com/lmurature/melist-api/src/api/domain/users"
+	"github.com/lmurature/melist-api/src/api/providers/mail"
+	i18n_utils "github.com/lmurature/melist-api/src/api/utils/i18n"
+	"github.com/sirupsen/logrus"
+)
+
+type mailService struct{}
+
+type mailServiceInterface interface {
+	GetMailPreferences(userId int64) (*users.MailPreferences, apierrors.ApiError)
+	UpdateMailPreferences(userId int64, preferences users.MailPreferences) (*users.MailPreferences, apierrors.ApiError)
+	SendPendingMails(now time.Time) apierrors.ApiError
+}
+
+var (
+	MailService mailServiceInterface
+
+	sendMail = mail.Send
+)
+
+func init() {
+	MailService = &mailService{}
+}
+
+func (s *mailService) GetMailPreferences(userId int64) (*users.MailPreferences, apierrors.ApiError) {
+	preferences, err := users.MailPreferencesDao.GetMailPreferences(userId)
+	if err != nil {
+		if err.Status() != http.StatusNotFound {
+			return nil, err
+		}
+		return users.DefaultMailPreferences(userId), nil
+	}
+	return preferences, nil
+}
+
+func (s *mailService) UpdateMailPreferences(userId int64, preferences users.MailPreferences) (*users.MailPreferences, apierrors.ApiError) {
+	causes := apierrors.CauseList{}
+	if !users.IsValidMailFrequency(preferences.Frequency) {
+		causes = append(causes, fmt.Sprintf("invalid frequency '%s', expected one of %s, %s, %s or %s", preferences.Frequency,
+			users.MailFrequencyInstant, users.MailFrequencyDaily, users.MailFrequencyWeekly, users.MailFrequencyOff))
+	}
+	if preferences.Locale != "" && !i18n_utils.IsSupportedLocale(preferences.Locale) {
+		causes = append(causes, fmt.Sprintf("unsupported locale '%s', expected one of %s, %s or %s", preferences.Locale,
+			i18n_utils.LocaleEsAR, i18n_utils.LocalePtBR, i18n_utils.LocaleEn))
+	}
+	for _, t := range preferences.DisabledTypes {
+		if !notifications.IsMailableType(t) {
+			causes = append(causes, fmt.Sprintf("notification type '%s' is not delivered by email", t))
+		}
+	}
+	if len(causes) > 0 {
+		return nil, apierrors.NewValidationApiError("invalid mail preferences", "bad_request", causes)
+	}
+
+	current, err := s.GetMailPreferences(userId)
+	if err != nil {
+		return nil, err
+	}
+
+	preferences.UserId = userId
+	if preferences.Locale == "" {
+		preferences.Locale = current.Locale
+	}
+	preferences.Locale = i18n_utils.NormalizeLocale(preferences.Locale)
+	if preferences.DisabledTypes == nil {
+		preferences.DisabledTypes = make([]string, 0)
+	}
+	// the first digest goes out a full period after subscribing instead of right away
+	preferences.DateLastDigest = current.DateLastDigest
+	if preferences.IsDigest() && !current.IsDigest() {
+		preferences.DateLastDigest = time.Now().UTC().Format(config.DbDateLayout)
+	}
+
+	return users.MailPreferencesDao.SaveMailPreferences(preferences)
+}
+
+// SendPendingMails mails the pending notifications of every user according to their preferences. Notifications a
+// user does not want by email are marked as handled so they are not considered again, and the ones that fail to be
+// mailed are retried on the next runs up to config.MailMaxAttempts times.
+func (s *mailService) SendPendingMails(now time.Time) apierrors.ApiError {
+	userIds, err := notifications.NotificationsDao.GetUsersWithPendingMails()
+	if err != nil {
+		return err
+	}
+
+	for _, userId := range userIds {
+		if err := s.sendUserMails(userId, now); err != nil {
+			logrus.Error(fmt.Sprintf("error while mailing notifications to user %d", userId), err)
+		}
+	}
+
+	return nil
+}
+
+func (s *mailService) sendUserMails(userId int64, now time.Time) apierrors.ApiError {
+	preferences, err := s.GetMailPreferences(userId)
+	if err != nil {
+		return err
+	}
+
+	pending, err := notifications.NotificationsDao.GetPendingMails(userId)
+	if err != nil {
+		return err
+	}
+
+	user, err := users.UserDao.GetUser(userId)
+	if err != nil {
+		return err
+	}
+
+	wanted := make([]notifications.Notification, 0)
+	skipped := make([]int64, 0)
+	for _, n := range pending {
+		if preferences.Frequency != users.MailFrequencyOff && user.Email != "" &&
+			notifications.IsMailableType(n.Type) && preferences.IsTypeEnabled(n.Type) {
+			wanted = append(wanted, n)
+		} else {
+			skipped = append(skipped, n.Id)
+		}
+	}
+
+	dateMailed := now.UTC().Format(config.DbDateLayout)
+	if len(skipped) > 0 {
+		if err := notifications.NotificationsDao.MarkAsMailed(skipped, userId, dateMailed); err != nil {
+			return err
+		}
+	}
+
+	if len(wanted) == 0 {
+		return nil
+	}
+
+	if preferences.Frequency == users.MailFrequencyInstant {
+		for _, n := range wanted {
+			if err := sendMail(user.Email, preferences.Locale, mail.Notification{
+				ListTitle: listTitle(n.ListId),
+				Message:   n.Localize(preferences.Locale).Message,
+				Url:       config.AppBaseUrl + n.Permalink,
+			}); err != nil {
+				// the notification is retried on the next run until it runs out of attempts
+				logrus.Error(fmt.Sprintf("error while mailing notification %d to user %d", n.Id, userId), err)
+				if err := notifications.NotificationsDao.RecordMailFailure([]int64{n.Id}, userId); err != nil {
+					return err
+				}
+				continue
+			}
+			if err := notifications.NotificationsDao.MarkAsMailed([]int64{n.Id}, userId, dateMailed); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if !preferences.IsDigestDue(now) {
+		return nil
+	}
+
+	digest := mail.Digest{Weekly: preferences.Frequency == users.MailFrequencyWeekly}
+	for _, list := range notifications.GroupByList(wanted) {
+		section := mail.DigestList{
+			Title: listTitle(list.ListId),
+			Url:   fmt.Sprintf("%s/lists/%d", config.AppBaseUrl, list.ListId),
+		}
+		for _, n := range list.Notifications {
+			section.Messages = append(section.Messages, n.Localize(preferences.Locale).Message)
+		}
+		digest.Lists = append(digest.Lists, section)
+	}
+	ids := make([]int64, 0, len(wanted))
+	for _, n := range wanted {
+		ids = append(ids, n.Id)
+	}
+
+	if err := sendMail(user.Email, preferences.Locale, digest); err != nil {
+		if recordErr := notifications.NotificationsDao.RecordMailFailure(ids, userId); recordErr != nil {
+			return recordErr
+		}
+		return err
+	}
+	if err := notifications.NotificationsDao.MarkAsMailed(ids, userId, dateMailed); err != nil {
+		return err
+	}
+
+	preferences.DateLastDigest = dateMailed
+	_, err = users.MailPreferencesDao.SaveMailPreferences(*preferences)
+	return err
+}
+
+func listTitle(listId int64) string {
+	list, err := lists.ListDao.GetList(listId)
+	if err != nil {
+		return ""
+	}
+	return list.Title
+}
@@ -0,0 +1,145 @@
+package mail_service
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lmurature/melist-api/src/api/config"
+	"github.com/lmurature/melist-api/src/api/domain/apierrors"
+	"github.com/lmurature/melist-api/src/api/domain/lists"
+	"github.com/lmurature/melist-api/src/api/domain/notifications"
+	"github.com/lmurature/melist-api/src/api/domain/users"
+	"github.com/lmurature/melist-api/src/api/providers/mail"
+	test_utils "github.com/lmurature/melist-api/src/api/utils/test"
+	"github.com/lmurature/melist-api/src/mailsink"
+	"github.com/stretchr/testify/assert"
+)
+
+func setup(t *testing.T) (*mailsink.Sink, *lists.List, *lists.List) {
+	groceries := test_utils.SetupSharedList(t)
+	gifts, _ := lists.ListDao.CreateList(lists.List{OwnerId: test_utils.OwnerId, Title: "gifts", Privacy: lists.PrivacyTypePrivate})
+
+	sink, err := mailsink.Listen("127.0.0.1:0")
+	assert.Nil(t, err)
+	go sink.Serve()
+	t.Cleanup(func() { sink.Close() })
+	config.SmtpAddress = sink.Addr()
+	config.EmailAddress = ""
+
+	return sink, groceries, gifts
+}
+
+func TestInstantMailsSkipOptedOutTypes(t *testing.T) {
+	sink, groceries, _ := setup(t)
+
+	_, err := MailService.UpdateMailPreferences(test_utils.OwnerId, users.MailPreferences{
+		Frequency:     users.MailFrequencyInstant,
+		DisabledTypes: []string{notifications.TypeDealEnded},
+	})
+	assert.Nil(t, err)
+
+	notifications.NotificationsDao.SaveNotification(*notifications.NewDealActivatedNotification(groceries.Id, "MLA1", "Coffee"))
+	notifications.NotificationsDao.SaveNotification(*notifications.NewDealEndedNotification(groceries.Id, "MLA1", "Coffee"))
+	notifications.NotificationsDao.SaveNotification(*notifications.NewAddedItemToListNotification(groceries.Id, "MLA2", "owner"))
+
+	assert.Nil(t, MailService.SendPendingMails(time.Now().UTC()))
+
+	messages := sink.Messages()
+	assert.EqualValues(t, 1, len(messages))
+	assert.EqualValues(t, []string{"owner@melist.com"}, messages[0].To)
+	assert.EqualValues(t, "Novedades en tu lista groceries", messages[0].Subject())
+	assert.True(t, strings.Contains(messages[0].Text(), "Coffee entró en una oferta"))
+
+	// the collaborator never opted in, so nothing is pending for anyone anymore
+	pending, _ := notifications.NotificationsDao.GetUsersWithPendingMails()
+	assert.EqualValues(t, 0, len(pending))
+
+	assert.Nil(t, MailService.SendPendingMails(time.Now().UTC()))
+	assert.EqualValues(t, 1, len(sink.Messages()))
+}
+
+func TestDailyDigestGroupsByListOncePerPeriod(t *testing.T) {
+	sink, groceries, gifts := setup(t)
+
+	_, err := MailService.UpdateMailPreferences(test_utils.OwnerId, users.MailPreferences{Frequency: users.MailFrequencyDaily})
+	assert.Nil(t, err)
+
+	notifications.NotificationsDao.SaveNotification(*notifications.NewPriceChangeNotification(groceries.Id, "MLA1", 100, 80, "ARS", "Coffee"))
+	notifications.NotificationsDao.SaveNotification(*notifications.NewEmptyStockNotification(gifts.Id, "MLA3", "Watch"))
+	notifications.NotificationsDao.SaveNotification(*notifications.NewCheckedItemNotification(groceries.Id, "MLA2", "collaborator"))
+
+	now := time.Now().UTC()
+	assert.Nil(t, MailService.SendPendingMails(now))
+	assert.EqualValues(t, 0, len(sink.Messages()))
+
+	tomorrow := now.Add(25 * time.Hour)
+	assert.Nil(t, MailService.SendPendingMails(tomorrow))
+
+	messages := sink.Messages()
+	assert.EqualValues(t, 1, len(messages))
+	assert.EqualValues(t, "Tu resumen diario de Melist", messages[0].Subject())
+	body := messages[0].Text()
+	assert.True(t, strings.Index(body, "groceries") < strings.Index(body, "gifts"))
+	assert.True(t, strings.Index(body, "MLA2 fue comprado") < strings.Index(body, "gifts"))
+	assert.True(t, strings.Contains(body, "Watch se quedó sin stock"))
+
+	preferences, _ := MailService.GetMailPreferences(test_utils.OwnerId)
+	assert.EqualValues(t, tomorrow.Format(config.DbDateLayout), preferences.DateLastDigest)
+
+	notifications.NotificationsDao.SaveNotification(*notifications.NewDealActivatedNotification(groceries.Id, "MLA1", "Coffee"))
+	assert.Nil(t, MailService.SendPendingMails(tomorrow.Add(time.Hour)))
+	assert.EqualValues(t, 1, len(sink.Messages()))
+}
+
+func TestFailedInstantMailsDoNotBlockTheRestAndGiveUp(t *testing.T) {
+	sink, groceries, _ := setup(t)
+
+	previousSendMail := sendMail
+	sendMail = func(emailAddress string, locale string, msg mail.Message) apierrors.ApiError {
+		if n, ok := msg.(mail.Notification); ok && strings.Contains(n.Message, "Coffee") {
+			return apierrors.NewInternalServerApiError("error while trying to mail", nil)
+		}
+		return previousSendMail(emailAddress, locale, msg)
+	}
+	t.Cleanup(func() { sendMail = previousSendMail })
+
+	_, err := MailService.UpdateMailPreferences(test_utils.OwnerId, users.MailPreferences{Frequency: users.MailFrequencyInstant})
+	assert.Nil(t, err)
+
+	notifications.NotificationsDao.SaveNotification(*notifications.NewDealActivatedNotification(groceries.Id, "MLA1", "Coffee"))
+	notifications.NotificationsDao.SaveNotification(*notifications.NewDealEndedNotification(groceries.Id, "MLA2", "Tea"))
+
+	assert.Nil(t, MailService.SendPendingMails(time.Now().UTC()))
+	assert.EqualValues(t, 1, len(sink.Messages()), "a failed mail does not hold back the next ones")
+
+	pending, _ := notifications.NotificationsDao.GetPendingMails(test_utils.OwnerId)
+	assert.EqualValues(t, 1, len(pending))
+
+	for i := 1; i < config.MailMaxAttempts; i++ {
+		assert.Nil(t, MailService.SendPendingMails(time.Now().UTC()))
+	}
+
+	pending, _ = notifications.NotificationsDao.GetPendingMails(test_utils.OwnerId)
+	assert.EqualValues(t, 0, len(pending), "the mail is given up after its last attempt")
+	userIds, _ := notifications.NotificationsDao.GetUsersWithPendingMails()
+	assert.EqualValues(t, 0, len(userIds))
+	assert.EqualValues(t, 1, len(sink.Messages()))
+}
+
+func TestUpdateMailPreferencesValidation(t *testing.T) {
+	setup(t)
+
+	preferences, err := MailService.GetMailPreferences(test_utils.OwnerId)
+	assert.Nil(t, err)
+	assert.EqualValues(t, users.MailFrequencyOff, preferences.Frequency)
+
+	_, err = MailService.UpdateMailPreferences(test_utils.OwnerId, users.MailPreferences{
+		Frequency:     "hourly",
+		DisabledTypes: []string{notifications.TypeListFavorited},
+	})
+	assert.NotNil(t, err)
+	assert.EqualValues(t, http.StatusBadRequest, err.Status())
+	assert.EqualValues(t, 2, len(err.Cause()))
+}
@@ -0,0 +1,73 @@
+package notifications_service
+
+import (
+	"github.com/lmurature/melist-api/src/api/domain/apierrors"
+	"github.com/lmurature/melist-api/src/api/domain/notifications"
+	date_utils "github.com/lmurature/melist-api/src/api/utils/date"
+)
+
+type notificationsService struct{}
+
+type notificationsServiceInterface interface {
+	GetInbox(userId int64, onlyUnread bool, locale string) (*notifications.Inbox, apierrors.ApiError)
+	MarkAsSeen(notificationId int64, userId int64) apierrors.ApiError
+	MarkAllAsSeen(userId int64) apierrors.ApiError
+	OpenStream(userId int64, lastEventId int64) (*notifications.Subscription, []notifications.Notification, apierrors.ApiError)
+}
+
+var (
+	NotificationsService notificationsServiceInterface
+)
+
+func init() {
+	NotificationsService = &notificationsService{}
+}
+
+func (s *notificationsService) GetInbox(userId int64, onlyUnread bool, locale string) (*notifications.Inbox, apierrors.ApiError) {
+	userNotifications, err := notifications.NotificationsDao.GetUserNotifications(userId, onlyUnread)
+	if err != nil {
+		return nil, err
+	}
+
+	unreadByList := make(map[int64]int)
+	unread := 0
+	for _, n := range userNotifications {
+		if !n.Seen {
+			unreadByList[n.ListId]++
+			unread++
+		}
+	}
+
+	return &notifications.Inbox{
+		Unread:        unread,
+		UnreadByList:  unreadByList,
+		Notifications: notifications.Localize(userNotifications, locale),
+	}, nil
+}
+
+func (s *notificationsService) MarkAsSeen(notificationId int64, userId int64) apierrors.ApiError {
+	return notifications.NotificationsDao.MarkAsSeen(notificationId, userId, date_utils.GetNowDateFormatted())
+}
+
+func (s *notificationsService) MarkAllAsSeen(userId int64) apierrors.ApiError {
+	return notifications.NotificationsDao.MarkAllAsSeen(userId, date_utils.GetNowDateFormatted())
+}
+
+// OpenStream subscribes the user to new notifications and returns the ones delivered after lastEventId, so a client
+// that reconnects does not miss what happened while it was away. The subscription is opened before reading the
+// backlog; callers must skip live notifications whose id was already sent.
+func (s *notificationsService) OpenStream(userId int64, lastEventId int64) (*notifications.Subscription, []notifications.Notification, apierrors.ApiError) {
+	subscription := notifications.NotificationsHub.Subscribe(userId)
+
+	backlog := make([]notifications.Notification, 0)
+	if lastEventId > 0 {
+		missed, err := notifications.NotificationsDao.GetUserNotificationsAfter(userId, lastEventId)
+		if err != nil {
+			subscription.Close()
+			return nil, nil, err
+		}
+		backlog = missed
+	}
+
+	return subscription, backlog, nil
+}
@@ -0,0 +1,104 @@
+package notifications_service
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/lmurature/melist-api/src/api/domain/notifications"
+	lists_service "github.com/lmurature/melist-api/src/api/services/lists"
+	i18n_utils "github.com/lmurature/melist-api/src/api/utils/i18n"
+	test_utils "github.com/lmurature/melist-api/src/api/utils/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNotificationsAreDeliveredToOwnerAndCollaborators(t *testing.T) {
+	list := test_utils.SetupSharedList(t)
+
+	first, _ := notifications.NotificationsDao.SaveNotification(*notifications.NewDealActivatedNotification(list.Id, "MLA1", "Test item"))
+	notifications.NotificationsDao.SaveNotification(*notifications.NewDealEndedNotification(list.Id, "MLA1", "Test item"))
+
+	for _, userId := range []int64{test_utils.OwnerId, test_utils.CollaboratorId} {
+		inbox, err := NotificationsService.GetInbox(userId, false, i18n_utils.DefaultLocale)
+		assert.Nil(t, err)
+		assert.EqualValues(t, 2, inbox.Unread)
+		assert.EqualValues(t, 2, inbox.UnreadByList[list.Id])
+		assert.EqualValues(t, 2, len(inbox.Notifications))
+	}
+
+	inbox, err := NotificationsService.GetInbox(test_utils.StrangerId, false, i18n_utils.DefaultLocale)
+	assert.Nil(t, err)
+	assert.EqualValues(t, 0, len(inbox.Notifications))
+
+	err = NotificationsService.MarkAsSeen(first.Id, test_utils.StrangerId)
+	assert.NotNil(t, err)
+	assert.EqualValues(t, http.StatusNotFound, err.Status())
+}
+
+func TestSeenStateIsPerUser(t *testing.T) {
+	list := test_utils.SetupSharedList(t)
+
+	first, _ := notifications.NotificationsDao.SaveNotification(*notifications.NewDealActivatedNotification(list.Id, "MLA1", "Test item"))
+	notifications.NotificationsDao.SaveNotification(*notifications.NewDealEndedNotification(list.Id, "MLA1", "Test item"))
+
+	assert.Nil(t, NotificationsService.MarkAsSeen(first.Id, test_utils.CollaboratorId))
+
+	inbox, _ := NotificationsService.GetInbox(test_utils.CollaboratorId, false, i18n_utils.DefaultLocale)
+	assert.EqualValues(t, 1, inbox.Unread)
+	for _, n := range inbox.Notifications {
+		assert.EqualValues(t, n.Id == first.Id, n.Seen)
+	}
+
+	unread, _ := NotificationsService.GetInbox(test_utils.CollaboratorId, true, i18n_utils.DefaultLocale)
+	assert.EqualValues(t, 1, len(unread.Notifications))
+
+	owned, err := lists_service.ListsService.GetMyLists(test_utils.OwnerId)
+	assert.Nil(t, err)
+	assert.EqualValues(t, 2, owned[0].Notifications)
+
+	shared, err := lists_service.ListsService.GetMySharedLists(test_utils.CollaboratorId, "")
+	assert.Nil(t, err)
+	assert.EqualValues(t, 1, shared[0].Notifications)
+
+	assert.Nil(t, NotificationsService.MarkAllAsSeen(test_utils.OwnerId))
+	owned, _ = lists_service.ListsService.GetMyLists(test_utils.OwnerId)
+	assert.EqualValues(t, 0, owned[0].Notifications)
+
+	inbox, _ = NotificationsService.GetInbox(test_utils.CollaboratorId, false, i18n_utils.DefaultLocale)
+	assert.EqualValues(t, 1, inbox.Unread)
+}
+
+func TestOpenStreamResumesAfterLastEventId(t *testing.T) {
+	list := test_utils.SetupSharedList(t)
+
+	first, _ := notifications.NotificationsDao.SaveNotification(*notifications.NewDealActivatedNotification(list.Id, "MLA1", "Test item"))
+	notifications.NotificationsDao.SaveNotification(*notifications.NewDealEndedNotification(list.Id, "MLA1", "Test item"))
+
+	subscription, backlog, err := NotificationsService.OpenStream(test_utils.CollaboratorId, first.Id)
+	assert.Nil(t, err)
+	defer subscription.Close()
+	assert.EqualValues(t, 1, len(backlog))
+	assert.True(t, backlog[0].Id > first.Id)
+
+	live, _ := notifications.NotificationsDao.SaveNotification(*notifications.NewDealActivatedNotification(list.Id, "MLA2", "Other item"))
+	received := <-subscription.C
+	assert.EqualValues(t, live.Id, received.Id)
+
+	_, backlog, err = NotificationsService.OpenStream(test_utils.StrangerId, 0)
+	assert.Nil(t, err)
+	assert.EqualValues(t, 0, len(backlog))
+}
+
+func TestInboxIsRenderedInCallerLocale(t *testing.T) {
+	list := test_utils.SetupSharedList(t)
+
+	notifications.NotificationsDao.SaveNotification(*notifications.NewPriceChangeNotification(list.Id, "MLA1", 100, 80.5, "ARS", "Coffee"))
+
+	inbox, err := NotificationsService.GetInbox(test_utils.CollaboratorId, false, i18n_utils.LocaleEn)
+	assert.Nil(t, err)
+	assert.EqualValues(t, "The price of Coffee changed! It was ARS 100.00, now it is ARS 80.50.", inbox.Notifications[0].Message)
+	assert.EqualValues(t, notifications.TypePriceChange, inbox.Notifications[0].Type)
+	assert.EqualValues(t, notifications.Params{ItemId: "MLA1", Title: "Coffee", OldPrice: 100, NewPrice: 80.5, CurrencyId: "ARS"}, inbox.Notifications[0].Params)
+
+	inbox, _ = NotificationsService.GetInbox(test_utils.OwnerId, false, i18n_utils.LocalePtBR)
+	assert.EqualValues(t, "O preço do produto Coffee mudou! Antes custava ARS 100.00, agora ARS 80.50.", inbox.Notifications[0].Message)
+}
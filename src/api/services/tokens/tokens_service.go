@@ -0,0 +1,202 @@
+package tokens_service
+
+import (
+	"fmt"
+	"github.com/lmurature/melist-api/src/api/config"
+	"github.com/lmurature/melist-api/src/api/domain/apierrors"
+	"github.com/lmurature/melist-api/src/api/domain/auth"
+	"github.com/lmurature/melist-api/src/api/domain/sessions"
+	"github.com/lmurature/melist-api/src/api/domain/users"
+	auth_provider "github.com/lmurature/melist-api/src/api/providers/auth"
+	crypto_utils "github.com/lmurature/melist-api/src/api/utils/crypto"
+	"github.com/sirupsen/logrus"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// tokensService keeps the Mercado Libre tokens of every user encrypted in the user table and refreshes them before
+// they expire, so the API and background jobs can act on behalf of users without the client sending them.
+type tokensService struct {
+	mu    sync.Mutex
+	locks map[int64]*sync.Mutex
+}
+
+type tokensServiceInterface interface {
+	SaveTokens(userId int64, tokens auth.MeliAuthResponse) apierrors.ApiError
+	GetAccessToken(userId int64) (string, apierrors.ApiError)
+	RevokeTokens(userId int64) apierrors.ApiError
+	EncryptStoredTokens() apierrors.ApiError
+}
+
+var (
+	TokensService tokensServiceInterface
+
+	now                = time.Now
+	refreshAccessToken = auth_provider.RefreshAccessToken
+)
+
+func init() {
+	TokensService = &tokensService{locks: make(map[int64]*sync.Mutex)}
+}
+
+// SaveTokens encrypts and stores the tokens Mercado Libre issued to the user.
+func (s *tokensService) SaveTokens(userId int64, tokens auth.MeliAuthResponse) apierrors.ApiError {
+	accessToken, err := crypto_utils.Encrypt(tokens.AccessToken)
+	if err != nil {
+		logrus.Error(fmt.Sprintf("error encrypting access token of user %d", userId), err)
+		return apierrors.NewInternalServerApiError("error when trying to save user tokens", err)
+	}
+	refreshToken, err := crypto_utils.Encrypt(tokens.RefreshToken)
+	if err != nil {
+		logrus.Error(fmt.Sprintf("error encrypting refresh token of user %d", userId), err)
+		return apierrors.NewInternalServerApiError("error when trying to save user tokens", err)
+	}
+
+	expiresAt := now().UTC().Add(time.Duration(tokens.ExpiresIn) * time.Second).Format(config.DbDateLayout)
+	return users.UserDao.UpdateUserTokens(userId, accessToken, refreshToken, expiresAt)
+}
+
+// GetAccessToken returns a Mercado Libre access token of the user that is valid for at least
+// config.MeliTokenRefreshMargin, refreshing it first if needed. Mercado Libre rotates the refresh token on every
+// refresh and rejects the old one, so refreshes of a user are serialized: two concurrent refreshes with the same token
+// would revoke it. The lock is per instance, which is enough while the API and the jobs run in the same process.
+func (s *tokensService) GetAccessToken(userId int64) (string, apierrors.ApiError) {
+	lock := s.userLock(userId)
+	lock.Lock()
+	defer lock.Unlock()
+
+	user, err := users.UserDao.GetUser(userId)
+	if err != nil {
+		return "", err
+	}
+
+	accessToken, decryptErr := crypto_utils.Decrypt(user.AccessToken)
+	if decryptErr != nil {
+		logrus.Error(fmt.Sprintf("error decrypting access token of user %d", userId), decryptErr)
+		return "", apierrors.NewInternalServerApiError("error when trying to get user tokens", decryptErr)
+	}
+	if accessToken == "" {
+		return "", apierrors.NewUnauthorizedApiError(fmt.Sprintf("user %d has not authorized melist in mercado libre", userId))
+	}
+
+	expiresAt, parseErr := time.Parse(config.DbDateLayout, user.TokenExpiresAt)
+	// tokens stored before their expiry was known are refreshed right away
+	if parseErr == nil && now().Add(config.MeliTokenRefreshMargin).Before(expiresAt) {
+		return accessToken, nil
+	}
+
+	refreshToken, decryptErr := crypto_utils.Decrypt(user.RefreshToken)
+	if decryptErr != nil {
+		logrus.Error(fmt.Sprintf("error decrypting refresh token of user %d", userId), decryptErr)
+		return "", apierrors.NewInternalServerApiError("error when trying to get user tokens", decryptErr)
+	}
+
+	result, err := refreshAccessToken(refreshToken)
+	if err != nil {
+		if isRevoked(err) {
+			logrus.Warn(fmt.Sprintf("mercado libre rejected the refresh token of user %d, revoking their tokens", userId))
+			if err := s.RevokeTokens(userId); err != nil {
+				return "", err
+			}
+			return "", apierrors.NewUnauthorizedApiError(fmt.Sprintf("user %d revoked the authorization of melist in mercado libre", userId))
+		}
+
+		// the token may still be good for a while, so a failed refresh is retried on the next call
+		if parseErr == nil && now().Before(expiresAt) {
+			logrus.Warn(fmt.Sprintf("error refreshing access token of user %d, using the current one", userId), err)
+			return accessToken, nil
+		}
+		logrus.Error(fmt.Sprintf("error refreshing access token of user %d", userId), err)
+		return "", err
+	}
+
+	if result.RefreshToken == "" {
+		result.RefreshToken = refreshToken
+	}
+	if err := s.SaveTokens(userId, *result); err != nil {
+		return "", err
+	}
+
+	logrus.Info(fmt.Sprintf("successfully refreshed mercado libre access token of user %d", userId))
+	return result.AccessToken, nil
+}
+
+// RevokeTokens forgets the Mercado Libre tokens of the user and revokes their sessions, so they have to log in again.
+func (s *tokensService) RevokeTokens(userId int64) apierrors.ApiError {
+	if err := users.UserDao.UpdateUserTokens(userId, "", "", ""); err != nil {
+		return err
+	}
+	return sessions.SessionDao.RevokeUserSessions(userId)
+}
+
+// EncryptStoredTokens encrypts the tokens that were stored in plaintext before they were encrypted at rest.
+func (s *tokensService) EncryptStoredTokens() apierrors.ApiError {
+	stored, err := users.UserDao.GetUsersWithTokens()
+	if err != nil {
+		return err
+	}
+
+	encrypted := 0
+	for _, user := range stored {
+		if crypto_utils.IsEncrypted(user.AccessToken) && crypto_utils.IsEncrypted(user.RefreshToken) {
+			continue
+		}
+		if err := s.encryptTokens(user.Id); err != nil {
+			return err
+		}
+		encrypted++
+	}
+
+	logrus.Info(fmt.Sprintf("encrypted the plaintext tokens of %d users", encrypted))
+	return nil
+}
+
+// encryptTokens encrypts the tokens of the user as they are now, since a refresh may have replaced them.
+func (s *tokensService) encryptTokens(userId int64) apierrors.ApiError {
+	lock := s.userLock(userId)
+	lock.Lock()
+	defer lock.Unlock()
+
+	user, err := users.UserDao.GetUser(userId)
+	if err != nil {
+		return err
+	}
+
+	tokens := make([]string, 0, 2)
+	for _, token := range []string{user.AccessToken, user.RefreshToken} {
+		if !crypto_utils.IsEncrypted(token) {
+			encrypted, encryptErr := crypto_utils.Encrypt(token)
+			if encryptErr != nil {
+				logrus.Error(fmt.Sprintf("error encrypting tokens of user %d", userId), encryptErr)
+				return apierrors.NewInternalServerApiError("error when trying to encrypt user tokens", encryptErr)
+			}
+			token = encrypted
+		}
+		tokens = append(tokens, token)
+	}
+
+	return users.UserDao.UpdateUserTokens(userId, tokens[0], tokens[1], user.TokenExpiresAt)
+}
+
+func (s *tokensService) userLock(userId int64) *sync.Mutex {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	lock, ok := s.locks[userId]
+	if !ok {
+		lock = &sync.Mutex{}
+		s.locks[userId] = lock
+	}
+	return lock
+}
+
+// isRevoked is whether Mercado Libre rejected the refresh token itself, as opposed to failing to answer.
+func isRevoked(err apierrors.ApiError) bool {
+	switch err.Status() {
+	case http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden:
+		return true
+	default:
+		return false
+	}
+}
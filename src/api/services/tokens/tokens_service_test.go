@@ -0,0 +1,184 @@
+package tokens_service
+
+import (
+	"net/http"
+	"os"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/lmurature/golang-restclient/rest"
+	"github.com/lmurature/melist-api/src/api/config"
+	"github.com/lmurature/melist-api/src/api/domain/apierrors"
+	"github.com/lmurature/melist-api/src/api/domain/auth"
+	"github.com/lmurature/melist-api/src/api/domain/sessions"
+	"github.com/lmurature/melist-api/src/api/domain/users"
+	"github.com/lmurature/melist-api/src/api/storage"
+	crypto_utils "github.com/lmurature/melist-api/src/api/utils/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+const userId = 1
+
+func TestMain(m *testing.M) {
+	rest.StartMockupServer()
+	config.TokenEncryptionKey = "test-key"
+	os.Exit(m.Run())
+}
+
+// setup stores tokens that expire in six hours from the clock.
+func setup(t *testing.T, clock *time.Time) {
+	storage.UseMemory()
+	users.UserDao.CreateUser(users.MelistUser{Id: userId})
+
+	previousNow := now
+	now = func() time.Time { return *clock }
+	t.Cleanup(func() { now = previousNow })
+
+	rest.FlushMockups()
+	assert.Nil(t, TokensService.SaveTokens(userId, auth.MeliAuthResponse{AccessToken: "APP_USR-1", RefreshToken: "TG-1", ExpiresIn: 21600}))
+}
+
+func mockRefresh(status int, body string) {
+	rest.FlushMockups()
+	rest.AddMockups(&rest.Mock{
+		URL:          "https://api.mercadolibre.com/oauth/token",
+		HTTPMethod:   http.MethodPost,
+		RespHTTPCode: status,
+		RespBody:     body,
+	})
+}
+
+func TestTokensAreEncryptedAtRest(t *testing.T) {
+	clock := time.Date(2021, 5, 1, 12, 0, 0, 0, time.UTC)
+	setup(t, &clock)
+
+	user, err := users.UserDao.GetUser(userId)
+	assert.Nil(t, err)
+	assert.NotContains(t, user.AccessToken, "APP_USR-1")
+	assert.NotContains(t, user.RefreshToken, "TG-1")
+	assert.EqualValues(t, "2021-05-01 18:00:00", user.TokenExpiresAt)
+
+	refreshToken, decryptErr := crypto_utils.Decrypt(user.RefreshToken)
+	assert.Nil(t, decryptErr)
+	assert.EqualValues(t, "TG-1", refreshToken)
+}
+
+func TestGetAccessTokenRefreshesBeforeExpiry(t *testing.T) {
+	clock := time.Date(2021, 5, 1, 12, 0, 0, 0, time.UTC)
+	setup(t, &clock)
+
+	accessToken, err := TokensService.GetAccessToken(userId)
+	assert.Nil(t, err)
+	assert.EqualValues(t, "APP_USR-1", accessToken, "no request is sent while the token is good")
+
+	clock = clock.Add(5*time.Hour + 55*time.Minute)
+	mockRefresh(http.StatusOK, `{"access_token": "APP_USR-2", "token_type": "bearer", "expires_in": 21600, "user_id": 1, "refresh_token": "TG-2"}`)
+
+	accessToken, err = TokensService.GetAccessToken(userId)
+	assert.Nil(t, err)
+	assert.EqualValues(t, "APP_USR-2", accessToken)
+
+	user, _ := users.UserDao.GetUser(userId)
+	refreshToken, _ := crypto_utils.Decrypt(user.RefreshToken)
+	assert.EqualValues(t, "TG-2", refreshToken, "the rotated refresh token replaces the used one")
+	assert.EqualValues(t, "2021-05-01 23:55:00", user.TokenExpiresAt)
+}
+
+func TestGetAccessTokenKeepsTokenWhenRefreshFails(t *testing.T) {
+	clock := time.Date(2021, 5, 1, 17, 55, 0, 0, time.UTC)
+	setup(t, &clock)
+	clock = clock.Add(5*time.Hour + 55*time.Minute)
+	mockRefresh(http.StatusInternalServerError, `{"message": "internal error", "error": "internal_error", "status": 500}`)
+
+	accessToken, err := TokensService.GetAccessToken(userId)
+	assert.Nil(t, err)
+	assert.EqualValues(t, "APP_USR-1", accessToken)
+
+	clock = clock.Add(10 * time.Minute)
+	_, err = TokensService.GetAccessToken(userId)
+	assert.NotNil(t, err)
+	assert.EqualValues(t, http.StatusInternalServerError, err.Status())
+}
+
+func TestGetAccessTokenRevokedByUser(t *testing.T) {
+	clock := time.Date(2021, 5, 1, 12, 0, 0, 0, time.UTC)
+	setup(t, &clock)
+	session, _, _ := sessions.New(userId, clock)
+	session, _ = sessions.SessionDao.CreateSession(*session)
+
+	clock = clock.Add(6 * time.Hour)
+	mockRefresh(http.StatusBadRequest, `{"message": "invalid_grant", "error": "invalid_grant", "status": 400}`)
+
+	_, err := TokensService.GetAccessToken(userId)
+	assert.NotNil(t, err)
+	assert.EqualValues(t, http.StatusUnauthorized, err.Status())
+
+	user, _ := users.UserDao.GetUser(userId)
+	assert.Empty(t, user.AccessToken)
+	assert.Empty(t, user.RefreshToken)
+
+	session, _ = sessions.SessionDao.GetSessionByAccessToken(session.AccessTokenHash)
+	assert.True(t, session.Revoked, "the user has to log in again")
+
+	_, err = TokensService.GetAccessToken(userId)
+	assert.NotNil(t, err)
+	assert.EqualValues(t, http.StatusUnauthorized, err.Status())
+}
+
+func TestConcurrentCallsRefreshOnce(t *testing.T) {
+	clock := time.Date(2021, 5, 1, 12, 0, 0, 0, time.UTC)
+	setup(t, &clock)
+	clock = clock.Add(6 * time.Hour)
+
+	var refreshes int32
+	previousRefresh := refreshAccessToken
+	refreshAccessToken = func(refreshToken string) (*auth.MeliAuthResponse, apierrors.ApiError) {
+		atomic.AddInt32(&refreshes, 1)
+		if refreshToken != "TG-1" {
+			return nil, apierrors.NewBadRequestApiError("invalid_grant")
+		}
+		return &auth.MeliAuthResponse{AccessToken: "APP_USR-2", ExpiresIn: 21600, RefreshToken: "TG-2"}, nil
+	}
+	t.Cleanup(func() { refreshAccessToken = previousRefresh })
+
+	var wg sync.WaitGroup
+	results := make([]string, 10)
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i], _ = TokensService.GetAccessToken(userId)
+		}(i)
+	}
+	wg.Wait()
+
+	assert.EqualValues(t, 1, atomic.LoadInt32(&refreshes))
+	for _, accessToken := range results {
+		assert.EqualValues(t, "APP_USR-2", accessToken)
+	}
+}
+
+func TestEncryptStoredTokens(t *testing.T) {
+	clock := time.Date(2021, 5, 1, 12, 0, 0, 0, time.UTC)
+	setup(t, &clock)
+	users.UserDao.CreateUser(users.MelistUser{Id: 2})
+	users.UserDao.UpdateUserTokens(2, "APP_USR-plain", "TG-plain", "2021-05-01 18:00:00")
+	encrypted, _ := users.UserDao.GetUser(userId)
+
+	assert.Nil(t, TokensService.EncryptStoredTokens())
+
+	user, err := users.UserDao.GetUser(2)
+	assert.Nil(t, err)
+	assert.True(t, crypto_utils.IsEncrypted(user.AccessToken))
+	assert.True(t, crypto_utils.IsEncrypted(user.RefreshToken))
+	assert.EqualValues(t, "2021-05-01 18:00:00", user.TokenExpiresAt)
+
+	accessToken, err := TokensService.GetAccessToken(2)
+	assert.Nil(t, err)
+	assert.EqualValues(t, "APP_USR-plain", accessToken)
+
+	user, _ = users.UserDao.GetUser(userId)
+	assert.EqualValues(t, encrypted.AccessToken, user.AccessToken, "tokens that were already encrypted are left as they are")
+}
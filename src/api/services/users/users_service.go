@@ -2,13 +2,16 @@ package users_service
 
 import (
 	"fmt"
+	"github.com/lmurature/melist-api/src/api/config"
 	"github.com/lmurature/melist-api/src/api/domain/apierrors"
 	"github.com/lmurature/melist-api/src/api/domain/lists"
 	"github.com/lmurature/melist-api/src/api/domain/share"
+	"github.com/lmurature/melist-api/src/api/domain/sites"
 	"github.com/lmurature/melist-api/src/api/domain/users"
 	"github.com/lmurature/melist-api/src/api/providers/mail"
 	users_provider "github.com/lmurature/melist-api/src/api/providers/users"
 	"github.com/lmurature/melist-api/src/api/utils/date"
+	i18n_utils "github.com/lmurature/melist-api/src/api/utils/i18n"
 	"github.com/sirupsen/logrus"
 	"net/http"
 )
@@ -18,10 +21,11 @@ type usersService struct{}
 type usersServiceInterface interface {
 	GetMeliUser(userId int64) (*users.User, apierrors.ApiError)
 	GetMyUser(accessToken string) (*users.User, apierrors.ApiError)
-	SaveUserToDb(u users.User, accessToken string, refreshToken string) apierrors.ApiError
+	GetSessionUser(userId int64) (*users.User, apierrors.ApiError)
+	SaveUserToDb(u users.User) apierrors.ApiError
 	GetUserFromDb(userId int64) (*users.MelistUser, apierrors.ApiError)
 	FindUserByEmail(email string) (*users.MelistUser, apierrors.ApiError)
-	UpdateUserDb(u users.User, accessToken string, refreshToken string) apierrors.ApiError
+	UpdateUserDb(u users.User) apierrors.ApiError
 	SearchUsers(query string) ([]users.MelistUser, apierrors.ApiError)
 	InviteUser(email string, shareType string, listId int64, callerId int64) (map[string]interface{}, apierrors.ApiError)
 	GetPendingUsersByList(listId int64, callerId int64) (share.ShareConfigs, apierrors.ApiError)
@@ -53,16 +57,33 @@ func (s *usersService) GetMyUser(accessToken string) (*users.User, apierrors.Api
 	return user, nil
 }
 
-func (s *usersService) SaveUserToDb(u users.User, accessToken string, refreshToken string) apierrors.ApiError {
+// GetSessionUser returns the user of a session as Mercado Libre reported it on their last login, without asking
+// Mercado Libre again.
+func (s *usersService) GetSessionUser(userId int64) (*users.User, apierrors.ApiError) {
+	user, err := users.UserDao.GetUser(userId)
+	if err != nil {
+		return nil, err
+	}
+
+	return &users.User{
+		Id:        user.Id,
+		FirstName: user.FirstName,
+		LastName:  user.LastName,
+		Nickname:  user.Nickname,
+		Email:     user.Email,
+		SiteId:    user.SiteId,
+	}, nil
+}
+
+func (s *usersService) SaveUserToDb(u users.User) apierrors.ApiError {
 	user := users.MelistUser{
-		Id:           u.Id,
-		FirstName:    u.FirstName,
-		LastName:     u.LastName,
-		Nickname:     u.Nickname,
-		Email:        u.Email,
-		DateCreated:  date_utils.GetNowDateFormatted(),
-		AccessToken:  accessToken,
-		RefreshToken: refreshToken,
+		Id:          u.Id,
+		FirstName:   u.FirstName,
+		LastName:    u.LastName,
+		Nickname:    u.Nickname,
+		Email:       u.Email,
+		SiteId:      sites.GetSiteIdOrDefault(u.SiteId),
+		DateCreated: date_utils.GetNowDateFormatted(),
 	}
 
 	_, err := users.UserDao.CreateUser(user)
@@ -89,16 +110,15 @@ func (s *usersService) FindUserByEmail(email string) (*users.MelistUser, apierro
 	return user, nil
 }
 
-func (s *usersService) UpdateUserDb(u users.User, accessToken string, refreshToken string) apierrors.ApiError {
+func (s *usersService) UpdateUserDb(u users.User) apierrors.ApiError {
 	user := users.MelistUser{
-		Id:           u.Id,
-		FirstName:    u.FirstName,
-		LastName:     u.LastName,
-		Nickname:     u.Nickname,
-		Email:        u.Email,
-		DateCreated:  date_utils.GetNowDateFormatted(),
-		AccessToken:  accessToken,
-		RefreshToken: refreshToken,
+		Id:          u.Id,
+		FirstName:   u.FirstName,
+		LastName:    u.LastName,
+		Nickname:    u.Nickname,
+		Email:       u.Email,
+		SiteId:      sites.GetSiteIdOrDefault(u.SiteId),
+		DateCreated: date_utils.GetNowDateFormatted(),
 	}
 	_, err := users.UserDao.UpdateUser(user)
 	if err != nil {
@@ -136,7 +156,16 @@ func (s *usersService) InviteUser(email string, shareType string, listId int64,
 		return nil, err
 	}
 
-	if err := list.ValidateUpdatability(callerId); err != nil {
+	configs, err := share.GetListShareConfigs(listId)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := list.Authorize(callerId, lists.ActionShare, configs); err != nil {
+		return nil, err
+	}
+
+	if err := (share.ShareConfig{Email: email, ShareType: shareType}).Validate(); err != nil {
 		return nil, err
 	}
 
@@ -148,6 +177,20 @@ func (s *usersService) InviteUser(email string, shareType string, listId int64,
 		}
 	}
 
+	if len(result) > 0 {
+		if err := list.AuthorizeShare(callerId, result[0].Id, shareType, configs); err != nil {
+			return nil, err
+		}
+	} else {
+		pending, err := share.ShareConfigDao.GetAllFutureListCollaborationByList(listId)
+		if err != nil {
+			return nil, err
+		}
+		if err := list.AuthorizeInvite(callerId, email, shareType, configs, pending); err != nil {
+			return nil, err
+		}
+	}
+
 	// user already exists and list owner wanted to give him access by email in modal..
 	if result != nil && len(result) > 0 {
 		_, err := share.ShareConfigDao.CreateShareConfig(share.ShareConfig{
@@ -171,12 +214,21 @@ func (s *usersService) InviteUser(email string, shareType string, listId int64,
 		return nil, err
 	}
 
-	listUrl := fmt.Sprintf("https://melist-app.herokuapp.com/lists/%d", listId)
-	mail.SendMail(email, share.GetFormattedShareType(shareType),
-		caller.FirstName,
-		caller.LastName,
-		list.Title,
-		listUrl)
+	locale := i18n_utils.DefaultLocale
+	if preferences, err := users.MailPreferencesDao.GetMailPreferences(callerId); err == nil {
+		locale = preferences.Locale
+	}
+
+	if err := mail.Send(email, locale, mail.Invitation{
+		InviterFirstName: caller.FirstName,
+		InviterLastName:  caller.LastName,
+		ListTitle:        list.Title,
+		ShareType:        share.GetFormattedShareType(shareType, locale),
+		Url:              fmt.Sprintf("%s/lists/%d", config.AppBaseUrl, listId),
+	}); err != nil {
+		// the future collaboration is already saved, the invited user can still join by logging in
+		logrus.Error(fmt.Sprintf("error while trying to mail invitation to list %d", listId), err)
+	}
 
 	return nil, nil
 }
@@ -187,7 +239,12 @@ func (s *usersService) GetPendingUsersByList(listId int64, callerId int64) (shar
 		return nil, err
 	}
 
-	if err := list.ValidateUpdatability(callerId); err != nil {
+	configs, err := share.GetListShareConfigs(listId)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := list.Authorize(callerId, lists.ActionViewShares, configs); err != nil {
 		return nil, err
 	}
 
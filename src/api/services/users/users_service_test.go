@@ -6,6 +6,10 @@ import (
 	"testing"
 
 	"github.com/lmurature/golang-restclient/rest"
+	"github.com/lmurature/melist-api/src/api/domain/lists"
+	"github.com/lmurature/melist-api/src/api/domain/share"
+	"github.com/lmurature/melist-api/src/api/domain/users"
+	"github.com/lmurature/melist-api/src/api/storage"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -51,3 +55,21 @@ func TestGetUserNoError(t *testing.T) {
 	assert.EqualValues(t, 1, user.Id)
 	assert.EqualValues(t, "pepe", user.Nickname)
 }
+
+func TestCoAdminsCannotOverwriteAdminInvitations(t *testing.T) {
+	storage.UseMemory()
+	users.UserDao.CreateUser(users.MelistUser{Id: 1, Nickname: "owner"})
+	users.UserDao.CreateUser(users.MelistUser{Id: 2, Nickname: "coadmin"})
+
+	list, _ := lists.ListDao.CreateList(lists.List{OwnerId: 1, Title: "groceries", Privacy: lists.PrivacyTypePrivate})
+	share.ShareConfigDao.CreateShareConfig(share.ShareConfig{ListId: list.Id, UserId: 2, ShareType: share.ShareTypeAdmin})
+	share.ShareConfigDao.CreateEmailShareConfig(share.ShareConfig{ListId: list.Id, Email: "admin@melist.test", ShareType: share.ShareTypeAdmin})
+
+	_, err := UsersService.InviteUser("admin@melist.test", share.ShareTypeRead, list.Id, 2)
+	assert.NotNil(t, err)
+	assert.EqualValues(t, http.StatusForbidden, err.Status())
+
+	pending, _ := share.ShareConfigDao.GetAllFutureListCollaborationByList(list.Id)
+	assert.EqualValues(t, 1, len(pending))
+	assert.EqualValues(t, share.ShareTypeAdmin, pending[0].ShareType)
+}
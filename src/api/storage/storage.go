@@ -0,0 +1,44 @@
+package storage
+
+import (
+	"fmt"
+
+	"github.com/lmurature/melist-api/src/api/clients/database"
+	"github.com/lmurature/melist-api/src/api/config"
+	"github.com/lmurature/melist-api/src/api/domain/alerts"
+	"github.com/lmurature/melist-api/src/api/domain/auth"
+	"github.com/lmurature/melist-api/src/api/domain/items"
+	"github.com/lmurature/melist-api/src/api/domain/lists"
+	"github.com/lmurature/melist-api/src/api/domain/notifications"
+	"github.com/lmurature/melist-api/src/api/domain/sessions"
+	"github.com/lmurature/melist-api/src/api/domain/share"
+	"github.com/lmurature/melist-api/src/api/domain/users"
+	"github.com/sirupsen/logrus"
+)
+
+// Every DAO package defaults to its mysql implementation, so only the memory backend needs to be wired here.
+func init() {
+	switch config.StorageBackend {
+	case config.StorageMySql:
+	case config.StorageMemory:
+		UseMemory()
+	default:
+		panic(fmt.Sprintf("invalid STORAGE_BACKEND value '%s', expected '%s' or '%s'",
+			config.StorageBackend, config.StorageMySql, config.StorageMemory))
+	}
+}
+
+// UseMemory replaces every DAO with an empty in-memory implementation. Calling it again discards all stored data.
+// The mysql client is dropped as well, so units of work no longer open database transactions.
+func UseMemory() {
+	database.DbClient = nil
+	users.UseMemoryStorage()
+	items.UseMemoryStorage()
+	lists.UseMemoryStorage()
+	share.UseMemoryStorage()
+	notifications.UseMemoryStorage()
+	alerts.UseMemoryStorage()
+	sessions.UseMemoryStorage()
+	auth.UseMemoryStorage()
+	logrus.Info("using in-memory storage")
+}
@@ -0,0 +1,87 @@
+package crypto_utils
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"strings"
+
+	"github.com/lmurature/melist-api/src/api/config"
+)
+
+// encryptedPrefix marks values sealed by Encrypt and the version of their format, so that values stored before
+// encryption existed can still be read.
+const encryptedPrefix = "enc:v1:"
+
+var (
+	ErrMissingKey = errors.New("token encryption key is not configured")
+	ErrMalformed  = errors.New("encrypted value is malformed")
+)
+
+// Encrypt seals plaintext with AES-256-GCM under config.TokenEncryptionKey. Empty values stay empty.
+func Encrypt(plaintext string) (string, error) {
+	if plaintext == "" {
+		return "", nil
+	}
+
+	aead, err := newAead()
+	if err != nil {
+		return "", err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+
+	sealed := aead.Seal(nonce, nonce, []byte(plaintext), nil)
+	return encryptedPrefix + base64.RawStdEncoding.EncodeToString(sealed), nil
+}
+
+// Decrypt opens a value sealed by Encrypt. Values without the prefix of Encrypt were stored in plaintext and are
+// returned as they are.
+func Decrypt(value string) (string, error) {
+	if !strings.HasPrefix(value, encryptedPrefix) {
+		return value, nil
+	}
+
+	sealed, err := base64.RawStdEncoding.DecodeString(strings.TrimPrefix(value, encryptedPrefix))
+	if err != nil {
+		return "", ErrMalformed
+	}
+
+	aead, err := newAead()
+	if err != nil {
+		return "", err
+	}
+	if len(sealed) < aead.NonceSize() {
+		return "", ErrMalformed
+	}
+
+	plaintext, err := aead.Open(nil, sealed[:aead.NonceSize()], sealed[aead.NonceSize():], nil)
+	if err != nil {
+		return "", err
+	}
+	return string(plaintext), nil
+}
+
+// IsEncrypted is whether value was sealed by Encrypt. Empty values need no encryption and count as encrypted.
+func IsEncrypted(value string) bool {
+	return value == "" || strings.HasPrefix(value, encryptedPrefix)
+}
+
+func newAead() (cipher.AEAD, error) {
+	if config.TokenEncryptionKey == "" {
+		return nil, ErrMissingKey
+	}
+
+	key := sha256.Sum256([]byte(config.TokenEncryptionKey))
+	block, err := aes.NewCipher(key[:])
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,60 @@
+package crypto_utils
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/lmurature/melist-api/src/api/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func useKey(t *testing.T, key string) {
+	previousKey := config.TokenEncryptionKey
+	config.TokenEncryptionKey = key
+	t.Cleanup(func() { config.TokenEncryptionKey = previousKey })
+}
+
+func TestEncryptAndDecrypt(t *testing.T) {
+	useKey(t, "test-key")
+
+	encrypted, err := Encrypt("APP_USR-123")
+	assert.Nil(t, err)
+	assert.True(t, strings.HasPrefix(encrypted, encryptedPrefix))
+	assert.NotContains(t, encrypted, "APP_USR-123")
+
+	again, err := Encrypt("APP_USR-123")
+	assert.Nil(t, err)
+	assert.NotEqual(t, encrypted, again, "every value gets its own nonce")
+
+	decrypted, err := Decrypt(encrypted)
+	assert.Nil(t, err)
+	assert.EqualValues(t, "APP_USR-123", decrypted)
+
+	useKey(t, "other-key")
+	_, err = Decrypt(encrypted)
+	assert.NotNil(t, err)
+}
+
+func TestDecryptPlaintextAndEmptyValues(t *testing.T) {
+	useKey(t, "test-key")
+
+	decrypted, err := Decrypt("APP_USR-stored-before-encryption")
+	assert.Nil(t, err)
+	assert.EqualValues(t, "APP_USR-stored-before-encryption", decrypted)
+	assert.False(t, IsEncrypted("APP_USR-stored-before-encryption"))
+	assert.True(t, IsEncrypted(""))
+
+	encrypted, err := Encrypt("")
+	assert.Nil(t, err)
+	assert.EqualValues(t, "", encrypted)
+
+	_, err = Decrypt(encryptedPrefix + "%%%")
+	assert.EqualValues(t, ErrMalformed, err)
+}
+
+func TestEncryptWithoutKey(t *testing.T) {
+	useKey(t, "")
+
+	_, err := Encrypt("APP_USR-123")
+	assert.EqualValues(t, ErrMissingKey, err)
+}
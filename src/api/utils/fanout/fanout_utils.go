@@ -0,0 +1,115 @@
+package fanout_utils
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/lmurature/melist-api/src/api/domain/apierrors"
+)
+
+// Options bound a fan-out. A zero Concurrency runs every task at once and a zero Timeout never times a task out.
+type Options struct {
+	Concurrency int
+	Timeout     time.Duration
+}
+
+// Result is the outcome of the task at Index. Err is set when the task failed, timed out or never ran because the
+// fan-out was cancelled.
+type Result struct {
+	Index int
+	Value interface{}
+	Err   apierrors.ApiError
+}
+
+type Task func(ctx context.Context, index int) (interface{}, apierrors.ApiError)
+
+// Run calls task for every index in [0, n) from a pool of workers and returns the results in index order. It never
+// fails as a whole: each result carries its own error. Tasks that outlive their timeout keep running in the
+// background, so they should honour the context they receive.
+func Run(ctx context.Context, n int, opts Options, task Task) []Result {
+	results := make([]Result, n)
+	if n == 0 {
+		return results
+	}
+
+	workers := opts.Concurrency
+	if workers <= 0 || workers > n {
+		workers = n
+	}
+
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				results[i] = runTask(ctx, i, opts.Timeout, task)
+			}
+		}()
+	}
+
+	next := 0
+dispatch:
+	for ; next < n; next++ {
+		select {
+		case indexes <- next:
+		case <-ctx.Done():
+			break dispatch
+		}
+	}
+	close(indexes)
+	wg.Wait()
+
+	for i := next; i < n; i++ {
+		results[i] = Result{Index: i, Err: contextError(ctx.Err(), i)}
+	}
+
+	return results
+}
+
+// FirstError returns the error of the first failed result, if any.
+func FirstError(results []Result) apierrors.ApiError {
+	for _, r := range results {
+		if r.Err != nil {
+			return r.Err
+		}
+	}
+	return nil
+}
+
+func runTask(ctx context.Context, index int, timeout time.Duration, task Task) Result {
+	if err := ctx.Err(); err != nil {
+		return Result{Index: index, Err: contextError(err, index)}
+	}
+
+	taskCtx, cancel := ctx, context.CancelFunc(func() {})
+	if timeout > 0 {
+		taskCtx, cancel = context.WithTimeout(ctx, timeout)
+	}
+	defer cancel()
+
+	// buffered so that a task that finishes after its timeout does not block forever
+	done := make(chan Result, 1)
+	go func() {
+		value, err := task(taskCtx, index)
+		done <- Result{Index: index, Value: value, Err: err}
+	}()
+
+	select {
+	case result := <-done:
+		return result
+	case <-taskCtx.Done():
+		return Result{Index: index, Err: contextError(taskCtx.Err(), index)}
+	}
+}
+
+func contextError(err error, index int) apierrors.ApiError {
+	if err == context.DeadlineExceeded {
+		return apierrors.NewApiError(fmt.Sprintf("task %d timed out", index), "timeout", http.StatusGatewayTimeout, apierrors.CauseList{})
+	}
+	return apierrors.NewApiError(fmt.Sprintf("task %d was cancelled", index), "cancelled", http.StatusServiceUnavailable, apierrors.CauseList{})
+}
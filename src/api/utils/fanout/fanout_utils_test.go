@@ -0,0 +1,82 @@
+package fanout_utils
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/lmurature/melist-api/src/api/domain/apierrors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunReturnsResultsInOrderWithPerTaskErrors(t *testing.T) {
+	results := Run(context.Background(), 5, Options{Concurrency: 2}, func(ctx context.Context, i int) (interface{}, apierrors.ApiError) {
+		if i == 3 {
+			return nil, apierrors.NewNotFoundApiError("not found")
+		}
+		return i * 10, nil
+	})
+
+	assert.EqualValues(t, 5, len(results))
+	for i, r := range results {
+		assert.EqualValues(t, i, r.Index)
+		if i == 3 {
+			assert.EqualValues(t, http.StatusNotFound, r.Err.Status())
+		} else {
+			assert.Nil(t, r.Err)
+			assert.EqualValues(t, i*10, r.Value)
+		}
+	}
+	assert.EqualValues(t, "not found", FirstError(results).Message())
+}
+
+func TestRunLimitsConcurrency(t *testing.T) {
+	var running, maxRunning int32
+	Run(context.Background(), 20, Options{Concurrency: 3}, func(ctx context.Context, i int) (interface{}, apierrors.ApiError) {
+		n := atomic.AddInt32(&running, 1)
+		for {
+			max := atomic.LoadInt32(&maxRunning)
+			if n <= max || atomic.CompareAndSwapInt32(&maxRunning, max, n) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+		atomic.AddInt32(&running, -1)
+		return nil, nil
+	})
+
+	assert.EqualValues(t, 3, atomic.LoadInt32(&maxRunning))
+}
+
+func TestRunTimesOutSlowTasks(t *testing.T) {
+	results := Run(context.Background(), 2, Options{Timeout: 20 * time.Millisecond}, func(ctx context.Context, i int) (interface{}, apierrors.ApiError) {
+		if i == 1 {
+			time.Sleep(time.Second)
+		}
+		return i, nil
+	})
+
+	assert.Nil(t, results[0].Err)
+	assert.NotNil(t, results[1].Err)
+	assert.EqualValues(t, http.StatusGatewayTimeout, results[1].Err.Status())
+	assert.EqualValues(t, "task 1 timed out", results[1].Err.Message())
+}
+
+func TestRunStopsWhenCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	var started int32
+	results := Run(ctx, 10, Options{Concurrency: 1}, func(ctx context.Context, i int) (interface{}, apierrors.ApiError) {
+		if atomic.AddInt32(&started, 1) == 2 {
+			cancel()
+		}
+		return i, nil
+	})
+
+	assert.EqualValues(t, 10, len(results))
+	assert.Nil(t, results[0].Err)
+	assert.True(t, atomic.LoadInt32(&started) < 10)
+	assert.NotNil(t, results[9].Err)
+	assert.EqualValues(t, "cancelled", results[9].Err.Code())
+}
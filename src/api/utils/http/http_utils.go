@@ -1,5 +1,54 @@
 package http_utils
 
-const (
-	BaseUrlMeli = "https://api.mercadolibre.com"
+import (
+	"net/url"
+	"strings"
+
+	"github.com/lmurature/melist-api/src/api/config"
+)
+
+var (
+	BaseUrlMeli = config.MeliBaseUrl
+
+	// redactedParams are query parameters that carry credentials and are never logged. Logins take their nonce in the
+	// body, but a client that still sends it in the url must not leak it either.
+	redactedParams = []string{"access_token", "nonce"}
 )
+
+// IsAllowedOrigin is whether a browser request with that Origin header comes from the Melist web app. Requests
+// without the header do not come from a browser page, so they cannot be forged by another site.
+func IsAllowedOrigin(origin string) bool {
+	if origin == "" {
+		return true
+	}
+	for _, allowed := range config.AllowedOrigins {
+		if strings.EqualFold(strings.TrimSuffix(allowed, "/"), origin) {
+			return true
+		}
+	}
+	return false
+}
+
+// RedactPath returns the path of a request with the values of credential query parameters hidden.
+func RedactPath(path string) string {
+	i := strings.Index(path, "?")
+	if i < 0 {
+		return path
+	}
+	query, err := url.ParseQuery(path[i+1:])
+	if err != nil {
+		return path[:i]
+	}
+
+	redacted := false
+	for _, param := range redactedParams {
+		if _, ok := query[param]; ok {
+			query.Set(param, "REDACTED")
+			redacted = true
+		}
+	}
+	if !redacted {
+		return path
+	}
+	return path[:i+1] + query.Encode()
+}
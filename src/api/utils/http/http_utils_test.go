@@ -0,0 +1,30 @@
+package http_utils
+
+import (
+	"testing"
+
+	"github.com/lmurature/melist-api/src/api/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsAllowedOrigin(t *testing.T) {
+	previous := config.AllowedOrigins
+	config.AllowedOrigins = []string{"https://melist.test", "http://localhost:3000/"}
+	t.Cleanup(func() { config.AllowedOrigins = previous })
+
+	assert.True(t, IsAllowedOrigin("https://melist.test"))
+	assert.True(t, IsAllowedOrigin("http://localhost:3000"))
+	assert.True(t, IsAllowedOrigin(""), "clients that are not browsers send no origin")
+	assert.False(t, IsAllowedOrigin("https://evil.test"))
+	assert.False(t, IsAllowedOrigin("https://melist.test.evil.test"))
+	assert.False(t, IsAllowedOrigin("null"))
+}
+
+func TestRedactPath(t *testing.T) {
+	assert.EqualValues(t, "/api/notifications/ws", RedactPath("/api/notifications/ws"))
+	assert.EqualValues(t, "/api/notifications/ws?access_token=REDACTED&last_event_id=3",
+		RedactPath("/api/notifications/ws?access_token=secret&last_event_id=3"))
+	assert.EqualValues(t, "/api/users/auth/login?nonce=REDACTED&site_id=MLB",
+		RedactPath("/api/users/auth/login?site_id=MLB&nonce=secret"))
+	assert.EqualValues(t, "/api/items/search?q=tv", RedactPath("/api/items/search?q=tv"))
+}
@@ -0,0 +1,70 @@
+package i18n_utils
+
+import (
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	LocaleEsAR = "es-AR"
+	LocalePtBR = "pt-BR"
+	LocaleEn   = "en"
+
+	DefaultLocale = LocaleEsAR
+)
+
+var (
+	SupportedLocales = []string{LocaleEsAR, LocalePtBR, LocaleEn}
+)
+
+// NormalizeLocale maps a locale such as "pt_BR", "en-US" or "es" to the supported locale with the same language,
+// falling back to DefaultLocale.
+func NormalizeLocale(locale string) string {
+	if normalized, ok := matchLocale(locale); ok {
+		return normalized
+	}
+	return DefaultLocale
+}
+
+func IsSupportedLocale(locale string) bool {
+	_, ok := matchLocale(locale)
+	return ok
+}
+
+// GetRequestLocale returns the locale asked for with the locale query parameter or, without it, the first supported
+// language of the Accept-Language header.
+func GetRequestLocale(c *gin.Context) string {
+	if locale := c.Query("locale"); locale != "" {
+		return NormalizeLocale(locale)
+	}
+
+	for _, tag := range strings.Split(c.GetHeader("Accept-Language"), ",") {
+		tag = strings.TrimSpace(strings.SplitN(tag, ";", 2)[0])
+		if normalized, ok := matchLocale(tag); ok {
+			return normalized
+		}
+	}
+
+	return DefaultLocale
+}
+
+func matchLocale(locale string) (string, bool) {
+	locale = strings.ReplaceAll(strings.TrimSpace(locale), "_", "-")
+	language := strings.ToLower(strings.SplitN(locale, "-", 2)[0])
+	if language == "" {
+		return "", false
+	}
+
+	for _, supported := range SupportedLocales {
+		if strings.EqualFold(supported, locale) {
+			return supported, true
+		}
+	}
+	for _, supported := range SupportedLocales {
+		if strings.ToLower(strings.SplitN(supported, "-", 2)[0]) == language {
+			return supported, true
+		}
+	}
+	return "", false
+}
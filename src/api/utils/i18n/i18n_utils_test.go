@@ -0,0 +1,32 @@
+package i18n_utils
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeLocale(t *testing.T) {
+	assert.EqualValues(t, LocalePtBR, NormalizeLocale("pt_BR"))
+	assert.EqualValues(t, LocaleEn, NormalizeLocale("en-US"))
+	assert.EqualValues(t, LocaleEsAR, NormalizeLocale("es"))
+	assert.EqualValues(t, DefaultLocale, NormalizeLocale("fr-FR"))
+	assert.EqualValues(t, DefaultLocale, NormalizeLocale(""))
+	assert.False(t, IsSupportedLocale("fr"))
+}
+
+func TestGetRequestLocale(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	request := func(url string, acceptLanguage string) *gin.Context {
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Request = httptest.NewRequest("GET", url, nil)
+		c.Request.Header.Set("Accept-Language", acceptLanguage)
+		return c
+	}
+
+	assert.EqualValues(t, LocalePtBR, GetRequestLocale(request("/api/notifications", "fr-FR,pt-BR;q=0.9,en;q=0.8")))
+	assert.EqualValues(t, LocaleEn, GetRequestLocale(request("/api/notifications?locale=en", "pt-BR")))
+	assert.EqualValues(t, DefaultLocale, GetRequestLocale(request("/api/notifications", "")))
+}
@@ -0,0 +1,38 @@
+package test_utils
+
+import (
+	"testing"
+
+	"github.com/lmurature/melist-api/src/api/domain/lists"
+	"github.com/lmurature/melist-api/src/api/domain/share"
+	"github.com/lmurature/melist-api/src/api/domain/users"
+	"github.com/lmurature/melist-api/src/api/storage"
+	"github.com/stretchr/testify/assert"
+)
+
+// Users created by SetupUsers. Only the owner has lists; the collaborator gets access through shares and the
+// stranger has access to nothing.
+const (
+	OwnerId        = 1
+	CollaboratorId = 2
+	StrangerId     = 3
+)
+
+// SetupUsers resets the storage to an empty in-memory one holding the owner, the collaborator and the stranger.
+func SetupUsers() {
+	storage.UseMemory()
+	users.UserDao.CreateUser(users.MelistUser{Id: OwnerId, Nickname: "owner", Email: "owner@melist.com"})
+	users.UserDao.CreateUser(users.MelistUser{Id: CollaboratorId, Nickname: "collaborator", Email: "collaborator@melist.com"})
+	users.UserDao.CreateUser(users.MelistUser{Id: StrangerId, Nickname: "stranger", Email: "stranger@melist.com"})
+}
+
+// SetupSharedList calls SetupUsers and returns the owner's private "groceries" list, which the collaborator can read.
+func SetupSharedList(t *testing.T) *lists.List {
+	SetupUsers()
+
+	list, err := lists.ListDao.CreateList(lists.List{OwnerId: OwnerId, Title: "groceries", Privacy: lists.PrivacyTypePrivate})
+	assert.Nil(t, err)
+	_, err = share.ShareConfigDao.CreateShareConfig(share.ShareConfig{UserId: CollaboratorId, ListId: list.Id, ShareType: share.ShareTypeRead})
+	assert.Nil(t, err)
+	return list
+}
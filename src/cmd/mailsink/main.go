@@ -0,0 +1,32 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/lmurature/melist-api/src/mailsink"
+	"github.com/sirupsen/logrus"
+)
+
+func main() {
+	addr := flag.String("addr", "127.0.0.1:2525", "address the mail sink listens on")
+	flag.Parse()
+
+	sink, err := mailsink.Listen(*addr)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	sink.OnMessage = func(m mailsink.Message) {
+		logrus.Info(fmt.Sprintf("mail to %s: %s\n%s", strings.Join(m.To, ", "), m.Subject(), m.Body()))
+	}
+
+	logrus.Info(fmt.Sprintf("mail sink listening on %s", sink.Addr()))
+	if err := sink.Serve(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/lmurature/melist-api/src/api/clients/database"
+	"github.com/lmurature/melist-api/src/api/clients/database/migrations"
+)
+
+const usage = `usage: migrate <command>
+
+commands:
+  up          apply every pending migration
+  down [n]    roll back the last n applied migrations (default 1)
+  verify      fail if there are pending or modified migrations
+  status      list migrations and whether they are applied`
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprintln(os.Stderr, usage)
+	}
+	flag.Parse()
+
+	if flag.NArg() < 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	if database.DbClient == nil {
+		fmt.Fprintln(os.Stderr, "migrate requires the mysql storage backend")
+		os.Exit(1)
+	}
+
+	migrator := migrations.NewMigrator(database.DbClient)
+
+	var err error
+	switch flag.Arg(0) {
+	case "up":
+		err = migrator.Up()
+	case "down":
+		steps := 1
+		if flag.NArg() > 1 {
+			steps, err = strconv.Atoi(flag.Arg(1))
+			if err != nil || steps < 1 {
+				fmt.Fprintln(os.Stderr, "down steps must be a positive integer")
+				os.Exit(2)
+			}
+		}
+		err = migrator.Down(steps)
+	case "verify":
+		err = migrator.Verify()
+	case "status":
+		err = printStatus(migrator)
+	default:
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func printStatus(migrator *migrations.Migrator) error {
+	status, err := migrator.Status()
+	if err != nil {
+		return err
+	}
+
+	for _, s := range status {
+		if s.Applied != nil {
+			fmt.Printf("%s\tapplied at %s\n", s.Migration, s.Applied.AppliedAt)
+		} else {
+			fmt.Printf("%s\tpending\n", s.Migration)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/lmurature/melist-api/src/simulator"
+	"github.com/sirupsen/logrus"
+)
+
+func main() {
+	addr := flag.String("addr", ":8081", "address the simulator listens on")
+	fixturesDir := flag.String("fixtures", "src/simulator/fixtures", "directory with items, categories, trends and users fixtures")
+	scenarioPath := flag.String("scenario", "", "optional scenario file with timed state changes")
+	flag.Parse()
+
+	fixtures, err := simulator.LoadFixtures(*fixturesDir)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	state := simulator.NewState(fixtures)
+
+	if *scenarioPath != "" {
+		scenario, err := simulator.LoadScenario(*scenarioPath)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		defer scenario.Play(state)()
+	}
+
+	logrus.Info(fmt.Sprintf("mercado libre simulator listening on %s with %d items", *addr, len(fixtures.Items)))
+	if err := simulator.NewServer(state).Run(*addr); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
@@ -1,14 +1,20 @@
 package jobs
 
 import (
+	"context"
 	"fmt"
 	"github.com/lmurature/melist-api/src/api/config"
+	"github.com/lmurature/melist-api/src/api/domain/alerts"
+	"github.com/lmurature/melist-api/src/api/domain/apierrors"
 	"github.com/lmurature/melist-api/src/api/domain/items"
+	"github.com/lmurature/melist-api/src/api/domain/lists"
 	"github.com/lmurature/melist-api/src/api/domain/notifications"
+	"github.com/lmurature/melist-api/src/api/domain/share"
 	items_provider "github.com/lmurature/melist-api/src/api/providers/items"
 	items_service "github.com/lmurature/melist-api/src/api/services/items"
 	lists_service "github.com/lmurature/melist-api/src/api/services/lists"
 	date_utils "github.com/lmurature/melist-api/src/api/utils/date"
+	fanout_utils "github.com/lmurature/melist-api/src/api/utils/fanout"
 	"github.com/onatm/clockwerk"
 	"github.com/sirupsen/logrus"
 	"net/http"
@@ -17,6 +23,12 @@ import (
 
 var (
 	ItemsJobs clockwerk.Job
+
+	itemsFanOut = fanout_utils.Options{Concurrency: config.ItemsFanOutConcurrency, Timeout: config.ItemsFanOutTimeout}
+
+	// analyzedAttributes are the only fields of the items the job needs from Mercado Libre.
+	analyzedAttributes = []string{"id", "title", "price", "original_price", "currency_id", "status", "available_quantity",
+		"deal_ids", "permalink", "catalog_product_id", "variations"}
 )
 
 type ItemsJobsStruct struct{}
@@ -32,80 +44,128 @@ func (i ItemsJobsStruct) Run() {
 
 func persistNotifications() {
 	// get all lists
-	lists, err := lists_service.ListsService.GetAllLists()
+	allLists, err := lists_service.ListsService.GetAllLists()
 	if err != nil {
 		logrus.Error("error while getting lists", err)
 		return
 	}
 
 	// for each list, get all items, analyze current data and last history, generate notifications and save item history.
-	for _, list := range lists {
-		listItems, err := lists_service.ListsService.GetItemsFromList(list.Id, list.OwnerId, true)
+	for _, list := range allLists {
+		listItems, err := lists_service.ListsService.GetItemsFromList(context.Background(), list.Id, list.OwnerId, lists_service.InfoNone)
 		if err != nil {
 			logrus.Error("error while getting list items")
 			continue
 		}
 
+		itemIds := make([]string, len(listItems))
+		for i := range listItems {
+			itemIds[i] = listItems[i].ItemId
+		}
+		for i, result := range items_service.ItemsService.GetItemsByIds(itemIds, analyzedAttributes) {
+			listItems[i].MeliItem, listItems[i].Error = result.Item, result.Error
+			if result.Error != nil || listItems[i].VariationId == 0 {
+				continue
+			}
+
+			// variations are analyzed with their own price and stock
+			variationItem, ok := result.Item.ForVariation(listItems[i].VariationId)
+			if !ok {
+				listItems[i].MeliItem = nil
+				listItems[i].Error = apierrors.NewNotFoundApiError(fmt.Sprintf("variation %d of item %s not found", listItems[i].VariationId, listItems[i].ItemId))
+				continue
+			}
+			listItems[i].MeliItem = variationItem
+		}
+
+		rules := subscribedRules(list)
+
+		// reviews and stock of every item are fetched concurrently, items that fail are analyzed on the next run
+		details := fanout_utils.Run(context.Background(), len(listItems), itemsFanOut, func(ctx context.Context, i int) (interface{}, apierrors.ApiError) {
+			return getItemDetails(listItems[i])
+		})
+
 		logrus.Info(fmt.Sprintf("about to analyze and save history for %d items from list %d", len(listItems), list.Id))
-		for _, item := range listItems {
-			lastHistory, err := items.ItemHistoryDao.GetLastItemHistory(item.ItemId)
-			if err != nil && err.Status() != http.StatusNotFound {
-				logrus.Error("error getting item last history", err)
+		for _, detail := range details {
+			item := listItems[detail.Index]
+			if detail.Err != nil {
+				logrus.Warn(fmt.Sprintf("skipping item %s of list %d", item.ItemId, list.Id), detail.Err)
 				continue
 			}
 
-			reviews, revErr := items_service.ItemsService.GetItemReviews(item.MeliItem.Id, item.MeliItem.CatalogProductId)
-			if revErr != nil {
+			lastHistory, err := items.ItemHistoryDao.GetLastItemHistory(item.ItemId, item.VariationId)
+			if err != nil && err.Status() != http.StatusNotFound {
+				logrus.Error("error getting item last history", err)
 				continue
 			}
 
-			realQ, err := items_provider.GetRealQuantity(item.MeliItem.Permalink)
-			if err == nil {
-				item.MeliItem.AvailableQuantity = int(*realQ)
+			d := detail.Value.(itemDetails)
+			if d.stock.Confidence > items.StockConfidenceNone {
+				item.MeliItem.AvailableQuantity = d.stock.Quantity
 			}
 
-			item.MeliItem.ReviewsQuantity = reviews.Paging.Total
+			item.MeliItem.ReviewsQuantity = d.reviewsQuantity
 
 			// analyze
 			if lastHistory != nil {
 				dateFetchedLastHistory, _ := time.Parse(config.DbDateLayout, lastHistory.DateFetched)
 				if time.Since(dateFetchedLastHistory).Hours() <= 10 {
-					fmt.Println(fmt.Sprintf("ignoring item %s history: recent history was persisted", item.ItemId))
+					fmt.Println(fmt.Sprintf("ignoring item %s (variation %d) history: recent history was persisted", item.ItemId, item.VariationId))
 					continue
 				}
 
 				if item.MeliItem.HasActiveDeal() && !lastHistory.HasDeal {
-					_, _ = notifications.NotificationsDao.SaveNotification(*notifications.NewDealActivatedNotification(list.Id, item.ItemId, item.MeliItem.Title))
+					saveNotification(item, notifications.NewDealActivatedNotification(list.Id, item.ItemId, item.MeliItem.Title))
 				} else if !item.MeliItem.HasActiveDeal() && lastHistory.HasDeal {
-					_, _ = notifications.NotificationsDao.SaveNotification(*notifications.NewDealEndedNotification(list.Id, item.ItemId, item.MeliItem.Title))
+					saveNotification(item, notifications.NewDealEndedNotification(list.Id, item.ItemId, item.MeliItem.Title))
 				}
 
 				if item.MeliItem.Price != lastHistory.Price {
-					_, _ = notifications.NotificationsDao.SaveNotification(*notifications.NewPriceChangeNotification(list.Id, item.ItemId, lastHistory.Price, item.MeliItem.Price, item.MeliItem.Title))
+					saveNotification(item, notifications.NewPriceChangeNotification(list.Id, item.ItemId, lastHistory.Price, item.MeliItem.Price, item.MeliItem.CurrencyId, item.MeliItem.Title))
 				}
 
-				if item.MeliItem.Status != "active" && lastHistory.Status == "active" {
-					_, _ = notifications.NotificationsDao.SaveNotification(*notifications.NewItemChangedStatusNotification(list.Id, item.ItemId, item.MeliItem.Title))
+				if item.ReachedTargetPrice(lastHistory.Price, item.MeliItem.Price) {
+					saveNotification(item, notifications.NewTargetPriceReachedNotification(list.Id, item.ItemId, item.MeliItem.Price, item.TargetPrice, item.MeliItem.CurrencyId, item.MeliItem.Title))
 				}
 
-				if item.MeliItem.AvailableQuantity == 0 &&
-					item.MeliItem.Status == "paused" &&
-					lastHistory.Quantity > 0 {
-					_, _ = notifications.NotificationsDao.SaveNotification(*notifications.NewEmptyStockNotification(list.Id, item.ItemId, item.MeliItem.Title))
+				if item.MeliItem.Status != "active" && lastHistory.Status == "active" {
+					saveNotification(item, notifications.NewItemChangedStatusNotification(list.Id, item.ItemId, item.MeliItem.Title))
 				}
 
-				if item.MeliItem.AvailableQuantity <= 3 && lastHistory.Quantity > 3 {
-					_, _ = notifications.NotificationsDao.SaveNotification(*notifications.NewNearEmptyStockNotification(list.Id, item.ItemId, item.MeliItem.AvailableQuantity, item.MeliItem.Title))
+				// the rounded quantity of the listing is not enough to tell users the stock is running out
+				if d.stock.IsReliable() {
+					if item.MeliItem.AvailableQuantity == 0 &&
+						item.MeliItem.Status == "paused" &&
+						lastHistory.Quantity > 0 {
+						saveNotification(item, notifications.NewEmptyStockNotification(list.Id, item.ItemId, item.MeliItem.Title))
+					}
+
+					if item.MeliItem.AvailableQuantity <= 3 && lastHistory.Quantity > 3 {
+						saveNotification(item, notifications.NewNearEmptyStockNotification(list.Id, item.ItemId, item.MeliItem.AvailableQuantity, item.MeliItem.Title))
+					}
 				}
 
 				if item.MeliItem.ReviewsQuantity > lastHistory.ReviewsQuantity {
-					_, _ = notifications.NotificationsDao.SaveNotification(*notifications.NewReviewItemNotification(list.Id, item.ItemId, item.MeliItem.Title))
+					saveNotification(item, notifications.NewReviewItemNotification(list.Id, item.ItemId, item.MeliItem.Title))
+				}
+
+				// then the alerts users set for themselves
+				itemRules := rules.ForItem(item.ItemId, item.VariationId)
+				if len(itemRules) > 0 {
+					observation := observe(list.Id, item, lastHistory, d, itemRules)
+					for _, rule := range itemRules {
+						if notification := rule.Evaluate(observation); notification != nil {
+							saveNotification(item, notification)
+						}
+					}
 				}
 			}
 
 			hist := items.ItemHistory{
 				ItemId:          item.MeliItem.Id,
+				VariationId:     item.VariationId,
 				Price:           item.MeliItem.Price,
+				CurrencyId:      item.MeliItem.CurrencyId,
 				Quantity:        item.MeliItem.AvailableQuantity,
 				Status:          item.MeliItem.Status,
 				HasDeal:         item.MeliItem.HasActiveDeal(),
@@ -117,3 +177,89 @@ func persistNotifications() {
 		}
 	}
 }
+
+// saveNotification saves a notification about the item of a list, naming its variation when it has one.
+func saveNotification(item items.ItemListDto, notification *notifications.Notification) {
+	if item.VariationId != 0 {
+		notification.ForVariation(item.VariationId, item.MeliItem.Variations[0].Name())
+	}
+	_, _ = notifications.NotificationsDao.SaveNotification(*notification)
+}
+
+type itemDetails struct {
+	reviewsQuantity int64
+	reviews         []items.Review
+	stock           items.StockResolution
+}
+
+// subscribedRules returns the alert rules set on the list by users that can still read it.
+func subscribedRules(list lists.List) alerts.AlertRules {
+	rules, err := alerts.AlertRuleDao.GetListRules(list.Id)
+	if err != nil {
+		logrus.Error(fmt.Sprintf("error getting alert rules of list %d", list.Id), err)
+		return nil
+	}
+	if len(rules) == 0 {
+		return rules
+	}
+
+	configs, err := share.GetListShareConfigs(list.Id)
+	if err != nil {
+		logrus.Error(fmt.Sprintf("error getting share configs of list %d", list.Id), err)
+		return nil
+	}
+
+	result := make(alerts.AlertRules, 0, len(rules))
+	for _, rule := range rules {
+		if list.Can(rule.UserId, lists.ActionRead, configs) {
+			result = append(result, rule)
+		}
+	}
+	return result
+}
+
+// observe gathers what the rules need to know of the item. The history of the item is only read when a rule looks
+// for its lowest price.
+func observe(listId int64, item items.ItemListDto, lastHistory *items.ItemHistory, d itemDetails, rules alerts.AlertRules) alerts.Observation {
+	observation := alerts.Observation{
+		ListId:        listId,
+		Item:          item.MeliItem,
+		LastHistory:   lastHistory,
+		StockReliable: d.stock.IsReliable(),
+	}
+
+	// reviews come newest first
+	if added := d.reviewsQuantity - lastHistory.ReviewsQuantity; added > 0 {
+		if added > int64(len(d.reviews)) {
+			added = int64(len(d.reviews))
+		}
+		observation.NewReviews = d.reviews[:added]
+	}
+
+	if rules.HasType(alerts.RuleTypeHistoricalLow) {
+		history, err := items.ItemHistoryDao.GetItemHistory(item.ItemId, item.VariationId)
+		if err != nil {
+			logrus.Error(fmt.Sprintf("error getting history of item %s", item.ItemId), err)
+		}
+		for _, h := range history {
+			if h.Price > 0 && (observation.LowestPrice == 0 || h.Price < observation.LowestPrice) {
+				observation.LowestPrice = h.Price
+			}
+		}
+	}
+
+	return observation
+}
+
+func getItemDetails(item items.ItemListDto) (interface{}, apierrors.ApiError) {
+	if item.Error != nil {
+		return nil, item.Error
+	}
+
+	reviews, err := items_service.ItemsService.GetItemReviews(item.MeliItem.Id, item.MeliItem.CatalogProductId)
+	if err != nil {
+		return nil, err
+	}
+
+	return itemDetails{reviewsQuantity: reviews.Paging.Total, reviews: reviews.Reviews, stock: items_provider.ResolveStock(*item.MeliItem)}, nil
+}
@@ -0,0 +1,251 @@
+package jobs
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"testing"
+
+	"github.com/lmurature/golang-restclient/rest"
+	"github.com/lmurature/melist-api/src/api/domain/alerts"
+	"github.com/lmurature/melist-api/src/api/domain/items"
+	"github.com/lmurature/melist-api/src/api/domain/lists"
+	"github.com/lmurature/melist-api/src/api/domain/notifications"
+	"github.com/lmurature/melist-api/src/api/domain/share"
+	"github.com/lmurature/melist-api/src/api/domain/users"
+	lists_service "github.com/lmurature/melist-api/src/api/services/lists"
+	"github.com/lmurature/melist-api/src/api/storage"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMain(m *testing.M) {
+	rest.StartMockupServer()
+	os.Exit(m.Run())
+}
+
+func setupListWithItem(t *testing.T) *lists.List {
+	storage.UseMemory()
+	users.UserDao.CreateUser(users.MelistUser{Id: 1, Nickname: "owner"})
+
+	list, err := lists.ListDao.CreateList(lists.List{OwnerId: 1, Title: "groceries", Privacy: lists.PrivacyTypePrivate})
+	assert.Nil(t, err)
+	items.ItemDao.InsertItem("MLA1")
+	items.ItemListDao.InsertItemToList(items.ItemListDto{ItemId: "MLA1", ListId: list.Id, Status: items.StatusNotChecked, UserId: 1})
+	addItemMockups(9, http.StatusOK, `<html><script>{"availableStock":7,"other":1}</script></html>`)
+
+	return list
+}
+
+// addItemMockups answers the item with the rounded available quantity of the listing and its page with pageStatus
+// and page.
+func addItemMockups(quantity int, pageStatus int, page string) {
+	rest.FlushMockups()
+	rest.AddMockups(&rest.Mock{
+		URL:          "https://api.mercadolibre.com/items?ids=MLA1&attributes=id,title,price,original_price,currency_id,status,available_quantity,deal_ids,permalink,catalog_product_id,variations",
+		HTTPMethod:   http.MethodGet,
+		RespHTTPCode: http.StatusOK,
+		RespBody:     fmt.Sprintf(`[{"code":200,"body":{"id":"MLA1","title":"Test item","price":500,"available_quantity":%d,"status":"active","permalink":"https://articulo.mercadolibre.com.ar/MLA-1"}}]`, quantity),
+	}, &rest.Mock{
+		URL:          "https://api.mercadolibre.com/reviews/item/MLA1?catalog_product_id=&limit=200&order=desc&order_criteria=dateCreated",
+		HTTPMethod:   http.MethodGet,
+		RespHTTPCode: http.StatusOK,
+		RespBody:     `{"paging":{"total":2},"reviews":[],"rating_average":4.5}`,
+	}, &rest.Mock{
+		URL:          "https://articulo.mercadolibre.com.ar/MLA-1",
+		HTTPMethod:   http.MethodGet,
+		RespHTTPCode: pageStatus,
+		RespBody:     page,
+	})
+}
+
+func TestPersistNotificationsFirstHistory(t *testing.T) {
+	list := setupListWithItem(t)
+
+	persistNotifications()
+
+	history, err := items.ItemHistoryDao.GetItemHistory("MLA1", 0)
+	assert.Nil(t, err)
+	assert.EqualValues(t, 1, len(history))
+	assert.EqualValues(t, 500, history[0].Price)
+	assert.EqualValues(t, 7, history[0].Quantity)
+	assert.EqualValues(t, 2, history[0].ReviewsQuantity)
+
+	listNotifications, err := notifications.NotificationsDao.GetListNotifications(list.Id)
+	assert.Nil(t, err)
+	assert.EqualValues(t, 0, len(listNotifications))
+}
+
+func TestPersistNotificationsPriceChange(t *testing.T) {
+	list := setupListWithItem(t)
+	items.ItemHistoryDao.InsertItemHistory(items.ItemHistory{
+		ItemId:          "MLA1",
+		Price:           600,
+		Quantity:        7,
+		Status:          "active",
+		ReviewsQuantity: 2,
+		DateFetched:     "2021-01-01 00:00:00",
+	})
+
+	persistNotifications()
+
+	history, err := items.ItemHistoryDao.GetItemHistory("MLA1", 0)
+	assert.Nil(t, err)
+	assert.EqualValues(t, 2, len(history))
+
+	listNotifications, err := notifications.NotificationsDao.GetListNotifications(list.Id)
+	assert.Nil(t, err)
+	assert.EqualValues(t, 1, len(listNotifications))
+	assert.EqualValues(t, "¡El producto Test item tuvo un cambio en su precio! Antes valía 600.00, ahora 500.00.", listNotifications[0].Message)
+}
+
+func TestPersistNotificationsTargetPriceReached(t *testing.T) {
+	list := setupListWithItem(t)
+	items.ItemListDao.UpdateItemDetails(items.ItemListDto{ItemId: "MLA1", ListId: list.Id, Quantity: 1, TargetPrice: 500, Priority: items.PriorityNormal})
+	items.ItemHistoryDao.InsertItemHistory(items.ItemHistory{
+		ItemId:          "MLA1",
+		Price:           600,
+		Quantity:        7,
+		Status:          "active",
+		ReviewsQuantity: 2,
+		DateFetched:     "2021-01-01 00:00:00",
+	})
+
+	persistNotifications()
+
+	listNotifications, err := notifications.NotificationsDao.GetListNotifications(list.Id)
+	assert.Nil(t, err)
+	assert.EqualValues(t, 2, len(listNotifications))
+	assert.EqualValues(t, notifications.TypeTargetPriceReached, listNotifications[0].Type)
+	assert.EqualValues(t, "¡El producto Test item llegó al precio que buscabas! Ahora vale 500.00 y querías pagar 500.00.", listNotifications[0].Message)
+}
+
+func TestPersistNotificationsAlertRules(t *testing.T) {
+	list := setupListWithItem(t)
+	users.UserDao.CreateUser(users.MelistUser{Id: 2, Nickname: "collaborator"})
+	users.UserDao.CreateUser(users.MelistUser{Id: 3, Nickname: "former collaborator"})
+	share.ShareConfigDao.CreateShareConfig(share.ShareConfig{UserId: 2, ListId: list.Id, ShareType: share.ShareTypeRead})
+	alerts.AlertRuleDao.CreateRule(alerts.AlertRule{UserId: 2, ListId: list.Id, Type: alerts.RuleTypePriceDrop, Threshold: 10})
+	alerts.AlertRuleDao.CreateRule(alerts.AlertRule{UserId: 2, ListId: list.Id, ItemId: "MLA2", Type: alerts.RuleTypePriceDrop, Threshold: 10})
+	alerts.AlertRuleDao.CreateRule(alerts.AlertRule{UserId: 3, ListId: list.Id, Type: alerts.RuleTypePriceDrop, Threshold: 10})
+	items.ItemHistoryDao.InsertItemHistory(items.ItemHistory{
+		ItemId:          "MLA1",
+		Price:           600,
+		Quantity:        7,
+		Status:          "active",
+		ReviewsQuantity: 2,
+		DateFetched:     "2021-01-01 00:00:00",
+	})
+
+	persistNotifications()
+
+	owner, err := notifications.NotificationsDao.GetUserNotifications(1, false)
+	assert.Nil(t, err)
+	assert.EqualValues(t, 1, len(owner))
+	assert.EqualValues(t, notifications.TypePriceChange, owner[0].Type)
+
+	collaborator, err := notifications.NotificationsDao.GetUserNotifications(2, false)
+	assert.Nil(t, err)
+	assert.EqualValues(t, 2, len(collaborator))
+	assert.EqualValues(t, notifications.TypeAlertPriceDrop, collaborator[0].Type)
+	assert.EqualValues(t, "¡El precio del producto Test item bajó un 10% o más! Antes valía 600.00, ahora 500.00.", collaborator[0].Message)
+
+	formerCollaborator, err := notifications.NotificationsDao.GetUserNotifications(3, false)
+	assert.Nil(t, err)
+	assert.EqualValues(t, 0, len(formerCollaborator))
+
+	// alerts are not shown to the other users of the list either
+	listNotifications, err := lists_service.ListsService.GetListNotifications(list.Id, 1, "es-AR")
+	assert.Nil(t, err)
+	assert.EqualValues(t, 1, len(listNotifications))
+}
+
+func TestPersistNotificationsNearEmptyStock(t *testing.T) {
+	list := setupListWithItem(t)
+	addItemMockups(9, http.StatusOK, `<html><script>window.__PRELOADED_STATE__ = {"availableStock":2};</script></html>`)
+	items.ItemHistoryDao.InsertItemHistory(items.ItemHistory{
+		ItemId:          "MLA1",
+		Price:           500,
+		Quantity:        7,
+		Status:          "active",
+		ReviewsQuantity: 2,
+		DateFetched:     "2021-01-01 00:00:00",
+	})
+
+	persistNotifications()
+
+	listNotifications, err := notifications.NotificationsDao.GetListNotifications(list.Id)
+	assert.Nil(t, err)
+	assert.EqualValues(t, 1, len(listNotifications))
+	assert.EqualValues(t, notifications.TypeNearEmptyStock, listNotifications[0].Type)
+}
+
+func TestPersistNotificationsIgnoresUnreliableStock(t *testing.T) {
+	list := setupListWithItem(t)
+	addItemMockups(1, http.StatusForbidden, `<html>captcha</html>`)
+	items.ItemHistoryDao.InsertItemHistory(items.ItemHistory{
+		ItemId:          "MLA1",
+		Price:           500,
+		Quantity:        15,
+		Status:          "active",
+		ReviewsQuantity: 2,
+		DateFetched:     "2021-01-01 00:00:00",
+	})
+
+	persistNotifications()
+
+	history, err := items.ItemHistoryDao.GetItemHistory("MLA1", 0)
+	assert.Nil(t, err)
+	assert.EqualValues(t, 2, len(history))
+
+	listNotifications, err := notifications.NotificationsDao.GetListNotifications(list.Id)
+	assert.Nil(t, err)
+	assert.EqualValues(t, 0, len(listNotifications))
+}
+
+func TestPersistNotificationsPerVariation(t *testing.T) {
+	storage.UseMemory()
+	users.UserDao.CreateUser(users.MelistUser{Id: 1, Nickname: "owner"})
+	list, err := lists.ListDao.CreateList(lists.List{OwnerId: 1, Title: "ropa", Privacy: lists.PrivacyTypePrivate})
+	assert.Nil(t, err)
+	items.ItemDao.InsertItem("MLA1")
+	items.ItemListDao.InsertItemToList(items.ItemListDto{ItemId: "MLA1", VariationId: 11, ListId: list.Id, Status: items.StatusNotChecked, UserId: 1})
+	rest.FlushMockups()
+	rest.AddMockups(&rest.Mock{
+		URL:          "https://api.mercadolibre.com/items?ids=MLA1&attributes=id,title,price,original_price,currency_id,status,available_quantity,deal_ids,permalink,catalog_product_id,variations",
+		HTTPMethod:   http.MethodGet,
+		RespHTTPCode: http.StatusOK,
+		RespBody:     `[{"code":200,"body":{"id":"MLA1","title":"Test item","price":500,"available_quantity":9,"status":"active","variations":[{"id":11,"price":450,"available_quantity":4,"attribute_combinations":[{"id":"COLOR","value_name":"Azul"}]},{"id":12,"price":500,"available_quantity":5}]}}]`,
+	}, &rest.Mock{
+		URL:          "https://api.mercadolibre.com/reviews/item/MLA1?catalog_product_id=&limit=200&order=desc&order_criteria=dateCreated",
+		HTTPMethod:   http.MethodGet,
+		RespHTTPCode: http.StatusOK,
+		RespBody:     `{"paging":{"total":2},"reviews":[],"rating_average":4.5}`,
+	})
+	items.ItemHistoryDao.InsertItemHistory(items.ItemHistory{
+		ItemId:          "MLA1",
+		VariationId:     11,
+		Price:           600,
+		Quantity:        4,
+		Status:          "active",
+		ReviewsQuantity: 2,
+		DateFetched:     "2021-01-01 00:00:00",
+	})
+
+	persistNotifications()
+
+	history, err := items.ItemHistoryDao.GetItemHistory("MLA1", 11)
+	assert.Nil(t, err)
+	assert.EqualValues(t, 2, len(history))
+	assert.EqualValues(t, 450, history[1].Price)
+	assert.EqualValues(t, 4, history[1].Quantity)
+
+	history, err = items.ItemHistoryDao.GetItemHistory("MLA1", 0)
+	assert.Nil(t, err)
+	assert.EqualValues(t, 0, len(history))
+
+	listNotifications, err := notifications.NotificationsDao.GetListNotifications(list.Id)
+	assert.Nil(t, err)
+	assert.EqualValues(t, 1, len(listNotifications))
+	assert.EqualValues(t, "¡El producto Test item (Azul) tuvo un cambio en su precio! Antes valía 600.00, ahora 450.00.", listNotifications[0].Message)
+	assert.EqualValues(t, 11, listNotifications[0].Params.VariationId)
+}
@@ -0,0 +1,44 @@
+package jobs
+
+import (
+	"fmt"
+	"github.com/lmurature/melist-api/src/api/config"
+	"github.com/lmurature/melist-api/src/api/domain/lists"
+	lists_service "github.com/lmurature/melist-api/src/api/services/lists"
+	"github.com/onatm/clockwerk"
+	"github.com/sirupsen/logrus"
+	"time"
+)
+
+var (
+	ListsJobs clockwerk.Job
+)
+
+type ListsJobsStruct struct{}
+
+func init() {
+	ListsJobs = &ListsJobsStruct{}
+}
+
+func (l ListsJobsStruct) Run() {
+	// hard delete lists that stayed in the trash bin longer than the retention period
+	go purgeExpiredLists()
+}
+
+func purgeExpiredLists() {
+	deletedBefore := time.Now().UTC().AddDate(0, 0, -config.ListTrashRetentionDays).Format(config.DbDateLayout)
+
+	expired, err := lists.ListDao.GetExpiredTrashedLists(deletedBefore)
+	if err != nil {
+		logrus.Error("error while getting expired trashed lists", err)
+		return
+	}
+
+	logrus.Info(fmt.Sprintf("about to purge %d lists deleted before %s", len(expired), deletedBefore))
+	for _, list := range expired {
+		if err := lists_service.ListsService.PurgeList(list.Id); err != nil {
+			logrus.Error(fmt.Sprintf("error while purging list %d", list.Id), err)
+			continue
+		}
+	}
+}
@@ -0,0 +1,36 @@
+package jobs
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/lmurature/melist-api/src/api/config"
+	"github.com/lmurature/melist-api/src/api/domain/lists"
+	"github.com/lmurature/melist-api/src/api/storage"
+	date_utils "github.com/lmurature/melist-api/src/api/utils/date"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPurgeExpiredLists(t *testing.T) {
+	storage.UseMemory()
+
+	expired, _ := lists.ListDao.CreateList(lists.List{OwnerId: 1, Title: "old", Privacy: lists.PrivacyTypePrivate})
+	recent, _ := lists.ListDao.CreateList(lists.List{OwnerId: 1, Title: "recent", Privacy: lists.PrivacyTypePrivate})
+	active, _ := lists.ListDao.CreateList(lists.List{OwnerId: 1, Title: "active", Privacy: lists.PrivacyTypePrivate})
+
+	longAgo := time.Now().UTC().AddDate(0, 0, -config.ListTrashRetentionDays-1).Format(config.DbDateLayout)
+	lists.ListDao.TrashList(expired.Id, longAgo)
+	lists.ListDao.TrashList(recent.Id, date_utils.GetNowDateFormatted())
+
+	purgeExpiredLists()
+
+	_, err := lists.ListDao.GetTrashedList(expired.Id)
+	assert.EqualValues(t, http.StatusNotFound, err.Status())
+
+	_, err = lists.ListDao.GetTrashedList(recent.Id)
+	assert.Nil(t, err)
+
+	_, err = lists.ListDao.GetList(active.Id)
+	assert.Nil(t, err)
+}
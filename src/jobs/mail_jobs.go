@@ -0,0 +1,40 @@
+package jobs
+
+import (
+	mail_service "github.com/lmurature/melist-api/src/api/services/mail"
+	"github.com/onatm/clockwerk"
+	"github.com/sirupsen/logrus"
+	"sync/atomic"
+	"time"
+)
+
+var (
+	MailJobs clockwerk.Job
+)
+
+// MailJobsStruct sends one batch of mails at a time: pending notifications are only marked as mailed once sent, so
+// a run that overlapped a slow one would mail them again.
+type MailJobsStruct struct {
+	running int32
+}
+
+func init() {
+	MailJobs = &MailJobsStruct{}
+}
+
+func (m *MailJobsStruct) Run() {
+	// mail instant notifications and the digests that are due
+	go m.sendPendingMails()
+}
+
+func (m *MailJobsStruct) sendPendingMails() {
+	if !atomic.CompareAndSwapInt32(&m.running, 0, 1) {
+		logrus.Info("skipping pending mails, the previous run did not finish yet")
+		return
+	}
+	defer atomic.StoreInt32(&m.running, 0)
+
+	if err := mail_service.MailService.SendPendingMails(time.Now().UTC()); err != nil {
+		logrus.Error("error while sending pending mails", err)
+	}
+}
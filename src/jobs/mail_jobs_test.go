@@ -0,0 +1,54 @@
+package jobs
+
+import (
+	"sync/atomic"
+	"testing"
+
+	"github.com/lmurature/melist-api/src/api/config"
+	"github.com/lmurature/melist-api/src/api/domain/notifications"
+	"github.com/lmurature/melist-api/src/api/domain/users"
+	mail_service "github.com/lmurature/melist-api/src/api/services/mail"
+	test_utils "github.com/lmurature/melist-api/src/api/utils/test"
+	"github.com/lmurature/melist-api/src/mailsink"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOverlappingMailRunsSendOnce(t *testing.T) {
+	list := test_utils.SetupSharedList(t)
+
+	sink, err := mailsink.Listen("127.0.0.1:0")
+	assert.Nil(t, err)
+	go sink.Serve()
+	t.Cleanup(func() { sink.Close() })
+	config.SmtpAddress = sink.Addr()
+	config.EmailAddress = ""
+
+	_, apiErr := mail_service.MailService.UpdateMailPreferences(test_utils.OwnerId, users.MailPreferences{Frequency: users.MailFrequencyInstant})
+	assert.Nil(t, apiErr)
+	notifications.NotificationsDao.SaveNotification(*notifications.NewDealActivatedNotification(list.Id, "MLA1", "Coffee"))
+
+	// the first run stays in the middle of sending until the second one is over
+	var messages int32
+	received := make(chan struct{})
+	release := make(chan struct{})
+	sink.OnMessage = func(m mailsink.Message) {
+		if atomic.AddInt32(&messages, 1) == 1 {
+			close(received)
+			<-release
+		}
+	}
+
+	job := &MailJobsStruct{}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		job.sendPendingMails()
+	}()
+	<-received
+
+	job.sendPendingMails()
+	close(release)
+	<-done
+
+	assert.EqualValues(t, 1, len(sink.Messages()))
+}
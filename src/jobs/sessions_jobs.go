@@ -0,0 +1,38 @@
+package jobs
+
+import (
+	"github.com/lmurature/melist-api/src/api/config"
+	"github.com/lmurature/melist-api/src/api/domain/auth"
+	"github.com/lmurature/melist-api/src/api/domain/sessions"
+	"github.com/onatm/clockwerk"
+	"github.com/sirupsen/logrus"
+	"time"
+)
+
+var (
+	SessionsJobs clockwerk.Job
+)
+
+type SessionsJobsStruct struct{}
+
+func init() {
+	SessionsJobs = &SessionsJobsStruct{}
+}
+
+func (s SessionsJobsStruct) Run() {
+	// delete sessions that can no longer be refreshed, revoked or not, and logins that were never finished
+	go purgeExpiredSessions()
+	go purgeExpiredLoginStates()
+}
+
+func purgeExpiredSessions() {
+	if err := sessions.SessionDao.DeleteExpiredSessions(time.Now().UTC().Format(config.DbDateLayout)); err != nil {
+		logrus.Error("error while purging expired sessions", err)
+	}
+}
+
+func purgeExpiredLoginStates() {
+	if err := auth.LoginStateDao.DeleteExpiredLoginStates(time.Now().UTC().Format(config.DbDateLayout)); err != nil {
+		logrus.Error("error while purging expired login states", err)
+	}
+}
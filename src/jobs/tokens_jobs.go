@@ -0,0 +1,46 @@
+package jobs
+
+import (
+	"fmt"
+	"github.com/lmurature/melist-api/src/api/config"
+	"github.com/lmurature/melist-api/src/api/domain/users"
+	tokens_service "github.com/lmurature/melist-api/src/api/services/tokens"
+	"github.com/onatm/clockwerk"
+	"github.com/sirupsen/logrus"
+	"time"
+)
+
+var (
+	TokensJobs clockwerk.Job
+)
+
+type TokensJobsStruct struct{}
+
+func init() {
+	TokensJobs = &TokensJobsStruct{}
+}
+
+func (t TokensJobsStruct) Run() {
+	// refresh the mercado libre tokens of users ahead of their expiry, so their refresh tokens do not go stale while
+	// they are away. It must run more often than config.MeliTokenRefreshMargin to catch every token in time.
+	go refreshExpiringTokens()
+}
+
+func refreshExpiringTokens() {
+	expiresBefore := time.Now().UTC().Add(config.MeliTokenRefreshMargin).Format(config.DbDateLayout)
+
+	expiring, err := users.UserDao.GetUsersWithExpiringTokens(expiresBefore)
+	if err != nil {
+		logrus.Error("error while getting users with expiring tokens", err)
+		return
+	}
+
+	logrus.Info(fmt.Sprintf("about to refresh the tokens of %d users expiring before %s", len(expiring), expiresBefore))
+	for _, user := range expiring {
+		// GetAccessToken refreshes the token, or drops it when the user revoked the authorization of melist
+		if _, err := tokens_service.TokensService.GetAccessToken(user.Id); err != nil {
+			logrus.Error(fmt.Sprintf("error while refreshing the tokens of user %d", user.Id), err)
+			continue
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package jobs
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/lmurature/golang-restclient/rest"
+	"github.com/lmurature/melist-api/src/api/config"
+	"github.com/lmurature/melist-api/src/api/domain/auth"
+	"github.com/lmurature/melist-api/src/api/domain/users"
+	tokens_service "github.com/lmurature/melist-api/src/api/services/tokens"
+	"github.com/lmurature/melist-api/src/api/storage"
+	crypto_utils "github.com/lmurature/melist-api/src/api/utils/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRefreshExpiringTokens(t *testing.T) {
+	storage.UseMemory()
+	previousKey := config.TokenEncryptionKey
+	config.TokenEncryptionKey = "test-key"
+	t.Cleanup(func() { config.TokenEncryptionKey = previousKey })
+
+	users.UserDao.CreateUser(users.MelistUser{Id: 1})
+	users.UserDao.CreateUser(users.MelistUser{Id: 2})
+	users.UserDao.CreateUser(users.MelistUser{Id: 3})
+	assert.Nil(t, tokens_service.TokensService.SaveTokens(1, auth.MeliAuthResponse{AccessToken: "APP_USR-1", RefreshToken: "TG-1", ExpiresIn: 300}))
+	assert.Nil(t, tokens_service.TokensService.SaveTokens(2, auth.MeliAuthResponse{AccessToken: "APP_USR-2", RefreshToken: "TG-2", ExpiresIn: 21600}))
+
+	rest.FlushMockups()
+	rest.AddMockups(&rest.Mock{
+		URL:          "https://api.mercadolibre.com/oauth/token",
+		HTTPMethod:   http.MethodPost,
+		RespHTTPCode: http.StatusOK,
+		RespBody:     `{"access_token": "APP_USR-1b", "token_type": "bearer", "expires_in": 21600, "user_id": 1, "refresh_token": "TG-1b"}`,
+	})
+
+	refreshExpiringTokens()
+
+	expiring, _ := users.UserDao.GetUser(1)
+	refreshToken, _ := crypto_utils.Decrypt(expiring.RefreshToken)
+	assert.EqualValues(t, "TG-1b", refreshToken, "tokens that expire within the margin are refreshed")
+
+	good, _ := users.UserDao.GetUser(2)
+	refreshToken, _ = crypto_utils.Decrypt(good.RefreshToken)
+	assert.EqualValues(t, "TG-2", refreshToken)
+
+	withoutTokens, _ := users.UserDao.GetUser(3)
+	assert.Empty(t, withoutTokens.RefreshToken)
+}
@@ -0,0 +1,216 @@
+package mailsink
+
+import (
+	"io/ioutil"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/mail"
+	"net/textproto"
+	"strings"
+	"sync"
+)
+
+// Message is an email accepted by the sink.
+type Message struct {
+	From string
+	To   []string
+	Data string
+}
+
+// Subject returns the decoded Subject header of the message.
+func (m Message) Subject() string {
+	msg, err := m.parse()
+	if err != nil {
+		return ""
+	}
+	subject, err := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject"))
+	if err != nil {
+		return msg.Header.Get("Subject")
+	}
+	return subject
+}
+
+// Body returns the raw message without its headers.
+func (m Message) Body() string {
+	if i := strings.Index(m.Data, "\r\n\r\n"); i >= 0 {
+		return m.Data[i+4:]
+	}
+	return ""
+}
+
+// Text returns the decoded text/plain part of the message, or the whole body if it is not multipart.
+func (m Message) Text() string {
+	return m.part("text/plain")
+}
+
+// Html returns the decoded text/html part of a multipart message.
+func (m Message) Html() string {
+	return m.part("text/html")
+}
+
+func (m Message) parse() (*mail.Message, error) {
+	return mail.ReadMessage(strings.NewReader(m.Data))
+}
+
+func (m Message) part(contentType string) string {
+	msg, err := m.parse()
+	if err != nil {
+		return ""
+	}
+
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	if err != nil || !strings.HasPrefix(mediaType, "multipart/") {
+		if contentType == "text/plain" {
+			return m.Body()
+		}
+		return ""
+	}
+
+	reader := multipart.NewReader(msg.Body, params["boundary"])
+	for {
+		p, err := reader.NextPart()
+		if err != nil {
+			return ""
+		}
+		// NextPart already decodes quoted-printable parts
+		if partType, _, _ := mime.ParseMediaType(p.Header.Get("Content-Type")); partType == contentType {
+			content, _ := ioutil.ReadAll(p)
+			return string(content)
+		}
+	}
+}
+
+// Sink is a minimal SMTP server that accepts every message and keeps it in memory instead of delivering it.
+// It does not support TLS nor AUTH, so clients must send without credentials.
+type Sink struct {
+	// OnMessage is called for every accepted message when set.
+	OnMessage func(m Message)
+
+	listener net.Listener
+	mu       sync.Mutex
+	messages []Message
+}
+
+// Listen opens the sink on addr; use "127.0.0.1:0" to get a free port in tests.
+func Listen(addr string) (*Sink, error) {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	return &Sink{listener: listener}, nil
+}
+
+func (s *Sink) Addr() string {
+	return s.listener.Addr().String()
+}
+
+// Serve accepts connections until the sink is closed.
+func (s *Sink) Serve() error {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return err
+		}
+		go s.handle(conn)
+	}
+}
+
+func (s *Sink) Close() error {
+	return s.listener.Close()
+}
+
+// Messages returns a copy of the accepted messages, oldest first.
+func (s *Sink) Messages() []Message {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append(make([]Message, 0, len(s.messages)), s.messages...)
+}
+
+// Reset forgets the accepted messages.
+func (s *Sink) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.messages = nil
+}
+
+func (s *Sink) handle(conn net.Conn) {
+	defer conn.Close()
+	tp := textproto.NewConn(conn)
+
+	reply := func(format string, args ...interface{}) bool {
+		return tp.PrintfLine(format, args...) == nil
+	}
+
+	if !reply("220 melist mail sink ready") {
+		return
+	}
+
+	var current Message
+	for {
+		line, err := tp.ReadLine()
+		if err != nil {
+			return
+		}
+
+		verb := strings.ToUpper(strings.SplitN(line, " ", 2)[0])
+		arg := strings.TrimSpace(strings.TrimPrefix(line, strings.SplitN(line, " ", 2)[0]))
+
+		switch verb {
+		case "HELO", "EHLO":
+			reply("250 melist mail sink")
+		case "MAIL":
+			current = Message{From: address(arg)}
+			reply("250 OK")
+		case "RCPT":
+			current.To = append(current.To, address(arg))
+			reply("250 OK")
+		case "DATA":
+			if !reply("354 end data with <CR><LF>.<CR><LF>") {
+				return
+			}
+			data, err := tp.ReadDotBytes()
+			if err != nil {
+				return
+			}
+			// ReadDotBytes normalizes line endings to \n, restore them so headers keep their separator
+			current.Data = strings.ReplaceAll(string(data), "\n", "\r\n")
+			s.store(current)
+			current = Message{}
+			reply("250 OK: queued")
+		case "RSET":
+			current = Message{}
+			reply("250 OK")
+		case "NOOP":
+			reply("250 OK")
+		case "QUIT":
+			reply("221 bye")
+			return
+		default:
+			reply("502 command not implemented")
+		}
+	}
+}
+
+func (s *Sink) store(m Message) {
+	s.mu.Lock()
+	s.messages = append(s.messages, m)
+	onMessage := s.OnMessage
+	s.mu.Unlock()
+
+	if onMessage != nil {
+		onMessage(m)
+	}
+}
+
+// address extracts the mailbox of a "FROM:<a@b.c>" or "TO:<a@b.c>" argument.
+func address(arg string) string {
+	if i := strings.Index(arg, ":"); i >= 0 {
+		arg = arg[i+1:]
+	}
+	arg = strings.TrimSpace(arg)
+	if i := strings.Index(arg, " "); i >= 0 {
+		arg = arg[:i]
+	}
+	return strings.Trim(arg, "<>")
+}
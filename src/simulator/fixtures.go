@@ -0,0 +1,86 @@
+package simulator
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/lmurature/melist-api/src/api/domain/items"
+	"github.com/lmurature/melist-api/src/api/domain/users"
+)
+
+const (
+	itemsFixture      = "items.json"
+	categoriesFixture = "categories.json"
+	trendsFixture     = "trends.json"
+	usersFixture      = "users.json"
+)
+
+// FixtureItem is an item as served by the simulator, together with its description and reviews.
+type FixtureItem struct {
+	items.Item
+	PlainText   string                     `json:"plain_text"`
+	ItemReviews *items.ItemReviewsResponse `json:"item_reviews,omitempty"`
+}
+
+// clone returns a copy of the item that does not share its reviews with i.
+func (i FixtureItem) clone() FixtureItem {
+	if i.ItemReviews != nil {
+		reviews := *i.ItemReviews
+		reviews.Reviews = append([]items.Review(nil), i.ItemReviews.Reviews...)
+		i.ItemReviews = &reviews
+	}
+	return i
+}
+
+// FixtureUser is a Mercado Libre user and the credentials the simulated oauth flow accepts for it.
+type FixtureUser struct {
+	users.User
+	AuthorizationCode string `json:"authorization_code"`
+	AccessToken       string `json:"access_token"`
+	RefreshToken      string `json:"refresh_token"`
+}
+
+type Fixtures struct {
+	Items      []FixtureItem                   `json:"items"`
+	Categories []items.Category                `json:"categories"`
+	Trends     map[string]items.CategoryTrends `json:"trends"`
+	Users      []FixtureUser                   `json:"users"`
+}
+
+// LoadFixtures reads every fixture file found in dir. Missing files are treated as empty.
+func LoadFixtures(dir string) (*Fixtures, error) {
+	var fixtures Fixtures
+
+	if err := readFixture(filepath.Join(dir, itemsFixture), &fixtures.Items); err != nil {
+		return nil, err
+	}
+	if err := readFixture(filepath.Join(dir, categoriesFixture), &fixtures.Categories); err != nil {
+		return nil, err
+	}
+	if err := readFixture(filepath.Join(dir, trendsFixture), &fixtures.Trends); err != nil {
+		return nil, err
+	}
+	if err := readFixture(filepath.Join(dir, usersFixture), &fixtures.Users); err != nil {
+		return nil, err
+	}
+
+	return &fixtures, nil
+}
+
+func readFixture(path string, target interface{}) error {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+
+	if err := json.Unmarshal(data, target); err != nil {
+		return fmt.Errorf("invalid fixture %s: %v", path, err)
+	}
+	return nil
+}
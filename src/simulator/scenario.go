@@ -0,0 +1,91 @@
+package simulator
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	ActionSetPrice  = "set_price"
+	ActionStartDeal = "start_deal"
+	ActionEndDeal   = "end_deal"
+	ActionSetStock  = "set_stock"
+	ActionSetStatus = "set_status"
+)
+
+// Step is a state change applied After a delay counted from the moment the scenario starts.
+type Step struct {
+	After    string  `json:"after"`
+	Action   string  `json:"action"`
+	ItemId   string  `json:"item_id"`
+	Price    float32 `json:"price,omitempty"`
+	Quantity int     `json:"quantity,omitempty"`
+	Status   string  `json:"status,omitempty"`
+}
+
+type Scenario struct {
+	Steps []Step `json:"steps"`
+}
+
+func LoadScenario(path string) (*Scenario, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var scenario Scenario
+	if err := json.Unmarshal(data, &scenario); err != nil {
+		return nil, fmt.Errorf("invalid scenario %s: %v", path, err)
+	}
+
+	for _, step := range scenario.Steps {
+		if _, err := time.ParseDuration(step.After); err != nil {
+			return nil, fmt.Errorf("invalid delay '%s' for step %s on item %s", step.After, step.Action, step.ItemId)
+		}
+	}
+
+	return &scenario, nil
+}
+
+// Play schedules every step of the scenario on state. The returned function cancels the steps still pending.
+func (scenario *Scenario) Play(state *State) (stop func()) {
+	timers := make([]*time.Timer, 0, len(scenario.Steps))
+	for _, step := range scenario.Steps {
+		delay, _ := time.ParseDuration(step.After)
+		s := step
+		timers = append(timers, time.AfterFunc(delay, func() {
+			if err := s.Apply(state); err != nil {
+				logrus.Error(fmt.Sprintf("error applying scenario step %s on item %s", s.Action, s.ItemId), err)
+				return
+			}
+			logrus.Info(fmt.Sprintf("applied scenario step %s on item %s", s.Action, s.ItemId))
+		}))
+	}
+
+	return func() {
+		for _, t := range timers {
+			t.Stop()
+		}
+	}
+}
+
+func (step Step) Apply(state *State) error {
+	switch step.Action {
+	case ActionSetPrice:
+		return state.SetPrice(step.ItemId, step.Price)
+	case ActionStartDeal:
+		return state.StartDeal(step.ItemId, step.Price)
+	case ActionEndDeal:
+		return state.EndDeal(step.ItemId)
+	case ActionSetStock:
+		return state.SetStock(step.ItemId, step.Quantity)
+	case ActionSetStatus:
+		return state.SetStatus(step.ItemId, step.Status)
+	default:
+		return fmt.Errorf("unknown scenario action %s", step.Action)
+	}
+}
@@ -0,0 +1,444 @@
+package simulator
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lmurature/melist-api/src/api/domain/apierrors"
+	"github.com/lmurature/melist-api/src/api/domain/auth"
+	"github.com/lmurature/melist-api/src/api/domain/items"
+	"github.com/lmurature/melist-api/src/api/domain/sites"
+)
+
+const (
+	defaultSearchLimit = 50
+	maxSearchLimit     = 50
+	permalinkUri       = "/vip/%s"
+	vipPage            = `<!DOCTYPE html><html><head><title>%s</title></head><body><script>window.__PRELOADED_STATE__ = {"id":"%s","availableStock":%d,"price":%.2f};</script></body></html>`
+)
+
+var (
+	searchSorts = map[string]items.Sort{
+		items.SearchSortRelevance: {Id: items.SearchSortRelevance, Name: "Más relevantes"},
+		items.SearchSortPriceAsc:  {Id: items.SearchSortPriceAsc, Name: "Menor precio"},
+		items.SearchSortPriceDesc: {Id: items.SearchSortPriceDesc, Name: "Mayor precio"},
+	}
+)
+
+// NewServer serves the subset of the Mercado Libre API melist uses, backed by state.
+// Routes under /_simulator change the state at runtime.
+func NewServer(state *State) *gin.Engine {
+	router := gin.New()
+	router.Use(gin.Recovery())
+
+	h := &handler{state: state}
+
+	router.GET("/items", h.getItems)
+	router.GET("/items/:item_id", h.getItem)
+	router.GET("/items/:item_id/description", h.getItemDescription)
+	router.GET("/reviews/item/:item_id", h.getItemReviews)
+	router.GET("/categories/:category_id", h.getCategory)
+	router.GET("/trends/:site_id/:category_id", h.getTrends)
+	router.GET("/sites/:site_id/search", h.search)
+	router.GET("/users/me", h.getMyUser)
+	router.GET("/users/:user_id", h.getUser)
+	router.POST("/oauth/token", h.createToken)
+	router.GET("/vip/:item_id", h.getItemPage)
+
+	router.POST("/_simulator/items/:item_id/price", h.setPrice)
+	router.POST("/_simulator/items/:item_id/deal/start", h.startDeal)
+	router.POST("/_simulator/items/:item_id/deal/end", h.endDeal)
+	router.POST("/_simulator/items/:item_id/stock", h.setStock)
+	router.POST("/_simulator/items/:item_id/status", h.setStatus)
+	router.POST("/_simulator/items/:item_id/reviews", h.addReview)
+
+	return router
+}
+
+type handler struct {
+	state *State
+}
+
+type stateChange struct {
+	Price    float32 `json:"price"`
+	Quantity *int    `json:"quantity"`
+	Status   string  `json:"status"`
+}
+
+func (h *handler) getItem(c *gin.Context) {
+	item, ok := h.state.GetItem(c.Param("item_id"))
+	if !ok {
+		notFound(c, fmt.Sprintf("Item with id %s not found.", c.Param("item_id")))
+		return
+	}
+
+	c.JSON(http.StatusOK, h.servedItem(c, item))
+}
+
+// getItems is the multiget form, which answers one entry with its own status per id and can project attributes.
+func (h *handler) getItems(c *gin.Context) {
+	ids := strings.Split(c.Query("ids"), ",")
+	if c.Query("ids") == "" || len(ids) > items.MultigetMaxIds {
+		badRequest(c, fmt.Sprintf("ids must have between 1 and %d item ids", items.MultigetMaxIds))
+		return
+	}
+
+	var attributes []string
+	if c.Query("attributes") != "" {
+		attributes = strings.Split(c.Query("attributes"), ",")
+	}
+
+	result := make([]gin.H, 0, len(ids))
+	for _, id := range ids {
+		item, ok := h.state.GetItem(id)
+		if !ok {
+			result = append(result, gin.H{"code": http.StatusNotFound, "body": apierrors.NewNotFoundApiError(fmt.Sprintf("Item with id %s not found.", id))})
+			continue
+		}
+		result = append(result, gin.H{"code": http.StatusOK, "body": project(h.servedItem(c, item), attributes)})
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+func (h *handler) getItemDescription(c *gin.Context) {
+	item, ok := h.state.GetItem(c.Param("item_id"))
+	if !ok {
+		notFound(c, fmt.Sprintf("Item with id %s not found.", c.Param("item_id")))
+		return
+	}
+
+	c.JSON(http.StatusOK, items.ItemDescription{Id: item.Id, PlainText: item.PlainText})
+}
+
+func (h *handler) getItemReviews(c *gin.Context) {
+	item, ok := h.state.GetItem(c.Param("item_id"))
+	if !ok {
+		notFound(c, fmt.Sprintf("Item with id %s not found.", c.Param("item_id")))
+		return
+	}
+
+	reviews := items.ItemReviewsResponse{Reviews: []items.Review{}}
+	if item.ItemReviews != nil {
+		reviews = *item.ItemReviews
+	}
+	reviews.Paging.Total = int64(len(reviews.Reviews))
+	c.JSON(http.StatusOK, reviews)
+}
+
+func (h *handler) getCategory(c *gin.Context) {
+	category, ok := h.state.GetCategory(c.Param("category_id"))
+	if !ok {
+		notFound(c, fmt.Sprintf("Category %s not found", c.Param("category_id")))
+		return
+	}
+
+	c.JSON(http.StatusOK, category)
+}
+
+func (h *handler) getTrends(c *gin.Context) {
+	trends, ok := h.state.GetTrends(c.Param("site_id"), c.Param("category_id"))
+	if !ok {
+		notFound(c, fmt.Sprintf("trends for category %s not found", c.Param("category_id")))
+		return
+	}
+
+	c.JSON(http.StatusOK, trends)
+}
+
+func (h *handler) search(c *gin.Context) {
+	offset, err := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if err != nil || offset < 0 {
+		badRequest(c, "invalid offset")
+		return
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(defaultSearchLimit)))
+	if err != nil || limit < 0 || limit > maxSearchLimit {
+		badRequest(c, "invalid limit")
+		return
+	}
+
+	sortId := c.DefaultQuery("sort", items.SearchSortRelevance)
+	if _, ok := searchSorts[sortId]; !ok {
+		badRequest(c, "invalid sort")
+		return
+	}
+
+	siteId := c.Param("site_id")
+	found := filterSearch(h.state.Search(siteId, c.Query("q")), c)
+
+	page := make([]items.Item, 0)
+	for i := offset; i < len(found) && i < offset+limit; i++ {
+		page = append(page, h.servedItem(c, &found[i]))
+	}
+
+	c.JSON(http.StatusOK, items.ItemSearchResponse{
+		SiteId: siteId,
+		Query:  c.Query("q"),
+		Paging: items.Paging{
+			Total:  int64(len(found)),
+			Offset: int64(offset),
+			Limit:  int64(limit),
+		},
+		Result:           page,
+		Sort:             searchSorts[sortId],
+		AvailableSorts:   availableSorts(sortId),
+		Filters:          appliedFilters(c),
+		AvailableFilters: []items.SearchFilter{},
+	})
+}
+
+// filterSearch applies the category, condition and price filters and the sort of the request to found.
+func filterSearch(found []FixtureItem, c *gin.Context) []FixtureItem {
+	min, max := float32(0), float32(-1)
+	if price := strings.SplitN(c.Query(items.SearchFilterPrice), "-", 2); len(price) == 2 {
+		if v, err := strconv.ParseFloat(price[0], 32); err == nil {
+			min = float32(v)
+		}
+		if v, err := strconv.ParseFloat(price[1], 32); err == nil {
+			max = float32(v)
+		}
+	}
+
+	result := make([]FixtureItem, 0, len(found))
+	for _, i := range found {
+		if category := c.Query(items.SearchFilterCategory); category != "" && i.CategoryId != category {
+			continue
+		}
+		if condition := c.Query(items.SearchFilterCondition); condition != "" && i.Condition != condition {
+			continue
+		}
+		if i.Price < min || (max >= 0 && i.Price > max) {
+			continue
+		}
+		result = append(result, i)
+	}
+
+	switch c.Query("sort") {
+	case items.SearchSortPriceAsc:
+		sort.SliceStable(result, func(i, j int) bool { return result[i].Price < result[j].Price })
+	case items.SearchSortPriceDesc:
+		sort.SliceStable(result, func(i, j int) bool { return result[i].Price > result[j].Price })
+	}
+	return result
+}
+
+func appliedFilters(c *gin.Context) []items.SearchFilter {
+	filters := make([]items.SearchFilter, 0)
+	for _, id := range []string{items.SearchFilterCategory, items.SearchFilterCondition, items.SearchFilterPrice} {
+		if value := c.Query(id); value != "" {
+			filters = append(filters, items.SearchFilter{Id: id, Name: id, Values: []items.SearchFilterValue{{Id: value, Name: value}}})
+		}
+	}
+	return filters
+}
+
+func availableSorts(sortId string) []items.Sort {
+	result := make([]items.Sort, 0, len(items.SearchSorts)-1)
+	for _, id := range items.SearchSorts {
+		if id != sortId {
+			result = append(result, searchSorts[id])
+		}
+	}
+	return result
+}
+
+func (h *handler) getMyUser(c *gin.Context) {
+	token := strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer ")
+	user, ok := h.state.GetUserByAccessToken(token)
+	if !ok {
+		err := apierrors.NewApiError("invalid access token", "unauthorized", http.StatusUnauthorized, apierrors.CauseList{})
+		c.JSON(err.Status(), err)
+		return
+	}
+
+	c.JSON(http.StatusOK, user.User)
+}
+
+func (h *handler) getUser(c *gin.Context) {
+	userId, err := strconv.ParseInt(c.Param("user_id"), 10, 64)
+	if err != nil {
+		badRequest(c, "invalid user_id")
+		return
+	}
+
+	user, ok := h.state.GetUser(userId)
+	if !ok {
+		notFound(c, "user not found")
+		return
+	}
+
+	c.JSON(http.StatusOK, user.User)
+}
+
+func (h *handler) createToken(c *gin.Context) {
+	var request auth.MeliAuthRequest
+	if err := c.ShouldBind(&request); err != nil {
+		badRequest(c, "invalid oauth request")
+		return
+	}
+
+	var user *FixtureUser
+	var ok bool
+	switch request.GrantType {
+	case auth.GrantTypeAuthorizationCode:
+		user, ok = h.state.ExchangeCode(request.Code)
+	case auth.GrantTypeRefreshToken:
+		user, ok = h.state.RefreshTokens(request.RefreshToken)
+	default:
+		badRequest(c, "unsupported grant_type")
+		return
+	}
+
+	if !ok {
+		err := apierrors.NewApiError("invalid_grant", "invalid_grant", http.StatusBadRequest, apierrors.CauseList{})
+		c.JSON(err.Status(), err)
+		return
+	}
+
+	c.JSON(http.StatusOK, auth.MeliAuthResponse{
+		AccessToken:  user.AccessToken,
+		TokenType:    "bearer",
+		ExpiresIn:    21600,
+		Scope:        "offline_access read write",
+		UserId:       user.Id,
+		RefreshToken: user.RefreshToken,
+	})
+}
+
+func (h *handler) getItemPage(c *gin.Context) {
+	item, ok := h.state.GetItem(c.Param("item_id"))
+	if !ok {
+		notFound(c, fmt.Sprintf("Item with id %s not found.", c.Param("item_id")))
+		return
+	}
+
+	c.Data(http.StatusOK, "text/html; charset=utf-8",
+		[]byte(fmt.Sprintf(vipPage, item.Title, item.Id, item.AvailableQuantity, item.Price)))
+}
+
+func (h *handler) setPrice(c *gin.Context) {
+	h.change(c, func(itemId string, change stateChange) error {
+		return h.state.SetPrice(itemId, change.Price)
+	})
+}
+
+func (h *handler) startDeal(c *gin.Context) {
+	h.change(c, func(itemId string, change stateChange) error {
+		return h.state.StartDeal(itemId, change.Price)
+	})
+}
+
+func (h *handler) endDeal(c *gin.Context) {
+	h.change(c, func(itemId string, change stateChange) error {
+		return h.state.EndDeal(itemId)
+	})
+}
+
+func (h *handler) setStock(c *gin.Context) {
+	h.change(c, func(itemId string, change stateChange) error {
+		if change.Quantity == nil {
+			return fmt.Errorf("quantity is mandatory")
+		}
+		return h.state.SetStock(itemId, *change.Quantity)
+	})
+}
+
+func (h *handler) setStatus(c *gin.Context) {
+	h.change(c, func(itemId string, change stateChange) error {
+		return h.state.SetStatus(itemId, change.Status)
+	})
+}
+
+func (h *handler) addReview(c *gin.Context) {
+	var review items.Review
+	if err := c.ShouldBindJSON(&review); err != nil {
+		badRequest(c, "invalid review body")
+		return
+	}
+
+	if err := h.state.AddReview(c.Param("item_id"), review); err != nil {
+		notFound(c, err.Error())
+		return
+	}
+
+	h.getItemReviews(c)
+}
+
+func (h *handler) change(c *gin.Context, f func(itemId string, change stateChange) error) {
+	var change stateChange
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&change); err != nil {
+			badRequest(c, "invalid state change body")
+			return
+		}
+	}
+
+	itemId := c.Param("item_id")
+	if _, ok := h.state.GetItem(itemId); !ok {
+		notFound(c, fmt.Sprintf("Item with id %s not found.", itemId))
+		return
+	}
+
+	if err := f(itemId, change); err != nil {
+		badRequest(c, err.Error())
+		return
+	}
+
+	h.getItem(c)
+}
+
+// servedItem fills the fields Mercado Libre computes, pointing the permalink to the simulated item page.
+func (h *handler) servedItem(c *gin.Context, item *FixtureItem) items.Item {
+	result := item.Item
+	result.Description = ""
+	result.Permalink = fmt.Sprintf("http://%s"+permalinkUri, c.Request.Host, item.Id)
+	if result.SiteId == "" {
+		result.SiteId = sites.SiteIdFromId(item.Id)
+	}
+	if site, ok := sites.GetSite(result.SiteId); ok && result.CurrencyId == "" {
+		result.CurrencyId = site.CurrencyId
+	}
+	if result.SubStatus == nil {
+		result.SubStatus = []string{}
+	}
+	if result.DealIds == nil {
+		result.DealIds = []string{}
+	}
+	return result
+}
+
+// project returns only the given fields of the item, or the whole item when there are none.
+func project(item items.Item, attributes []string) interface{} {
+	if len(attributes) == 0 {
+		return item
+	}
+
+	var fields map[string]interface{}
+	encoded, _ := json.Marshal(item)
+	_ = json.Unmarshal(encoded, &fields)
+
+	result := make(map[string]interface{}, len(attributes))
+	for _, a := range attributes {
+		if v, ok := fields[a]; ok {
+			result[a] = v
+		}
+	}
+	return result
+}
+
+func notFound(c *gin.Context, message string) {
+	err := apierrors.NewNotFoundApiError(message)
+	c.JSON(err.Status(), err)
+}
+
+func badRequest(c *gin.Context, message string) {
+	err := apierrors.NewBadRequestApiError(message)
+	c.JSON(err.Status(), err)
+}
@@ -0,0 +1,199 @@
+package simulator
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lmurature/melist-api/src/api/domain/auth"
+	"github.com/lmurature/melist-api/src/api/domain/items"
+	"github.com/lmurature/melist-api/src/api/domain/users"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestServer(t *testing.T) (*httptest.Server, *State) {
+	gin.SetMode(gin.TestMode)
+	fixtures, err := LoadFixtures("fixtures")
+	assert.Nil(t, err)
+	state := NewState(fixtures)
+	return httptest.NewServer(NewServer(state)), state
+}
+
+func getJson(t *testing.T, url string, header http.Header, target interface{}) int {
+	req, _ := http.NewRequest(http.MethodGet, url, nil)
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	resp, err := http.DefaultClient.Do(req)
+	assert.Nil(t, err)
+	defer resp.Body.Close()
+	if target != nil {
+		assert.Nil(t, json.NewDecoder(resp.Body).Decode(target))
+	}
+	return resp.StatusCode
+}
+
+func postJson(t *testing.T, url string, body interface{}, target interface{}) int {
+	data, _ := json.Marshal(body)
+	resp, err := http.Post(url, "application/json", bytes.NewReader(data))
+	assert.Nil(t, err)
+	defer resp.Body.Close()
+	if target != nil {
+		assert.Nil(t, json.NewDecoder(resp.Body).Decode(target))
+	}
+	return resp.StatusCode
+}
+
+func TestGetItemAndPermalink(t *testing.T) {
+	server, _ := newTestServer(t)
+	defer server.Close()
+
+	var item items.Item
+	status := getJson(t, server.URL+"/items/MLA900000001", nil, &item)
+	assert.EqualValues(t, http.StatusOK, status)
+	assert.EqualValues(t, "Celular Motorola Moto G20 64gb 4gb Ram Azul", item.Title)
+	assert.EqualValues(t, 2, len(item.Variations))
+	assert.True(t, strings.HasPrefix(item.Permalink, server.URL))
+
+	resp, err := http.Get(item.Permalink)
+	assert.Nil(t, err)
+	defer resp.Body.Close()
+	page := new(bytes.Buffer)
+	page.ReadFrom(resp.Body)
+	assert.Contains(t, page.String(), `"availableStock":48,`)
+
+	status = getJson(t, server.URL+"/items/MLA1", nil, nil)
+	assert.EqualValues(t, http.StatusNotFound, status)
+}
+
+func TestMultigetItems(t *testing.T) {
+	server, _ := newTestServer(t)
+	defer server.Close()
+
+	var entries []map[string]interface{}
+	status := getJson(t, server.URL+"/items?ids=MLA900000001,MLA1&attributes=id,title", nil, &entries)
+	assert.EqualValues(t, http.StatusOK, status)
+	assert.EqualValues(t, 2, len(entries))
+	assert.EqualValues(t, 200, entries[0]["code"])
+	assert.EqualValues(t, map[string]interface{}{"id": "MLA900000001", "title": "Celular Motorola Moto G20 64gb 4gb Ram Azul"}, entries[0]["body"])
+	assert.EqualValues(t, 404, entries[1]["code"])
+
+	status = getJson(t, server.URL+"/items", nil, nil)
+	assert.EqualValues(t, http.StatusBadRequest, status)
+}
+
+func TestSearchPaging(t *testing.T) {
+	server, _ := newTestServer(t)
+	defer server.Close()
+
+	var result items.ItemSearchResponse
+	status := getJson(t, server.URL+"/sites/MLA/search?q=&offset=1&limit=1", nil, &result)
+	assert.EqualValues(t, http.StatusOK, status)
+	assert.EqualValues(t, 3, result.Paging.Total)
+	assert.EqualValues(t, 1, len(result.Result))
+	assert.EqualValues(t, "MLA900000002", result.Result[0].Id)
+
+	status = getJson(t, server.URL+"/sites/MLB/search?q=jbl", nil, &result)
+	assert.EqualValues(t, http.StatusOK, status)
+	assert.EqualValues(t, 1, result.Paging.Total)
+	assert.EqualValues(t, "MLB900000004", result.Result[0].Id)
+}
+
+func TestSearchFiltersAndSort(t *testing.T) {
+	server, _ := newTestServer(t)
+	defer server.Close()
+
+	var result items.ItemSearchResponse
+	status := getJson(t, server.URL+"/sites/MLA/search?q=&sort=price_desc&price=1000-100000", nil, &result)
+	assert.EqualValues(t, http.StatusOK, status)
+	assert.EqualValues(t, 2, result.Paging.Total)
+	assert.EqualValues(t, "MLA900000001", result.Result[0].Id)
+	assert.EqualValues(t, "MLA900000003", result.Result[1].Id)
+	assert.EqualValues(t, "price_desc", result.Sort.Id)
+	assert.EqualValues(t, 1, len(result.Filters))
+	assert.EqualValues(t, "price", result.Filters[0].Id)
+
+	status = getJson(t, server.URL+"/sites/MLA/search?q=&category=MLA1652", nil, &result)
+	assert.EqualValues(t, http.StatusOK, status)
+	assert.EqualValues(t, 1, result.Paging.Total)
+	assert.EqualValues(t, "MLA900000002", result.Result[0].Id)
+}
+
+func TestStateChanges(t *testing.T) {
+	server, state := newTestServer(t)
+	defer server.Close()
+
+	var item items.Item
+	status := postJson(t, server.URL+"/_simulator/items/MLA900000002/deal/start", map[string]interface{}{"price": 139999}, &item)
+	assert.EqualValues(t, http.StatusOK, status)
+	assert.True(t, item.HasActiveDeal())
+	assert.EqualValues(t, 154999, item.OriginalPrice)
+
+	status = postJson(t, server.URL+"/_simulator/items/MLA900000002/deal/end", nil, &item)
+	assert.EqualValues(t, http.StatusOK, status)
+	assert.False(t, item.HasActiveDeal())
+	assert.EqualValues(t, 154999, item.Price)
+
+	status = postJson(t, server.URL+"/_simulator/items/MLA900000003/stock", map[string]interface{}{"quantity": 0}, &item)
+	assert.EqualValues(t, http.StatusOK, status)
+	assert.EqualValues(t, 0, item.AvailableQuantity)
+	assert.EqualValues(t, "paused", item.Status)
+
+	scenario := &Scenario{Steps: []Step{{After: "1ms", Action: ActionSetPrice, ItemId: "MLA900000001", Price: 100}}}
+	defer scenario.Play(state)()
+	time.Sleep(50 * time.Millisecond)
+
+	changed, _ := state.GetItem("MLA900000001")
+	assert.EqualValues(t, 100, changed.Price)
+}
+
+func TestResetDiscardsAddedReviews(t *testing.T) {
+	fixtures, err := LoadFixtures("fixtures")
+	assert.Nil(t, err)
+	state := NewState(fixtures)
+
+	assert.Nil(t, state.AddReview("MLA900000001", items.Review{ReviewId: 2, Title: "Malo", Rate: 1}))
+	item, _ := state.GetItem("MLA900000001")
+	assert.EqualValues(t, 2, len(item.ItemReviews.Reviews))
+	assert.EqualValues(t, 1, len(fixtures.Items[0].ItemReviews.Reviews), "fixtures are not changed")
+
+	state.Reset(fixtures)
+
+	item, _ = state.GetItem("MLA900000001")
+	assert.EqualValues(t, 1, len(item.ItemReviews.Reviews))
+	assert.EqualValues(t, 1, item.ItemReviews.Reviews[0].ReviewId)
+}
+
+func TestOauthAndUsersMe(t *testing.T) {
+	server, _ := newTestServer(t)
+	defer server.Close()
+
+	var token auth.MeliAuthResponse
+	status := postJson(t, server.URL+"/oauth/token", auth.MeliAuthRequest{
+		GrantType: auth.GrantTypeAuthorizationCode,
+		Code:      "TG-SIM-CODE-LUCIA",
+	}, &token)
+	assert.EqualValues(t, http.StatusOK, status)
+	assert.EqualValues(t, 100000101, token.UserId)
+
+	var me users.User
+	status = getJson(t, server.URL+"/users/me", http.Header{"Authorization": {"Bearer " + token.AccessToken}}, &me)
+	assert.EqualValues(t, http.StatusOK, status)
+	assert.EqualValues(t, "LUCIAGOMEZ", me.Nickname)
+
+	var refreshed auth.MeliAuthResponse
+	status = postJson(t, server.URL+"/oauth/token", auth.MeliAuthRequest{
+		GrantType:    auth.GrantTypeRefreshToken,
+		RefreshToken: token.RefreshToken,
+	}, &refreshed)
+	assert.EqualValues(t, http.StatusOK, status)
+	assert.NotEqual(t, token.AccessToken, refreshed.AccessToken)
+
+	status = getJson(t, server.URL+"/users/me", http.Header{"Authorization": {"Bearer " + token.AccessToken}}, nil)
+	assert.EqualValues(t, http.StatusUnauthorized, status)
+}
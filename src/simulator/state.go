@@ -0,0 +1,239 @@
+package simulator
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/lmurature/melist-api/src/api/domain/items"
+)
+
+const (
+	simulatedDealId = "SIMULATED_DEAL"
+
+	statusActive = "active"
+	statusPaused = "paused"
+)
+
+// State is the mutable marketplace the simulator serves. Every method is safe for concurrent use.
+type State struct {
+	mu         sync.RWMutex
+	items      map[string]FixtureItem
+	categories map[string]items.Category
+	trends     map[string]items.CategoryTrends
+	users      []FixtureUser
+	tokens     int64
+}
+
+func NewState(fixtures *Fixtures) *State {
+	s := &State{}
+	s.Reset(fixtures)
+	return s
+}
+
+// Reset discards every state change and starts over from the given fixtures.
+func (s *State) Reset(fixtures *Fixtures) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.items = make(map[string]FixtureItem)
+	for _, i := range fixtures.Items {
+		s.items[i.Id] = i.clone()
+	}
+
+	s.categories = make(map[string]items.Category)
+	for _, c := range fixtures.Categories {
+		s.categories[c.Id] = c
+	}
+
+	s.trends = make(map[string]items.CategoryTrends)
+	for k, v := range fixtures.Trends {
+		s.trends[k] = v
+	}
+
+	s.users = make([]FixtureUser, len(fixtures.Users))
+	copy(s.users, fixtures.Users)
+}
+
+func (s *State) GetItem(itemId string) (*FixtureItem, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	item, ok := s.items[itemId]
+	if !ok {
+		return nil, false
+	}
+	item = item.clone()
+	return &item, true
+}
+
+func (s *State) GetCategory(categoryId string) (*items.Category, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	category, ok := s.categories[categoryId]
+	return &category, ok
+}
+
+// GetTrends looks trends up by "SITE/CATEGORY" first, falling back to the category id alone.
+func (s *State) GetTrends(siteId string, categoryId string) (items.CategoryTrends, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if trends, ok := s.trends[fmt.Sprintf("%s/%s", siteId, categoryId)]; ok {
+		return trends, true
+	}
+	trends, ok := s.trends[categoryId]
+	return trends, ok
+}
+
+// Search returns the items of the site whose title contains every word of the query, ordered by id.
+func (s *State) Search(siteId string, query string) []FixtureItem {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	words := strings.Fields(strings.ToLower(query))
+	result := make([]FixtureItem, 0)
+	for _, i := range s.items {
+		if !strings.HasPrefix(i.Id, siteId) {
+			continue
+		}
+		title := strings.ToLower(i.Title)
+		matches := true
+		for _, w := range words {
+			if !strings.Contains(title, w) {
+				matches = false
+				break
+			}
+		}
+		if matches {
+			result = append(result, i)
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Id < result[j].Id
+	})
+	return result
+}
+
+func (s *State) GetUser(userId int64) (*FixtureUser, bool) {
+	return s.findUser(func(u FixtureUser) bool { return u.Id == userId })
+}
+
+func (s *State) GetUserByAccessToken(token string) (*FixtureUser, bool) {
+	return s.findUser(func(u FixtureUser) bool { return token != "" && u.AccessToken == token })
+}
+
+// ExchangeCode issues fresh tokens for the user owning the authorization code.
+func (s *State) ExchangeCode(code string) (*FixtureUser, bool) {
+	return s.rotateTokens(func(u FixtureUser) bool { return code != "" && u.AuthorizationCode == code })
+}
+
+// RefreshTokens rotates the tokens of the user owning the refresh token. The old tokens stop working.
+func (s *State) RefreshTokens(refreshToken string) (*FixtureUser, bool) {
+	return s.rotateTokens(func(u FixtureUser) bool { return refreshToken != "" && u.RefreshToken == refreshToken })
+}
+
+// SetPrice changes the current price of an item without starting a deal.
+func (s *State) SetPrice(itemId string, price float32) error {
+	return s.updateItem(itemId, func(i *FixtureItem) {
+		i.Price = price
+	})
+}
+
+// StartDeal discounts the item to price, keeping its current price as the original one.
+func (s *State) StartDeal(itemId string, price float32) error {
+	return s.updateItem(itemId, func(i *FixtureItem) {
+		if !i.HasActiveDeal() {
+			i.OriginalPrice = i.Price
+		}
+		i.Price = price
+		i.DealIds = []string{simulatedDealId}
+	})
+}
+
+// EndDeal restores the original price of an item with an active deal.
+func (s *State) EndDeal(itemId string) error {
+	return s.updateItem(itemId, func(i *FixtureItem) {
+		if i.OriginalPrice > 0 {
+			i.Price = i.OriginalPrice
+		}
+		i.OriginalPrice = 0
+		i.DealIds = nil
+	})
+}
+
+// SetStock changes the available quantity of an item. Running out of stock pauses the item, as Mercado Libre does.
+func (s *State) SetStock(itemId string, quantity int) error {
+	return s.updateItem(itemId, func(i *FixtureItem) {
+		i.AvailableQuantity = quantity
+		if quantity == 0 {
+			i.Status = statusPaused
+		} else if i.Status == statusPaused {
+			i.Status = statusActive
+		}
+	})
+}
+
+func (s *State) SetStatus(itemId string, status string) error {
+	return s.updateItem(itemId, func(i *FixtureItem) {
+		i.Status = status
+	})
+}
+
+// AddReview puts review first in the reviews of the item. Reviews are replaced instead of changed in place, since
+// copies of the item handed out before may still be reading them.
+func (s *State) AddReview(itemId string, review items.Review) error {
+	return s.updateItem(itemId, func(i *FixtureItem) {
+		var reviews items.ItemReviewsResponse
+		if i.ItemReviews != nil {
+			reviews = *i.ItemReviews
+		}
+		reviews.Reviews = append([]items.Review{review}, reviews.Reviews...)
+		reviews.Paging.Total = int64(len(reviews.Reviews))
+		i.ItemReviews = &reviews
+	})
+}
+
+func (s *State) updateItem(itemId string, f func(i *FixtureItem)) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	item, ok := s.items[itemId]
+	if !ok {
+		return fmt.Errorf("item %s not found", itemId)
+	}
+	f(&item)
+	s.items[itemId] = item
+	return nil
+}
+
+func (s *State) findUser(f func(u FixtureUser) bool) (*FixtureUser, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, u := range s.users {
+		if f(u) {
+			return &u, true
+		}
+	}
+	return nil, false
+}
+
+func (s *State) rotateTokens(f func(u FixtureUser) bool) (*FixtureUser, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i := range s.users {
+		if f(s.users[i]) {
+			s.tokens++
+			s.users[i].AccessToken = fmt.Sprintf("APP_USR-SIM-%d-%d", s.users[i].Id, s.tokens)
+			s.users[i].RefreshToken = fmt.Sprintf("TG-SIM-%d-%d", s.users[i].Id, s.tokens)
+			u := s.users[i]
+			return &u, true
+		}
+	}
+	return nil, false
+}